@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/madhava-poojari/dashboard-api/internal/config"
@@ -29,6 +30,12 @@ func main() {
 
 	srv := appServer.NewHTTPServer()
 
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+	if err := appServer.StartWorkers(workersCtx); err != nil {
+		log.Fatalf("start workers: %v", err)
+	}
+
 	// graceful shutdown
 	go func() {
 		log.Printf("listening on %s", cfg.BindAddr)
@@ -38,12 +45,18 @@ func main() {
 	}()
 
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
+	stopWorkers()
 
 	ctxShutdown, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctxShutdown); err != nil {
 		log.Printf("shutdown error: %v", err)
 	}
+	// Running jobs were cancelled with the workers; let them record how far
+	// they got before the pool closes.
+	if err := appServer.WaitWorkers(ctxShutdown); err != nil {
+		log.Printf("jobs still running at shutdown: %v", err)
+	}
 }
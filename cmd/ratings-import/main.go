@@ -0,0 +1,95 @@
+// Command ratings-import loads a FIDE or US Chess rating list file from local
+// disk and records official ratings for students with a matching ID.
+//
+//	ratings-import -federation fide -file players_list_foa.txt -period 2026-10
+//	ratings-import -federation fide -file players_list_xml_foa.xml
+//	ratings-import -federation fide -file rapid_oct25frl.txt -period 2026-10
+//	ratings-import -federation fide -list blitz -file oct25.txt -period 2026-10
+//	ratings-import -federation uscf -file supplement-2026-10.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/config"
+	"github.com/madhava-poojari/dashboard-api/internal/ratinglists"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+func main() {
+	federation := flag.String("federation", "", "rating list federation: fide or uscf")
+	file := flag.String("file", "", "path to the rating list file")
+	periodStr := flag.String("period", time.Now().Format("2006-01"), "list period as YYYY-MM")
+	listStr := flag.String("list", "", "FIDE single-type list: standard, rapid or blitz (default: from the file name)")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+	period, err := time.Parse("2006-01", *periodStr)
+	if err != nil {
+		log.Fatalf("invalid -period %q: %v", *periodStr, err)
+	}
+
+	list := ratinglists.FIDEListFromFileName(*file)
+	if *listStr != "" {
+		if list, err = ratinglists.ParseFIDEList(*listStr); err != nil {
+			log.Fatalf("invalid -list: %v", err)
+		}
+	}
+
+	var parse func(f *os.File, fn ratinglists.HandlerFunc) error
+	switch strings.ToLower(*federation) {
+	case ratinglists.FederationFIDE:
+		if strings.EqualFold(filepath.Ext(*file), ".xml") {
+			parse = func(f *os.File, fn ratinglists.HandlerFunc) error { return ratinglists.ParseFIDEXML(f, list, fn) }
+		} else {
+			parse = func(f *os.File, fn ratinglists.HandlerFunc) error { return ratinglists.ParseFIDETXT(f, list, fn) }
+		}
+	case ratinglists.FederationUSCF:
+		if *listStr != "" {
+			log.Fatal("-list only applies to FIDE lists")
+		}
+		list = ratinglists.ListCombined
+		parse = func(f *os.File, fn ratinglists.HandlerFunc) error { return ratinglists.ParseUSCFSupplement(f, fn) }
+	default:
+		log.Fatalf("-federation must be fide or uscf, got %q", *federation)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	s, err := store.NewGormStore(cfg)
+	if err != nil {
+		log.Fatalf("db connect: %v", err)
+	}
+	defer s.Close()
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("open %s: %v", *file, err)
+	}
+	defer f.Close()
+
+	res, err := service.NewRatingImportService(s).Import(context.Background(), strings.ToLower(*federation), list, period, *file,
+		func(fn ratinglists.HandlerFunc) error { return parse(f, fn) })
+	if err != nil {
+		if res != nil {
+			log.Fatalf("import failed after %d rows: %v", res.Parsed, err)
+		}
+		log.Fatalf("import failed: %v", err)
+	}
+	if res.List != "" {
+		res.Federation += " " + res.List
+	}
+	log.Printf("imported %s list %s from %s: parsed=%d matched=%d flagged=%d not_listed=%d",
+		res.Federation, res.Period, res.SourceFile, res.Parsed, res.Matched, res.Flagged, res.NotListed)
+}
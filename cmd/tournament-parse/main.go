@@ -0,0 +1,55 @@
+// Command tournament-parse runs the server-side tournament page parser on
+// saved HTML files and prints the parsed fields and confidence report. Use it
+// to check parser changes against the fixtures in
+// internal/tournamentparse/testdata.
+//
+//	tournament-parse internal/tournamentparse/testdata/*.html
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/tournamentparse"
+)
+
+func main() {
+	nowStr := flag.String("now", "", "reference date (YYYY-MM-DD) for date sanity checks; default today")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: tournament-parse [-now YYYY-MM-DD] page.html...")
+	}
+	now := time.Now()
+	if *nowStr != "" {
+		t, err := time.Parse("2006-01-02", *nowStr)
+		if err != nil {
+			log.Fatalf("invalid -now: %v", err)
+		}
+		now = t
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	failed := false
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("open %s: %v", path, err)
+		}
+		t, rep, err := tournamentparse.Parse(f, now)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		if !rep.Valid {
+			failed = true
+		}
+		_ = enc.Encode(map[string]interface{}{"file": path, "tournament": t, "report": rep})
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,49 @@
+// Command zip-centroids-import (re)loads the offline ZIP centroid dataset used
+// for tournament distance search and geocodes tournaments missing coordinates.
+//
+//	zip-centroids-import -file data/US.txt
+//	zip-centroids-import -fetch            # download US.zip from GeoNames first
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/madhava-poojari/dashboard-api/internal/config"
+	"github.com/madhava-poojari/dashboard-api/internal/geo"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+func main() {
+	file := flag.String("file", "", "path to the GeoNames postal code file (defaults to ZIP_CENTROIDS_FILE)")
+	fetch := flag.Bool("fetch", false, "download the GeoNames dump to -file before loading it")
+	url := flag.String("url", geo.GeoNamesUSURL, "GeoNames archive to download with -fetch")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	if *file == "" {
+		*file = cfg.ZipCentroidsFile
+	}
+	if *fetch {
+		if err := geo.FetchGeoNames(context.Background(), nil, *url, *file); err != nil {
+			log.Fatalf("fetch %s: %v", *url, err)
+		}
+		log.Printf("downloaded %s to %s", *url, *file)
+	}
+	s, err := store.NewGormStore(cfg)
+	if err != nil {
+		log.Fatalf("db connect: %v", err)
+	}
+	defer s.Close()
+
+	n, err := service.NewGeoService(s).LoadCentroids(context.Background(), *file)
+	if err != nil {
+		log.Fatalf("import failed after %d rows: %v", n, err)
+	}
+	log.Printf("loaded %d zip centroids from %s", n, *file)
+}
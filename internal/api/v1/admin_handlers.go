@@ -5,15 +5,28 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
 	"github.com/madhava-poojari/dashboard-api/internal/utils"
 )
 
 type AdminHandler struct {
-	store serviceStore
+	store  serviceStore
+	events *service.EventBus
 }
 
-func NewAdminHandler(store serviceStore) *AdminHandler {
-	return &AdminHandler{store: store}
+func NewAdminHandler(store serviceStore, events *service.EventBus) *AdminHandler {
+	return &AdminHandler{store: store, events: events}
+}
+
+// publish queues an event on behalf of the requesting admin in tx.
+func (h *AdminHandler) publish(r *http.Request, tx *store.Store, eventType string, userIDs []string, data map[string]interface{}) error {
+	var actor string
+	if current := auth.GetUserFromCtx(r.Context()); current != nil {
+		actor = current.ID
+	}
+	return h.events.Enqueue(r.Context(), tx, service.Event{Type: eventType, ActorID: actor, UserIDs: userIDs, Data: data})
 }
 
 func (h *AdminHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
@@ -54,8 +67,23 @@ func (h *AdminHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request)
 		}
 		userUpdates["password_hash"] = hash
 	}
+	userID := chi.URLParam(r, "id")
+	wasApproved := true
+	if payload.Approved != nil && *payload.Approved {
+		if u, err := h.store.GetUserByID(r.Context(), userID); err == nil {
+			wasApproved = u.Approved
+		}
+	}
 	// Update user status logic here
-	err := h.store.UpdateUserFields(r.Context(), chi.URLParam(r, "id"), userUpdates)
+	err := h.store.WithTx(r.Context(), func(tx *store.Store) error {
+		if err := tx.UpdateUserFields(r.Context(), userID, userUpdates); err != nil {
+			return err
+		}
+		if !wasApproved {
+			return h.publish(r, tx, service.EventUserApproved, []string{userID}, map[string]interface{}{"user_id": userID})
+		}
+		return nil
+	})
 	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "couldnt process the updates ", nil, err)
 		return
@@ -75,6 +103,21 @@ func (h *AdminHandler) GetUnapprovedUsers(w http.ResponseWriter, r *http.Request
 
 // GetStudentsWithAssignments returns all students with their assignment info
 func (h *AdminHandler) GetStudentsWithAssignments(w http.ResponseWriter, r *http.Request) {
+	lq, err := parseListQuery(r, store.StudentAssignmentListSpec)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+		return
+	}
+	if lq.Paged() {
+		page, err := h.store.ListStudentsWithAssignmentsPage(r.Context(), lq)
+		if err != nil {
+			writeListError(w, err, "error fetching students")
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusOK, true, "success", page, nil)
+		return
+	}
+
 	students, err := h.store.ListStudentsWithAssignments(r.Context())
 	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching students", nil, err)
@@ -103,6 +146,21 @@ func (h *AdminHandler) GetMentorsPicker(w http.ResponseWriter, r *http.Request)
 
 // GetCoachesWithAssignments returns all coaches with their assignment info
 func (h *AdminHandler) GetCoachesWithAssignments(w http.ResponseWriter, r *http.Request) {
+	lq, err := parseListQuery(r, store.CoachAssignmentListSpec)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+		return
+	}
+	if lq.Paged() {
+		page, err := h.store.ListCoachesWithAssignmentsPage(r.Context(), lq)
+		if err != nil {
+			writeListError(w, err, "error fetching coaches")
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusOK, true, "success", page, nil)
+		return
+	}
+
 	coaches, err := h.store.ListCoachesWithAssignments(r.Context())
 	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching coaches", nil, err)
@@ -136,7 +194,20 @@ func (h *AdminHandler) UpdateAssignments(w http.ResponseWriter, r *http.Request)
 			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "student_id is required", nil, nil)
 			return
 		}
-		if err := h.store.SetStudentCoachAssignment(r.Context(), payload.StudentID, payload.CoachID); err != nil {
+		err := h.store.WithTx(r.Context(), func(tx *store.Store) error {
+			if err := tx.SetStudentCoachAssignment(r.Context(), payload.StudentID, payload.CoachID); err != nil {
+				return err
+			}
+			if payload.CoachID != "" {
+				if err := h.publish(r, tx, service.EventCoachAssigned, []string{payload.StudentID, payload.CoachID},
+					map[string]interface{}{"student_id": payload.StudentID, "coach_id": payload.CoachID}); err != nil {
+					return err
+				}
+			}
+			return h.publish(r, tx, service.EventUserAssigned, nil, map[string]interface{}{
+				"assignment_type": payload.AssignmentType, "student_id": payload.StudentID, "coach_id": payload.CoachID})
+		})
+		if err != nil {
 			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error updating student assignment", nil, err)
 			return
 		}
@@ -148,7 +219,20 @@ func (h *AdminHandler) UpdateAssignments(w http.ResponseWriter, r *http.Request)
 			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "coach_id is required", nil, nil)
 			return
 		}
-		if err := h.store.SetCoachMentorAssignment(r.Context(), payload.CoachID, payload.MentorCoachID); err != nil {
+		err := h.store.WithTx(r.Context(), func(tx *store.Store) error {
+			if err := tx.SetCoachMentorAssignment(r.Context(), payload.CoachID, payload.MentorCoachID); err != nil {
+				return err
+			}
+			if payload.MentorCoachID != "" {
+				if err := h.publish(r, tx, service.EventMentorAssigned, []string{payload.CoachID},
+					map[string]interface{}{"coach_id": payload.CoachID, "mentor_id": payload.MentorCoachID}); err != nil {
+					return err
+				}
+			}
+			return h.publish(r, tx, service.EventUserAssigned, nil, map[string]interface{}{
+				"assignment_type": payload.AssignmentType, "coach_id": payload.CoachID, "mentor_id": payload.MentorCoachID})
+		})
+		if err != nil {
 			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error updating mentor assignment", nil, err)
 			return
 		}
@@ -160,7 +244,20 @@ func (h *AdminHandler) UpdateAssignments(w http.ResponseWriter, r *http.Request)
 			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "student_id is required", nil, nil)
 			return
 		}
-		if err := h.store.SetStudentMentor(r.Context(), payload.StudentID, payload.MentorID); err != nil {
+		err := h.store.WithTx(r.Context(), func(tx *store.Store) error {
+			if err := tx.SetStudentMentor(r.Context(), payload.StudentID, payload.MentorID); err != nil {
+				return err
+			}
+			if payload.MentorID != "" {
+				if err := h.publish(r, tx, service.EventMentorAssigned, []string{payload.StudentID},
+					map[string]interface{}{"student_id": payload.StudentID, "mentor_id": payload.MentorID}); err != nil {
+					return err
+				}
+			}
+			return h.publish(r, tx, service.EventUserAssigned, nil, map[string]interface{}{
+				"assignment_type": payload.AssignmentType, "student_id": payload.StudentID, "mentor_id": payload.MentorID})
+		})
+		if err != nil {
 			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error updating mentor assignment", nil, err)
 			return
 		}
@@ -0,0 +1,219 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// APIKeyHandler is the admin CRUD for machine-client API keys.
+type APIKeyHandler struct {
+	store *store.Store
+}
+
+func NewAPIKeyHandler(s serviceStore) *APIKeyHandler {
+	return &APIKeyHandler{store: s.Store}
+}
+
+type apiKeyRequest struct {
+	Name      *string    `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// apiKeyCreatedResponse carries the plaintext key, which is only ever shown once.
+type apiKeyCreatedResponse struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+func validateScopes(scopes []string) (string, bool) {
+	if len(scopes) == 0 {
+		return "at least one scope is required", false
+	}
+	for _, s := range scopes {
+		if !auth.ValidAPIKeyScope(s) {
+			return "unknown scope " + s + " (allowed: " + strings.Join(auth.APIKeyScopes, ", ") + ")", false
+		}
+	}
+	return "", true
+}
+
+func apiKeyIDFromURL(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GET /admin/api-keys
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.store.ListAPIKeys(r.Context())
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching api keys", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", keys, nil)
+}
+
+// POST /admin/api-keys - returns the plaintext key once; only its hash is stored.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	var req apiKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid body", nil, err.Error())
+		return
+	}
+	if req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "name is required", nil, nil)
+		return
+	}
+	if msg, ok := validateScopes(req.Scopes); !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, msg, nil, nil)
+		return
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "expires_at must be in the future", nil, nil)
+		return
+	}
+
+	prefix, plain := auth.GenerateAPIKey()
+	k := &models.APIKey{
+		Name:      strings.TrimSpace(*req.Name),
+		Prefix:    prefix,
+		Scopes:    utils.DatatypesJSONFromStrings(req.Scopes),
+		CreatedBy: current.ID,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := h.store.CreateAPIKey(ctx, k, plain); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error creating api key", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "api key created; store it now, it won't be shown again", apiKeyCreatedResponse{APIKey: k, Key: plain}, nil)
+}
+
+// PATCH /admin/api-keys/{id} - name, scopes, expires_at
+func (h *APIKeyHandler) UpdateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiKeyIDFromURL(w, r)
+	if !ok {
+		return
+	}
+	var req apiKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid body", nil, err.Error())
+		return
+	}
+	fields := map[string]interface{}{}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "name cannot be empty", nil, nil)
+			return
+		}
+		fields["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Scopes != nil {
+		if msg, ok := validateScopes(req.Scopes); !ok {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, msg, nil, nil)
+			return
+		}
+		fields["scopes"] = utils.DatatypesJSONFromStrings(req.Scopes)
+	}
+	if req.ExpiresAt != nil {
+		fields["expires_at"] = *req.ExpiresAt
+	}
+	if len(fields) == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "nothing to update", nil, nil)
+		return
+	}
+
+	k, err := h.store.UpdateAPIKey(r.Context(), id, fields)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "api key not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error updating api key", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "api key updated", k, nil)
+}
+
+// POST /admin/api-keys/{id}/rotate - issue a new secret for the same key.
+// The old secret keeps working for API_KEY_ROTATION_GRACE_MINUTES, or for
+// {"grace_minutes": n} when given (0 cuts it off at once).
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiKeyIDFromURL(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		GraceMinutes *int `json:"grace_minutes"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid body", nil, err.Error())
+			return
+		}
+	}
+	grace := h.store.Cfg.APIKeyRotationGrace
+	if req.GraceMinutes != nil {
+		if *req.GraceMinutes < 0 {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "grace_minutes cannot be negative", nil, nil)
+			return
+		}
+		grace = time.Duration(*req.GraceMinutes) * time.Minute
+	}
+	existing, err := h.store.GetAPIKey(r.Context(), id)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "api key not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching api key", nil, err.Error())
+		return
+	}
+	if existing.RevokedAt != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "api key is revoked", nil, nil)
+		return
+	}
+
+	prefix, plain := auth.GenerateAPIKey()
+	k, err := h.store.RotateAPIKey(r.Context(), id, prefix, plain, grace)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error rotating api key", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "api key rotated; store it now, it won't be shown again", apiKeyCreatedResponse{APIKey: k, Key: plain}, nil)
+}
+
+// DELETE /admin/api-keys/{id} - revoke
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiKeyIDFromURL(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.RevokeAPIKey(r.Context(), id); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "api key not found or already revoked", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error revoking api key", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "api key revoked", nil, nil)
+}
@@ -0,0 +1,447 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// AssessmentHandler serves rubric-scored assessments of students and coaches.
+type AssessmentHandler struct {
+	store *store.Store
+}
+
+func NewAssessmentHandler(s serviceStore) *AssessmentHandler {
+	return &AssessmentHandler{store: s.Store}
+}
+
+func writeAssessmentError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case store.IsNotFound(err):
+		utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+	case errors.Is(err, store.ErrInvalidAssessment):
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+	default:
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, msg, nil, err.Error())
+	}
+}
+
+// canAuthorAssessment keeps the authoring rules of the old assessment note
+// tags: student assessments by the student's coach or mentor, coach
+// assessments by the coach's mentor; admins may author both.
+func (h *AssessmentHandler) canAuthorAssessment(ctx context.Context, current *models.User, typ models.AssessmentType, subjectID string) bool {
+	if !store.TagAllowedForRole(string(typ), current.Role) {
+		return false
+	}
+	if current.Role == models.RoleAdmin {
+		return true
+	}
+	if typ == models.AssessmentTypeCoach {
+		ok, err := h.store.IsMentorOfCoach(ctx, current.ID, subjectID)
+		return err == nil && ok
+	}
+	return CanManageStudent(ctx, h.store, current, subjectID)
+}
+
+// canViewAssessments: students and coaches see their own; otherwise the
+// same people who may author them.
+func (h *AssessmentHandler) canViewAssessments(ctx context.Context, current *models.User, subjectID string) bool {
+	if current.ID == subjectID || current.Role == models.RoleAdmin {
+		return true
+	}
+	if ok, err := h.store.IsMentorOfCoach(ctx, current.ID, subjectID); err == nil && ok {
+		return true
+	}
+	return CanManageStudent(ctx, h.store, current, subjectID)
+}
+
+// parseDateRange reads optional from/to query dates (YYYY-MM-DD or RFC3339).
+func parseDateRange(r *http.Request) (from, to *time.Time, err error) {
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, perr := parseDateFlexible(v)
+		if perr != nil {
+			return nil, nil, errors.New("invalid from date")
+		}
+		from = &t
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, perr := parseDateFlexible(v)
+		if perr != nil {
+			return nil, nil, errors.New("invalid to date")
+		}
+		to = &t
+	}
+	return from, to, nil
+}
+
+// GET /assessments/rubrics?type=&include_inactive=
+func (h *AssessmentHandler) ListRubrics(w http.ResponseWriter, r *http.Request) {
+	typ := models.AssessmentType(r.URL.Query().Get("type"))
+	if typ != "" && !store.ValidAssessmentType(typ) {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid type", nil, nil)
+		return
+	}
+	includeInactive, _ := strconv.ParseBool(r.URL.Query().Get("include_inactive"))
+	rubrics, err := h.store.ListRubrics(r.Context(), typ, includeInactive)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching rubrics", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", rubrics, nil)
+}
+
+// POST /assessments/rubrics (admin)
+func (h *AssessmentHandler) CreateRubric(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name        string                `json:"name"`
+		Type        models.AssessmentType `json:"type"`
+		Description string                `json:"description"`
+		MinScore    *int                  `json:"min_score"`
+		MaxScore    *int                  `json:"max_score"`
+		Criteria    []struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		} `json:"criteria"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "name is required", nil, nil)
+		return
+	}
+	current := auth.GetUserFromCtx(r.Context())
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	rubric := &models.Rubric{
+		Name:        req.Name,
+		Type:        req.Type,
+		Description: req.Description,
+		MinScore:    1,
+		MaxScore:    5,
+		Active:      true,
+		CreatedBy:   current.ID,
+	}
+	if req.MinScore != nil {
+		rubric.MinScore = *req.MinScore
+	}
+	if req.MaxScore != nil {
+		rubric.MaxScore = *req.MaxScore
+	}
+	for _, c := range req.Criteria {
+		if c.Name == "" {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "criterion name is required", nil, nil)
+			return
+		}
+		rubric.Criteria = append(rubric.Criteria, models.RubricCriterion{Name: c.Name, Description: c.Description})
+	}
+	if err := h.store.CreateRubric(r.Context(), rubric); err != nil {
+		writeAssessmentError(w, err, "create rubric failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "rubric created", rubric, nil)
+}
+
+// PATCH /assessments/rubrics/{id} (admin) - name, description, active
+func (h *AssessmentHandler) UpdateRubric(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	var req struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		Active      *bool   `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	updates := map[string]interface{}{}
+	if req.Name != nil && *req.Name != "" {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if len(updates) == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "nothing to update", nil, nil)
+		return
+	}
+	if err := h.store.UpdateRubric(r.Context(), id, updates); err != nil {
+		writeAssessmentError(w, err, "update rubric failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "rubric updated", nil, nil)
+}
+
+// POST /assessments/rubrics/{id}/criteria (admin)
+func (h *AssessmentHandler) AddCriterion(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "name is required", nil, nil)
+		return
+	}
+	c := &models.RubricCriterion{RubricID: id, Name: req.Name, Description: req.Description}
+	if err := h.store.AddRubricCriterion(r.Context(), c); err != nil {
+		writeAssessmentError(w, err, "create criterion failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "criterion created", c, nil)
+}
+
+// POST /assessments
+func (h *AssessmentHandler) CreateAssessment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RubricID   uint   `json:"rubric_id"`
+		SubjectID  string `json:"subject_id"`
+		AssessedOn string `json:"assessed_on"`
+		Comment    string `json:"comment"`
+		Scores     []struct {
+			CriterionID uint   `json:"criterion_id"`
+			Score       int    `json:"score"`
+			Comment     string `json:"comment"`
+		} `json:"scores"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	if req.RubricID == 0 || req.SubjectID == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "rubric_id and subject_id are required", nil, nil)
+		return
+	}
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	rubric, err := h.store.GetRubric(ctx, req.RubricID)
+	if err != nil {
+		writeAssessmentError(w, err, "error fetching rubric")
+		return
+	}
+	if !h.canAuthorAssessment(ctx, current, rubric.Type, req.SubjectID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "not allowed to create "+string(rubric.Type), nil, nil)
+		return
+	}
+
+	a := &models.Assessment{
+		RubricID:   rubric.ID,
+		Type:       rubric.Type,
+		SubjectID:  req.SubjectID,
+		AssessorID: current.ID,
+		AssessedOn: time.Now().Truncate(24 * time.Hour),
+		Comment:    req.Comment,
+	}
+	if req.AssessedOn != "" {
+		t, err := parseDateFlexible(req.AssessedOn)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid assessed_on", nil, nil)
+			return
+		}
+		a.AssessedOn = t
+	}
+	for _, sc := range req.Scores {
+		a.Scores = append(a.Scores, models.AssessmentScore{CriterionID: sc.CriterionID, Score: sc.Score, Comment: sc.Comment})
+	}
+	if err := h.store.CreateAssessment(ctx, a); err != nil {
+		writeAssessmentError(w, err, "create assessment failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "assessment created", a, nil)
+}
+
+// GET /assessments?subject_id=&rubric_id=&from=&to=
+func (h *AssessmentHandler) ListAssessments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	f, ok := h.subjectFilter(w, r, current)
+	if !ok {
+		return
+	}
+	list, err := h.store.ListAssessments(ctx, f)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching assessments", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", list, nil)
+}
+
+// subjectFilter builds the filter of a per-subject request and checks that
+// current may see the subject's assessments, writing the error response.
+func (h *AssessmentHandler) subjectFilter(w http.ResponseWriter, r *http.Request, current *models.User) (store.AssessmentFilter, bool) {
+	q := r.URL.Query()
+	f := store.AssessmentFilter{SubjectID: q.Get("subject_id")}
+	if f.SubjectID == "" {
+		f.SubjectID = current.ID
+	}
+	if v := q.Get("rubric_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid rubric_id", nil, nil)
+			return f, false
+		}
+		f.RubricID = uint(id)
+	}
+	var err error
+	if f.From, f.To, err = parseDateRange(r); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+		return f, false
+	}
+	if !h.canViewAssessments(r.Context(), current, f.SubjectID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return f, false
+	}
+	return f, true
+}
+
+// DELETE /assessments/{id} - the assessor or an admin
+func (h *AssessmentHandler) DeleteAssessment(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	a, err := h.store.GetAssessment(ctx, id)
+	if err != nil {
+		writeAssessmentError(w, err, "error fetching assessment")
+		return
+	}
+	if current.Role != models.RoleAdmin && current.ID != a.AssessorID {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	if err := h.store.DeleteAssessment(ctx, id); err != nil {
+		writeAssessmentError(w, err, "delete assessment failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "assessment deleted", nil, nil)
+}
+
+// GET /assessments/trends?subject_id=&rubric_id=&from=&to=
+func (h *AssessmentHandler) GetTrends(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	f, ok := h.subjectFilter(w, r, current)
+	if !ok {
+		return
+	}
+	if f.RubricID == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "rubric_id is required", nil, nil)
+		return
+	}
+	trends, err := h.store.GetAssessmentTrends(ctx, f)
+	if err != nil {
+		writeAssessmentError(w, err, "error fetching trends")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", trends, nil)
+}
+
+// GET /assessments/cohort?rubric_id=&coach_id=&from=&to= - latest scores of
+// a cohort side by side with its averages. Student rubrics compare the
+// students of coach_id (or the requester's own students); coach rubrics the
+// coaches a mentor mentors. Admins without coach_id see everyone.
+func (h *AssessmentHandler) GetCohort(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	rubricID, err := strconv.ParseUint(r.URL.Query().Get("rubric_id"), 10, 64)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "rubric_id is required", nil, nil)
+		return
+	}
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+		return
+	}
+	rubric, err := h.store.GetRubric(ctx, uint(rubricID))
+	if err != nil {
+		writeAssessmentError(w, err, "error fetching rubric")
+		return
+	}
+
+	var users []*models.User
+	scoped := true // false: every assessed subject
+	coachID := r.URL.Query().Get("coach_id")
+	switch {
+	case rubric.Type == models.AssessmentTypeCoach && current.Role == models.RoleAdmin:
+		scoped = false
+	case rubric.Type == models.AssessmentTypeCoach && current.Role == models.RoleMentor:
+		users, err = h.store.ListCoachesForMentor(ctx, current.ID)
+	case rubric.Type == models.AssessmentTypeCoach:
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	case coachID != "":
+		if current.Role != models.RoleAdmin && coachID != current.ID {
+			if ok, merr := h.store.IsMentorOfCoach(ctx, current.ID, coachID); merr != nil || !ok {
+				utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+				return
+			}
+		}
+		users, err = h.store.ListStudentsForCoachOrMentor(ctx, coachID)
+	case current.Role == models.RoleAdmin:
+		scoped = false
+	default:
+		users, err = h.store.ListStudentsForCoachOrMentor(ctx, current.ID)
+	}
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching cohort", nil, err.Error())
+		return
+	}
+	var subjects []string
+	if scoped {
+		subjects = make([]string, 0, len(users))
+		for _, u := range users {
+			if u.ID != current.ID {
+				subjects = append(subjects, u.ID)
+			}
+		}
+	}
+
+	cohort, err := h.store.CompareCohort(ctx, rubric.ID, subjects, from, to)
+	if err != nil {
+		writeAssessmentError(w, err, "error comparing cohort")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", cohort, nil)
+}
@@ -5,21 +5,24 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/madhava-poojari/dashboard-api/internal/auth"
 	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
 	"github.com/madhava-poojari/dashboard-api/internal/store"
 	"github.com/madhava-poojari/dashboard-api/internal/utils"
 )
 
 type AttendanceHandler struct {
-	store *store.Store
+	store  *store.Store
+	events *service.EventBus
 }
 
-func NewAttendanceHandler(s serviceStore) *AttendanceHandler {
-	return &AttendanceHandler{store: s.Store}
+func NewAttendanceHandler(s serviceStore, events *service.EventBus) *AttendanceHandler {
+	return &AttendanceHandler{store: s.Store, events: events}
 }
 
 func parseDateFlexible(s string) (time.Time, error) {
@@ -162,24 +165,44 @@ func (h *AttendanceHandler) CreateAttendance(w http.ResponseWriter, r *http.Requ
 	}
 
 	created := []*models.Attendance{}
-	for _, sid := range studentIDs {
-		a := &models.Attendance{
-			StudentID:       sid,
-			CoachID:         coachID,
-			ClassType:       req.ClassType,
-			Date:            date,
-			SessionID:       req.SessionID,
-			IsVerified:      false, // always default false on create
-			ClassHighlights: req.ClassHighlights,
-			Homework:        req.Homework,
-			CreatedAt:       time.Now(),
-			UpdatedAt:       time.Now(),
-		}
-		if err := h.store.CreateAttendance(ctx, a); err != nil {
-			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "create attendance failed", nil, err.Error())
-			return
+	err = h.store.WithTx(ctx, func(tx *store.Store) error {
+		for _, sid := range studentIDs {
+			a := &models.Attendance{
+				StudentID:       sid,
+				CoachID:         coachID,
+				ClassType:       req.ClassType,
+				Date:            date,
+				SessionID:       req.SessionID,
+				IsVerified:      false, // always default false on create
+				ClassHighlights: req.ClassHighlights,
+				Homework:        req.Homework,
+				CreatedAt:       time.Now(),
+				UpdatedAt:       time.Now(),
+			}
+			if err := tx.CreateAttendance(ctx, a); err != nil {
+				return err
+			}
+			err := h.events.Enqueue(ctx, tx, service.Event{
+				Type:    service.EventAttendanceCreated,
+				ActorID: current.ID,
+				Data: map[string]interface{}{
+					"attendance_id": a.ID,
+					"student_id":    a.StudentID,
+					"coach_id":      a.CoachID,
+					"class_type":    string(a.ClassType),
+					"date":          a.Date.Format("2006-01-02"),
+				},
+			})
+			if err != nil {
+				return err
+			}
+			created = append(created, a)
 		}
-		created = append(created, a)
+		return nil
+	})
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "create attendance failed", nil, err.Error())
+		return
 	}
 	if len(created) == 1 {
 		utils.WriteJSONResponse(w, http.StatusCreated, true, "created", created[0], nil)
@@ -198,24 +221,33 @@ func (h *AttendanceHandler) ListAttendances(w http.ResponseWriter, r *http.Reque
 	}
 
 	q := r.URL.Query()
-	monthStr := q.Get("month")
-	yearStr := q.Get("year")
-	if monthStr == "" || yearStr == "" {
-		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "month and year are required", nil, nil)
-		return
-	}
-	month, err := strconv.Atoi(monthStr)
-	if err != nil || month < 1 || month > 12 {
-		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid month", nil, nil)
+	lq, err := parseListQuery(r, store.AttendanceListSpec)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
 		return
 	}
-	year, err := strconv.Atoi(yearStr)
-	if err != nil || year < 1970 || year > 2100 {
-		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid year", nil, nil)
-		return
+	// month/year scope the full list; pages may span all dates
+	monthStr := q.Get("month")
+	yearStr := q.Get("year")
+	var start, end time.Time
+	if monthStr != "" || yearStr != "" || !lq.Paged() {
+		if monthStr == "" || yearStr == "" {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "month and year are required", nil, nil)
+			return
+		}
+		month, err := strconv.Atoi(monthStr)
+		if err != nil || month < 1 || month > 12 {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid month", nil, nil)
+			return
+		}
+		year, err := strconv.Atoi(yearStr)
+		if err != nil || year < 1970 || year > 2100 {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid year", nil, nil)
+			return
+		}
+		start = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, 0)
 	}
-	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	end := start.AddDate(0, 1, 0)
 
 	studentID := q.Get("student_id")
 	coachID := q.Get("coach_id")
@@ -308,6 +340,16 @@ func (h *AttendanceHandler) ListAttendances(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if lq.Paged() {
+		page, err := h.store.ListAttendancesPage(ctx, f, lq)
+		if err != nil {
+			writeListError(w, err, "error fetching attendances")
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusOK, true, "ok", page, nil)
+		return
+	}
+
 	out, err := h.store.ListAttendances(ctx, f)
 	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching attendances", nil, err.Error())
@@ -384,6 +426,8 @@ func (h *AttendanceHandler) UpdateAttendance(w http.ResponseWriter, r *http.Requ
 		IsVerified      *bool                       `json:"is_verified"`
 		ClassHighlights *string                     `json:"class_highlights"`
 		Homework        *string                     `json:"homework"`
+		Rejected        *bool                       `json:"rejected"`
+		RejectionReason *string                     `json:"rejection_reason"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
@@ -416,13 +460,39 @@ func (h *AttendanceHandler) UpdateAttendance(w http.ResponseWriter, r *http.Requ
 	if req.Homework != nil {
 		updates["homework"] = *req.Homework
 	}
-	if req.IsVerified != nil {
-		// only mentor/admin can verify
+	if req.IsVerified != nil || req.Rejected != nil {
+		// only mentor/admin can verify or reject
 		if current.Role == models.RoleCoach {
 			utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
 			return
 		}
+	}
+	rejecting := req.Rejected != nil && *req.Rejected
+	if rejecting && req.IsVerified != nil && *req.IsVerified {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "cannot verify and reject at once", nil, nil)
+		return
+	}
+	if req.IsVerified != nil {
 		updates["is_verified"] = *req.IsVerified
+		if *req.IsVerified && !existing.IsVerified {
+			updates["verified_at"] = time.Now()
+			updates["reviewed_by"] = current.ID
+		}
+	}
+	if rejecting {
+		reason := ""
+		if req.RejectionReason != nil {
+			reason = strings.TrimSpace(*req.RejectionReason)
+		}
+		if reason == "" {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "rejection_reason is required", nil, nil)
+			return
+		}
+		updates["is_verified"] = false
+		updates["verified_at"] = nil
+		updates["rejected_at"] = time.Now()
+		updates["rejection_reason"] = reason
+		updates["reviewed_by"] = current.ID
 	}
 
 	if len(updates) == 0 {
@@ -430,7 +500,39 @@ func (h *AttendanceHandler) UpdateAttendance(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	updated, err := h.store.UpdateAttendanceByID(ctx, uint(idU64), updates)
+	var updated *models.Attendance
+	err = h.store.WithTx(ctx, func(tx *store.Store) error {
+		var err error
+		if updated, err = tx.UpdateAttendanceByID(ctx, uint(idU64), updates); err != nil {
+			return err
+		}
+		if rejecting {
+			return h.events.Enqueue(ctx, tx, service.Event{
+				Type:    service.EventAttendanceRejected,
+				ActorID: current.ID,
+				UserIDs: []string{updated.CoachID},
+				Data: map[string]interface{}{
+					"attendance_id": updated.ID,
+					"class_type":    string(updated.ClassType),
+					"date":          updated.Date.Format("2006-01-02"),
+					"reason":        updated.RejectionReason,
+				},
+			})
+		}
+		if req.IsVerified == nil || !*req.IsVerified || existing.IsVerified {
+			return nil
+		}
+		return h.events.Enqueue(ctx, tx, service.Event{
+			Type:    service.EventAttendanceVerified,
+			ActorID: current.ID,
+			UserIDs: []string{updated.StudentID, updated.CoachID},
+			Data: map[string]interface{}{
+				"attendance_id": updated.ID,
+				"class_type":    string(updated.ClassType),
+				"date":          updated.Date.Format("2006-01-02"),
+			},
+		})
+	})
 	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update failed", nil, err.Error())
 		return
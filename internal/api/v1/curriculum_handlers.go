@@ -0,0 +1,526 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+	"gorm.io/datatypes"
+)
+
+// CurriculumHandler serves the academy syllabus (levels > modules > topics),
+// lesson plan templates and students' topic mastery.
+type CurriculumHandler struct {
+	store *store.Store
+}
+
+func NewCurriculumHandler(s serviceStore) *CurriculumHandler {
+	return &CurriculumHandler{store: s.Store}
+}
+
+// curriculumResource is one piece of a topic's reference material.
+type curriculumResource struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func writeCurriculumError(w http.ResponseWriter, err error, msg string) {
+	if store.IsNotFound(err) {
+		utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusInternalServerError, false, msg, nil, err.Error())
+}
+
+// GET /curriculum - the full syllabus
+func (h *CurriculumHandler) ListCurriculum(w http.ResponseWriter, r *http.Request) {
+	levels, err := h.store.ListCurriculum(r.Context())
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching curriculum", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", levels, nil)
+}
+
+// GET /curriculum/levels/{id}
+func (h *CurriculumHandler) GetLevel(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	level, err := h.store.GetCurriculumLevel(r.Context(), id)
+	if err != nil {
+		writeCurriculumError(w, err, "error fetching level")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", level, nil)
+}
+
+// POST /curriculum/levels (admin)
+func (h *CurriculumHandler) CreateLevel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Position    int    `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "name is required", nil, nil)
+		return
+	}
+	level := &models.CurriculumLevel{Name: req.Name, Description: req.Description, Position: req.Position}
+	if err := h.store.CreateCurriculumLevel(r.Context(), level); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "create level failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "level created", level, nil)
+}
+
+// PATCH /curriculum/levels/{id} (admin)
+func (h *CurriculumHandler) UpdateLevel(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	var req struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		Position    *int    `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	updates := map[string]interface{}{}
+	if req.Name != nil && *req.Name != "" {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Position != nil {
+		updates["position"] = *req.Position
+	}
+	if len(updates) == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "nothing to update", nil, nil)
+		return
+	}
+	if err := h.store.UpdateCurriculumLevel(r.Context(), id, updates); err != nil {
+		writeCurriculumError(w, err, "update level failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "level updated", nil, nil)
+}
+
+// DELETE /curriculum/levels/{id} (admin)
+func (h *CurriculumHandler) DeleteLevel(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	if err := h.store.DeleteCurriculumLevel(r.Context(), id); err != nil {
+		writeCurriculumError(w, err, "delete level failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "level deleted", nil, nil)
+}
+
+// POST /curriculum/levels/{id}/modules (admin)
+func (h *CurriculumHandler) CreateModule(w http.ResponseWriter, r *http.Request) {
+	levelID, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	var req struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Position    int    `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Title == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "title is required", nil, nil)
+		return
+	}
+	m := &models.CurriculumModule{LevelID: levelID, Title: req.Title, Description: req.Description, Position: req.Position}
+	if err := h.store.CreateCurriculumModule(r.Context(), m); err != nil {
+		writeCurriculumError(w, err, "create module failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "module created", m, nil)
+}
+
+// PATCH /curriculum/modules/{id} (admin)
+func (h *CurriculumHandler) UpdateModule(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	var req struct {
+		LevelID     *uint   `json:"level_id"`
+		Title       *string `json:"title"`
+		Description *string `json:"description"`
+		Position    *int    `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	updates := map[string]interface{}{}
+	if req.LevelID != nil {
+		updates["level_id"] = *req.LevelID
+	}
+	if req.Title != nil && *req.Title != "" {
+		updates["title"] = *req.Title
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Position != nil {
+		updates["position"] = *req.Position
+	}
+	if len(updates) == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "nothing to update", nil, nil)
+		return
+	}
+	if err := h.store.UpdateCurriculumModule(r.Context(), id, updates); err != nil {
+		writeCurriculumError(w, err, "update module failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "module updated", nil, nil)
+}
+
+// DELETE /curriculum/modules/{id} (admin)
+func (h *CurriculumHandler) DeleteModule(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	if err := h.store.DeleteCurriculumModule(r.Context(), id); err != nil {
+		writeCurriculumError(w, err, "delete module failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "module deleted", nil, nil)
+}
+
+// POST /curriculum/modules/{id}/topics (admin)
+func (h *CurriculumHandler) CreateTopic(w http.ResponseWriter, r *http.Request) {
+	moduleID, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	var req struct {
+		Title      string               `json:"title"`
+		Objectives []string             `json:"objectives"`
+		Resources  []curriculumResource `json:"resources"`
+		Position   int                  `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Title == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "title is required", nil, nil)
+		return
+	}
+	if req.Resources == nil {
+		req.Resources = []curriculumResource{}
+	}
+	refs, _ := json.Marshal(req.Resources)
+	t := &models.CurriculumTopic{
+		ModuleID:   moduleID,
+		Title:      req.Title,
+		Objectives: utils.DatatypesJSONFromStrings(append([]string{}, req.Objectives...)),
+		Resources:  datatypes.JSON(refs),
+		Position:   req.Position,
+	}
+	if err := h.store.CreateCurriculumTopic(r.Context(), t); err != nil {
+		writeCurriculumError(w, err, "create topic failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "topic created", t, nil)
+}
+
+// PATCH /curriculum/topics/{id} (admin)
+func (h *CurriculumHandler) UpdateTopic(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	var req struct {
+		ModuleID   *uint                `json:"module_id"`
+		Title      *string              `json:"title"`
+		Objectives []string             `json:"objectives"`
+		Resources  []curriculumResource `json:"resources"`
+		Position   *int                 `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	updates := map[string]interface{}{}
+	if req.ModuleID != nil {
+		updates["module_id"] = *req.ModuleID
+	}
+	if req.Title != nil && *req.Title != "" {
+		updates["title"] = *req.Title
+	}
+	if req.Objectives != nil {
+		updates["objectives"] = utils.DatatypesJSONFromStrings(req.Objectives)
+	}
+	if req.Resources != nil {
+		refs, _ := json.Marshal(req.Resources)
+		updates["resources"] = datatypes.JSON(refs)
+	}
+	if req.Position != nil {
+		updates["position"] = *req.Position
+	}
+	if len(updates) == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "nothing to update", nil, nil)
+		return
+	}
+	if err := h.store.UpdateCurriculumTopic(r.Context(), id, updates); err != nil {
+		writeCurriculumError(w, err, "update topic failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "topic updated", nil, nil)
+}
+
+// DELETE /curriculum/topics/{id} (admin)
+func (h *CurriculumHandler) DeleteTopic(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	if err := h.store.DeleteCurriculumTopic(r.Context(), id); err != nil {
+		writeCurriculumError(w, err, "delete topic failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "topic deleted", nil, nil)
+}
+
+// POST /curriculum/levels/{id}/lesson-plans - instantiate the level as the
+// student's new active lesson plan. module_ids limits it to some modules.
+func (h *CurriculumHandler) CreateLessonPlanFromLevel(w http.ResponseWriter, r *http.Request) {
+	levelID, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	var req struct {
+		UserID    string `json:"user_id"`
+		Title     string `json:"title"`
+		ModuleIDs []uint `json:"module_ids"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "user_id is required", nil, nil)
+		return
+	}
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if !CanManageStudent(ctx, h.store, current, req.UserID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "only mentor or coach can create lesson plan", nil, nil)
+		return
+	}
+
+	lp := &models.LessonPlan{
+		UserID:    req.UserID,
+		Title:     req.Title,
+		CreatedBy: current.ID,
+	}
+	if t, err := time.Parse(time.RFC3339, req.StartDate); err == nil {
+		lp.StartDate = t
+	}
+	if t, err := time.Parse(time.RFC3339, req.EndDate); err == nil {
+		lp.EndDate = t
+	}
+	if err := h.store.CreateLessonPlanFromLevel(ctx, lp, levelID, req.ModuleIDs); err != nil {
+		writeCurriculumError(w, err, "create lesson plan failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "lesson plan created", lp, nil)
+}
+
+// GET /curriculum/students/{id} - the student's level, mastered topics and
+// progress per level and module.
+func (h *CurriculumHandler) GetStudentProgress(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "id")
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if !CanAccessStudentData(ctx, h.store, current, studentID) && !CanManageStudent(ctx, h.store, current, studentID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	student, err := h.store.GetUserByID(ctx, studentID)
+	if err != nil {
+		writeCurriculumError(w, err, "error fetching student")
+		return
+	}
+	mastered, err := h.store.ListTopicMastery(ctx, studentID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching mastery", nil, err.Error())
+		return
+	}
+	progress, err := h.store.StudentCurriculumProgress(ctx, studentID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching progress", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{
+		"curriculum_level_id": student.UserDetails.CurriculumLevelID,
+		"mastered":            mastered,
+		"levels":              progress,
+	}, nil)
+}
+
+// PUT /curriculum/students/{id}/level - body {"level_id": 1}, null clears it
+func (h *CurriculumHandler) SetStudentLevel(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "id")
+	var req struct {
+		LevelID *uint `json:"level_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if !CanManageStudent(ctx, h.store, current, studentID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	if err := h.store.SetStudentCurriculumLevel(ctx, studentID, req.LevelID); err != nil {
+		writeCurriculumError(w, err, "update level failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "level updated", nil, nil)
+}
+
+// PUT /curriculum/students/{id}/mastery/{topicId} - body {"note": "..."}
+func (h *CurriculumHandler) MarkTopicMastered(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "id")
+	topicID, ok := uintParam(r, "topicId")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid topic id", nil, nil)
+		return
+	}
+	var req struct {
+		Note       string `json:"note"`
+		MasteredAt string `json:"mastered_at"`
+	}
+	// The body is optional
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if !CanManageStudent(ctx, h.store, current, studentID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	m := &models.TopicMastery{StudentID: studentID, TopicID: topicID, MarkedBy: current.ID, Note: req.Note}
+	if req.MasteredAt != "" {
+		t, err := parseDateFlexible(req.MasteredAt)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid mastered_at", nil, nil)
+			return
+		}
+		m.MasteredAt = t
+	}
+	if err := h.store.MarkTopicMastered(ctx, m); err != nil {
+		writeCurriculumError(w, err, "update mastery failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "topic mastered", m, nil)
+}
+
+// DELETE /curriculum/students/{id}/mastery/{topicId}
+func (h *CurriculumHandler) UnmarkTopicMastered(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "id")
+	topicID, ok := uintParam(r, "topicId")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid topic id", nil, nil)
+		return
+	}
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if !CanManageStudent(ctx, h.store, current, studentID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	if err := h.store.UnmarkTopicMastered(ctx, studentID, topicID); err != nil {
+		writeCurriculumError(w, err, "update mastery failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "mastery removed", nil, nil)
+}
+
+// GET /reports/syllabus?coach_id= - a coach's students against their
+// curriculum level. Coaches get their own students; mentors the students of
+// coaches they mentor.
+func (h *CurriculumHandler) GetSyllabusReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	coachID := r.URL.Query().Get("coach_id")
+	if coachID == "" {
+		coachID = current.ID
+	}
+	switch {
+	case current.Role == models.RoleAdmin, coachID == current.ID:
+	case current.Role == models.RoleMentor:
+		ok, err := h.store.IsMentorOfCoach(ctx, current.ID, coachID)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+			return
+		}
+		if !ok {
+			utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+			return
+		}
+	default:
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+
+	rows, err := h.store.SyllabusReport(ctx, coachID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error building report", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", map[string]interface{}{
+		"coach_id":     coachID,
+		"generated_at": time.Now(),
+		"students":     rows,
+	}, nil)
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// DigestHandler previews the weekly digest email.
+type DigestHandler struct {
+	store  *store.Store
+	digest *service.DigestService
+}
+
+func NewDigestHandler(s serviceStore, ds *service.DigestService) *DigestHandler {
+	return &DigestHandler{store: s.Store, digest: ds}
+}
+
+// GET /admin/digest/preview/{userId}?format=html|text|json
+// Renders the user's digest for the current week without sending it. It
+// renders even for users who opted out or have nothing to report.
+func (h *DigestHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	u, err := h.store.GetUserByID(ctx, chi.URLParam(r, "userId"))
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "user not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching user", nil, err.Error())
+		return
+	}
+	d, err := h.digest.Build(ctx, u, time.Now())
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error building digest", nil, err.Error())
+		return
+	}
+
+	var body, ctype string
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "html":
+		body, err = service.RenderDigestHTML(d)
+		ctype = "text/html; charset=utf-8"
+	case "text":
+		body, err = service.RenderDigestText(d)
+		ctype = "text/plain; charset=utf-8"
+	case "json":
+		utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{
+			"subject": service.DigestSubject(d),
+			"empty":   d.Empty(),
+			"digest":  d,
+		}, nil)
+		return
+	default:
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "format must be html, text or json", nil, nil)
+		return
+	}
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error rendering digest", nil, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", ctype)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(body))
+}
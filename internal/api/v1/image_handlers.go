@@ -8,23 +8,21 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/madhava-poojari/dashboard-api/internal/auth"
-	"github.com/madhava-poojari/dashboard-api/internal/config"
 	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
 	"github.com/madhava-poojari/dashboard-api/internal/utils"
 	"gorm.io/datatypes"
 )
 
 type ImageHandler struct {
 	store        serviceStore
-	imageStorage *utils.R2Storage
-	cfg          *config.Config
+	imageStorage utils.Storage
 }
 
-func NewImageHandler(store serviceStore, cfg *config.Config) *ImageHandler {
+func NewImageHandler(store serviceStore, images utils.Storage) *ImageHandler {
 	return &ImageHandler{
 		store:        store,
-		imageStorage: utils.NewR2Storage(cfg.R2AccessKeyID, cfg.R2SecretAccessKey, cfg.R2Endpoint, cfg.R2BucketName),
-		cfg:          cfg,
+		imageStorage: images,
 	}
 }
 
@@ -56,27 +54,30 @@ func (h *ImageHandler) UploadProfilePicture(w http.ResponseWriter, r *http.Reque
 	}
 	defer file.Close()
 
-	// Delete old profile picture file if exists
 	user, err := h.store.GetUserByID(ctx, id)
 	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusNotFound, false, "user not found", nil, nil)
 		return
 	}
-	if user.UserDetails.ProfilePictureURL != "" {
-		_ = h.imageStorage.DeleteFile(user.UserDetails.ProfilePictureURL)
-	}
 
-	// Save new file
+	// Save the new file first; the old one is only queued for deletion once
+	// the profile points at the new one.
 	urlSuffix, err := h.imageStorage.SaveFile("profile-pictures", header.Filename, file)
 	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to save file", nil, err.Error())
 		return
 	}
 
-	// Update user_details.profile_picture_url
-	if err := h.store.UpdateUserDetailsFields(ctx, id, map[string]interface{}{
-		"profile_picture_url": urlSuffix,
-	}); err != nil {
+	err = h.store.WithTx(ctx, func(tx *store.Store) error {
+		if err := tx.UpdateUserDetailsFields(ctx, id, map[string]interface{}{
+			"profile_picture_url": urlSuffix,
+		}); err != nil {
+			return err
+		}
+		return tx.EnqueueStorageDelete(ctx, store.StorageImages, user.UserDetails.ProfilePictureURL)
+	})
+	if err != nil {
+		discardUpload(ctx, h.store.Store, h.imageStorage, store.StorageImages, urlSuffix)
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to update profile", nil, err.Error())
 		return
 	}
@@ -107,13 +108,15 @@ func (h *ImageHandler) DeleteProfilePicture(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if user.UserDetails.ProfilePictureURL != "" {
-		_ = h.imageStorage.DeleteFile(user.UserDetails.ProfilePictureURL)
-	}
-
-	if err := h.store.UpdateUserDetailsFields(ctx, id, map[string]interface{}{
-		"profile_picture_url": "",
-	}); err != nil {
+	err = h.store.WithTx(ctx, func(tx *store.Store) error {
+		if err := tx.UpdateUserDetailsFields(ctx, id, map[string]interface{}{
+			"profile_picture_url": "",
+		}); err != nil {
+			return err
+		}
+		return tx.EnqueueStorageDelete(ctx, store.StorageImages, user.UserDetails.ProfilePictureURL)
+	})
+	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to update profile", nil, err.Error())
 		return
 	}
@@ -136,6 +139,21 @@ func (h *ImageHandler) ListGallery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	lq, err := parseListQuery(r, store.ImageListSpec)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+		return
+	}
+	if lq.Paged() {
+		page, err := h.store.ListGalleryImagesPage(ctx, id, lq)
+		if err != nil {
+			writeListError(w, err, "failed to list gallery")
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusOK, true, "success", page, nil)
+		return
+	}
+
 	images, err := h.store.ListGalleryImages(ctx, id)
 	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to list gallery", nil, err.Error())
@@ -174,13 +192,6 @@ func (h *ImageHandler) UploadGalleryImage(w http.ResponseWriter, r *http.Request
 	}
 	defer file.Close()
 
-	subDir := fmt.Sprintf("gallery/%s", id)
-	urlSuffix, err := h.imageStorage.SaveFile(subDir, header.Filename, file)
-	if err != nil {
-		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to save file", nil, err.Error())
-		return
-	}
-
 	// Read form fields
 	title := r.FormValue("title")
 	if title == "" {
@@ -188,6 +199,13 @@ func (h *ImageHandler) UploadGalleryImage(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	subDir := fmt.Sprintf("gallery/%s", id)
+	urlSuffix, err := h.imageStorage.SaveFile(subDir, header.Filename, file)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to save file", nil, err.Error())
+		return
+	}
+
 	// Parse tags from JSON string (e.g. '["tag1","tag2"]')
 	var tags datatypes.JSON
 	tagsStr := r.FormValue("tags")
@@ -208,6 +226,7 @@ func (h *ImageHandler) UploadGalleryImage(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := h.store.CreateImage(ctx, img); err != nil {
+		discardUpload(ctx, h.store.Store, h.imageStorage, store.StorageImages, urlSuffix)
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to save image record", nil, err.Error())
 		return
 	}
@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/jobs"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// JobHandler lets admins see, trigger and pause background jobs.
+type JobHandler struct {
+	jobs *jobs.Scheduler
+}
+
+func NewJobHandler(sc *jobs.Scheduler) *JobHandler {
+	return &JobHandler{jobs: sc}
+}
+
+// writeJobError maps scheduler errors to responses.
+func writeJobError(w http.ResponseWriter, err error, msg string) {
+	if errors.Is(err, jobs.ErrUnknownJob) || store.IsNotFound(err) {
+		utils.WriteJSONResponse(w, http.StatusNotFound, false, "job not found", nil, nil)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusInternalServerError, false, msg, nil, err.Error())
+}
+
+// GET /admin/jobs
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	list, err := h.jobs.Jobs(r.Context())
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching jobs", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", list, nil)
+}
+
+// POST /admin/jobs/{name}/run - runs on the next scheduler tick, even if paused.
+func (h *JobHandler) RunJob(w http.ResponseWriter, r *http.Request) {
+	current := auth.GetUserFromCtx(r.Context())
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if err := h.jobs.Trigger(r.Context(), chi.URLParam(r, "name"), current.ID); err != nil {
+		writeJobError(w, err, "trigger failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusAccepted, true, "job queued", nil, nil)
+}
+
+// POST /admin/jobs/{name}/pause
+func (h *JobHandler) PauseJob(w http.ResponseWriter, r *http.Request) {
+	if err := h.jobs.Pause(r.Context(), chi.URLParam(r, "name")); err != nil {
+		writeJobError(w, err, "pause failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "job paused", nil, nil)
+}
+
+// POST /admin/jobs/{name}/resume
+func (h *JobHandler) ResumeJob(w http.ResponseWriter, r *http.Request) {
+	if err := h.jobs.Resume(r.Context(), chi.URLParam(r, "name")); err != nil {
+		writeJobError(w, err, "resume failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "job resumed", nil, nil)
+}
+
+// GET /admin/jobs/{name}/runs?status=failed&limit=&offset=
+func (h *JobHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	switch status {
+	case "", models.JobRunRunning, models.JobRunSucceeded, models.JobRunFailed:
+	default:
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "status must be running, succeeded or failed", nil, nil)
+		return
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := h.jobs.Runs(r.Context(), chi.URLParam(r, "name"), status, limit, offset)
+	if err != nil {
+		writeJobError(w, err, "error fetching runs")
+		return
+	}
+	if list == nil {
+		list = []models.JobRun{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{
+		"runs":  list,
+		"total": total,
+	}, nil)
+}
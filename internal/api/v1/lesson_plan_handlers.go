@@ -0,0 +1,309 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// lessonPlanItemInput is the request shape of a curriculum item.
+type lessonPlanItemInput struct {
+	Topic      string                      `json:"topic"`
+	Objective  string                      `json:"objective"`
+	TargetDate string                      `json:"target_date"`
+	Status     models.LessonPlanItemStatus `json:"status"`
+	Position   int                         `json:"position"`
+}
+
+func (in lessonPlanItemInput) toModel() (models.LessonPlanItem, error) {
+	item := models.LessonPlanItem{
+		Topic:     in.Topic,
+		Objective: in.Objective,
+		Status:    in.Status,
+		Position:  in.Position,
+	}
+	if in.Topic == "" {
+		return item, errors.New("topic is required")
+	}
+	if in.Status != "" && !store.ValidLessonPlanItemStatus(in.Status) {
+		return item, store.ErrInvalidItemStatus
+	}
+	if in.TargetDate != "" {
+		t, err := parseDateFlexible(in.TargetDate)
+		if err != nil {
+			return item, errors.New("invalid target_date")
+		}
+		item.TargetDate = &t
+	}
+	return item, nil
+}
+
+// uintParam parses a positive numeric URL param.
+func uintParam(r *http.Request, name string) (uint, bool) {
+	n, err := strconv.ParseUint(chi.URLParam(r, name), 10, 64)
+	return uint(n), err == nil && n > 0
+}
+
+// writeItemError maps lesson plan item store errors to responses.
+func writeItemError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case store.IsNotFound(err):
+		utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+	case errors.Is(err, store.ErrInvalidItemStatus), errors.Is(err, store.ErrInvalidItemOrder), errors.Is(err, store.ErrAttendanceMismatch):
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+	default:
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, msg, nil, err.Error())
+	}
+}
+
+// GET /notes/lesson-plans?user_id=&archived= - a student's plans with items
+// and progress. Readable by the student and their coach/mentor.
+func (h *NotesHandler) ListLessonPlans(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = current.ID
+	}
+	ok, err := h.store.IsRelatedStudent(ctx, current.ID, userID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return
+	}
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+
+	var archived *bool
+	if v := r.URL.Query().Get("archived"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid archived", nil, nil)
+			return
+		}
+		archived = &b
+	}
+	plans, err := h.store.ListLessonPlans(ctx, userID, archived)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching lesson plans", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", plans, nil)
+}
+
+// GET /notes/lesson-plans/{id}
+func (h *NotesHandler) GetLessonPlan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	lp, err := h.store.GetLessonPlan(ctx, id)
+	if err != nil {
+		writeItemError(w, err, "error fetching lesson plan")
+		return
+	}
+	related, err := h.store.IsRelatedStudent(ctx, current.ID, lp.UserID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return
+	}
+	if !related {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", lp, nil)
+}
+
+// POST /notes/lesson-plans/{id}/items
+func (h *NotesHandler) AddLessonPlanItem(w http.ResponseWriter, r *http.Request) {
+	var req lessonPlanItemInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	item, err := req.toModel()
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+		return
+	}
+	lp, _, ok := h.lessonPlanFromURL(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.AddLessonPlanItem(r.Context(), lp.ID, &item); err != nil {
+		writeItemError(w, err, "create item failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "item created", item, nil)
+}
+
+// PATCH /notes/lesson-plans/{id}/items/{itemId}
+func (h *NotesHandler) UpdateLessonPlanItem(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Topic      *string                      `json:"topic"`
+		Objective  *string                      `json:"objective"`
+		TargetDate *string                      `json:"target_date"` // "" clears it
+		Status     *models.LessonPlanItemStatus `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	itemID, ok := uintParam(r, "itemId")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid item id", nil, nil)
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Topic != nil {
+		if *req.Topic == "" {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "topic is required", nil, nil)
+			return
+		}
+		updates["topic"] = *req.Topic
+	}
+	if req.Objective != nil {
+		updates["objective"] = *req.Objective
+	}
+	if req.TargetDate != nil {
+		if *req.TargetDate == "" {
+			updates["target_date"] = nil
+		} else if t, err := parseDateFlexible(*req.TargetDate); err == nil {
+			updates["target_date"] = t
+		} else {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid target_date", nil, nil)
+			return
+		}
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+
+	lp, _, ok := h.lessonPlanFromURL(w, r)
+	if !ok {
+		return
+	}
+	if len(updates) == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "nothing to update", nil, nil)
+		return
+	}
+	item, err := h.store.UpdateLessonPlanItem(r.Context(), lp.ID, itemID, updates)
+	if err != nil {
+		writeItemError(w, err, "update item failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "item updated", item, nil)
+}
+
+// DELETE /notes/lesson-plans/{id}/items/{itemId}
+func (h *NotesHandler) DeleteLessonPlanItem(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := uintParam(r, "itemId")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid item id", nil, nil)
+		return
+	}
+	lp, _, ok := h.lessonPlanFromURL(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteLessonPlanItem(r.Context(), lp.ID, itemID); err != nil {
+		writeItemError(w, err, "delete item failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "item deleted", nil, nil)
+}
+
+// PUT /notes/lesson-plans/{id}/items/order - body {"item_ids": [...]}
+func (h *NotesHandler) ReorderLessonPlanItems(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ItemIDs []uint `json:"item_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	lp, _, ok := h.lessonPlanFromURL(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	if err := h.store.ReorderLessonPlanItems(ctx, lp.ID, req.ItemIDs); err != nil {
+		writeItemError(w, err, "reorder failed")
+		return
+	}
+	updated, err := h.store.GetLessonPlan(ctx, lp.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching lesson plan", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "items reordered", updated, nil)
+}
+
+// POST /notes/lesson-plans/{id}/items/{itemId}/sessions - body
+// {"attendance_id": 1, "complete": false}. Ties a class to the item and
+// advances its status.
+func (h *NotesHandler) LinkLessonPlanItemSession(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AttendanceID uint `json:"attendance_id"`
+		Complete     bool `json:"complete"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AttendanceID == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "attendance_id is required", nil, nil)
+		return
+	}
+	itemID, ok := uintParam(r, "itemId")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid item id", nil, nil)
+		return
+	}
+	lp, current, ok := h.lessonPlanFromURL(w, r)
+	if !ok {
+		return
+	}
+	item, err := h.store.LinkAttendanceToItem(r.Context(), lp, itemID, req.AttendanceID, req.Complete, current.ID)
+	if err != nil {
+		writeItemError(w, err, "link session failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "session linked", item, nil)
+}
+
+// DELETE /notes/lesson-plans/{id}/items/{itemId}/sessions/{attendanceId}
+func (h *NotesHandler) UnlinkLessonPlanItemSession(w http.ResponseWriter, r *http.Request) {
+	itemID, ok1 := uintParam(r, "itemId")
+	attendanceID, ok2 := uintParam(r, "attendanceId")
+	if !ok1 || !ok2 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	lp, _, ok := h.lessonPlanFromURL(w, r)
+	if !ok {
+		return
+	}
+	item, err := h.store.UnlinkAttendanceFromItem(r.Context(), lp.ID, itemID, attendanceID)
+	if err != nil {
+		writeItemError(w, err, "unlink session failed")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "session unlinked", item, nil)
+}
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+const (
+	dashboardDefaultMonths = 6
+	dashboardMaxMonths     = 24
+)
+
+// MentorHandler serves mentors' views of their coaches.
+type MentorHandler struct {
+	store *store.Store
+}
+
+func NewMentorHandler(s serviceStore) *MentorHandler {
+	return &MentorHandler{store: s.Store}
+}
+
+// parseMonth reads YYYY-MM, or a YYYY-MM-DD / RFC3339 date whose month is
+// used, as the first day of that month in UTC.
+func parseMonth(v string) (time.Time, error) {
+	t, err := time.Parse("2006-01", v)
+	if err != nil {
+		if t, err = parseDateFlexible(v); err != nil {
+			return time.Time{}, fmt.Errorf("want YYYY-MM or YYYY-MM-DD")
+		}
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// GET /mentor/dashboard?from=2026-01&to=2026-06&mentor_id=
+// Per-coach metrics for each month from `from` through `to` (both
+// inclusive; default the last six months). Admins pass mentor_id.
+func (h *MentorHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	q := r.URL.Query()
+
+	mentorID := current.ID
+	if v := q.Get("mentor_id"); v != "" && v != current.ID {
+		if current.Role != models.RoleAdmin {
+			utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+			return
+		}
+		mentorID = v
+	}
+	if mentorID == current.ID && current.Role != models.RoleMentor {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "mentor_id is required", nil, nil)
+		return
+	}
+	mentor, err := h.store.GetUserByID(ctx, mentorID)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "mentor not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching mentor", nil, err.Error())
+		return
+	}
+	if mentor.Role != models.RoleMentor {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "user is not a mentor", nil, nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if v := q.Get("to"); v != "" {
+		if to, err = parseMonth(v); err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid to", nil, err.Error())
+			return
+		}
+	}
+	from := to.AddDate(0, 1-dashboardDefaultMonths, 0)
+	if v := q.Get("from"); v != "" {
+		if from, err = parseMonth(v); err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid from", nil, err.Error())
+			return
+		}
+	}
+	end := to.AddDate(0, 1, 0)
+	if from.After(to) {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "from must not be after to", nil, nil)
+		return
+	}
+	if from.AddDate(0, dashboardMaxMonths, 0).Before(end) {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, fmt.Sprintf("range is limited to %d months", dashboardMaxMonths), nil, nil)
+		return
+	}
+
+	coaches, err := h.store.ListCoachesForMentor(ctx, mentorID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching coaches", nil, err.Error())
+		return
+	}
+	d, err := h.store.GetMentorDashboard(ctx, mentorID, coaches, from, end)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error building dashboard", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", d, nil)
+}
@@ -0,0 +1,426 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/config"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+type MessagingHandler struct {
+	store       *store.Store
+	messaging   *service.MessagingService
+	verifyToken string
+}
+
+func NewMessagingHandler(s serviceStore, cfg *config.Config, ms *service.MessagingService) *MessagingHandler {
+	return &MessagingHandler{store: s.Store, messaging: ms, verifyToken: cfg.WhatsAppVerifyToken}
+}
+
+// GET /messaging/groups?user_id= - all groups for staff, or one user's groups.
+// Students and parents only see their own.
+func (h *MessagingHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	userID := r.URL.Query().Get("user_id")
+	if current.Role == models.RoleStudent && userID == "" {
+		userID = current.ID
+	}
+	if userID != "" && !CanAccessStudentData(ctx, h.store, current, userID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	list, err := h.store.ListMessagingGroups(ctx, userID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching groups", nil, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.MessagingGroup{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", list, nil)
+}
+
+// POST /messaging/groups - {"name", "invite_link", "description", "provider"}
+func (h *MessagingHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	var req struct {
+		Name        string `json:"name"`
+		Provider    string `json:"provider"`
+		InviteLink  string `json:"invite_link"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "name is required", nil, nil)
+		return
+	}
+	if req.Provider == "" {
+		req.Provider = models.ChannelWhatsApp
+	}
+	g := &models.MessagingGroup{
+		Name:        req.Name,
+		Provider:    req.Provider,
+		InviteLink:  req.InviteLink,
+		Description: req.Description,
+		CreatedBy:   current.ID,
+	}
+	if err := h.store.CreateMessagingGroup(ctx, g); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "create failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "group created", g, nil)
+}
+
+// PATCH /messaging/groups/{id}
+func (h *MessagingHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	var req struct {
+		Name        *string `json:"name"`
+		InviteLink  *string `json:"invite_link"`
+		Description *string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "name cannot be empty", nil, nil)
+			return
+		}
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.InviteLink != nil {
+		updates["invite_link"] = *req.InviteLink
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if len(updates) == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "no updatable fields provided", nil, nil)
+		return
+	}
+	if _, err := h.store.GetMessagingGroup(ctx, id); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return
+	}
+	if err := h.store.UpdateMessagingGroup(ctx, id, updates); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update failed", nil, err.Error())
+		return
+	}
+	g, err := h.store.GetMessagingGroup(ctx, id)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "group updated", g, nil)
+}
+
+// DELETE /messaging/groups/{id}
+func (h *MessagingHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	if err := h.store.DeleteMessagingGroup(r.Context(), id); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "delete failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "deleted", nil, nil)
+}
+
+// GET /messaging/groups/{id}/members
+func (h *MessagingHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	list, err := h.store.ListGroupMembers(r.Context(), id)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching members", nil, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.MessagingGroupMember{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", list, nil)
+}
+
+// groupMemberFromURL checks the {id} group exists and that the requester
+// may manage the {userId} user's memberships.
+func (h *MessagingHandler) groupMemberFromURL(w http.ResponseWriter, r *http.Request, current *models.User) (uint, string, bool) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return 0, "", false
+	}
+	userID := chi.URLParam(r, "userId")
+	if !CanAccessStudentData(r.Context(), h.store, current, userID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return 0, "", false
+	}
+	if _, err := h.store.GetMessagingGroup(r.Context(), id); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return 0, "", false
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return 0, "", false
+	}
+	return id, userID, true
+}
+
+// PUT /messaging/groups/{id}/members/{userId}
+func (h *MessagingHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	groupID, userID, ok := h.groupMemberFromURL(w, r, current)
+	if !ok {
+		return
+	}
+	if _, err := h.store.GetUserByID(ctx, userID); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "user not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return
+	}
+	if err := h.store.AddGroupMember(ctx, groupID, userID, current.ID); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "add failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "member added", nil, nil)
+}
+
+// DELETE /messaging/groups/{id}/members/{userId}
+func (h *MessagingHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	groupID, userID, ok := h.groupMemberFromURL(w, r, current)
+	if !ok {
+		return
+	}
+	if err := h.store.RemoveGroupMember(ctx, groupID, userID); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "remove failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "member removed", nil, nil)
+}
+
+// writeSendError maps messaging send errors to responses. Messages that
+// reached the log are returned so the caller can see which failed.
+func writeSendError(w http.ResponseWriter, err error, sent interface{}) {
+	if errors.Is(err, service.ErrNoPhone) {
+		utils.WriteJSONResponse(w, http.StatusUnprocessableEntity, false, err.Error(), sent, nil)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusBadGateway, false, "send failed", sent, err.Error())
+}
+
+// POST /messaging/messages - {"user_id", "template", "params": [...], "family": true}
+// Sends a template to the user, and to their family when family is set.
+func (h *MessagingHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	var req struct {
+		UserID   string   `json:"user_id"`
+		Template string   `json:"template"`
+		Params   []string `json:"params"`
+		Family   bool     `json:"family"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	if req.UserID == "" || req.Template == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "user_id and template are required", nil, nil)
+		return
+	}
+	if !CanAccessStudentData(ctx, h.store, current, req.UserID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	to, err := h.store.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "user not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return
+	}
+	if req.Family {
+		sent, err := h.messaging.SendToFamily(ctx, to, req.Template, req.Params, current.ID)
+		if err != nil {
+			writeSendError(w, err, sent)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusCreated, true, "messages sent", sent, nil)
+		return
+	}
+	m, err := h.messaging.SendTemplate(ctx, to, req.Template, req.Params, current.ID)
+	if err != nil {
+		writeSendError(w, err, m)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "message sent", m, nil)
+}
+
+// GET /messaging/messages?user_id=&limit= - delivery log, newest first.
+// Only admins may list without user_id.
+func (h *MessagingHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	q := r.URL.Query()
+	userID := q.Get("user_id")
+	if userID == "" && current.Role != models.RoleAdmin {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "user_id is required", nil, nil)
+		return
+	}
+	if userID != "" && !CanAccessStudentData(ctx, h.store, current, userID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	list, err := h.store.ListOutboundMessages(ctx, userID, limit)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching messages", nil, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.OutboundMessage{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", list, nil)
+}
+
+// POST /attendances/{id}/homework/send - sends the class's homework to the
+// student and their family.
+func (h *MessagingHandler) SendHomework(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	a, err := h.store.GetAttendanceByID(ctx, id)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return
+	}
+	if a.CoachID != current.ID && !CanAccessStudentData(ctx, h.store, current, a.StudentID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	if a.Homework == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "this class has no homework", nil, nil)
+		return
+	}
+	sent, err := h.messaging.SendHomework(ctx, a, current.ID)
+	if err != nil {
+		writeSendError(w, err, sent)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "homework sent", sent, nil)
+}
+
+// GET /messaging/webhook - provider subscription handshake: echo
+// hub.challenge when hub.verify_token matches.
+func (h *MessagingHandler) VerifyWebhook(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if h.verifyToken == "" || q.Get("hub.mode") != "subscribe" || q.Get("hub.verify_token") != h.verifyToken {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(q.Get("hub.challenge")))
+}
+
+// POST /messaging/webhook - delivery status callbacks from the provider.
+func (h *MessagingHandler) StatusWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	n, err := h.messaging.HandleStatusCallback(r.Context(), r, body)
+	if errors.Is(err, service.ErrInvalidSignature) {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "invalid signature", nil, nil)
+		return
+	}
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error applying status", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]int{"updated": n}, nil)
+}
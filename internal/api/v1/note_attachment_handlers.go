@@ -0,0 +1,312 @@
+package v1
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// discardUpload removes a file saved for a DB write that then failed. It
+// goes through the outbox so a storage hiccup doesn't leave the file
+// behind; if the outbox can't be reached either, it deletes directly.
+func discardUpload(ctx context.Context, s *store.Store, files utils.Storage, storage, key string) {
+	if err := s.EnqueueStorageDelete(ctx, storage, key); err != nil {
+		_ = files.DeleteFile(key)
+	}
+}
+
+// attachmentKind is an accepted attachment format: the MIME type sniffed
+// from its content and its size limit.
+type attachmentKind struct {
+	ContentType string
+	Sniffed     string
+	MaxBytes    int64
+}
+
+// attachmentKinds maps file extensions to accepted formats. PGN has no
+// magic number, so it only has to sniff as plain text.
+var attachmentKinds = map[string]attachmentKind{
+	".png":  {ContentType: "image/png", Sniffed: "image/png", MaxBytes: 5 << 20},
+	".jpg":  {ContentType: "image/jpeg", Sniffed: "image/jpeg", MaxBytes: 5 << 20},
+	".jpeg": {ContentType: "image/jpeg", Sniffed: "image/jpeg", MaxBytes: 5 << 20},
+	".gif":  {ContentType: "image/gif", Sniffed: "image/gif", MaxBytes: 5 << 20},
+	".webp": {ContentType: "image/webp", Sniffed: "image/webp", MaxBytes: 5 << 20},
+	".pdf":  {ContentType: "application/pdf", Sniffed: "application/pdf", MaxBytes: 10 << 20},
+	".pgn":  {ContentType: "application/x-chess-pgn", Sniffed: "text/plain", MaxBytes: 1 << 20},
+}
+
+const (
+	maxAttachmentBytes  = 10 << 20
+	attachmentURLExpiry = 15 * time.Minute
+)
+
+var pgnHeaderRe = regexp.MustCompile(`^\[(\w+)\s+"(.*)"\]\s*$`)
+
+// attachmentMetadata reads what is cheap to know about a file: image size,
+// or the game count and first game's headers of a PGN.
+func attachmentMetadata(kind attachmentKind, data []byte) map[string]interface{} {
+	meta := map[string]interface{}{}
+	switch {
+	case strings.HasPrefix(kind.ContentType, "image/"):
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			meta["width"] = cfg.Width
+			meta["height"] = cfg.Height
+		}
+	case kind.ContentType == "application/x-chess-pgn":
+		games := 0
+		headers := map[string]string{}
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		for sc.Scan() {
+			m := pgnHeaderRe.FindStringSubmatch(strings.TrimSpace(sc.Text()))
+			if m == nil {
+				continue
+			}
+			if m[1] == "Event" {
+				games++
+			}
+			if games <= 1 {
+				headers[m[1]] = m[2]
+			}
+		}
+		meta["games"] = games
+		meta["headers"] = headers
+	}
+	return meta
+}
+
+// attachmentResponse is an attachment plus a short-lived download link.
+type attachmentResponse struct {
+	models.NoteAttachment
+	DownloadURL string `json:"download_url"`
+}
+
+func attachmentDownloadPath(a *models.NoteAttachment) string {
+	return fmt.Sprintf("/notes/%d/attachments/%d/download", a.NoteID, a.ID)
+}
+
+// POST /notes/{id}/attachments - multipart "file" plus optional "caption"
+func (h *NotesHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanModifyNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentBytes+(1<<20))
+	if err := r.ParseMultipartForm(maxAttachmentBytes); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "file too large or invalid form", nil, err.Error())
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "missing file field", nil, err.Error())
+		return
+	}
+	defer file.Close()
+
+	kind, ok := attachmentKinds[strings.ToLower(filepath.Ext(header.Filename))]
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusUnsupportedMediaType, false, "unsupported file type", nil, nil)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, kind.MaxBytes+1))
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "error reading file", nil, err.Error())
+		return
+	}
+	if int64(len(data)) > kind.MaxBytes {
+		utils.WriteJSONResponse(w, http.StatusRequestEntityTooLarge, false, fmt.Sprintf("%s files are limited to %d MB", kind.ContentType, kind.MaxBytes>>20), nil, nil)
+		return
+	}
+	if len(data) == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "empty file", nil, nil)
+		return
+	}
+	if sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data)); sniffed != kind.Sniffed {
+		utils.WriteJSONResponse(w, http.StatusUnsupportedMediaType, false, "file content does not match its extension", nil, sniffed)
+		return
+	}
+
+	key, err := h.files.SaveFile(fmt.Sprintf("note-attachments/%d", note.ID), header.Filename, bytes.NewReader(data))
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to save file", nil, err.Error())
+		return
+	}
+	a := &models.NoteAttachment{
+		NoteID:      note.ID,
+		FileName:    filepath.Base(header.Filename),
+		ContentType: kind.ContentType,
+		SizeBytes:   int64(len(data)),
+		StorageKey:  key,
+		Caption:     r.FormValue("caption"),
+		Metadata:    attachmentMetadata(kind, data),
+		UploadedBy:  current.ID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.store.CreateNoteAttachment(ctx, a); err != nil {
+		discardUpload(ctx, h.store, h.files, store.StorageUploads, key)
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to save attachment", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "attachment uploaded", attachmentResponse{*a, attachmentDownloadPath(a)}, nil)
+}
+
+// GET /notes/{id}/attachments
+func (h *NotesHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanAccessNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	list, err := h.store.ListNoteAttachments(ctx, note.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching attachments", nil, err.Error())
+		return
+	}
+	out := make([]attachmentResponse, 0, len(list))
+	for i := range list {
+		out = append(out, attachmentResponse{list[i], attachmentDownloadPath(&list[i])})
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", out, nil)
+}
+
+// GET /notes/{id}/attachments/{attachmentId}/download - checks the note's
+// visibility, then redirects to a short-lived signed URL when the storage
+// supports one, or streams the file. ?redirect=false returns the signed URL
+// as JSON instead.
+func (h *NotesHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	attachmentID, ok := uintParam(r, "attachmentId")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid attachment id", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanAccessNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	a, err := h.store.GetNoteAttachment(ctx, note.ID, attachmentID)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "attachment not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching attachment", nil, err.Error())
+		return
+	}
+
+	if signer, ok := h.files.(utils.URLSigner); ok {
+		url, err := signer.SignedURL(a.StorageKey, a.FileName, attachmentURLExpiry)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error signing download url", nil, err.Error())
+			return
+		}
+		if r.URL.Query().Get("redirect") == "false" {
+			utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{
+				"url":        url,
+				"expires_at": time.Now().Add(attachmentURLExpiry),
+			}, nil)
+			return
+		}
+		http.Redirect(w, r, url, http.StatusFound)
+		return
+	}
+
+	rc, err := h.files.Open(a.StorageKey)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error reading attachment", nil, err.Error())
+		return
+	}
+	defer rc.Close()
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName}))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = io.Copy(w, rc)
+}
+
+// DELETE /notes/{id}/attachments/{attachmentId}
+func (h *NotesHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	attachmentID, ok := uintParam(r, "attachmentId")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid attachment id", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanModifyNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	err := h.store.WithTx(ctx, func(tx *store.Store) error {
+		a, err := tx.GetNoteAttachment(ctx, note.ID, attachmentID)
+		if err != nil {
+			return err
+		}
+		if err := tx.DeleteNoteAttachment(ctx, note.ID, a.ID); err != nil {
+			return err
+		}
+		return tx.EnqueueStorageDelete(ctx, store.StorageUploads, a.StorageKey)
+	})
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "attachment not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "delete failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "attachment deleted", nil, nil)
+}
@@ -0,0 +1,291 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+const maxCommentLength = 5000
+
+// commentBody validates a comment body and its @mentions against the note's
+// audience, writing the error response itself.
+func (h *NotesHandler) commentBody(w http.ResponseWriter, r *http.Request, note *models.Note, body string) (string, []string, bool) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "body is required", nil, nil)
+		return "", nil, false
+	}
+	if len(body) > maxCommentLength {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "comment is too long", nil, nil)
+		return "", nil, false
+	}
+	mentions := store.ParseMentions(body)
+	bad, err := h.store.UnmentionableUsers(r.Context(), note, mentions)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error checking mentions", nil, err.Error())
+		return "", nil, false
+	}
+	if len(bad) > 0 {
+		utils.WriteJSONResponse(w, http.StatusUnprocessableEntity, false, "some mentioned users can't see this note", map[string]interface{}{"user_ids": bad}, nil)
+		return "", nil, false
+	}
+	return body, mentions, true
+}
+
+// publishMentions queues, in tx, a notification for users newly mentioned
+// in comment c.
+func (h *NotesHandler) publishMentions(r *http.Request, tx *store.Store, note *models.Note, c *models.NoteComment, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return h.events.Enqueue(r.Context(), tx, service.Event{
+		Type:    service.EventNoteMentioned,
+		ActorID: c.AuthorID,
+		UserIDs: userIDs,
+		Data:    map[string]interface{}{"note_id": note.ID, "title": note.Title, "student_id": note.UserID, "comment_id": c.ID},
+	})
+}
+
+// commentFromURL loads the {commentId} comment of note, writing the error
+// response itself.
+func (h *NotesHandler) commentFromURL(w http.ResponseWriter, r *http.Request, note *models.Note) (*models.NoteComment, bool) {
+	commentID, ok := uintParam(r, "commentId")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid comment id", nil, nil)
+		return nil, false
+	}
+	c, err := h.store.GetNoteComment(r.Context(), note.ID, commentID)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "comment not found", nil, nil)
+			return nil, false
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching comment", nil, err.Error())
+		return nil, false
+	}
+	return c, true
+}
+
+// GET /notes/{id}/comments - threads, oldest first
+func (h *NotesHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanAccessNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	threads, err := h.store.ListNoteComments(ctx, note.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching comments", nil, err.Error())
+		return
+	}
+	if threads == nil {
+		threads = []*models.NoteComment{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", threads, nil)
+}
+
+// POST /notes/{id}/comments - anyone who can read the note can comment.
+// Body: {"body": "... @USR00ABCDE ...", "parent_id": 12}
+func (h *NotesHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanAccessNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	var in struct {
+		Body     string `json:"body"`
+		ParentID *uint  `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid json", nil, err.Error())
+		return
+	}
+	body, mentions, ok := h.commentBody(w, r, note, in.Body)
+	if !ok {
+		return
+	}
+	c := &models.NoteComment{NoteID: note.ID, ParentID: in.ParentID, AuthorID: current.ID, Body: body}
+	err := h.store.WithTx(ctx, func(tx *store.Store) error {
+		if err := tx.CreateNoteComment(ctx, c, mentions); err != nil {
+			return err
+		}
+		return h.publishMentions(r, tx, note, c, mentions)
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidParentComment) {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to add comment", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "comment added", c, nil)
+}
+
+// PATCH /notes/{id}/comments/{commentId} - author only
+func (h *NotesHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	// The author may have lost access to the note since commenting.
+	if !h.store.CanAccessNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	c, ok := h.commentFromURL(w, r, note)
+	if !ok {
+		return
+	}
+	if c.AuthorID != current.ID {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "only the author can edit a comment", nil, nil)
+		return
+	}
+	var in struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid json", nil, err.Error())
+		return
+	}
+	body, mentions, ok := h.commentBody(w, r, note, in.Body)
+	if !ok {
+		return
+	}
+	already := map[string]bool{}
+	for _, m := range c.Mentions {
+		already[m.UserID] = true
+	}
+	var added []string
+	for _, id := range mentions {
+		if !already[id] {
+			added = append(added, id)
+		}
+	}
+	err := h.store.WithTx(ctx, func(tx *store.Store) error {
+		if err := tx.UpdateNoteComment(ctx, c, body, mentions); err != nil {
+			return err
+		}
+		return h.publishMentions(r, tx, note, c, added)
+	})
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "comment updated", c, nil)
+}
+
+// DELETE /notes/{id}/comments/{commentId} - the author, or anyone who can
+// edit the note (moderation)
+func (h *NotesHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanAccessNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	c, ok := h.commentFromURL(w, r, note)
+	if !ok {
+		return
+	}
+	if c.AuthorID != current.ID && !h.store.CanModifyNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	if err := h.store.DeleteNoteComment(ctx, c); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "delete failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "comment deleted", nil, nil)
+}
+
+// GET /users/me/mentions?unread=true&limit=&offset=
+func (h *NotesHandler) ListMyMentions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	items, err := h.store.ListMentions(ctx, current, q.Get("unread") == "true", limit, offset)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching mentions", nil, err.Error())
+		return
+	}
+	if items == nil {
+		items = []store.MentionInboxItem{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", items, nil)
+}
+
+// POST /users/me/mentions/read - {"comment_ids": [..]}; empty marks all
+func (h *NotesHandler) MarkMentionsRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	var in struct {
+		CommentIDs []uint `json:"comment_ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid json", nil, err.Error())
+			return
+		}
+	}
+	n, err := h.store.MarkMentionsRead(ctx, current.ID, in.CommentIDs)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]int64{"marked": n}, nil)
+}
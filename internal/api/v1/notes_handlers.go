@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -9,18 +10,21 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/madhava-poojari/dashboard-api/internal/auth"
 	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
 	"github.com/madhava-poojari/dashboard-api/internal/store"
 	"github.com/madhava-poojari/dashboard-api/internal/utils"
 )
 
 // NotesHandler holds store pointer
 type NotesHandler struct {
-	store *store.Store
+	store  *store.Store
+	files  utils.Storage
+	events *service.EventBus
 }
 
 // NewNotesHandler matches your AuthHandler style
-func NewNotesHandler(s serviceStore) *NotesHandler {
-	return &NotesHandler{store: s.Store}
+func NewNotesHandler(s serviceStore, files utils.Storage, events *service.EventBus) *NotesHandler {
+	return &NotesHandler{store: s.Store, files: files, events: events}
 }
 
 // POST /api/v1/notes
@@ -31,7 +35,7 @@ func (h *NotesHandler) CreateNote(w http.ResponseWriter, r *http.Request) {
 		Description    string                 `json:"description"`
 		PrimaryTag     string                 `json:"primary_tag"`
 		Tags           []string               `json:"tags"`
-		Visibility     int                    `json:"visibility"`
+		Visibility     interface{}            `json:"visibility"` // level name, or legacy 1-4
 		AdditionalInfo map[string]interface{} `json:"additional_info"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -74,6 +78,16 @@ func (h *NotesHandler) CreateNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	visibility := models.NoteVisibilityStudentAndStaff
+	if req.Visibility != nil {
+		v, ok := parseNoteVisibility(req.Visibility)
+		if !ok {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid visibility", nil, nil)
+			return
+		}
+		visibility = v
+	}
+
 	n := &models.Note{
 		UserID:         req.UserID,
 		Title:          req.Title,
@@ -82,13 +96,22 @@ func (h *NotesHandler) CreateNote(w http.ResponseWriter, r *http.Request) {
 		Tags:           utils.DatatypesJSONFromStrings(req.Tags),
 		IsStarred:      false,
 		AdditionalInfo: utils.DatatypesJSONFromMap(req.AdditionalInfo),
-		Visibility:     req.Visibility,
+		Visibility:     visibility,
 		CreatedBy:      current.ID,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
 
-	if err := h.store.CreateNote(ctx, n); err != nil {
+	err = h.store.WithTx(ctx, func(tx *store.Store) error {
+		if err := tx.CreateNote(ctx, n); err != nil {
+			return err
+		}
+		if err := h.publishNoteEvent(ctx, tx, service.EventNoteAddressed, current.ID, n, []string{n.UserID}); err != nil {
+			return err
+		}
+		return h.publishNoteEvent(ctx, tx, service.EventNoteCreated, current.ID, n, nil)
+	})
+	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "create note failed", nil, err.Error())
 		return
 	}
@@ -104,11 +127,23 @@ func (h *NotesHandler) CreateLessonPlan(w http.ResponseWriter, r *http.Request)
 		StartDate   string   `json:"start_date"`
 		EndDate     string   `json:"end_date"`
 		Result      string   `json:"result"`
+		// Items are the curriculum; without them each description line
+		// becomes a pending item.
+		Items []lessonPlanItemInput `json:"items"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
 		return
 	}
+	items := make([]models.LessonPlanItem, 0, len(req.Items))
+	for _, in := range req.Items {
+		item, err := in.toModel()
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+			return
+		}
+		items = append(items, item)
+	}
 	ctx := r.Context()
 	current := auth.GetUserFromCtx(ctx)
 	if current == nil {
@@ -143,6 +178,7 @@ func (h *NotesHandler) CreateLessonPlan(w http.ResponseWriter, r *http.Request)
 		CreatedBy:   current.ID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+		Items:       items,
 	}
 	if t, err := time.Parse(time.RFC3339, req.StartDate); err == nil {
 		lp.StartDate = t
@@ -188,26 +224,7 @@ func (h *NotesHandler) UpdateLessonPlan(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Permission Check: Admin or Mentor Coach of the student
-	// Only Admin and Mentor Coach can update the lesson plan
-	allowed := false
-	if current.Role == "admin" {
-		allowed = true
-	} else {
-		isMentor, err := h.store.IsMentorOf(ctx, current.ID, lp.UserID)
-		if err == nil && isMentor {
-			allowed = true
-		} else {
-			isCoach, err := h.store.IsCoachOf(ctx, current.ID, lp.UserID)
-			if err == nil && isCoach {
-				allowed = true
-			}
-
-		}
-
-	}
-
-	if !allowed {
+	if !h.canEditLessonPlan(ctx, current, &lp) {
 		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
 		return
 	}
@@ -225,6 +242,11 @@ func (h *NotesHandler) UpdateLessonPlan(w http.ResponseWriter, r *http.Request)
 	}
 	if req.Active != nil {
 		updates["active"] = *req.Active
+		if *req.Active {
+			updates["archived_at"] = nil
+		} else if lp.ArchivedAt == nil {
+			updates["archived_at"] = time.Now()
+		}
 	}
 	if req.StartDate != nil {
 		if t, err := time.Parse(time.RFC3339, *req.StartDate); err == nil {
@@ -238,7 +260,7 @@ func (h *NotesHandler) UpdateLessonPlan(w http.ResponseWriter, r *http.Request)
 	}
 
 	if len(updates) > 0 {
-		if err := h.store.UpdateLessonPlanFields(ctx, planID, updates); err != nil {
+		if err := h.store.UpdateLessonPlanFields(ctx, lp.ID, updates, current.ID); err != nil {
 			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update failed", nil, err.Error())
 			return
 		}
@@ -284,21 +306,42 @@ func (h *NotesHandler) GetNotesByUser(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	notes, lp, err := h.store.GetNotesByStudent(ctx, userID, limit, offset)
+	// Cursor paging is opt-in; limit+offset keeps the original shape.
+	lq, err := parseListQuery(r, store.NoteListSpec)
 	if err != nil {
-		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching notes", nil, err.Error())
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
 		return
 	}
-
-	// filter by visibility
-	filtered := []*models.Note{}
-	for _, n := range notes {
-		if h.store.CanAccessNoteForRequester(ctx, current, n) {
-			filtered = append(filtered, n)
+	for i, v := range lq.Filters["visibility"] {
+		vis, ok := parseNoteVisibility(v)
+		if !ok {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid visibility filter "+strconv.Quote(v), nil, nil)
+			return
+		}
+		lq.Filters["visibility"][i] = string(vis)
+	}
+	if lq.Cursor != "" || (lq.Limit > 0 && r.URL.Query().Get("offset") == "") {
+		page, err := h.store.ListNotesPage(ctx, current, userID, lq)
+		if err != nil {
+			writeListError(w, err, "error fetching notes")
+			return
+		}
+		lp, err := h.store.GetActiveLessonPlan(ctx, userID)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching lesson plan", nil, err.Error())
+			return
 		}
+		utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{"lesson_plan": lp, "notes": page}, nil)
+		return
+	}
+
+	notes, lp, err := h.store.GetNotesByStudent(ctx, current, userID, limit, offset)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching notes", nil, err.Error())
+		return
 	}
 
-	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{"lesson_plan": lp, "notes": filtered}, nil)
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{"lesson_plan": lp, "notes": notes}, nil)
 }
 
 // PATCH /api/v1/notes/{id}
@@ -322,12 +365,17 @@ func (h *NotesHandler) UpdateNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.store.CanAccessNoteForRequester(ctx, current, &note) {
+	if !h.store.CanModifyNoteForRequester(ctx, current, &note) {
 		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
 		return
 	}
+	updates, errMsg := noteUpdates(payload)
+	if errMsg != "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, errMsg, nil, nil)
+		return
+	}
 
-	if err := h.store.UpdateNoteFields(ctx, noteID, payload); err != nil {
+	if err := h.store.UpdateNoteFields(ctx, note.ID, updates, current.ID); err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update failed", nil, err.Error())
 		return
 	}
@@ -349,13 +397,442 @@ func (h *NotesHandler) DeleteNote(w http.ResponseWriter, r *http.Request) {
 		utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, err.Error())
 		return
 	}
-	if !h.store.CanAccessNoteForRequester(ctx, current, &note) {
+	if !h.store.CanModifyNoteForRequester(ctx, current, &note) {
 		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
 		return
 	}
-	if err := h.store.DeleteNoteSoft(ctx, noteID); err != nil {
+	if err := h.store.DeleteNoteSoft(ctx, note.ID, current.ID); err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "delete failed", nil, err.Error())
 		return
 	}
 	utils.WriteJSONResponse(w, http.StatusOK, true, "deleted", nil, nil)
 }
+
+// noteUpdates turns a PATCH payload into column updates. Only the note's
+// content can change; the student, author and timestamps can't. Returns an
+// error message (empty if valid).
+func noteUpdates(payload map[string]interface{}) (map[string]interface{}, string) {
+	updates := map[string]interface{}{}
+	for k, v := range payload {
+		switch k {
+		case "title", "description", "primary_tag":
+			s, ok := v.(string)
+			if !ok {
+				return nil, k + " must be a string"
+			}
+			updates[k] = s
+		case "is_starred":
+			b, ok := v.(bool)
+			if !ok {
+				return nil, k + " must be a boolean"
+			}
+			updates[k] = b
+		case "tags":
+			raw, ok := v.([]interface{})
+			if !ok {
+				return nil, "tags must be a list of strings"
+			}
+			tags := make([]string, 0, len(raw))
+			for _, t := range raw {
+				s, ok := t.(string)
+				if !ok {
+					return nil, "tags must be a list of strings"
+				}
+				tags = append(tags, s)
+			}
+			updates[k] = utils.DatatypesJSONFromStrings(tags)
+		case "additional_info":
+			m, ok := v.(map[string]interface{})
+			if !ok && v != nil {
+				return nil, "additional_info must be an object"
+			}
+			updates[k] = utils.DatatypesJSONFromMap(m)
+		case "visibility":
+			vis, ok := parseNoteVisibility(v)
+			if !ok {
+				return nil, "invalid visibility"
+			}
+			updates[k] = vis
+		default:
+			return nil, k + " can't be updated"
+		}
+	}
+	if len(updates) == 0 {
+		return nil, "no updates provided"
+	}
+	return updates, ""
+}
+
+// parseNoteVisibility accepts a level name, or the legacy 1-4 values as
+// numbers or strings.
+func parseNoteVisibility(v interface{}) (models.NoteVisibility, bool) {
+	legacy := map[string]models.NoteVisibility{
+		"1": models.NoteVisibilityAdminOnly,
+		"2": models.NoteVisibilityMentors,
+		"3": models.NoteVisibilityStaff,
+		"4": models.NoteVisibilityStudentAndStaff,
+	}
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case float64:
+		s = strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return "", false
+	}
+	if l, ok := legacy[s]; ok {
+		return l, true
+	}
+	switch vis := models.NoteVisibility(s); vis {
+	case models.NoteVisibilityAdminOnly, models.NoteVisibilityMentors, models.NoteVisibilityStaff,
+		models.NoteVisibilityStudentAndStaff, models.NoteVisibilityParents:
+		return vis, true
+	}
+	return "", false
+}
+
+// publishNoteEvent queues, in tx, an event telling userIDs about something
+// that happened on n. The notification service drops anyone who can't read
+// the note.
+func (h *NotesHandler) publishNoteEvent(ctx context.Context, tx *store.Store, eventType, actorID string, n *models.Note, userIDs []string) error {
+	return h.events.Enqueue(ctx, tx, service.Event{
+		Type:    eventType,
+		ActorID: actorID,
+		UserIDs: userIDs,
+		Data:    map[string]interface{}{"note_id": n.ID, "title": n.Title, "student_id": n.UserID},
+	})
+}
+
+// noteFromURL loads the {id} note, writing the error response itself.
+func (h *NotesHandler) noteFromURL(w http.ResponseWriter, r *http.Request) (*models.Note, bool) {
+	var note models.Note
+	if err := h.store.DB.WithContext(r.Context()).First(&note, "id = ?", chi.URLParam(r, "id")).Error; err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return nil, false
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return nil, false
+	}
+	return &note, true
+}
+
+// GET /notes/{id}/shares
+func (h *NotesHandler) ListShares(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanModifyNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	shares, err := h.store.ListNoteShares(ctx, note.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching shares", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", shares, nil)
+}
+
+// POST /notes/{id}/shares {"user_id": "..."} - give one user read access
+func (h *NotesHandler) ShareNote(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "user_id is required", nil, nil)
+		return
+	}
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanModifyNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	if _, err := h.store.GetUserByID(ctx, req.UserID); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "No such user", nil, err.Error())
+		return
+	}
+	var share *models.NoteShare
+	err := h.store.WithTx(ctx, func(tx *store.Store) error {
+		var err error
+		if share, err = tx.ShareNote(ctx, note.ID, req.UserID, current.ID); err != nil {
+			return err
+		}
+		return h.publishNoteEvent(ctx, tx, service.EventNoteShared, current.ID, note, []string{req.UserID})
+	})
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "share failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "shared", share, nil)
+}
+
+// DELETE /notes/{id}/shares/{userId}
+func (h *NotesHandler) UnshareNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanModifyNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	if err := h.store.UnshareNote(ctx, note.ID, chi.URLParam(r, "userId")); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "note is not shared with this user", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "unshare failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "unshared", nil, nil)
+}
+
+// canEditLessonPlan: admin, or the student's mentor or coach.
+func (h *NotesHandler) canEditLessonPlan(ctx context.Context, current *models.User, lp *models.LessonPlan) bool {
+	if current.Role == models.RoleAdmin {
+		return true
+	}
+	if isMentor, err := h.store.IsMentorOf(ctx, current.ID, lp.UserID); err == nil && isMentor {
+		return true
+	}
+	isCoach, err := h.store.IsCoachOf(ctx, current.ID, lp.UserID)
+	return err == nil && isCoach
+}
+
+// revisionParam parses a revision number URL param or query value.
+func revisionParam(v string) (int, bool) {
+	n, err := strconv.Atoi(v)
+	return n, err == nil && n > 0
+}
+
+// GET /notes/{id}/revisions - revisions written at a visibility level the
+// requester is outside of are left out.
+func (h *NotesHandler) ListNoteRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanModifyNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	revs, err := h.store.ListNoteRevisions(ctx, current, note.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching revisions", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", revs, nil)
+}
+
+// GET /notes/{id}/revisions/diff?from=&to=
+func (h *NotesHandler) DiffNoteRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	from, ok1 := revisionParam(r.URL.Query().Get("from"))
+	to, ok2 := revisionParam(r.URL.Query().Get("to"))
+	if !ok1 || !ok2 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "from and to revisions are required", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanModifyNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	a, err := h.store.GetNoteRevision(ctx, current, note.ID, from)
+	if err == nil {
+		var b *models.NoteRevision
+		if b, err = h.store.GetNoteRevision(ctx, current, note.ID, to); err == nil {
+			utils.WriteJSONResponse(w, http.StatusOK, true, "ok", store.DiffNoteRevisions(a, b), nil)
+			return
+		}
+	}
+	if store.IsNotFound(err) {
+		utils.WriteJSONResponse(w, http.StatusNotFound, false, "revision not found", nil, nil)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching revisions", nil, err.Error())
+}
+
+// POST /notes/{id}/revisions/{rev}/restore
+func (h *NotesHandler) RestoreNoteRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	rev, ok := revisionParam(chi.URLParam(r, "rev"))
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid revision", nil, nil)
+		return
+	}
+	note, ok := h.noteFromURL(w, r)
+	if !ok {
+		return
+	}
+	if !h.store.CanModifyNoteForRequester(ctx, current, note) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	if err := h.store.RestoreNoteRevision(ctx, current, note.ID, rev); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "revision not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "restore failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "restored", nil, nil)
+}
+
+// lessonPlanFromURL loads the {id} lesson plan and checks edit access,
+// writing the error response itself.
+func (h *NotesHandler) lessonPlanFromURL(w http.ResponseWriter, r *http.Request) (*models.LessonPlan, *models.User, bool) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return nil, nil, false
+	}
+	var lp models.LessonPlan
+	if err := h.store.DB.WithContext(ctx).First(&lp, "id = ?", chi.URLParam(r, "id")).Error; err != nil {
+		utils.WriteJSONResponse(w, http.StatusNotFound, false, "lesson plan not found", nil, err.Error())
+		return nil, nil, false
+	}
+	if !h.canEditLessonPlan(ctx, current, &lp) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return nil, nil, false
+	}
+	return &lp, current, true
+}
+
+// GET /notes/lesson-plans/{id}/revisions
+func (h *NotesHandler) ListLessonPlanRevisions(w http.ResponseWriter, r *http.Request) {
+	lp, _, ok := h.lessonPlanFromURL(w, r)
+	if !ok {
+		return
+	}
+	revs, err := h.store.ListLessonPlanRevisions(r.Context(), lp.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching revisions", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", revs, nil)
+}
+
+// GET /notes/lesson-plans/{id}/revisions/diff?from=&to=
+func (h *NotesHandler) DiffLessonPlanRevisions(w http.ResponseWriter, r *http.Request) {
+	from, ok1 := revisionParam(r.URL.Query().Get("from"))
+	to, ok2 := revisionParam(r.URL.Query().Get("to"))
+	if !ok1 || !ok2 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "from and to revisions are required", nil, nil)
+		return
+	}
+	lp, _, ok := h.lessonPlanFromURL(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	a, err := h.store.GetLessonPlanRevision(ctx, lp.ID, from)
+	if err == nil {
+		var b *models.LessonPlanRevision
+		if b, err = h.store.GetLessonPlanRevision(ctx, lp.ID, to); err == nil {
+			utils.WriteJSONResponse(w, http.StatusOK, true, "ok", store.DiffLessonPlanRevisions(a, b), nil)
+			return
+		}
+	}
+	if store.IsNotFound(err) {
+		utils.WriteJSONResponse(w, http.StatusNotFound, false, "revision not found", nil, nil)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching revisions", nil, err.Error())
+}
+
+// POST /notes/lesson-plans/{id}/revisions/{rev}/restore
+func (h *NotesHandler) RestoreLessonPlanRevision(w http.ResponseWriter, r *http.Request) {
+	rev, ok := revisionParam(chi.URLParam(r, "rev"))
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid revision", nil, nil)
+		return
+	}
+	lp, current, ok := h.lessonPlanFromURL(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.RestoreLessonPlanRevision(r.Context(), lp.ID, rev, current.ID); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "revision not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "restore failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "restored", nil, nil)
+}
+
+// GET /admin/notes/deleted?user_id= - soft-deleted notes
+func (h *NotesHandler) ListDeletedNotes(w http.ResponseWriter, r *http.Request) {
+	notes, err := h.store.ListDeletedNotes(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching deleted notes", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", notes, nil)
+}
+
+// POST /admin/notes/{id}/undelete
+func (h *NotesHandler) UndeleteNote(w http.ResponseWriter, r *http.Request) {
+	current := auth.GetUserFromCtx(r.Context())
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	if err := h.store.UndeleteNote(r.Context(), uint(id), current.ID); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "no deleted note with this id", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "undelete failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "undeleted", nil, nil)
+}
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+)
+
+func TestNoteUpdates(t *testing.T) {
+	decode := func(s string) map[string]interface{} {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	got, msg := noteUpdates(decode(`{"title": "Rook endings", "tags": ["endgame"], "is_starred": true, "visibility": 3}`))
+	if msg != "" {
+		t.Fatalf("valid payload rejected: %s", msg)
+	}
+	if got["title"] != "Rook endings" || got["is_starred"] != true || got["visibility"] != models.NoteVisibilityStaff {
+		t.Errorf("updates = %v", got)
+	}
+	if tags, _ := json.Marshal(got["tags"]); string(tags) != `["endgame"]` {
+		t.Errorf("tags = %s", tags)
+	}
+
+	for _, payload := range []string{
+		`{"user_id": "S00001"}`,
+		`{"title": "x", "created_by": "C00001"}`,
+		`{"id": 5}`,
+		`{"deleted_at": null}`,
+		`{"visibility": "everyone"}`,
+		`{"title": 5}`,
+		`{"tags": "endgame"}`,
+		`{}`,
+	} {
+		if _, msg := noteUpdates(decode(payload)); msg == "" {
+			t.Errorf("%s: accepted, want an error", payload)
+		}
+	}
+}
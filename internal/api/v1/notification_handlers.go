@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+	"gorm.io/datatypes"
+)
+
+type NotificationHandler struct {
+	store *store.Store
+}
+
+func NewNotificationHandler(s serviceStore) *NotificationHandler {
+	return &NotificationHandler{store: s.Store}
+}
+
+// GET /notifications?unread=true&limit=&offset=
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	list, err := h.store.ListNotifications(ctx, current.ID, q.Get("unread") == "true", limit, offset)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching notifications", nil, err.Error())
+		return
+	}
+	unread, err := h.store.CountUnreadNotifications(ctx, current.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error counting notifications", nil, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.Notification{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{
+		"notifications": list,
+		"unread":        unread,
+	}, nil)
+}
+
+// GET /notifications/unread-count
+func (h *NotificationHandler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	unread, err := h.store.CountUnreadNotifications(ctx, current.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error counting notifications", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]int64{"unread": unread}, nil)
+}
+
+// POST /notifications/read - {"ids": [..]}; empty marks all
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	var in struct {
+		IDs []uint `json:"ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid json", nil, err.Error())
+			return
+		}
+	}
+	n, err := h.store.MarkNotificationsRead(ctx, current.ID, in.IDs)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]int64{"marked": n}, nil)
+}
+
+// DELETE /notifications/{id}
+func (h *NotificationHandler) DeleteNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	if err := h.store.DeleteNotification(ctx, current.ID, id); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "delete failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "deleted", nil, nil)
+}
+
+// GET /notifications/preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	p, err := h.store.GetNotificationPreference(ctx, current.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching preferences", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", p, nil)
+}
+
+// PUT /notifications/preferences
+// Body: {"channels": ["in_app","email"], "event_channels": {"note.shared": []}, "webhook_url": "https://...", "digest_opt_out": false}
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	var in struct {
+		Channels      []string            `json:"channels"`
+		EventChannels map[string][]string `json:"event_channels"`
+		WebhookURL    string              `json:"webhook_url"`
+		DigestOptOut  bool                `json:"digest_opt_out"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid json", nil, err.Error())
+		return
+	}
+	usesWebhook := false
+	check := func(list []string) bool {
+		for _, c := range list {
+			if !store.ValidNotificationChannel(c) {
+				return false
+			}
+			if c == models.ChannelWebhook {
+				usesWebhook = true
+			}
+		}
+		return true
+	}
+	if in.Channels == nil {
+		in.Channels = []string{}
+	}
+	if !check(in.Channels) {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid channel", nil, nil)
+		return
+	}
+	events := datatypes.JSONMap{}
+	for t, list := range in.EventChannels {
+		if !check(list) {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid channel for "+t, nil, nil)
+			return
+		}
+		if list == nil {
+			list = []string{}
+		}
+		events[t] = list
+	}
+	if in.WebhookURL != "" {
+		u, err := url.Parse(in.WebhookURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "webhook_url must be an https URL", nil, nil)
+			return
+		}
+	} else if usesWebhook {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "webhook_url is required for the webhook channel", nil, nil)
+		return
+	}
+	channels, _ := json.Marshal(in.Channels)
+	p := &models.NotificationPreference{
+		UserID:        current.ID,
+		Channels:      datatypes.JSON(channels),
+		EventChannels: events,
+		WebhookURL:    in.WebhookURL,
+		DigestOptOut:  in.DigestOptOut,
+	}
+	if err := h.store.SaveNotificationPreference(ctx, p); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "preferences updated", p, nil)
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// OfficialRatingHandler serves FIDE/USCF ratings imported by cmd/ratings-import.
+type OfficialRatingHandler struct {
+	store *store.Store
+}
+
+func NewOfficialRatingHandler(s serviceStore) *OfficialRatingHandler {
+	return &OfficialRatingHandler{store: s.Store}
+}
+
+// GET /users/{id}/official-ratings - full rating and title history
+func (h *OfficialRatingHandler) GetUserOfficialRatings(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if !CanAccessStudentData(ctx, h.store, current, id) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+
+	history, err := h.store.ListOfficialRatings(ctx, id)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching official ratings", nil, err.Error())
+		return
+	}
+	flags, err := h.store.ListOpenRatingIDMismatches(ctx, []string{id})
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching rating flags", nil, err.Error())
+		return
+	}
+
+	byFederation := map[string][]models.OfficialRating{"fide": {}, "uscf": {}}
+	for _, r := range history {
+		byFederation[r.Federation] = append(byFederation[r.Federation], r)
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", map[string]interface{}{
+		"history": byFederation,
+		"flags":   flags,
+	}, nil)
+}
+
+// GET /reports/official-ratings - latest official ratings for every student
+// the requester can see (admin: all active students; coach/mentor: their own).
+func (h *OfficialRatingHandler) GetRosterReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+
+	var students []*models.User
+	var err error
+	switch current.Role {
+	case models.RoleAdmin:
+		students, err = h.store.ListActiveStudents(ctx, true)
+	case models.RoleCoach, models.RoleMentor:
+		students, err = h.store.ListStudentsForCoachOrMentor(ctx, current.ID)
+	default:
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching students", nil, err.Error())
+		return
+	}
+
+	rows, err := h.store.OfficialRatingsRoster(ctx, students)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error building report", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", rows, nil)
+}
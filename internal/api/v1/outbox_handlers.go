@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// OutboxHandler lets admins inspect the outbox and retry dead letters.
+type OutboxHandler struct {
+	store *store.Store
+}
+
+func NewOutboxHandler(s serviceStore) *OutboxHandler {
+	return &OutboxHandler{store: s.Store}
+}
+
+// GET /admin/outbox?status=dead&kind=storage.delete&limit=&offset=
+func (h *OutboxHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	switch status {
+	case "", models.OutboxPending, models.OutboxDone, models.OutboxDead:
+	default:
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "status must be pending, done or dead", nil, nil)
+		return
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := h.store.ListOutboxMessages(r.Context(), status, q.Get("kind"), limit, offset)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching outbox", nil, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.OutboxMessage{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{
+		"messages": list,
+		"total":    total,
+	}, nil)
+}
+
+// POST /admin/outbox/{id}/retry - puts a dead message back in the queue.
+func (h *OutboxHandler) RetryMessage(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	if err := h.store.RetryOutboxMessage(r.Context(), id); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "no dead message with that id", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "retry failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusAccepted, true, "message queued", nil, nil)
+}
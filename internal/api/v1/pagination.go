@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// parseListQuery reads limit, cursor, sort=field:dir[,field:dir], total=true
+// and any of the spec's filter fields (comma separated values match any).
+func parseListQuery(r *http.Request, spec store.ListSpec) (store.ListQuery, error) {
+	q := r.URL.Query()
+	lq := store.ListQuery{
+		Cursor:    q.Get("cursor"),
+		WithTotal: q.Get("total") == "true",
+		Filters:   map[string][]string{},
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > store.MaxPageSize {
+			return lq, errors.New("invalid limit")
+		}
+		lq.Limit = n
+	}
+	sort, err := spec.ParseSort(q.Get("sort"))
+	if err != nil {
+		return lq, err
+	}
+	lq.Sort = sort
+	for field := range spec.Filters {
+		if v := q.Get(field); v != "" {
+			lq.Filters[field] = strings.Split(v, ",")
+		}
+	}
+	return lq, nil
+}
+
+// writeListError answers a failed page query: bad cursors and filter values
+// are the client's fault, anything else is ours.
+func writeListError(w http.ResponseWriter, err error, msg string) {
+	if errors.Is(err, store.ErrInvalidCursor) || errors.Is(err, store.ErrInvalidFilter) {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusInternalServerError, false, msg, nil, err.Error())
+}
@@ -0,0 +1,182 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+type RatingHandler struct {
+	store *store.Store
+}
+
+func NewRatingHandler(s serviceStore) *RatingHandler {
+	return &RatingHandler{store: s.Store}
+}
+
+// ratingPoint and ratingSeries are the chart-friendly response shape.
+type ratingPoint struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	Rating     int       `json:"rating"`
+	Games      int       `json:"games"`
+}
+
+type ratingSeries struct {
+	Source      string        `json:"source"`
+	TimeControl string        `json:"time_control"`
+	Current     int           `json:"current"`
+	Points      []ratingPoint `json:"points"`
+}
+
+// GET /users/{id}/ratings?source=&time_control=&from=&to=
+func (h *RatingHandler) GetRatingHistory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if !CanAccessStudentData(ctx, h.store, current, id) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+
+	q := r.URL.Query()
+	f := store.RatingHistoryFilter{
+		Source:      q.Get("source"),
+		TimeControl: q.Get("time_control"),
+	}
+	if v := q.Get("from"); v != "" {
+		t, err := parseDateFlexible(v)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid from", nil, err.Error())
+			return
+		}
+		f.From = &t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := parseDateFlexible(v)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid to", nil, err.Error())
+			return
+		}
+		f.To = &t
+	}
+
+	snaps, err := h.store.GetRatingHistory(ctx, id, f)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching ratings", nil, err.Error())
+		return
+	}
+	syncs, err := h.store.ListChessAccountSyncs(ctx, id)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching sync state", nil, err.Error())
+		return
+	}
+
+	// snapshots come ordered by source, time_control, recorded_at
+	series := []*ratingSeries{}
+	var cur *ratingSeries
+	for _, s := range snaps {
+		if cur == nil || cur.Source != s.Source || cur.TimeControl != s.TimeControl {
+			cur = &ratingSeries{Source: s.Source, TimeControl: s.TimeControl}
+			series = append(series, cur)
+		}
+		cur.Points = append(cur.Points, ratingPoint{RecordedAt: s.RecordedAt, Rating: s.Rating, Games: s.Games})
+		cur.Current = s.Rating
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", map[string]interface{}{
+		"series":   series,
+		"accounts": syncs,
+	}, nil)
+}
+
+// GET /users/{id}/games/since-last-class
+func (h *RatingHandler) GetGamesSinceLastClass(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if !CanAccessStudentData(ctx, h.store, current, id) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+
+	last, err := h.store.GetLastAttendanceDate(ctx, id)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching last class", nil, err.Error())
+		return
+	}
+	// With no class on record, fall back to the last 30 days.
+	since := time.Now().AddDate(0, 0, -30)
+	if last != nil {
+		since = *last
+	}
+
+	games, err := h.store.ListExternalGamesSince(ctx, id, since)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching games", nil, err.Error())
+		return
+	}
+
+	summary := map[string]int{"win": 0, "loss": 0, "draw": 0}
+	for _, g := range games {
+		summary[g.Result]++
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", map[string]interface{}{
+		"last_class_date": last,
+		"since":           since,
+		"summary":         summary,
+		"games":           games,
+	}, nil)
+}
+
+// POST /users/{id}/ratings/sync - queue a pull of ratings and games instead
+// of waiting for the next scheduled sync. The outbox runs it shortly.
+func (h *RatingHandler) SyncRatings(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	if !CanAccessStudentData(ctx, h.store, current, id) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+
+	if _, err := h.store.GetChessAccountForUser(ctx, id); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "user not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching user", nil, err.Error())
+		return
+	}
+	if err := h.store.EnqueueRatingSync(ctx, id); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "queue sync failed", nil, err.Error())
+		return
+	}
+	// The current cursor rows; poll them (or the history) for the result.
+	syncs, err := h.store.ListChessAccountSyncs(ctx, id)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching sync state", nil, err.Error())
+		return
+	}
+	if syncs == nil {
+		syncs = []models.ChessAccountSync{}
+	}
+	utils.WriteJSONResponse(w, http.StatusAccepted, true, "sync queued", syncs, nil)
+}
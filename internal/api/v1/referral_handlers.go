@@ -28,6 +28,21 @@ func NewReferralHandler(ss serviceStore) *ReferralHandler {
 func (rh *ReferralHandler) GetGraph(w http.ResponseWriter, r *http.Request) {
 	stateFilter := r.URL.Query().Get("state")
 
+	lq, err := parseListQuery(r, store.ReferralGraphListSpec)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+		return
+	}
+	if lq.Paged() {
+		data, err := rh.referralService.GetNetworkGraphPage(r.Context(), stateFilter, lq)
+		if err != nil {
+			writeListError(w, err, "Failed to fetch network graph")
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusOK, true, "Network graph fetched successfully", data, nil)
+		return
+	}
+
 	data, err := rh.referralService.GetNetworkGraph(r.Context(), stateFilter)
 	if err != nil {
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "Failed to fetch network graph", nil, err)
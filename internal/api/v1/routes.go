@@ -21,10 +21,11 @@ type API struct {
 	cfg    *config.Config
 	router *chi.Mux
 	store  *store.Store
+	svcs   *service.Services
 }
 
-func NewAPI(cfg *config.Config, s *store.Store) *API {
-	api := &API{cfg: cfg, router: chi.NewRouter(), store: s}
+func NewAPI(cfg *config.Config, s *store.Store, svcs *service.Services) *API {
+	api := &API{cfg: cfg, router: chi.NewRouter(), store: s, svcs: svcs}
 	api.router.Use(middleware.Logger)
 	// Use cors.Handler (not middleware.CORS)
 	// api.router.Use(cors.Handler(cors.Options{
@@ -56,12 +57,27 @@ func (a *API) routes() {
 
 	authH := NewAuthHandler(a.cfg, usvc, ss)
 	userH := NewUserHandler(ss)
-	adminH := NewAdminHandler(ss)
-	notesH := NewNotesHandler(ss)
-	attH := NewAttendanceHandler(ss)
-	imgH := NewImageHandler(ss, a.cfg)
-	scraperH := NewScraperHandler(ss, a.cfg)
+	adminH := NewAdminHandler(ss, a.svcs.Events)
+	notesH := NewNotesHandler(ss, a.svcs.Uploads, a.svcs.Events)
+	attH := NewAttendanceHandler(ss, a.svcs.Events)
+	imgH := NewImageHandler(ss, a.svcs.Images)
+	scraperH := NewScraperHandler(ss, a.cfg, a.svcs.Ingest, a.svcs.Events)
+	apiKeyH := NewAPIKeyHandler(ss)
 	referralH := NewReferralHandler(ss)
+	ratingH := NewRatingHandler(ss)
+	officialH := NewOfficialRatingHandler(ss)
+	tournamentH := NewTournamentHandler(ss)
+	searchH := NewSearchHandler(ss)
+	curriculumH := NewCurriculumHandler(ss)
+	assessmentH := NewAssessmentHandler(ss)
+	notificationH := NewNotificationHandler(ss)
+	schedH := NewScheduleHandler(ss)
+	messagingH := NewMessagingHandler(ss, a.cfg, a.svcs.Messaging)
+	webhookH := NewWebhookHandler(ss)
+	outboxH := NewOutboxHandler(ss)
+	jobH := NewJobHandler(a.svcs.Jobs)
+	digestH := NewDigestHandler(ss, a.svcs.Digest)
+	mentorH := NewMentorHandler(ss)
 
 	r := a.router
 	// auth routes
@@ -79,11 +95,36 @@ func (a *API) routes() {
 		r.Group(func(r chi.Router) {
 			r.Use(auth.AuthMiddleware(ss.Store))
 			r.Post("/", notesH.CreateNote)
+			r.Get("/lesson-plans", notesH.ListLessonPlans)
 			r.Post("/lesson-plans", notesH.CreateLessonPlan)
+			r.Get("/lesson-plans/{id}", notesH.GetLessonPlan)
 			r.Patch("/lesson-plans/{id}", notesH.UpdateLessonPlan)
+			r.Post("/lesson-plans/{id}/items", notesH.AddLessonPlanItem)
+			r.Put("/lesson-plans/{id}/items/order", notesH.ReorderLessonPlanItems)
+			r.Patch("/lesson-plans/{id}/items/{itemId}", notesH.UpdateLessonPlanItem)
+			r.Delete("/lesson-plans/{id}/items/{itemId}", notesH.DeleteLessonPlanItem)
+			r.Post("/lesson-plans/{id}/items/{itemId}/sessions", notesH.LinkLessonPlanItemSession)
+			r.Delete("/lesson-plans/{id}/items/{itemId}/sessions/{attendanceId}", notesH.UnlinkLessonPlanItemSession)
+			r.Get("/lesson-plans/{id}/revisions", notesH.ListLessonPlanRevisions)
+			r.Get("/lesson-plans/{id}/revisions/diff", notesH.DiffLessonPlanRevisions)
+			r.Post("/lesson-plans/{id}/revisions/{rev}/restore", notesH.RestoreLessonPlanRevision)
 			r.Get("/", notesH.GetNotesByUser)
 			r.Patch("/{id}", notesH.UpdateNote)
 			r.Delete("/{id}", notesH.DeleteNote)
+			r.Get("/{id}/shares", notesH.ListShares)
+			r.Post("/{id}/shares", notesH.ShareNote)
+			r.Delete("/{id}/shares/{userId}", notesH.UnshareNote)
+			r.Get("/{id}/revisions", notesH.ListNoteRevisions)
+			r.Get("/{id}/revisions/diff", notesH.DiffNoteRevisions)
+			r.Post("/{id}/revisions/{rev}/restore", notesH.RestoreNoteRevision)
+			r.Get("/{id}/attachments", notesH.ListAttachments)
+			r.Post("/{id}/attachments", notesH.UploadAttachment)
+			r.Get("/{id}/attachments/{attachmentId}/download", notesH.DownloadAttachment)
+			r.Delete("/{id}/attachments/{attachmentId}", notesH.DeleteAttachment)
+			r.Get("/{id}/comments", notesH.ListComments)
+			r.Post("/{id}/comments", notesH.CreateComment)
+			r.Patch("/{id}/comments/{commentId}", notesH.UpdateComment)
+			r.Delete("/{id}/comments/{commentId}", notesH.DeleteComment)
 		})
 	})
 
@@ -98,6 +139,17 @@ func (a *API) routes() {
 			r.Get("/{id}", attH.GetAttendance)
 			r.Patch("/{id}", attH.UpdateAttendance)
 			r.Delete("/{id}", attH.DeleteAttendance)
+			r.Post("/{id}/homework/send", messagingH.SendHomework)
+		})
+	})
+
+	// mentor analytics over their coaches
+	r.Route("/mentor", func(r chi.Router) {
+		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.AuthMiddleware(ss.Store))
+			r.Use(auth.RoleMiddleware("mentor", "admin"))
+			r.Get("/dashboard", mentorH.GetDashboard)
 		})
 	})
 
@@ -108,6 +160,8 @@ func (a *API) routes() {
 		r.With(authMiddleware).Get("/students", userH.GetStudents)
 		r.With(authMiddleware).Get("/coaches", userH.GetCoachesForAttendance)
 		r.With(authMiddleware).Get("/me", userH.GetSelfProfile)
+		r.With(authMiddleware).Get("/me/mentions", notesH.ListMyMentions)
+		r.With(authMiddleware).Post("/me/mentions/read", notesH.MarkMentionsRead)
 		r.With(authMiddleware).Post("/reset-password", userH.ResetOwnPassword)
 		r.With(authMiddleware).Get("/{id}", userH.GetUser)
 		r.With(authMiddleware).Put("/{id}", userH.UpdateUser)
@@ -124,6 +178,15 @@ func (a *API) routes() {
 
 		// Tournaments (nearby)
 		r.With(authMiddleware).Get("/{id}/tournaments", userH.GetTournaments)
+		r.With(authMiddleware).Get("/{id}/tournament-registrations", tournamentH.ListStudentRegistrations)
+
+		// Lichess / chess.com ratings and games
+		r.With(authMiddleware).Get("/{id}/ratings", ratingH.GetRatingHistory)
+		r.With(authMiddleware).Get("/{id}/games/since-last-class", ratingH.GetGamesSinceLastClass)
+		r.With(authMiddleware, auth.RoleMiddleware("coach", "mentor", "admin")).Post("/{id}/ratings/sync", ratingH.SyncRatings)
+
+		// FIDE / USCF official ratings (imported from published lists)
+		r.With(authMiddleware).Get("/{id}/official-ratings", officialH.GetUserOfficialRatings)
 	})
 
 	// Image routes (public gallery)
@@ -151,6 +214,50 @@ func (a *API) routes() {
 
 		// Unified assignment update endpoint (student<->coach, coach<->mentor)
 		adminGroup.Put("/assignments", adminH.UpdateAssignments)
+
+		// Deleted notes
+		adminGroup.Get("/notes/deleted", notesH.ListDeletedNotes)
+		adminGroup.Post("/notes/{id}/undelete", notesH.UndeleteNote)
+
+		// Scraper queue health
+		adminGroup.Get("/scraper/health", scraperH.GetHealth)
+		adminGroup.Post("/scraper/scopes/reset", scraperH.ResetScope)
+		adminGroup.Get("/scraper/pages", scraperH.ListPages)
+		adminGroup.Get("/scraper/pages/{id}", scraperH.GetPage)
+
+		// API keys for machine clients
+		adminGroup.Get("/api-keys", apiKeyH.ListAPIKeys)
+		adminGroup.Post("/api-keys", apiKeyH.CreateAPIKey)
+		adminGroup.Patch("/api-keys/{id}", apiKeyH.UpdateAPIKey)
+		adminGroup.Post("/api-keys/{id}/rotate", apiKeyH.RotateAPIKey)
+		adminGroup.Delete("/api-keys/{id}", apiKeyH.RevokeAPIKey)
+
+		// Holidays (no classes or reminders on these dates)
+		adminGroup.Post("/holidays", schedH.CreateHoliday)
+		adminGroup.Delete("/holidays/{id}", schedH.DeleteHoliday)
+
+		// Outbound webhooks for academy events
+		adminGroup.Get("/webhooks", webhookH.ListWebhooks)
+		adminGroup.Post("/webhooks", webhookH.CreateWebhook)
+		adminGroup.Patch("/webhooks/{id}", webhookH.UpdateWebhook)
+		adminGroup.Delete("/webhooks/{id}", webhookH.DeleteWebhook)
+		adminGroup.Post("/webhooks/{id}/rotate-secret", webhookH.RotateWebhookSecret)
+		adminGroup.Get("/webhooks/{id}/deliveries", webhookH.ListDeliveries)
+		adminGroup.Post("/webhooks/deliveries/{id}/replay", webhookH.ReplayDelivery)
+
+		// Outbox (queued side effects) and its dead letters
+		adminGroup.Get("/outbox", outboxH.ListMessages)
+		adminGroup.Post("/outbox/{id}/retry", outboxH.RetryMessage)
+
+		// Background jobs
+		adminGroup.Get("/jobs", jobH.ListJobs)
+		adminGroup.Post("/jobs/{name}/run", jobH.RunJob)
+		adminGroup.Post("/jobs/{name}/pause", jobH.PauseJob)
+		adminGroup.Post("/jobs/{name}/resume", jobH.ResumeJob)
+		adminGroup.Get("/jobs/{name}/runs", jobH.ListRuns)
+
+		// Weekly digest email, rendered without sending
+		adminGroup.Get("/digest/preview/{userId}", digestH.Preview)
 	})
 
 	r.Route("/referral-network", func(r chi.Router) {
@@ -170,6 +277,125 @@ func (a *API) routes() {
 		})
 	})
 
+	// Tournament registrations and results
+	r.Route("/tournaments", func(r chi.Router) {
+		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.AuthMiddleware(a.store))
+			r.Get("/", tournamentH.SearchTournaments)
+			r.With(auth.RoleMiddleware("coach", "mentor", "admin")).Get("/roster", tournamentH.GetUpcomingRoster)
+			r.Get("/changes", tournamentH.ListChanges)
+			r.Get("/{id}/history", tournamentH.GetHistory)
+			r.Get("/{id}/registrations", tournamentH.ListRegistrations)
+			r.Put("/{id}/registrations/{studentId}", tournamentH.SetRegistration)
+			r.Delete("/{id}/registrations/{studentId}", tournamentH.DeleteRegistration)
+			r.With(auth.RoleMiddleware("coach", "mentor", "admin")).Put("/{id}/registrations/{studentId}/result", tournamentH.RecordResult)
+		})
+	})
+
+	r.Route("/reports", func(r chi.Router) {
+		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.AuthMiddleware(a.store))
+			r.Use(auth.RoleMiddleware("coach", "mentor", "admin"))
+			r.Get("/official-ratings", officialH.GetRosterReport)
+			r.Get("/syllabus", curriculumH.GetSyllabusReport)
+		})
+	})
+
+	// Academy curriculum library, templates and topic mastery
+	r.Route("/curriculum", func(r chi.Router) {
+		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.AuthMiddleware(a.store))
+			r.Get("/", curriculumH.ListCurriculum)
+			r.Get("/levels/{id}", curriculumH.GetLevel)
+			r.Get("/students/{id}", curriculumH.GetStudentProgress)
+
+			staff := r.With(auth.RoleMiddleware("coach", "mentor", "admin"))
+			staff.Post("/levels/{id}/lesson-plans", curriculumH.CreateLessonPlanFromLevel)
+			staff.Put("/students/{id}/level", curriculumH.SetStudentLevel)
+			staff.Put("/students/{id}/mastery/{topicId}", curriculumH.MarkTopicMastered)
+			staff.Delete("/students/{id}/mastery/{topicId}", curriculumH.UnmarkTopicMastered)
+
+			admin := r.With(auth.RoleMiddleware("admin"))
+			admin.Post("/levels", curriculumH.CreateLevel)
+			admin.Patch("/levels/{id}", curriculumH.UpdateLevel)
+			admin.Delete("/levels/{id}", curriculumH.DeleteLevel)
+			admin.Post("/levels/{id}/modules", curriculumH.CreateModule)
+			admin.Patch("/modules/{id}", curriculumH.UpdateModule)
+			admin.Delete("/modules/{id}", curriculumH.DeleteModule)
+			admin.Post("/modules/{id}/topics", curriculumH.CreateTopic)
+			admin.Patch("/topics/{id}", curriculumH.UpdateTopic)
+			admin.Delete("/topics/{id}", curriculumH.DeleteTopic)
+		})
+	})
+
+	// Rubric-scored assessments of students and coaches
+	r.Route("/assessments", func(r chi.Router) {
+		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.AuthMiddleware(a.store))
+			r.Get("/", assessmentH.ListAssessments)
+			r.Get("/trends", assessmentH.GetTrends)
+			r.Get("/rubrics", assessmentH.ListRubrics)
+
+			staff := r.With(auth.RoleMiddleware("coach", "mentor", "admin"))
+			staff.Post("/", assessmentH.CreateAssessment)
+			staff.Delete("/{id}", assessmentH.DeleteAssessment)
+			staff.Get("/cohort", assessmentH.GetCohort)
+
+			admin := r.With(auth.RoleMiddleware("admin"))
+			admin.Post("/rubrics", assessmentH.CreateRubric)
+			admin.Patch("/rubrics/{id}", assessmentH.UpdateRubric)
+			admin.Post("/rubrics/{id}/criteria", assessmentH.AddCriterion)
+		})
+	})
+
+	// In-app notifications and delivery preferences for the current user
+	r.Route("/notifications", func(r chi.Router) {
+		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.AuthMiddleware(a.store))
+			r.Get("/", notificationH.ListNotifications)
+			r.Get("/unread-count", notificationH.UnreadCount)
+			r.Post("/read", notificationH.MarkRead)
+			r.Get("/preferences", notificationH.GetPreferences)
+			r.Put("/preferences", notificationH.UpdatePreferences)
+			r.Delete("/{id}", notificationH.DeleteNotification)
+		})
+	})
+
+	// Chat groups, templated messages and provider delivery callbacks
+	r.Route("/messaging", func(r chi.Router) {
+		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
+		// Called by the provider, verified by signature
+		r.Get("/webhook", messagingH.VerifyWebhook)
+		r.Post("/webhook", messagingH.StatusWebhook)
+		r.Group(func(r chi.Router) {
+			r.Use(auth.AuthMiddleware(a.store))
+			r.Get("/groups", messagingH.ListGroups)
+
+			staff := r.With(auth.RoleMiddleware("coach", "mentor", "admin"))
+			staff.Get("/groups/{id}/members", messagingH.ListMembers)
+			staff.Put("/groups/{id}/members/{userId}", messagingH.AddMember)
+			staff.Delete("/groups/{id}/members/{userId}", messagingH.RemoveMember)
+			staff.Post("/messages", messagingH.SendMessage)
+			staff.Get("/messages", messagingH.ListMessages)
+
+			admin := r.With(auth.RoleMiddleware("admin"))
+			admin.Post("/groups", messagingH.CreateGroup)
+			admin.Patch("/groups/{id}", messagingH.UpdateGroup)
+			admin.Delete("/groups/{id}", messagingH.DeleteGroup)
+		})
+	})
+
+	// Full-text search, filtered by the requester's access
+	r.Route("/search", func(r chi.Router) {
+		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
+		r.With(auth.AuthMiddleware(a.store)).Get("/", searchH.Search)
+	})
+
 	r.Route("/health", func(r chi.Router) {
 		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
 		r.Get("/", HealthHandler(a.store))
@@ -177,14 +403,15 @@ func (a *API) routes() {
 
 	// Scraper routes (API-key protected, used by the Chrome extension)
 	r.Route("/scraper", func(r chi.Router) {
-		r.Use(scraperH.APIKeyAuth)
+		r.Use(auth.APIKeyMiddleware(a.store))
 		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
-		r.Get("/zipcodes", scraperH.GetZipcodes)
-		r.Post("/tournaments", scraperH.SubmitTournaments)
+		r.With(auth.RequireAPIKeyScope(auth.ScopeScraperRead)).Get("/zipcodes", scraperH.GetZipcodes)
+		r.With(auth.RequireAPIKeyScope(auth.ScopeScraperWrite)).Post("/zipcodes/fail", scraperH.FailZipcode)
+		r.With(auth.RequireAPIKeyScope(auth.ScopeScraperWrite)).Post("/tournaments", scraperH.SubmitTournaments)
+		r.With(auth.RequireAPIKeyScope(auth.ScopeScraperWrite)).Post("/pages", scraperH.SubmitPage)
 	})
 
 	// Schedule routes (class time slots)
-	schedH := NewScheduleHandler(ss)
 	r.Route("/schedules", func(r chi.Router) {
 		r.Options("/*", func(w http.ResponseWriter, r *http.Request) {})
 		r.Group(func(r chi.Router) {
@@ -194,6 +421,10 @@ func (a *API) routes() {
 			r.With(auth.RoleMiddleware("coach", "mentor", "admin")).Get("/", schedH.ListSchedules)
 			r.With(auth.RoleMiddleware("coach", "mentor", "admin")).Patch("/{id}", schedH.UpdateSchedule)
 			r.With(auth.RoleMiddleware("coach", "mentor", "admin")).Delete("/{id}", schedH.DeleteSchedule)
+			r.Get("/holidays", schedH.ListHolidays)
+			r.With(auth.RoleMiddleware("coach", "mentor", "admin")).Get("/{id}/cancellations", schedH.ListCancellations)
+			r.With(auth.RoleMiddleware("coach", "mentor", "admin")).Post("/{id}/cancellations", schedH.CancelClass)
+			r.With(auth.RoleMiddleware("coach", "mentor", "admin")).Delete("/{id}/cancellations/{date}", schedH.UncancelClass)
 		})
 	})
 
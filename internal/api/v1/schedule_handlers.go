@@ -1,11 +1,13 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/madhava-poojari/dashboard-api/internal/auth"
@@ -51,12 +53,15 @@ func validateScheduleCreate(studentID string, dayOfWeek *int, startTime, timezon
 	if timezone == "" {
 		return "timezone is required"
 	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "timezone must be an IANA name like America/New_York"
+	}
 	return ""
 }
 
 // validateScheduleUpdate validates the partial update fields for a schedule.
 // Returns the updates map and an error message string (empty if valid).
-func validateScheduleUpdate(dayOfWeek *int, startTime *string, timezone *string) (map[string]interface{}, string) {
+func validateScheduleUpdate(dayOfWeek *int, startTime *string, timezone *string, coachID *string) (map[string]interface{}, string) {
 	updates := map[string]interface{}{}
 
 	if dayOfWeek != nil {
@@ -72,8 +77,14 @@ func validateScheduleUpdate(dayOfWeek *int, startTime *string, timezone *string)
 		if *timezone == "" {
 			return nil, "timezone cannot be empty"
 		}
+		if _, err := time.LoadLocation(*timezone); err != nil {
+			return nil, "timezone must be an IANA name like America/New_York"
+		}
 		updates["timezone"] = *timezone
 	}
+	if coachID != nil {
+		updates["coach_id"] = *coachID
+	}
 
 	if len(updates) == 0 {
 		return nil, "no updates provided"
@@ -81,12 +92,44 @@ func validateScheduleUpdate(dayOfWeek *int, startTime *string, timezone *string)
 	return updates, ""
 }
 
+// scheduleCoach picks the coach for a student's class: coachID if given,
+// which must be one of the student's coaches, else the requester when they
+// coach the student, else the student's only coach. A student with several
+// coaches needs coachID. Returns an error message (empty if valid).
+func (h *ScheduleHandler) scheduleCoach(ctx context.Context, current *models.User, studentID, coachID string) (string, string, error) {
+	coaches, err := h.store.ListCoachIDsForStudent(ctx, studentID)
+	if err != nil {
+		return "", "", err
+	}
+	if coachID != "" {
+		for _, c := range coaches {
+			if c == coachID {
+				return coachID, "", nil
+			}
+		}
+		return "", "coach_id is not one of the student's coaches", nil
+	}
+	for _, c := range coaches {
+		if c == current.ID {
+			return c, "", nil
+		}
+	}
+	switch len(coaches) {
+	case 0:
+		return "", "", nil
+	case 1:
+		return coaches[0], "", nil
+	}
+	return "", "coach_id is required for a student with more than one coach", nil
+}
+
 // ---- Handlers ----
 
 // POST /schedules
 func (h *ScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		StudentID string `json:"student_id"`
+		CoachID   string `json:"coach_id"`
 		DayOfWeek *int   `json:"day_of_week"`
 		StartTime string `json:"start_time"`
 		Timezone  string `json:"timezone"`
@@ -115,8 +158,19 @@ func (h *ScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	coachID, errMsg, err := h.scheduleCoach(ctx, current, req.StudentID, req.CoachID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching coaches", nil, err.Error())
+		return
+	}
+	if errMsg != "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, errMsg, nil, nil)
+		return
+	}
+
 	cs := &models.ClassSchedule{
 		StudentID: req.StudentID,
+		CoachID:   coachID,
 		DayOfWeek: *req.DayOfWeek,
 		StartTime: normalizeTime(req.StartTime),
 		Timezone:  req.Timezone,
@@ -213,6 +267,7 @@ func (h *ScheduleHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request)
 		DayOfWeek *int    `json:"day_of_week"`
 		StartTime *string `json:"start_time"`
 		Timezone  *string `json:"timezone"`
+		CoachID   *string `json:"coach_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
@@ -220,11 +275,20 @@ func (h *ScheduleHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Validate
-	updates, errMsg := validateScheduleUpdate(req.DayOfWeek, req.StartTime, req.Timezone)
+	updates, errMsg := validateScheduleUpdate(req.DayOfWeek, req.StartTime, req.Timezone, req.CoachID)
 	if errMsg != "" {
 		utils.WriteJSONResponse(w, http.StatusBadRequest, false, errMsg, nil, nil)
 		return
 	}
+	if req.CoachID != nil && *req.CoachID != "" {
+		if _, errMsg, err := h.scheduleCoach(ctx, current, existing.StudentID, *req.CoachID); err != nil {
+			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching coaches", nil, err.Error())
+			return
+		} else if errMsg != "" {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, errMsg, nil, nil)
+			return
+		}
+	}
 
 	updated, err := h.store.UpdateScheduleByID(ctx, uint(idU64), updates)
 	if err != nil {
@@ -276,5 +340,172 @@ func (h *ScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request)
 	utils.WriteJSONResponse(w, http.StatusOK, true, "deleted", nil, nil)
 }
 
+// scheduleFromURL loads the {id} schedule and checks the requester manages
+// its student, writing the error response itself.
+func (h *ScheduleHandler) scheduleFromURL(w http.ResponseWriter, r *http.Request, current *models.User) (*models.ClassSchedule, bool) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return nil, false
+	}
+	cs, err := h.store.GetScheduleByID(r.Context(), id)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return nil, false
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return nil, false
+	}
+	if !CanAccessStudentData(r.Context(), h.store, current, cs.StudentID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return nil, false
+	}
+	return cs, true
+}
+
+// GET /schedules/{id}/cancellations - upcoming cancelled dates
+func (h *ScheduleHandler) ListCancellations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	cs, ok := h.scheduleFromURL(w, r, current)
+	if !ok {
+		return
+	}
+	list, err := h.store.ListClassCancellations(ctx, cs.ID, time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching cancellations", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", list, nil)
+}
+
+// POST /schedules/{id}/cancellations - {"date": "2026-11-26", "reason": "..."}
+// Date is the class's local date in the schedule's timezone.
+func (h *ScheduleHandler) CancelClass(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	cs, ok := h.scheduleFromURL(w, r, current)
+	if !ok {
+		return
+	}
+	var req struct {
+		Date   string `json:"date"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "date must be YYYY-MM-DD", nil, nil)
+		return
+	}
+	if int(date.Weekday()) != cs.DayOfWeek {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "no class on that date for this schedule", nil, nil)
+		return
+	}
+	c := &models.ClassCancellation{ScheduleID: cs.ID, Date: date, Reason: req.Reason, CreatedBy: current.ID}
+	if err := h.store.CancelClass(ctx, c); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "cancel failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "class cancelled", c, nil)
+}
+
+// DELETE /schedules/{id}/cancellations/{date}
+func (h *ScheduleHandler) UncancelClass(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	cs, ok := h.scheduleFromURL(w, r, current)
+	if !ok {
+		return
+	}
+	date, err := time.Parse("2006-01-02", chi.URLParam(r, "date"))
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "date must be YYYY-MM-DD", nil, nil)
+		return
+	}
+	if err := h.store.UncancelClass(ctx, cs.ID, date); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "delete failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "cancellation removed", nil, nil)
+}
+
+// GET /schedules/holidays - upcoming holidays
+func (h *ScheduleHandler) ListHolidays(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store.ListHolidays(r.Context(), time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching holidays", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", list, nil)
+}
+
+// POST /admin/holidays - {"date": "2026-12-25", "name": "Christmas"}
+func (h *ScheduleHandler) CreateHoliday(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	var req struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request", nil, err.Error())
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil || strings.TrimSpace(req.Name) == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "date (YYYY-MM-DD) and name are required", nil, nil)
+		return
+	}
+	hol := &models.Holiday{Date: date, Name: strings.TrimSpace(req.Name), CreatedBy: current.ID}
+	if err := h.store.CreateHoliday(ctx, hol); err != nil {
+		utils.WriteJSONResponse(w, http.StatusConflict, false, "holiday already exists for that date", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "created", hol, nil)
+}
+
+// DELETE /admin/holidays/{id}
+func (h *ScheduleHandler) DeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	if err := h.store.DeleteHoliday(r.Context(), id); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "delete failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "deleted", nil, nil)
+}
+
 // dummy-use fmt to satisfy import if needed elsewhere in package
 var _ = fmt.Sprint
@@ -3,50 +3,79 @@ package v1
 import (
 	"encoding/json"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
 	"github.com/madhava-poojari/dashboard-api/internal/config"
 	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
 	"github.com/madhava-poojari/dashboard-api/internal/utils"
 )
 
 type ScraperHandler struct {
-	ss  serviceStore
-	cfg *config.Config
+	ss     serviceStore
+	cfg    *config.Config
+	ingest *service.TournamentIngestService
+	events *service.EventBus
 }
 
-func NewScraperHandler(ss serviceStore, cfg *config.Config) *ScraperHandler {
-	return &ScraperHandler{ss: ss, cfg: cfg}
+func NewScraperHandler(ss serviceStore, cfg *config.Config, ingest *service.TournamentIngestService, events *service.EventBus) *ScraperHandler {
+	return &ScraperHandler{ss: ss, cfg: cfg, ingest: ingest, events: events}
 }
 
-// APIKeyAuth middleware validates the X-Scraper-Key header against SCRAPER_API_KEY.
-func (h *ScraperHandler) APIKeyAuth(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		key := r.Header.Get("X-Scraper-Key")
-		if key == "" || key != h.cfg.ScraperAPIKey {
-			utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "invalid or missing scraper API key", nil, nil)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
+const (
+	defaultClaimLimit = 25
+	maxClaimLimit     = 200
+)
+
+// scraperInstance identifies the caller for leases: the X-Scraper-Instance
+// header, falling back to the API key and client host. The port is left
+// out: it changes with every connection, and the scraper must still own
+// its lease when it reports back on a new one.
+func scraperInstance(r *http.Request) string {
+	if v := strings.TrimSpace(r.Header.Get("X-Scraper-Instance")); v != "" {
+		return v
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if k := auth.GetAPIKeyFromCtx(r.Context()); k != nil {
+		return k.Prefix + "@" + host
+	}
+	return host
 }
 
-// GetZipcodes syncs zipcode_distances from user_details, then returns
-// (zipcode, distance) pairs that need scraping.
+// GetZipcodes claims up to ?limit= due (zipcode, distance) scopes for the
+// calling scraper instance. Claimed scopes are leased, so another instance
+// polling at the same time gets different ones. Submitting tournaments for a
+// scope completes it. Scopes are kept in step with user_details by the
+// scraper.sync-scopes job.
 func (h *ScraperHandler) GetZipcodes(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	if err := h.ss.SyncZipcodesFromUserDetails(ctx); err != nil {
-		log.Printf("[scraper] sync zipcodes error: %v", err)
-		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to sync zipcodes", nil, err.Error())
-		return
+	limit := defaultClaimLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid limit", nil, nil)
+			return
+		}
+		if n > maxClaimLimit {
+			n = maxClaimLimit
+		}
+		limit = n
 	}
 
-	zds, err := h.ss.GetZipcodesForScraping(ctx)
+	zds, err := h.ss.ClaimScrapeScopes(ctx, scraperInstance(r), limit)
 	if err != nil {
-		log.Printf("[scraper] get zipcodes error: %v", err)
+		log.Printf("[scraper] claim zipcodes error: %v", err)
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to get zipcodes", nil, err.Error())
 		return
 	}
@@ -54,6 +83,73 @@ func (h *ScraperHandler) GetZipcodes(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSONResponse(w, http.StatusOK, true, "", zds, nil)
 }
 
+type failScopeRequest struct {
+	Zipcode  string `json:"zipcode"`
+	Distance int    `json:"distance"`
+	Error    string `json:"error"`
+}
+
+// FailZipcode records a failed scrape of a claimed scope so it is retried
+// with backoff instead of waiting for the lease to expire.
+func (h *ScraperHandler) FailZipcode(w http.ResponseWriter, r *http.Request) {
+	var req failScopeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request body", nil, err.Error())
+		return
+	}
+	req.Zipcode = strings.TrimSpace(req.Zipcode)
+	if req.Zipcode == "" || req.Distance <= 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "zipcode and distance are required", nil, nil)
+		return
+	}
+	msg := strings.TrimSpace(req.Error)
+	if msg == "" {
+		msg = "scrape failed"
+	}
+
+	if err := h.ss.FailScrapeScope(r.Context(), req.Zipcode, req.Distance, scraperInstance(r), msg); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "scope not found or leased by another scraper", nil, nil)
+			return
+		}
+		log.Printf("[scraper] fail scope error (zip=%s, dist=%d): %v", req.Zipcode, req.Distance, err)
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to record failure", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "failure recorded", nil, nil)
+}
+
+// GET /admin/scraper/health - queue counts, backlog, active leases and failures
+func (h *ScraperHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	health, err := h.ss.GetScraperHealth(r.Context())
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching scraper health", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", health, nil)
+}
+
+// POST /admin/scraper/scopes/reset - make a parked or failing scope due again
+func (h *ScraperHandler) ResetScope(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Zipcode  string `json:"zipcode"`
+		Distance int    `json:"distance"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request body", nil, err.Error())
+		return
+	}
+	if err := h.ss.ResetScrapeScope(r.Context(), strings.TrimSpace(req.Zipcode), req.Distance); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "scope not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error resetting scope", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "scope reset", nil, nil)
+}
+
 // submitTournamentsRequest is the expected JSON body for POST /scraper/tournaments.
 type submitTournamentsRequest struct {
 	Zipcode     string              `json:"zipcode"`
@@ -70,6 +166,7 @@ type tournamentPayload struct {
 	StartDate   string `json:"start_date"` // ISO date string "2026-03-11"
 	Organizer   string `json:"organizer"`
 	Description string `json:"description"`
+	Zipcode     string `json:"zipcode"` // optional, improves geocoding over city/state
 }
 
 // SubmitTournaments accepts scraped tournament data for a (zipcode, distance) and persists it.
@@ -91,6 +188,10 @@ func (h *ScraperHandler) SubmitTournaments(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Convert payloads to models
+	var keyID *uint
+	if k := auth.GetAPIKeyFromCtx(r.Context()); k != nil && k.ID != 0 {
+		keyID = &k.ID
+	}
 	tournaments := make([]models.Tournament, 0, len(req.Tournaments))
 	for _, t := range req.Tournaments {
 		mt := models.Tournament{
@@ -101,6 +202,8 @@ func (h *ScraperHandler) SubmitTournaments(w http.ResponseWriter, r *http.Reques
 			Dates:       t.Dates,
 			Organizer:   t.Organizer,
 			Description: strings.TrimSpace(t.Description),
+			Zipcode:     strings.TrimSpace(t.Zipcode),
+			APIKeyID:    keyID,
 		}
 		if t.StartDate != "" {
 			if parsed, err := time.Parse("2006-01-02", t.StartDate); err == nil {
@@ -110,7 +213,31 @@ func (h *ScraperHandler) SubmitTournaments(w http.ResponseWriter, r *http.Reques
 		tournaments = append(tournaments, mt)
 	}
 
-	if err := h.ss.UpsertTournaments(r.Context(), req.Zipcode, req.Distance, tournaments); err != nil {
+	var added []models.Tournament
+	err := h.ss.WithTx(r.Context(), func(tx *store.Store) error {
+		var err error
+		if added, err = tx.UpsertTournaments(r.Context(), req.Zipcode, req.Distance, tournaments); err != nil {
+			return err
+		}
+		for _, t := range added {
+			data := map[string]interface{}{
+				"tournament_id": t.ID,
+				"title":         t.Title,
+				"url_path":      t.URLPath,
+				"city":          t.City,
+				"state":         t.State,
+				"dates":         t.Dates,
+			}
+			if t.StartDate != nil {
+				data["start_date"] = t.StartDate.Format("2006-01-02")
+			}
+			if err := h.events.Enqueue(r.Context(), tx, service.Event{Type: service.EventTournamentNew, Data: data}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		log.Printf("[scraper] upsert tournaments error (zip=%s, dist=%d): %v", req.Zipcode, req.Distance, err)
 		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to save tournaments", nil, err.Error())
 		return
@@ -120,5 +247,96 @@ func (h *ScraperHandler) SubmitTournaments(w http.ResponseWriter, r *http.Reques
 		"zipcode":  req.Zipcode,
 		"distance": req.Distance,
 		"count":    len(tournaments),
+		"new":      len(added),
 	}, nil)
 }
+
+// maxPageBytes caps a submitted HTML page.
+const maxPageBytes = 5 << 20
+
+type submitPageRequest struct {
+	URLPath    string `json:"url_path"`
+	HTML       string `json:"html"`
+	CapturedAt string `json:"captured_at"` // RFC3339, defaults to now
+}
+
+// SubmitPage accepts the raw HTML of a tournament page, parses it server-side
+// and stores the page with a per-field confidence report. Valid parses update
+// the tournament's structured details.
+func (h *ScraperHandler) SubmitPage(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPageBytes)
+	var req submitPageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid request body", nil, err.Error())
+		return
+	}
+	req.URLPath = strings.TrimSpace(req.URLPath)
+	if req.URLPath == "" || strings.TrimSpace(req.HTML) == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "url_path and html are required", nil, nil)
+		return
+	}
+	captured := time.Now()
+	if req.CapturedAt != "" {
+		t, err := time.Parse(time.RFC3339, req.CapturedAt)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid captured_at", nil, err.Error())
+			return
+		}
+		captured = t
+	}
+	var keyID *uint
+	if k := auth.GetAPIKeyFromCtx(r.Context()); k != nil && k.ID != 0 {
+		keyID = &k.ID
+	}
+
+	res, err := h.ingest.IngestPage(r.Context(), req.URLPath, req.HTML, captured, keyID)
+	if err != nil {
+		log.Printf("[scraper] ingest page error (url=%s): %v", req.URLPath, err)
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "failed to ingest page", nil, err.Error())
+		return
+	}
+	msg := "page parsed"
+	if !res.Report.Valid {
+		msg = "page stored but failed validation"
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, msg, res, nil)
+}
+
+// GET /admin/scraper/pages?url_path=&invalid=true&limit= - recent parse reports
+func (h *ScraperHandler) ListPages(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := store.TournamentPageFilter{URLPath: q.Get("url_path"), InvalidOnly: q.Get("invalid") == "true"}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 500 {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid limit", nil, nil)
+			return
+		}
+		f.Limit = n
+	}
+	pages, err := h.ss.ListTournamentPages(r.Context(), f)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching pages", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", pages, nil)
+}
+
+// GET /admin/scraper/pages/{id} - one captured page including its HTML
+func (h *ScraperHandler) GetPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+		return
+	}
+	page, err := h.ss.GetTournamentPage(r.Context(), uint(id))
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "page not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching page", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", page, nil)
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// SearchHandler serves full-text search across students, notes, attendance
+// highlights/homework and tournaments.
+type SearchHandler struct {
+	store *store.Store
+}
+
+func NewSearchHandler(s serviceStore) *SearchHandler {
+	return &SearchHandler{store: s.Store}
+}
+
+// GET /search?q=&types=students,notes,attendance,tournaments&limit=
+// Results are grouped by type; snippets are HTML-escaped and wrap matches in
+// <mark>.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+
+	q := r.URL.Query()
+	query := strings.TrimSpace(q.Get("q"))
+	if query == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "q is required", nil, nil)
+		return
+	}
+	if len(query) > 200 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "q is too long", nil, nil)
+		return
+	}
+
+	types := store.SearchTypes
+	if v := q.Get("types"); v != "" {
+		types = nil
+		for _, t := range strings.Split(v, ",") {
+			t = strings.TrimSpace(t)
+			if !validSearchType(t) {
+				utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid type: "+t, nil, nil)
+				return
+			}
+			types = append(types, t)
+		}
+	}
+
+	limit := 10
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 50 {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid limit", nil, nil)
+			return
+		}
+		limit = n
+	}
+
+	res, err := h.store.Search(ctx, current, query, types, limit)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "search failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", res, nil)
+}
+
+func validSearchType(t string) bool {
+	for _, st := range store.SearchTypes {
+		if t == st {
+			return true
+		}
+	}
+	return false
+}
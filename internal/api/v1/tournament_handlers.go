@@ -0,0 +1,442 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/geo"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// TournamentHandler tracks which students are going to scraped tournaments
+// and how they did.
+type TournamentHandler struct {
+	store *store.Store
+}
+
+func NewTournamentHandler(s serviceStore) *TournamentHandler {
+	return &TournamentHandler{store: s.Store}
+}
+
+type setRegistrationRequest struct {
+	Status  models.TournamentRegistrationStatus `json:"status"`
+	Section string                              `json:"section"`
+}
+
+type recordResultRequest struct {
+	Score        *float64 `json:"score"`
+	Place        *int     `json:"place"`
+	RatingChange *int     `json:"rating_change"`
+	Section      string   `json:"section"`
+	Notes        string   `json:"notes"`
+}
+
+func validRegistrationStatus(s models.TournamentRegistrationStatus) bool {
+	switch s {
+	case models.TournamentRegistrationPlanning, models.TournamentRegistrationRegistered, models.TournamentRegistrationWithdrawn:
+		return true
+	}
+	return false
+}
+
+// tournamentFromURL loads the {id} tournament, writing the error response itself.
+func (h *TournamentHandler) tournamentFromURL(w http.ResponseWriter, r *http.Request) (*models.Tournament, bool) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid tournament id", nil, nil)
+		return nil, false
+	}
+	t, err := h.store.GetTournament(r.Context(), uint(id))
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "tournament not found", nil, nil)
+			return nil, false
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching tournament", nil, err.Error())
+		return nil, false
+	}
+	return t, true
+}
+
+// visibleStudentIDs returns the students whose registrations the user may
+// see; nil means all of them (admin).
+func (h *TournamentHandler) visibleStudentIDs(r *http.Request, current *models.User) ([]string, error) {
+	switch current.Role {
+	case models.RoleAdmin:
+		return nil, nil
+	case models.RoleCoach, models.RoleMentor:
+		students, err := h.store.ListStudentsForCoachOrMentor(r.Context(), current.ID)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(students))
+		for _, s := range students {
+			ids = append(ids, s.ID)
+		}
+		return ids, nil
+	default:
+		return []string{current.ID}, nil
+	}
+}
+
+// PUT /tournaments/{id}/registrations/{studentId} - mark planning / registered / withdrawn.
+// Students (and parents on the student's account) can set their own; coaches,
+// mentors and admins can set it for their students.
+func (h *TournamentHandler) SetRegistration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	studentID := chi.URLParam(r, "studentId")
+	if !CanAccessStudentData(ctx, h.store, current, studentID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	t, ok := h.tournamentFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var req setRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid body", nil, err.Error())
+		return
+	}
+	if req.Status == "" {
+		req.Status = models.TournamentRegistrationPlanning
+	}
+	if !validRegistrationStatus(req.Status) {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "status must be planning, registered or withdrawn", nil, nil)
+		return
+	}
+
+	reg, err := h.store.SetTournamentRegistrationStatus(ctx, t.ID, studentID, req.Status, strings.TrimSpace(req.Section), current.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error saving registration", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "registration saved", reg, nil)
+}
+
+// DELETE /tournaments/{id}/registrations/{studentId}
+func (h *TournamentHandler) DeleteRegistration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	studentID := chi.URLParam(r, "studentId")
+	if !CanAccessStudentData(ctx, h.store, current, studentID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	t, ok := h.tournamentFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteTournamentRegistration(ctx, t.ID, studentID); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "registration not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error deleting registration", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "registration deleted", nil, nil)
+}
+
+// PUT /tournaments/{id}/registrations/{studentId}/result - coach/mentor/admin only
+func (h *TournamentHandler) RecordResult(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	studentID := chi.URLParam(r, "studentId")
+	if !CanAccessStudentData(ctx, h.store, current, studentID) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	t, ok := h.tournamentFromURL(w, r)
+	if !ok {
+		return
+	}
+	if t.StartDate != nil && t.StartDate.After(time.Now()) {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "tournament has not started yet", nil, nil)
+		return
+	}
+
+	var req recordResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid body", nil, err.Error())
+		return
+	}
+	if req.Score != nil && *req.Score < 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "score cannot be negative", nil, nil)
+		return
+	}
+	if req.Place != nil && *req.Place < 1 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "place must be at least 1", nil, nil)
+		return
+	}
+
+	reg, err := h.store.RecordTournamentResult(ctx, t.ID, studentID, store.TournamentResult{
+		Score:        req.Score,
+		Place:        req.Place,
+		RatingChange: req.RatingChange,
+		Section:      strings.TrimSpace(req.Section),
+		Notes:        req.Notes,
+	}, current.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error saving result", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "result saved", reg, nil)
+}
+
+// GET /tournaments/{id}/registrations - who is going, limited to the students
+// the requester can see.
+func (h *TournamentHandler) ListRegistrations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	t, ok := h.tournamentFromURL(w, r)
+	if !ok {
+		return
+	}
+	ids, err := h.visibleStudentIDs(r, current)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching students", nil, err.Error())
+		return
+	}
+	regs, err := h.store.ListTournamentRegistrations(ctx, t.ID, ids)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching registrations", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", map[string]interface{}{
+		"tournament":    t,
+		"registrations": regs,
+	}, nil)
+}
+
+// GET /tournaments/roster?from=&to= - upcoming tournaments with the students
+// going, grouped by tournament. Admin: academy-wide; coach/mentor: their students.
+func (h *TournamentHandler) GetUpcomingRoster(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var to *time.Time
+	q := r.URL.Query()
+	if v := q.Get("from"); v != "" {
+		t, err := parseDateFlexible(v)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid from", nil, err.Error())
+			return
+		}
+		from = t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := parseDateFlexible(v)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid to", nil, err.Error())
+			return
+		}
+		to = &t
+	}
+
+	ids, err := h.visibleStudentIDs(r, current)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching students", nil, err.Error())
+		return
+	}
+	roster, err := h.store.UpcomingTournamentRoster(ctx, from, to, ids)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error building roster", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", roster, nil)
+}
+
+const (
+	defaultSearchRadius = 50
+	maxSearchRadius     = 500
+)
+
+// GET /tournaments?near=zip&radius=50&from=&to= - tournaments within radius
+// miles of a zipcode (default: the requester's own), nearest first.
+func (h *TournamentHandler) SearchTournaments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+
+	q := r.URL.Query()
+	near := strings.TrimSpace(q.Get("near"))
+	if near == "" {
+		near = current.UserDetails.Zipcode
+	}
+	if near == "" {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "near zipcode is required", nil, nil)
+		return
+	}
+	radius := float64(defaultSearchRadius)
+	if v := q.Get("radius"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f > maxSearchRadius {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "radius must be between 0 and 500 miles", nil, nil)
+			return
+		}
+		radius = f
+	}
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var to *time.Time
+	if v := q.Get("from"); v != "" {
+		t, err := parseDateFlexible(v)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid from", nil, err.Error())
+			return
+		}
+		from = t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := parseDateFlexible(v)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid to", nil, err.Error())
+			return
+		}
+		to = &t
+	}
+
+	c, err := h.store.GetZipCentroid(ctx, near)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "unknown zipcode", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error geocoding zipcode", nil, err.Error())
+		return
+	}
+	results, err := h.store.SearchTournamentsNear(ctx, geo.Point{Lat: c.Latitude, Lng: c.Longitude}, radius, &from, to)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error searching tournaments", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", map[string]interface{}{
+		"near":        c,
+		"radius":      radius,
+		"tournaments": results,
+	}, nil)
+}
+
+// GET /tournaments/changes?since=&type=&only_affected=true - tournaments that
+// changed, were cancelled or reinstated since a time (default: 7 days ago),
+// with the visible students planning to attend so coaches can warn them.
+func (h *TournamentHandler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+
+	q := r.URL.Query()
+	f := store.TournamentChangesFilter{Since: time.Now().AddDate(0, 0, -7)}
+	if v := q.Get("since"); v != "" {
+		t, err := parseDateFlexible(v)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid since", nil, err.Error())
+			return
+		}
+		f.Since = t
+	}
+	for _, t := range q["type"] {
+		switch t {
+		case models.TournamentCreated, models.TournamentUpdated, models.TournamentCancelled, models.TournamentReinstated:
+			f.ChangeTypes = append(f.ChangeTypes, t)
+		default:
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "type must be created, updated, cancelled or reinstated", nil, nil)
+			return
+		}
+	}
+	f.OnlyAffected = q.Get("only_affected") == "true"
+
+	ids, err := h.visibleStudentIDs(r, current)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching students", nil, err.Error())
+		return
+	}
+	f.StudentIDs = ids
+
+	changes, err := h.store.ListTournamentChanges(ctx, f)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching changes", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", map[string]interface{}{
+		"since":   f.Since,
+		"changes": changes,
+	}, nil)
+}
+
+// GET /tournaments/{id}/history - revision history with field diffs
+func (h *TournamentHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.tournamentFromURL(w, r)
+	if !ok {
+		return
+	}
+	revs, err := h.store.ListTournamentRevisions(r.Context(), t.ID)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching history", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", map[string]interface{}{
+		"tournament": t,
+		"revisions":  revs,
+	}, nil)
+}
+
+// GET /users/{id}/tournament-registrations - a student's registrations and results
+func (h *TournamentHandler) ListStudentRegistrations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	if !CanAccessStudentData(ctx, h.store, current, id) {
+		utils.WriteJSONResponse(w, http.StatusForbidden, false, "forbidden", nil, nil)
+		return
+	}
+	regs, err := h.store.ListStudentTournamentRegistrations(ctx, id)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching registrations", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "success", regs, nil)
+}
@@ -3,7 +3,6 @@ package v1
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -74,15 +73,15 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		Mentor: mentorInfo,
 	}
 
-	// Embed schedule for student profiles
+	// Embed schedule and official ratings for student profiles
 	if u.Role == models.RoleStudent {
 		schedule, err := h.store.ListSchedulesForStudents(ctx, []string{u.ID})
 		if err == nil {
 			resp.Schedule = schedule
 		}
+		h.attachOfficialRatings(ctx, &resp)
 	}
 
-	fmt.Println("Sending response: ", resp)
 	utils.WriteJSONResponse(w, http.StatusOK, true, "success", resp, nil)
 }
 
@@ -125,12 +124,13 @@ func (h *UserHandler) GetSelfProfile(w http.ResponseWriter, r *http.Request) {
 		Mentor: mentorInfo,
 	}
 
-	// Embed schedule for student profiles
+	// Embed schedule and official ratings for student profiles
 	if u.Role == models.RoleStudent {
 		schedule, err := h.store.ListSchedulesForStudents(ctx, []string{u.ID})
 		if err == nil {
 			resp.Schedule = schedule
 		}
+		h.attachOfficialRatings(ctx, &resp)
 	}
 
 	utils.WriteJSONResponse(w, http.StatusOK, true, "success", resp, nil)
@@ -138,7 +138,6 @@ func (h *UserHandler) GetSelfProfile(w http.ResponseWriter, r *http.Request) {
 
 // PUT /users/{id} - only allowed to update profile fields (not role, id, approval)
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
-	print("UpdateUser called")
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "missing id", nil, nil)
@@ -166,18 +165,12 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		AdditionalInfo    *map[string]interface{} `json:"additional_info,omitempty"`
 		SyllabusURL       *string                 `json:"syllabus_url,omitempty"`
 		PersonalMeetLink  *string                 `json:"personal_meet_link,omitempty"`
-		AddedInWhatsapp   *bool                   `json:"added_in_whatsapp,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "bad request", nil, err.Error())
 		return
 	}
-	if payload.AddedInWhatsapp != nil {
-		fmt.Println("whatsapp in payload:", *payload.AddedInWhatsapp)
-	} else {
-		fmt.Println("whatsapp in payload: nil")
-	}
 
 	ctx := r.Context()
 	current := auth.GetUserFromCtx(ctx)
@@ -258,9 +251,6 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	if payload.PersonalMeetLink != nil {
 		detailUpdates["personal_meet_link"] = *payload.PersonalMeetLink
 	}
-	if payload.AddedInWhatsapp != nil {
-		detailUpdates["added_in_whatsapp"] = *payload.AddedInWhatsapp
-	}
 	// DOB and Age are mutually exclusive.
 	// If DOB is provided, store it and remove age from additional_info.
 	// If Age is provided (without DOB), store age in additional_info and clear DOB.
@@ -283,7 +273,6 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		detailUpdates["dob"] = nil
 	}
 
-	print("detailUpdates length: ", len(detailUpdates))
 	if len(detailUpdates) > 0 {
 		if err := h.store.UpdateUserDetailsFields(ctx, id, detailUpdates); err != nil {
 			utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update details failed", nil, err.Error())
@@ -303,6 +292,21 @@ func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	lq, err := parseListQuery(r, store.UserListSpec)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, err.Error(), nil, nil)
+		return
+	}
+	if lq.Paged() && current.Role != models.RoleStudent {
+		page, err := h.store.ListUsersPage(ctx, current, lq)
+		if err != nil {
+			writeListError(w, err, "error fetching users")
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusOK, true, "success", page, nil)
+		return
+	}
+
 	if current.Role == "admin" {
 		users, err := h.store.ListUsersAdmin(ctx)
 		if err != nil {
@@ -455,6 +459,19 @@ func CanAccessStudentData(ctx context.Context, s *store.Store, current *models.U
 	return current.ID == coachID || current.ID == mentorID
 }
 
+// CanManageStudent reports whether current may record progress for the
+// student: admins and any of the student's coaches or mentors.
+func CanManageStudent(ctx context.Context, s *store.Store, current *models.User, studentID string) bool {
+	if current.Role == models.RoleAdmin {
+		return true
+	}
+	if isCoach, err := s.IsCoachOf(ctx, current.ID, studentID); err == nil && isCoach {
+		return true
+	}
+	isMentor, err := s.IsMentorOf(ctx, current.ID, studentID)
+	return err == nil && isMentor
+}
+
 func (h *UserHandler) getPersonInfoByID(
 	ctx context.Context,
 	id string,
@@ -520,6 +537,18 @@ func (h *UserHandler) GetCoachesForAttendance(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// attachOfficialRatings embeds the latest imported FIDE/USCF ratings and any
+// open ID mismatch flags. Failures leave the profile without them.
+func (h *UserHandler) attachOfficialRatings(ctx context.Context, resp *models.UserResponse) {
+	ids := []string{resp.User.ID}
+	if latest, err := h.store.ListLatestOfficialRatings(ctx, ids); err == nil {
+		resp.OfficialRatings = latest
+	}
+	if flags, err := h.store.ListOpenRatingIDMismatches(ctx, ids); err == nil {
+		resp.RatingIDFlags = flags
+	}
+}
+
 // copyAdditionalInfo returns a shallow copy of the additional_info map
 // so that modifications don't mutate the original GORM-loaded data.
 func copyAdditionalInfo(src map[string]interface{}) map[string]interface{} {
@@ -0,0 +1,287 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/madhava-poojari/dashboard-api/internal/auth"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+	"gorm.io/datatypes"
+)
+
+// WebhookHandler is the admin CRUD for outbound webhooks and their
+// delivery log.
+type WebhookHandler struct {
+	store *store.Store
+}
+
+func NewWebhookHandler(s serviceStore) *WebhookHandler {
+	return &WebhookHandler{store: s.Store}
+}
+
+type webhookRequest struct {
+	Name   *string  `json:"name"`
+	URL    *string  `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// webhookCreatedResponse carries the signing secret, which is only shown
+// on create and rotate.
+type webhookCreatedResponse struct {
+	*models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+func newWebhookSecret() string { return "whsec_" + utils.RandomToken() }
+
+func validateWebhookURL(raw string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "url must be an https URL", false
+	}
+	return "", true
+}
+
+func validateWebhookEvents(events []string) (string, bool) {
+	if len(events) == 0 {
+		return "at least one event is required", false
+	}
+	for _, e := range events {
+		if e == "*" {
+			continue
+		}
+		known := false
+		for _, k := range service.WebhookEvents {
+			known = known || e == k
+		}
+		if !known {
+			return "unknown event " + e + " (allowed: *, " + strings.Join(service.WebhookEvents, ", ") + ")", false
+		}
+	}
+	return "", true
+}
+
+func webhookIDFromURL(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, ok := uintParam(r, "id")
+	if !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid id", nil, nil)
+	}
+	return id, ok
+}
+
+// GET /admin/webhooks
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store.ListWebhookEndpoints(r.Context())
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching webhooks", nil, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.WebhookEndpoint{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{
+		"webhooks": list,
+		"events":   service.WebhookEvents,
+	}, nil)
+}
+
+// POST /admin/webhooks - {"name", "url", "events": ["attendance.created"]}
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	current := auth.GetUserFromCtx(ctx)
+	if current == nil {
+		utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "unauthorized", nil, nil)
+		return
+	}
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid body", nil, err.Error())
+		return
+	}
+	if req.Name == nil || strings.TrimSpace(*req.Name) == "" || req.URL == nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "name and url are required", nil, nil)
+		return
+	}
+	if msg, ok := validateWebhookURL(*req.URL); !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, msg, nil, nil)
+		return
+	}
+	if msg, ok := validateWebhookEvents(req.Events); !ok {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, msg, nil, nil)
+		return
+	}
+	events, _ := json.Marshal(req.Events)
+	e := &models.WebhookEndpoint{
+		Name:      strings.TrimSpace(*req.Name),
+		URL:       strings.TrimSpace(*req.URL),
+		Secret:    newWebhookSecret(),
+		Events:    datatypes.JSON(events),
+		Active:    req.Active == nil || *req.Active,
+		CreatedBy: current.ID,
+	}
+	if err := h.store.CreateWebhookEndpoint(ctx, e); err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error creating webhook", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, true, "webhook created; store the secret now, it won't be shown again", webhookCreatedResponse{WebhookEndpoint: e, Secret: e.Secret}, nil)
+}
+
+// PATCH /admin/webhooks/{id} - name, url, events, active
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, ok := webhookIDFromURL(w, r)
+	if !ok {
+		return
+	}
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "invalid body", nil, err.Error())
+		return
+	}
+	fields := map[string]interface{}{}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, "name cannot be empty", nil, nil)
+			return
+		}
+		fields["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.URL != nil {
+		if msg, ok := validateWebhookURL(*req.URL); !ok {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, msg, nil, nil)
+			return
+		}
+		fields["url"] = strings.TrimSpace(*req.URL)
+	}
+	if req.Events != nil {
+		if msg, ok := validateWebhookEvents(req.Events); !ok {
+			utils.WriteJSONResponse(w, http.StatusBadRequest, false, msg, nil, nil)
+			return
+		}
+		events, _ := json.Marshal(req.Events)
+		fields["events"] = datatypes.JSON(events)
+	}
+	if req.Active != nil {
+		fields["active"] = *req.Active
+	}
+	if len(fields) == 0 {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "no updatable fields provided", nil, nil)
+		return
+	}
+	if err := h.store.UpdateWebhookEndpoint(ctx, id, fields); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "update failed", nil, err.Error())
+		return
+	}
+	e, err := h.store.GetWebhookEndpoint(ctx, id)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "webhook updated", e, nil)
+}
+
+// POST /admin/webhooks/{id}/rotate-secret - the old secret stops working at once.
+func (h *WebhookHandler) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, ok := webhookIDFromURL(w, r)
+	if !ok {
+		return
+	}
+	secret := newWebhookSecret()
+	if err := h.store.UpdateWebhookEndpoint(ctx, id, map[string]interface{}{"secret": secret}); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "rotate failed", nil, err.Error())
+		return
+	}
+	e, err := h.store.GetWebhookEndpoint(ctx, id)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "secret rotated; store it now, it won't be shown again", webhookCreatedResponse{WebhookEndpoint: e, Secret: secret}, nil)
+}
+
+// DELETE /admin/webhooks/{id}
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromURL(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteWebhookEndpoint(r.Context(), id); err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "delete failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "deleted", nil, nil)
+}
+
+// GET /admin/webhooks/{id}/deliveries?status=failed&limit=&offset=
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, ok := webhookIDFromURL(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	status := q.Get("status")
+	switch status {
+	case "", models.WebhookPending, models.WebhookDelivered, models.WebhookFailed:
+	default:
+		utils.WriteJSONResponse(w, http.StatusBadRequest, false, "status must be pending, delivered or failed", nil, nil)
+		return
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := h.store.ListWebhookDeliveries(ctx, id, status, limit, offset)
+	if err != nil {
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error fetching deliveries", nil, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.WebhookDelivery{}
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, true, "ok", map[string]interface{}{
+		"deliveries": list,
+		"total":      total,
+	}, nil)
+}
+
+// POST /admin/webhooks/deliveries/{id}/replay - queues the same payload
+// again, e.g. after the receiver fixed a bug. The event id is kept so
+// receivers can dedupe.
+func (h *WebhookHandler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromURL(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.store.ReplayWebhookDelivery(r.Context(), id)
+	if err != nil {
+		if store.IsNotFound(err) {
+			utils.WriteJSONResponse(w, http.StatusNotFound, false, "not found", nil, nil)
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "replay failed", nil, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusAccepted, true, "delivery queued", d, nil)
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// API key scopes. Add new machine-client scopes here.
+const (
+	ScopeScraperRead  = "scraper:read"
+	ScopeScraperWrite = "scraper:write"
+)
+
+var APIKeyScopes = []string{ScopeScraperRead, ScopeScraperWrite}
+
+const ctxAPIKeyKey ctxKey = "currentAPIKey"
+
+// legacyKeyPrefix marks the synthetic key built from SCRAPER_API_KEY.
+const legacyKeyPrefix = "legacy-env"
+
+func ValidAPIKeyScope(scope string) bool {
+	for _, s := range APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAPIKey returns a new key as "<prefix>.<secret>" and its prefix.
+func GenerateAPIKey() (prefix, plaintext string) {
+	prefix = "dak_" + utils.GenerateRandomString(12)
+	return prefix, prefix + "." + utils.RandomToken()
+}
+
+// APIKeyScopeList decodes the scopes of a key.
+func APIKeyScopeList(k *models.APIKey) []string {
+	var scopes []string
+	_ = json.Unmarshal(k.Scopes, &scopes)
+	return scopes
+}
+
+func apiKeyHasScope(k *models.APIKey, scope string) bool {
+	for _, s := range APIKeyScopeList(k) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAPIKeyFromCtx returns the key authenticated by APIKeyMiddleware. Its ID
+// is 0 for the legacy SCRAPER_API_KEY.
+func GetAPIKeyFromCtx(ctx context.Context) *models.APIKey {
+	if k, ok := ctx.Value(ctxAPIKeyKey).(*models.APIKey); ok {
+		return k
+	}
+	return nil
+}
+
+// apiKeyFromRequest reads X-API-Key, "Authorization: ApiKey <key>" or the
+// scraper extension's X-Scraper-Key header.
+func apiKeyFromRequest(r *http.Request) string {
+	if v := r.Header.Get("X-API-Key"); v != "" {
+		return strings.TrimSpace(v)
+	}
+	if parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2); len(parts) == 2 && parts[0] == "ApiKey" {
+		return strings.TrimSpace(parts[1])
+	}
+	return strings.TrimSpace(r.Header.Get("X-Scraper-Key"))
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// APIKeyMiddleware authenticates a machine client by API key and requires
+// every listed scope. The single SCRAPER_API_KEY env key is still accepted,
+// with the scraper scopes only, so existing installs keep working until they
+// are issued their own key.
+func APIKeyMiddleware(s *store.Store, requiredScopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			plain := apiKeyFromRequest(r)
+			if plain == "" {
+				utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "missing api key", nil, nil)
+				return
+			}
+
+			var key *models.APIKey
+			if legacy := s.Cfg.ScraperAPIKey; legacy != "" && subtle.ConstantTimeCompare([]byte(plain), []byte(legacy)) == 1 {
+				key = &models.APIKey{
+					Name:   "SCRAPER_API_KEY",
+					Prefix: legacyKeyPrefix,
+					Scopes: utils.DatatypesJSONFromStrings([]string{ScopeScraperRead, ScopeScraperWrite}),
+				}
+			} else {
+				k, err := s.AuthenticateAPIKey(r.Context(), plain, remoteIP(r))
+				switch {
+				case errors.Is(err, store.ErrAPIKeyExpired):
+					utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "api key expired", nil, nil)
+					return
+				case errors.Is(err, store.ErrAPIKeyRevoked), errors.Is(err, store.ErrAPIKeyInvalid):
+					utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "invalid or missing api key", nil, nil)
+					return
+				case err != nil:
+					utils.WriteJSONResponse(w, http.StatusInternalServerError, false, "error checking api key", nil, err.Error())
+					return
+				}
+				key = k
+			}
+
+			for _, scope := range requiredScopes {
+				if !apiKeyHasScope(key, scope) {
+					utils.WriteJSONResponse(w, http.StatusForbidden, false, "api key lacks scope "+scope, nil, nil)
+					return
+				}
+			}
+			ctx := context.WithValue(r.Context(), ctxAPIKeyKey, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireAPIKeyScope checks an additional scope on routes already behind
+// APIKeyMiddleware.
+func RequireAPIKeyScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := GetAPIKeyFromCtx(r.Context())
+			if k == nil {
+				utils.WriteJSONResponse(w, http.StatusUnauthorized, false, "invalid or missing api key", nil, nil)
+				return
+			}
+			if !apiKeyHasScope(k, scope) {
+				utils.WriteJSONResponse(w, http.StatusForbidden, false, "api key lacks scope "+scope, nil, nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
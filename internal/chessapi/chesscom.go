@@ -0,0 +1,171 @@
+package chessapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChesscomClient reads public account data from the chess.com published-data API.
+type ChesscomClient struct {
+	baseClient
+}
+
+// NewChesscomClient creates a client for baseURL (normally https://api.chess.com).
+// chess.com asks for a descriptive User-Agent with contact details.
+func NewChesscomClient(doer HTTPDoer, baseURL, userAgent string, limiter *RateLimiter) *ChesscomClient {
+	return &ChesscomClient{baseClient{http: doer, baseURL: strings.TrimRight(baseURL, "/"), userAgent: userAgent, limiter: limiter}}
+}
+
+var chesscomClasses = map[string]string{
+	"chess_bullet": TimeControlBullet,
+	"chess_blitz":  TimeControlBlitz,
+	"chess_rapid":  TimeControlRapid,
+	"chess_daily":  TimeControlDaily,
+}
+
+func (c *ChesscomClient) playerURL(username string) string {
+	return c.baseURL + "/pub/player/" + url.PathEscape(strings.ToLower(username))
+}
+
+// GetRatings returns the account's current rating per time control.
+func (c *ChesscomClient) GetRatings(ctx context.Context, username string) ([]Rating, error) {
+	// The stats object also carries non-game keys such as "fide" (a bare
+	// number) and "tactics", so only decode the game classes.
+	var body map[string]json.RawMessage
+	if err := c.getJSON(ctx, c.playerURL(username)+"/stats", &body); err != nil {
+		return nil, err
+	}
+	out := []Rating{}
+	for key, raw := range body {
+		tc, ok := chesscomClasses[key]
+		if !ok {
+			continue
+		}
+		var st struct {
+			Last struct {
+				Rating int `json:"rating"`
+			} `json:"last"`
+			Record struct {
+				Win  int `json:"win"`
+				Loss int `json:"loss"`
+				Draw int `json:"draw"`
+			} `json:"record"`
+		}
+		if err := json.Unmarshal(raw, &st); err != nil {
+			return nil, fmt.Errorf("decode %s stats: %w", key, err)
+		}
+		if st.Last.Rating == 0 {
+			continue
+		}
+		out = append(out, Rating{TimeControl: tc, Rating: st.Last.Rating, Games: st.Record.Win + st.Record.Loss + st.Record.Draw})
+	}
+	return out, nil
+}
+
+type chesscomPlayer struct {
+	Username string `json:"username"`
+	Rating   int    `json:"rating"`
+	Result   string `json:"result"`
+}
+
+type chesscomGame struct {
+	URL       string         `json:"url"`
+	UUID      string         `json:"uuid"`
+	EndTime   int64          `json:"end_time"`
+	Rated     bool           `json:"rated"`
+	TimeClass string         `json:"time_class"`
+	Rules     string         `json:"rules"`
+	White     chesscomPlayer `json:"white"`
+	Black     chesscomPlayer `json:"black"`
+}
+
+// chesscomDrawResults are the per-player result codes that mean a draw.
+var chesscomDrawResults = map[string]bool{
+	"agreed": true, "repetition": true, "stalemate": true, "insufficient": true,
+	"50move": true, "timevsinsufficient": true,
+}
+
+// GetGamesSince returns up to max games that ended after since, oldest first.
+// Games are published in monthly archives, so only months at or after since
+// are fetched.
+func (c *ChesscomClient) GetGamesSince(ctx context.Context, username string, since time.Time, max int) ([]Game, error) {
+	var archives struct {
+		Archives []string `json:"archives"`
+	}
+	if err := c.getJSON(ctx, c.playerURL(username)+"/games/archives", &archives); err != nil {
+		return nil, err
+	}
+
+	// Archive URLs are absolute; rebuild them from the trailing YYYY/MM so a
+	// stub base URL is honoured too.
+	var months []string
+	for _, a := range archives.Archives {
+		parts := strings.Split(strings.TrimRight(a, "/"), "/")
+		if len(parts) < 2 {
+			continue
+		}
+		ym := parts[len(parts)-2] + "/" + parts[len(parts)-1]
+		if !since.IsZero() && ym < since.UTC().Format("2006/01") {
+			continue
+		}
+		months = append(months, ym)
+	}
+	sort.Strings(months)
+
+	me := strings.ToLower(username)
+	var games []Game
+	for _, ym := range months {
+		var body struct {
+			Games []chesscomGame `json:"games"`
+		}
+		if err := c.getJSON(ctx, c.playerURL(username)+"/games/"+ym, &body); err != nil {
+			return nil, fmt.Errorf("archive %s: %w", ym, err)
+		}
+		for _, cg := range body.Games {
+			playedAt := time.Unix(cg.EndTime, 0).UTC()
+			if !playedAt.After(since) || (cg.Rules != "" && cg.Rules != "chess") {
+				continue
+			}
+			tc, ok := chesscomClasses["chess_"+cg.TimeClass]
+			if !ok {
+				tc = cg.TimeClass
+			}
+			g := Game{
+				ID:          cg.UUID,
+				PlayedAt:    playedAt,
+				TimeControl: tc,
+				Rated:       cg.Rated,
+				URL:         cg.URL,
+			}
+			if g.ID == "" {
+				g.ID = cg.URL
+			}
+			self, opp := cg.Black, cg.White
+			g.Color = "black"
+			if strings.ToLower(cg.White.Username) == me {
+				self, opp = cg.White, cg.Black
+				g.Color = "white"
+			}
+			g.OpponentName, g.OpponentRating = opp.Username, opp.Rating
+			switch {
+			case self.Result == "win":
+				g.Result = "win"
+			case chesscomDrawResults[self.Result]:
+				g.Result = "draw"
+			default:
+				g.Result = "loss"
+			}
+			games = append(games, g)
+		}
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].PlayedAt.Before(games[j].PlayedAt) })
+	if max > 0 && len(games) > max {
+		games = games[:max]
+	}
+	return games, nil
+}
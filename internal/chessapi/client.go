@@ -0,0 +1,149 @@
+package chessapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Time control names shared by both sources.
+const (
+	TimeControlBullet    = "bullet"
+	TimeControlBlitz     = "blitz"
+	TimeControlRapid     = "rapid"
+	TimeControlClassical = "classical"
+	TimeControlDaily     = "daily"
+)
+
+// HTTPDoer is the subset of *http.Client the API clients use. Swap it for a
+// stub to run the sync against a local fake server or canned responses.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Rating is the current rating of an account for one time control.
+type Rating struct {
+	TimeControl string
+	Rating      int
+	Games       int
+}
+
+// Game is a finished game normalized across sources, seen from the
+// perspective of the synced account.
+type Game struct {
+	ID             string
+	PlayedAt       time.Time
+	TimeControl    string
+	Rated          bool
+	Color          string // "white" | "black"
+	Result         string // "win" | "loss" | "draw"
+	OpponentName   string
+	OpponentRating int
+	URL            string
+}
+
+// ErrNotFound is returned when the account does not exist on the source.
+var ErrNotFound = fmt.Errorf("chess account not found")
+
+// RateLimiter spaces outgoing requests at least Interval apart. Both public
+// APIs ask clients to send requests serially and back off on 429.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewRateLimiter returns a limiter that allows one request per interval.
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+	return &RateLimiter{interval: interval}
+}
+
+// Wait blocks until the next request slot or until ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	wait := l.next.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	l.next = now.Add(wait + l.interval)
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Penalize pushes the next slot out, used after a 429 response.
+func (l *RateLimiter) Penalize(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.next) {
+		l.next = until
+	}
+}
+
+// baseClient holds what the lichess and chess.com clients share.
+type baseClient struct {
+	http      HTTPDoer
+	baseURL   string
+	userAgent string
+	limiter   *RateLimiter
+}
+
+// get performs a rate-limited GET and returns the response for the caller to
+// read. Non-2xx responses are turned into errors.
+func (c *baseClient) get(ctx context.Context, url, accept string) (*http.Response, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		resp.Body.Close()
+		c.limiter.Penalize(time.Minute)
+		return nil, fmt.Errorf("rate limited by %s", c.baseURL)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: status %d: %s", url, resp.StatusCode, body)
+	}
+	return resp, nil
+}
+
+func (c *baseClient) getJSON(ctx context.Context, url string, out interface{}) error {
+	resp, err := c.get(ctx, url, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
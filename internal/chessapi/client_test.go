@@ -0,0 +1,266 @@
+package chessapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDoer answers requests from canned bodies keyed by URL path and
+// records what was asked.
+type fakeDoer struct {
+	bodies   map[string]string
+	statuses map[string]int
+	requests []*http.Request
+}
+
+func (f *fakeDoer) Do(req *http.Request) (*http.Response, error) {
+	f.requests = append(f.requests, req)
+	status := http.StatusOK
+	if s, ok := f.statuses[req.URL.Path]; ok {
+		status = s
+	}
+	body, ok := f.bodies[req.URL.Path]
+	if !ok && status == http.StatusOK {
+		status = http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     http.Header{},
+		Request:    req,
+	}, nil
+}
+
+func ratingsByTC(rs []Rating) map[string]Rating {
+	out := map[string]Rating{}
+	for _, r := range rs {
+		out[r.TimeControl] = r
+	}
+	return out
+}
+
+func TestLichessGetRatings(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"/api/user/Magnus": `{"perfs": {
+			"blitz": {"games": 120, "rating": 1850},
+			"rapid": {"games": 0, "rating": 1500, "prov": true},
+			"correspondence": {"games": 4, "rating": 1600},
+			"puzzle": {"games": 900, "rating": 2100}
+		}}`,
+	}}
+	c := NewLichessClient(doer, "https://lichess.test/", "test-agent", NewRateLimiter(0))
+	got, err := c.GetRatings(context.Background(), "Magnus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byTC := ratingsByTC(got)
+	if len(byTC) != 2 {
+		t.Fatalf("got %d ratings, want 2 (blitz, daily): %+v", len(byTC), got)
+	}
+	if r := byTC[TimeControlBlitz]; r.Rating != 1850 || r.Games != 120 {
+		t.Errorf("blitz = %+v", r)
+	}
+	if r := byTC[TimeControlDaily]; r.Rating != 1600 {
+		t.Errorf("daily = %+v", r)
+	}
+	if ua := doer.requests[0].Header.Get("User-Agent"); ua != "test-agent" {
+		t.Errorf("User-Agent = %q", ua)
+	}
+}
+
+func TestLichessGetGamesSince(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"/api/games/user/kid": `{"id":"g1","rated":true,"speed":"blitz","lastMoveAt":1700000000000,"winner":"white","players":{"white":{"user":{"name":"Kid","id":"kid"},"rating":1500},"black":{"user":{"name":"Opp","id":"opp"},"rating":1600}}}
+
+{"id":"g2","rated":false,"speed":"rapid","lastMoveAt":1700000600000,"players":{"white":{"user":{"name":"Other","id":"other"},"rating":1400},"black":{"user":{"name":"Kid","id":"kid"},"rating":1510}}}
+{"id":"g3","rated":true,"speed":"bullet","lastMoveAt":1700001200000,"winner":"white","players":{"white":{"user":{"name":"Foe","id":"foe"},"rating":1700},"black":{"user":{"name":"Kid","id":"kid"},"rating":1505}}}
+`,
+	}}
+	c := NewLichessClient(doer, "https://lichess.test", "", NewRateLimiter(0))
+	since := time.UnixMilli(1600000000000)
+	games, err := c.GetGamesSince(context.Background(), "kid", since, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		id, tc, color, result, opp string
+		rated                      bool
+	}{
+		{"g1", TimeControlBlitz, "white", "win", "Opp", true},
+		{"g2", TimeControlRapid, "black", "draw", "Other", false},
+		{"g3", TimeControlBullet, "black", "loss", "Foe", true},
+	}
+	if len(games) != len(want) {
+		t.Fatalf("got %d games, want %d", len(games), len(want))
+	}
+	for i, w := range want {
+		g := games[i]
+		if g.ID != w.id || g.TimeControl != w.tc || g.Color != w.color || g.Result != w.result || g.OpponentName != w.opp || g.Rated != w.rated {
+			t.Errorf("game %d = %+v, want %+v", i, g, w)
+		}
+	}
+	if games[0].URL != "https://lichess.test/g1" {
+		t.Errorf("URL = %q", games[0].URL)
+	}
+	if !games[0].PlayedAt.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("PlayedAt = %v", games[0].PlayedAt)
+	}
+
+	q := doer.requests[0].URL.Query()
+	// Games started up to six hours before since are asked for again.
+	if q.Get("since") != "1599978400000" || q.Get("max") != "50" || q.Get("sort") != "dateAsc" {
+		t.Errorf("query = %v", q)
+	}
+	if a := doer.requests[0].Header.Get("Accept"); a != "application/x-ndjson" {
+		t.Errorf("Accept = %q", a)
+	}
+	if len(doer.requests) != 1 {
+		t.Errorf("made %d requests, want 1", len(doer.requests))
+	}
+
+	// A full page of games already seen drops the lookback.
+	doer.requests = nil
+	games, err = c.GetGamesSince(context.Background(), "kid", time.UnixMilli(1700001200000), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doer.requests) != 2 {
+		t.Fatalf("made %d requests, want 2", len(doer.requests))
+	}
+	if s := doer.requests[1].URL.Query().Get("since"); s != "1700001200001" {
+		t.Errorf("retry since = %s", s)
+	}
+	if len(games) != 3 {
+		t.Errorf("got %d games", len(games))
+	}
+}
+
+func TestChesscomGetRatings(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"/pub/player/kid/stats": `{
+			"chess_blitz": {"last": {"rating": 1320}, "record": {"win": 10, "loss": 5, "draw": 1}},
+			"chess_rapid": {"last": {"rating": 0}},
+			"fide": 1500,
+			"tactics": {"highest": {"rating": 2000}}
+		}`,
+	}}
+	c := NewChesscomClient(doer, "https://chess.test", "", NewRateLimiter(0))
+	got, err := c.GetRatings(context.Background(), "Kid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].TimeControl != TimeControlBlitz || got[0].Rating != 1320 || got[0].Games != 16 {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestChesscomGetGamesSince(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"/pub/player/kid/games/archives": `{"archives": [
+			"https://api.chess.com/pub/player/kid/games/2023/09",
+			"https://api.chess.com/pub/player/kid/games/2023/11",
+			"https://api.chess.com/pub/player/kid/games/2023/10"
+		]}`,
+		"/pub/player/kid/games/2023/10": `{"games": [
+			{"url":"https://www.chess.com/game/live/2","uuid":"u2","end_time":1698000000,"rated":true,"time_class":"blitz","rules":"chess",
+			 "white":{"username":"Opp","rating":1400,"result":"agreed"},"black":{"username":"Kid","rating":1300,"result":"agreed"}},
+			{"url":"https://www.chess.com/game/live/1","uuid":"u1","end_time":1696500000,"rated":true,"time_class":"rapid","rules":"chess",
+			 "white":{"username":"Kid","rating":1300,"result":"win"},"black":{"username":"Opp","rating":1400,"result":"resigned"}},
+			{"url":"https://www.chess.com/game/live/9","uuid":"u9","end_time":1698100000,"rated":true,"time_class":"blitz","rules":"chess960",
+			 "white":{"username":"Kid","rating":1300,"result":"win"},"black":{"username":"Opp","rating":1400,"result":"resigned"}}
+		]}`,
+		"/pub/player/kid/games/2023/11": `{"games": [
+			{"url":"https://www.chess.com/game/live/3","uuid":"","end_time":1699000000,"rated":false,"time_class":"bullet","rules":"chess",
+			 "white":{"username":"Opp","rating":1400,"result":"win"},"black":{"username":"kid","rating":1300,"result":"timeout"}}
+		]}`,
+	}}
+	c := NewChesscomClient(doer, "https://chess.test", "", NewRateLimiter(0))
+	since := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	games, err := c.GetGamesSince(context.Background(), "Kid", since, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ id, tc, color, result string }{
+		{"u1", TimeControlRapid, "white", "win"},
+		{"u2", TimeControlBlitz, "black", "draw"},
+		{"https://www.chess.com/game/live/3", TimeControlBullet, "black", "loss"}, // no uuid: the URL is the id
+	}
+	if len(games) != len(want) {
+		t.Fatalf("got %d games, want %d: %+v", len(games), len(want), games)
+	}
+	for i, w := range want {
+		g := games[i]
+		if g.ID != w.id || g.TimeControl != w.tc || g.Color != w.color || g.Result != w.result {
+			t.Errorf("game %d = %+v, want %+v", i, g, w)
+		}
+	}
+
+	// The 2023/09 archive is before since and must not be fetched.
+	var paths []string
+	for _, r := range doer.requests {
+		paths = append(paths, r.URL.Path)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if strings.HasSuffix(p, "/2023/09") {
+			t.Errorf("fetched archive before since: %v", paths)
+		}
+	}
+
+	// max keeps the oldest games.
+	games, err = c.GetGamesSince(context.Background(), "Kid", since, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 || games[0].ID != "u1" {
+		t.Errorf("with max 1 got %+v", games)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	doer := &fakeDoer{
+		bodies:   map[string]string{"/api/user/slow": "slow down"},
+		statuses: map[string]int{"/api/user/slow": http.StatusTooManyRequests},
+	}
+	limiter := NewRateLimiter(0)
+	c := NewLichessClient(doer, "https://lichess.test", "", limiter)
+
+	if _, err := c.GetRatings(context.Background(), "nobody"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing account: err = %v, want ErrNotFound", err)
+	}
+	if _, err := c.GetRatings(context.Background(), "slow"); err == nil {
+		t.Error("429: want an error")
+	}
+	limiter.mu.Lock()
+	next := limiter.next
+	limiter.mu.Unlock()
+	if time.Until(next) < 50*time.Second {
+		t.Errorf("429 should push the limiter out about a minute, next slot in %s", time.Until(next))
+	}
+}
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	l := NewRateLimiter(20 * time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Errorf("3 requests took %s, want at least 40ms", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.Penalize(time.Hour)
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait on cancelled ctx = %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package chessapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LichessClient reads public account data from the lichess.org API.
+type LichessClient struct {
+	baseClient
+}
+
+// NewLichessClient creates a client for baseURL (normally https://lichess.org).
+func NewLichessClient(doer HTTPDoer, baseURL, userAgent string, limiter *RateLimiter) *LichessClient {
+	return &LichessClient{baseClient{http: doer, baseURL: strings.TrimRight(baseURL, "/"), userAgent: userAgent, limiter: limiter}}
+}
+
+// lichessPerfs maps lichess perf keys to our time controls.
+var lichessPerfs = map[string]string{
+	"bullet":         TimeControlBullet,
+	"blitz":          TimeControlBlitz,
+	"rapid":          TimeControlRapid,
+	"classical":      TimeControlClassical,
+	"correspondence": TimeControlDaily,
+}
+
+// GetRatings returns the account's current rating per time control.
+// Provisional ratings with no games are skipped.
+func (c *LichessClient) GetRatings(ctx context.Context, username string) ([]Rating, error) {
+	var body struct {
+		Perfs map[string]struct {
+			Games  int  `json:"games"`
+			Rating int  `json:"rating"`
+			Prov   bool `json:"prov"`
+		} `json:"perfs"`
+	}
+	if err := c.getJSON(ctx, c.baseURL+"/api/user/"+url.PathEscape(username), &body); err != nil {
+		return nil, err
+	}
+	out := []Rating{}
+	for key, p := range body.Perfs {
+		tc, ok := lichessPerfs[key]
+		if !ok || p.Games == 0 {
+			continue
+		}
+		out = append(out, Rating{TimeControl: tc, Rating: p.Rating, Games: p.Games})
+	}
+	return out, nil
+}
+
+type lichessPlayer struct {
+	User struct {
+		Name string `json:"name"`
+		ID   string `json:"id"`
+	} `json:"user"`
+	Rating int `json:"rating"`
+}
+
+type lichessGame struct {
+	ID         string `json:"id"`
+	Rated      bool   `json:"rated"`
+	Speed      string `json:"speed"`
+	LastMoveAt int64  `json:"lastMoveAt"`
+	Status     string `json:"status"`
+	Winner     string `json:"winner"`
+	Players    struct {
+		White lichessPlayer `json:"white"`
+		Black lichessPlayer `json:"black"`
+	} `json:"players"`
+}
+
+// lichessLookback is how far before since GetGamesSince asks from. It is
+// longer than any classical game.
+const lichessLookback = 6 * time.Hour
+
+// GetGamesSince returns up to max finished games, oldest first, including
+// every game that ended after since. Lichess filters and sorts by when a
+// game started while since is when the last stored game ended, so games
+// started up to lichessLookback before since are fetched again to catch
+// those still in progress at the last sync; callers dedupe by game ID. If a
+// full page holds only games already seen, the lookback is dropped so the
+// caller still moves on.
+func (c *LichessClient) GetGamesSince(ctx context.Context, username string, since time.Time, max int) ([]Game, error) {
+	if since.IsZero() {
+		return c.exportGames(ctx, username, since, max)
+	}
+	games, err := c.exportGames(ctx, username, since.Add(-lichessLookback), max)
+	if err != nil || len(games) < max {
+		return games, err
+	}
+	for _, g := range games {
+		if g.PlayedAt.After(since) {
+			return games, nil
+		}
+	}
+	return c.exportGames(ctx, username, since.Add(time.Millisecond), max)
+}
+
+// exportGames streams the NDJSON game export for games started after from.
+func (c *LichessClient) exportGames(ctx context.Context, username string, from time.Time, max int) ([]Game, error) {
+	q := url.Values{}
+	q.Set("max", fmt.Sprint(max))
+	q.Set("moves", "false")
+	q.Set("finished", "true")
+	q.Set("sort", "dateAsc")
+	if !from.IsZero() {
+		q.Set("since", fmt.Sprint(from.UnixMilli()))
+	}
+	resp, err := c.get(ctx, c.baseURL+"/api/games/user/"+url.PathEscape(username)+"?"+q.Encode(), "application/x-ndjson")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	me := strings.ToLower(username)
+	var games []Game
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var lg lichessGame
+		if err := json.Unmarshal([]byte(line), &lg); err != nil {
+			return nil, fmt.Errorf("decode lichess game: %w", err)
+		}
+		tc, ok := lichessPerfs[lg.Speed]
+		if !ok {
+			tc = lg.Speed
+		}
+		g := Game{
+			ID:          lg.ID,
+			PlayedAt:    time.UnixMilli(lg.LastMoveAt).UTC(),
+			TimeControl: tc,
+			Rated:       lg.Rated,
+			URL:         c.baseURL + "/" + lg.ID,
+		}
+		var opp lichessPlayer
+		if strings.ToLower(lg.Players.White.User.ID) == me || strings.EqualFold(lg.Players.White.User.Name, username) {
+			g.Color, opp = "white", lg.Players.Black
+		} else {
+			g.Color, opp = "black", lg.Players.White
+		}
+		g.OpponentName, g.OpponentRating = opp.User.Name, opp.Rating
+		switch lg.Winner {
+		case "":
+			g.Result = "draw"
+		case g.Color:
+			g.Result = "win"
+		default:
+			g.Result = "loss"
+		}
+		games = append(games, g)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return games, nil
+}
@@ -0,0 +1,78 @@
+// Package geo holds the distance math used for tournament proximity search
+// and the loader for the offline ZIP centroid dataset.
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+const earthRadiusMiles = 3958.8
+
+// Point is a latitude/longitude pair in degrees.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// HaversineMiles returns the great-circle distance between a and b.
+func HaversineMiles(a, b Point) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox returns the lat/lng box that contains every point within
+// radius miles of p. It is a cheap SQL prefilter before the exact haversine.
+func BoundingBox(p Point, radiusMiles float64) (minLat, maxLat, minLng, maxLng float64) {
+	dLat := radiusMiles / earthRadiusMiles * 180 / math.Pi
+	minLat, maxLat = p.Lat-dLat, p.Lat+dLat
+	cos := math.Cos(p.Lat * math.Pi / 180)
+	if cos < 0.01 || maxLat >= 90 || minLat <= -90 {
+		return math.Max(minLat, -90), math.Min(maxLat, 90), -180, 180
+	}
+	dLng := dLat / cos
+	return minLat, maxLat, p.Lng - dLng, p.Lng + dLng
+}
+
+// CoverCenters picks a subset of the keyed points such that every point is
+// within radius miles of some chosen center. It is a greedy cover, not a
+// minimal one, but keeps the result stable for the same input.
+func CoverCenters(points map[string]Point, radiusMiles float64) []string {
+	keys := make([]string, 0, len(points))
+	for k := range points {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	covered := make(map[string]bool, len(keys))
+	var centers []string
+	for len(covered) < len(keys) {
+		// take the uncovered point that covers the most uncovered points
+		best, bestCount := "", -1
+		for _, k := range keys {
+			if covered[k] {
+				continue
+			}
+			n := 0
+			for _, o := range keys {
+				if !covered[o] && HaversineMiles(points[k], points[o]) <= radiusMiles {
+					n++
+				}
+			}
+			if n > bestCount {
+				best, bestCount = k, n
+			}
+		}
+		centers = append(centers, best)
+		for _, o := range keys {
+			if HaversineMiles(points[best], points[o]) <= radiusMiles {
+				covered[o] = true
+			}
+		}
+	}
+	return centers
+}
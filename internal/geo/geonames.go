@@ -0,0 +1,127 @@
+package geo
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Centroid is one row of the ZIP centroid dataset.
+type Centroid struct {
+	Zipcode   string
+	PlaceName string
+	State     string // two-letter code
+	Point
+}
+
+// ParseGeoNames reads a GeoNames postal code dump (e.g. US.txt from
+// download.geonames.org/export/zip/) and calls fn for every row. The file is
+// tab separated: country, postal code, place name, admin name1, admin code1,
+// admin name2, admin code2, admin name3, admin code3, latitude, longitude,
+// accuracy.
+func ParseGeoNames(r io.Reader, fn func(Centroid) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimRight(sc.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		cols := strings.Split(text, "\t")
+		if len(cols) < 11 {
+			return fmt.Errorf("line %d: expected at least 11 columns, got %d", line, len(cols))
+		}
+		lat, err := strconv.ParseFloat(strings.TrimSpace(cols[9]), 64)
+		if err != nil {
+			return fmt.Errorf("line %d: latitude: %w", line, err)
+		}
+		lng, err := strconv.ParseFloat(strings.TrimSpace(cols[10]), 64)
+		if err != nil {
+			return fmt.Errorf("line %d: longitude: %w", line, err)
+		}
+		if err := fn(Centroid{
+			Zipcode:   strings.TrimSpace(cols[1]),
+			PlaceName: strings.TrimSpace(cols[2]),
+			State:     strings.ToUpper(strings.TrimSpace(cols[4])),
+			Point:     Point{Lat: lat, Lng: lng},
+		}); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// GeoNamesUSURL is the published US postal code dump.
+const GeoNamesUSURL = "https://download.geonames.org/export/zip/US.zip"
+
+// FetchGeoNames downloads a GeoNames postal code archive from url and writes
+// the country file inside it (US.txt for US.zip) to dest. The file is
+// written next to dest first and renamed, so a failed download never leaves
+// a truncated dataset behind.
+func FetchGeoNames(ctx context.Context, client *http.Client, url, dest string) error {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	want := strings.TrimSuffix(path.Base(url), path.Ext(url)) + ".txt"
+	for _, f := range zr.File {
+		if f.Name != want {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		tmp := dest + ".tmp"
+		out, err := os.Create(tmp)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, rc); err != nil {
+			out.Close()
+			os.Remove(tmp)
+			return err
+		}
+		if err := out.Close(); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		return os.Rename(tmp, dest)
+	}
+	return fmt.Errorf("%s has no %s", url, want)
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunExclusive runs fn on one replica at a time until ctx is cancelled. It
+// is for long-running loops that poll more often than a job could run:
+// whichever replica takes the advisory lock for name runs fn, and the
+// others retry every interval. fn's context is cancelled when ctx is, or
+// when the lock's session is lost, after which the lock is sought again.
+// fn must tolerate briefly overlapping another replica's run while a lost
+// lock changes hands.
+func (sc *Scheduler) RunExclusive(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		lock, err := sc.store.TryAdvisoryLock(ctx, lockKey(name))
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[jobs] %s: take lock: %v", name, err)
+		}
+		if lock != nil {
+			sc.holdWhile(ctx, name, lock.Ping, interval, fn)
+			if err := lock.Release(); err != nil {
+				log.Printf("[jobs] %s: release lock: %v", name, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// holdWhile runs fn until ctx is done or ping fails, checking every
+// interval, and returns once fn has.
+func (sc *Scheduler) holdWhile(ctx context.Context, name string, ping func(context.Context) error, interval time.Duration, fn func(ctx context.Context)) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(runCtx)
+	}()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			if err := ping(runCtx); err != nil && runCtx.Err() == nil {
+				log.Printf("[jobs] %s: lost lock: %v", name, err)
+				cancel()
+				<-done
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHoldWhileStopsOnLostLock(t *testing.T) {
+	sc := &Scheduler{}
+	var pings atomic.Int32
+	ping := func(context.Context) error {
+		if pings.Add(1) >= 2 {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+	stopped := make(chan struct{})
+	go func() {
+		sc.holdWhile(context.Background(), "test", ping, 5*time.Millisecond, func(ctx context.Context) { <-ctx.Done() })
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("fn kept running after the lock was lost")
+	}
+	if n := pings.Load(); n != 2 {
+		t.Errorf("pinged %d times, want 2", n)
+	}
+}
+
+func TestHoldWhileStopsWithContext(t *testing.T) {
+	sc := &Scheduler{}
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran atomic.Bool
+	stopped := make(chan struct{})
+	go func() {
+		sc.holdWhile(ctx, "test", func(context.Context) error { return nil }, time.Hour, func(ctx context.Context) {
+			ran.Store(true)
+			<-ctx.Done()
+		})
+		close(stopped)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("holdWhile didn't return after ctx was cancelled")
+	}
+	if !ran.Load() {
+		t.Error("fn never ran")
+	}
+}
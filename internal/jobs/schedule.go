@@ -0,0 +1,205 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when a job next runs.
+type Schedule interface {
+	// Next returns the first run time strictly after t.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule reads a cron spec in loc. It accepts the five standard
+// fields (minute hour day-of-month month day-of-week) with *, lists,
+// ranges, /steps and JAN-DEC / SUN-SAT names, the @yearly, @monthly,
+// @weekly, @daily and @hourly shorthands, and "@every <duration>".
+func ParseSchedule(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if loc == nil {
+		loc = time.UTC
+	}
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid @every duration %q", rest)
+		}
+		return every(d), nil
+	}
+	if s, ok := shorthands[spec]; ok {
+		spec = s
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron spec %q: want 5 fields, got %d", spec, len(fields))
+	}
+	cs := &cronSchedule{loc: loc}
+	var err error
+	if cs.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if cs.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if cs.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if cs.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	// 7 is accepted as Sunday, as in most crons.
+	if cs.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if cs.dow.has(7) {
+		cs.dow |= 1
+	}
+	cs.domAny = fields[2] == "*" || fields[2] == "?"
+	cs.dowAny = fields[4] == "*" || fields[4] == "?"
+	return cs, nil
+}
+
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	dayNames   = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+)
+
+// bits is a set of small integers, bit i meaning i is included.
+type bits uint64
+
+const allHours bits = 1<<24 - 1
+
+func (b bits) has(i int) bool { return b&(1<<uint(i)) != 0 }
+
+func parseField(field string, min, max int, names map[string]int) (bits, error) {
+	var out bits
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step in %q", part)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		switch {
+		case rng == "*" || rng == "?":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = fieldValue(a, names); err != nil {
+				return 0, err
+			}
+			if hi, err = fieldValue(b, names); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := fieldValue(rng, names)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			if hasStep {
+				hi = max // "5/15" means from 5 to the end, every 15
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for i := lo; i <= hi; i += step {
+			out |= 1 << uint(i)
+		}
+	}
+	return out, nil
+}
+
+func fieldValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad value %q", s)
+	}
+	return v, nil
+}
+
+type cronSchedule struct {
+	loc                      *time.Location
+	minute, hour, dom, month bits
+	dow                      bits
+	domAny, dowAny           bool
+}
+
+// dayMatches follows cron: when both day fields are restricted, a day
+// matching either one counts.
+func (cs *cronSchedule) dayMatches(t time.Time) bool {
+	dom, dow := cs.dom.has(t.Day()), cs.dow.has(int(t.Weekday()))
+	switch {
+	case cs.domAny && cs.dowAny:
+		return true
+	case cs.domAny:
+		return dow
+	case cs.dowAny:
+		return dom
+	}
+	return dom || dow
+}
+
+// Next walks forward a month, day, hour or minute at a time, skipping
+// whole units that can't match. Hours and minutes advance in absolute
+// time, so local times skipped when clocks go forward are skipped. When
+// clocks go back, jobs pinned to particular hours run in the first pass
+// through the repeated hour only.
+func (cs *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(cs.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case !cs.month.has(int(m)):
+			t = advance(t, time.Date(y, m+1, 1, 0, 0, 0, 0, cs.loc))
+		case !cs.dayMatches(t):
+			t = advance(t, time.Date(y, m, d+1, 0, 0, 0, 0, cs.loc))
+		case !cs.hour.has(t.Hour()):
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case !cs.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		case cs.hour != allHours && t.Add(-time.Hour).Format("15:04") == t.Format("15:04"):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// advance moves to next, or by an hour if a DST change made next land at
+// or before t.
+func advance(t, next time.Time) time.Time {
+	if !next.After(t) {
+		return t.Add(time.Hour).Truncate(time.Hour)
+	}
+	return next
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(time.Duration(e))
+}
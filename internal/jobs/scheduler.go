@@ -0,0 +1,313 @@
+// Package jobs runs periodic background work inside the API process.
+//
+// Every replica runs a Scheduler with the same jobs. Job state (next run
+// time, paused, manual trigger) lives in Postgres, and a run happens under
+// a per-job advisory lock, so each due run is picked up by exactly one
+// replica. Run history is kept in job_runs.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+var ErrUnknownJob = errors.New("unknown job")
+
+// Job is one piece of periodic work.
+type Job struct {
+	Name        string
+	Schedule    string // see ParseSchedule; read in the scheduler's timezone
+	Description string
+	Timeout     time.Duration // 0 means no limit
+	Run         func(ctx context.Context) error
+}
+
+// JobInfo is a job's definition with its shared state, for the admin API.
+type JobInfo struct {
+	models.JobState
+	Description string `json:"description"`
+	Running     bool   `json:"running_here"` // running on the replica that answered
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+	running  atomic.Bool
+}
+
+// Scheduler starts due jobs on each tick. Register every job before Run.
+type Scheduler struct {
+	store    *store.Store
+	loc      *time.Location
+	instance string
+
+	entries []*entry
+	byName  map[string]*entry
+	wg      sync.WaitGroup
+}
+
+func NewScheduler(s *store.Store, loc *time.Location) *Scheduler {
+	if loc == nil {
+		loc = time.UTC
+	}
+	host, _ := os.Hostname()
+	return &Scheduler{
+		store:    s,
+		loc:      loc,
+		instance: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		byName:   map[string]*entry{},
+	}
+}
+
+// Register adds j. Jobs are wired at startup, so a bad schedule or a
+// duplicate name is a programming error and panics.
+func (sc *Scheduler) Register(j Job) {
+	sched, err := ParseSchedule(j.Schedule, sc.loc)
+	if err != nil {
+		panic(fmt.Sprintf("jobs: %s: %v", j.Name, err))
+	}
+	if _, dup := sc.byName[j.Name]; dup {
+		panic("jobs: duplicate job " + j.Name)
+	}
+	e := &entry{job: j, schedule: sched}
+	sc.entries = append(sc.entries, e)
+	sc.byName[j.Name] = e
+}
+
+// Run checks for due jobs every tick until ctx is cancelled. Cancelling
+// ctx also cancels running jobs; Wait blocks until they have returned.
+func (sc *Scheduler) Run(ctx context.Context, tick time.Duration) {
+	t := time.NewTicker(tick)
+	defer t.Stop()
+	ensured := false
+	for {
+		if !ensured {
+			if err := sc.ensureStates(ctx); err != nil {
+				if ctx.Err() == nil {
+					log.Printf("[jobs] save job states: %v", err)
+				}
+			} else {
+				ensured = true
+			}
+		}
+		if ensured {
+			sc.startDue(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Wait blocks until running jobs have finished, or ctx is done.
+func (sc *Scheduler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		sc.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (sc *Scheduler) ensureStates(ctx context.Context) error {
+	now := time.Now()
+	states := make([]models.JobState, 0, len(sc.entries))
+	for _, e := range sc.entries {
+		next := e.schedule.Next(now)
+		states = append(states, models.JobState{Name: e.job.Name, Schedule: e.job.Schedule, NextRunAt: &next, UpdatedAt: now})
+	}
+	return sc.store.EnsureJobStates(ctx, states)
+}
+
+// startDue launches every job that is due or was triggered and isn't
+// already running here. Whether it actually runs is settled under the lock.
+func (sc *Scheduler) startDue(ctx context.Context) {
+	states, err := sc.store.ListJobStates(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("[jobs] load job states: %v", err)
+		}
+		return
+	}
+	now := time.Now()
+	for i := range states {
+		st := &states[i]
+		e, ok := sc.byName[st.Name]
+		if !ok || !isDue(st, now) || !e.running.CompareAndSwap(false, true) {
+			continue
+		}
+		sc.wg.Add(1)
+		go func() {
+			defer sc.wg.Done()
+			defer e.running.Store(false)
+			sc.runLocked(ctx, e)
+		}()
+	}
+}
+
+func isDue(st *models.JobState, now time.Time) bool {
+	if st.RunRequested {
+		return true
+	}
+	return !st.Paused && st.NextRunAt != nil && !st.NextRunAt.After(now)
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("jobs:" + name))
+	return int64(h.Sum64())
+}
+
+func (sc *Scheduler) runLocked(ctx context.Context, e *entry) {
+	name := e.job.Name
+	lock, err := sc.store.TryAdvisoryLock(ctx, lockKey(name))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("[jobs] %s: take lock: %v", name, err)
+		}
+		return
+	}
+	if lock == nil {
+		return // another replica is running it
+	}
+	defer func() {
+		if err := lock.Release(); err != nil {
+			log.Printf("[jobs] %s: release lock: %v", name, err)
+		}
+	}()
+
+	// Check again under the lock: another replica may have just run it.
+	st, err := sc.store.GetJobState(ctx, name)
+	if err != nil {
+		log.Printf("[jobs] %s: load state: %v", name, err)
+		return
+	}
+	now := time.Now()
+	if !isDue(st, now) {
+		return
+	}
+	if err := sc.store.AbandonJobRuns(ctx, name); err != nil {
+		log.Printf("[jobs] %s: close abandoned runs: %v", name, err)
+	}
+	run := &models.JobRun{
+		JobName:   name,
+		Trigger:   models.JobTriggerSchedule,
+		Status:    models.JobRunRunning,
+		Instance:  sc.instance,
+		StartedAt: now,
+	}
+	var next *time.Time
+	if st.RunRequested {
+		run.Trigger, run.TriggeredBy = models.JobTriggerManual, st.RunRequestedBy
+	} else {
+		n := e.schedule.Next(now)
+		next = &n
+	}
+	if err := sc.store.StartJobRun(ctx, run, next); err != nil {
+		log.Printf("[jobs] %s: record start: %v", name, err)
+		return
+	}
+
+	err = sc.execute(ctx, e)
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(now).Milliseconds()
+	run.Status = models.JobRunSucceeded
+	if err != nil {
+		run.Status, run.Error = models.JobRunFailed, err.Error()
+		log.Printf("[jobs] %s (%s) failed after %s: %v", name, run.Trigger, finished.Sub(now).Round(time.Millisecond), err)
+	}
+	// Record the result even if we are shutting down.
+	rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := sc.store.FinishJobRun(rctx, run); err != nil {
+		log.Printf("[jobs] %s: record result: %v", name, err)
+	}
+}
+
+func (sc *Scheduler) execute(ctx context.Context, e *entry) (err error) {
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return e.job.Run(ctx)
+}
+
+// Jobs lists the registered jobs with their shared state.
+func (sc *Scheduler) Jobs(ctx context.Context) ([]JobInfo, error) {
+	states, err := sc.store.ListJobStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]models.JobState{}
+	for _, st := range states {
+		byName[st.Name] = st
+	}
+	out := make([]JobInfo, 0, len(sc.entries))
+	for _, e := range sc.entries {
+		st, ok := byName[e.job.Name]
+		if !ok {
+			st = models.JobState{Name: e.job.Name, Schedule: e.job.Schedule}
+		}
+		out = append(out, JobInfo{JobState: st, Description: e.job.Description, Running: e.running.Load()})
+	}
+	return out, nil
+}
+
+// Trigger asks for an immediate run on whichever replica picks it up
+// first. It runs even if the job is paused.
+func (sc *Scheduler) Trigger(ctx context.Context, name, requestedBy string) error {
+	if _, ok := sc.byName[name]; !ok {
+		return ErrUnknownJob
+	}
+	return sc.store.UpdateJobState(ctx, name, map[string]interface{}{"run_requested": true, "run_requested_by": requestedBy})
+}
+
+// Pause stops scheduled runs of name until Resume.
+func (sc *Scheduler) Pause(ctx context.Context, name string) error {
+	if _, ok := sc.byName[name]; !ok {
+		return ErrUnknownJob
+	}
+	return sc.store.UpdateJobState(ctx, name, map[string]interface{}{"paused": true})
+}
+
+// Resume restarts scheduled runs from the next slot; runs missed while
+// paused are not made up.
+func (sc *Scheduler) Resume(ctx context.Context, name string) error {
+	e, ok := sc.byName[name]
+	if !ok {
+		return ErrUnknownJob
+	}
+	return sc.store.UpdateJobState(ctx, name, map[string]interface{}{"paused": false, "next_run_at": e.schedule.Next(time.Now())})
+}
+
+// Runs returns name's run history, newest first.
+func (sc *Scheduler) Runs(ctx context.Context, name, status string, limit, offset int) ([]models.JobRun, int64, error) {
+	if _, ok := sc.byName[name]; !ok {
+		return nil, 0, ErrUnknownJob
+	}
+	return sc.store.ListJobRuns(ctx, name, status, limit, offset)
+}
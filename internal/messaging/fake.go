@@ -0,0 +1,75 @@
+package messaging
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FakeSecretHeader carries the shared secret on Fake callbacks.
+const FakeSecretHeader = "X-Fake-Secret"
+
+// Fake is a local provider for development and tests: it logs and records
+// messages instead of sending them. Its callbacks are JSON:
+// [{"provider_message_id": "...", "status": "delivered"}].
+type Fake struct {
+	mu            sync.Mutex
+	seq           int
+	sent          []TemplateMessage
+	secret        string
+	allowUnsigned bool
+}
+
+// NewFake returns a Fake whose callbacks must carry secret in
+// FakeSecretHeader. With no secret, callbacks are accepted only when
+// allowUnsigned is set, which is for development; otherwise all are
+// refused, so no one can forge message statuses.
+func NewFake(secret string, allowUnsigned bool) *Fake {
+	return &Fake{secret: secret, allowUnsigned: allowUnsigned}
+}
+
+func (f *Fake) Name() string { return "fake" }
+
+func (f *Fake) SendTemplate(ctx context.Context, msg TemplateMessage) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	f.sent = append(f.sent, msg)
+	log.Printf("[messaging] fake send %s to %s %v", msg.Template, msg.To, msg.Params)
+	return fmt.Sprintf("fake-%d", f.seq), nil
+}
+
+// Sent returns a copy of the messages sent so far.
+func (f *Fake) Sent() []TemplateMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]TemplateMessage(nil), f.sent...)
+}
+
+func (f *Fake) VerifyCallback(r *http.Request, body []byte) bool {
+	if f.secret == "" {
+		return f.allowUnsigned
+	}
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get(FakeSecretHeader)), []byte(f.secret)) == 1
+}
+
+func (f *Fake) ParseStatusCallback(body []byte) ([]StatusUpdate, error) {
+	var in []struct {
+		ProviderMessageID string `json:"provider_message_id"`
+		Status            string `json:"status"`
+		Error             string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return nil, err
+	}
+	out := make([]StatusUpdate, 0, len(in))
+	for _, u := range in {
+		out = append(out, StatusUpdate{ProviderMessageID: u.ProviderMessageID, Status: u.Status, Error: u.Error, Timestamp: time.Now()})
+	}
+	return out, nil
+}
@@ -0,0 +1,36 @@
+package messaging
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFakeVerifyCallback(t *testing.T) {
+	body := []byte(`[{"provider_message_id": "fake-1", "status": "delivered"}]`)
+	tests := []struct {
+		name          string
+		secret        string
+		allowUnsigned bool
+		header        string
+		want          bool
+	}{
+		{"development, unsigned", "", true, "", true},
+		{"deployed, unsigned", "", false, "", false},
+		{"deployed, header without secret configured", "", false, "guess", false},
+		{"secret matches", "s3cret", false, "s3cret", true},
+		{"secret wrong", "s3cret", true, "nope", false},
+		{"secret missing", "s3cret", true, "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/messaging/webhook", strings.NewReader(string(body)))
+			if tc.header != "" {
+				r.Header.Set(FakeSecretHeader, tc.header)
+			}
+			if got := NewFake(tc.secret, tc.allowUnsigned).VerifyCallback(r, body); got != tc.want {
+				t.Errorf("VerifyCallback = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
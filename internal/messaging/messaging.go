@@ -0,0 +1,71 @@
+// Package messaging sends templated chat messages (class reminders,
+// homework) through an external provider and reads back delivery status.
+package messaging
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Delivery statuses, in the order a message normally moves through them.
+const (
+	StatusQueued    = "queued"
+	StatusSent      = "sent"
+	StatusDelivered = "delivered"
+	StatusRead      = "read"
+	StatusFailed    = "failed"
+)
+
+// HTTPDoer is the subset of *http.Client the providers use.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TemplateMessage is a pre-approved template filled with positional
+// parameters. To is a phone number in international format.
+type TemplateMessage struct {
+	To       string
+	Template string
+	Language string
+	Params   []string
+}
+
+// StatusUpdate is one delivery status reported by a provider callback.
+type StatusUpdate struct {
+	ProviderMessageID string
+	Status            string
+	Timestamp         time.Time
+	Error             string
+}
+
+// Provider is a messaging backend. Callbacks are verified and parsed by the
+// provider that sent the messages, since each signs them differently.
+type Provider interface {
+	Name() string
+	SendTemplate(ctx context.Context, msg TemplateMessage) (providerMessageID string, err error)
+	// VerifyCallback checks the callback's signature over body.
+	VerifyCallback(r *http.Request, body []byte) bool
+	ParseStatusCallback(body []byte) ([]StatusUpdate, error)
+}
+
+// NormalizePhone reduces a phone number to digits with a country code.
+// Ten-digit numbers are taken as North American and get defaultCountry.
+// It returns "" when the number can't be used.
+func NormalizePhone(phone, defaultCountry string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := strings.TrimPrefix(b.String(), "00")
+	switch {
+	case len(digits) == 10 && !strings.HasPrefix(strings.TrimSpace(phone), "+"):
+		return defaultCountry + digits
+	case len(digits) < 8 || len(digits) > 15:
+		return ""
+	}
+	return digits
+}
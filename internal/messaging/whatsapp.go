@@ -0,0 +1,154 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WhatsAppCloud sends template messages through the WhatsApp Business
+// Cloud API and reads its status webhooks.
+type WhatsAppCloud struct {
+	http          HTTPDoer
+	baseURL       string
+	phoneNumberID string
+	accessToken   string
+	appSecret     string
+}
+
+// NewWhatsAppCloud creates a client for baseURL (normally
+// https://graph.facebook.com/v21.0). appSecret signs the webhooks.
+func NewWhatsAppCloud(doer HTTPDoer, baseURL, phoneNumberID, accessToken, appSecret string) *WhatsAppCloud {
+	if doer == nil {
+		doer = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &WhatsAppCloud{
+		http:          doer,
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		phoneNumberID: phoneNumberID,
+		accessToken:   accessToken,
+		appSecret:     appSecret,
+	}
+}
+
+func (c *WhatsAppCloud) Name() string { return "whatsapp" }
+
+func (c *WhatsAppCloud) SendTemplate(ctx context.Context, msg TemplateMessage) (string, error) {
+	params := make([]map[string]string, 0, len(msg.Params))
+	for _, p := range msg.Params {
+		params = append(params, map[string]string{"type": "text", "text": p})
+	}
+	template := map[string]interface{}{
+		"name":     msg.Template,
+		"language": map[string]string{"code": msg.Language},
+	}
+	if len(params) > 0 {
+		template["components"] = []map[string]interface{}{{"type": "body", "parameters": params}}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                msg.To,
+		"type":              "template",
+		"template":          template,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+c.phoneNumberID+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+				Code    int    `json:"code"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(raw, &e) == nil && e.Error.Message != "" {
+			return "", fmt.Errorf("whatsapp %d: %s", e.Error.Code, e.Error.Message)
+		}
+		return "", fmt.Errorf("whatsapp returned %s", resp.Status)
+	}
+	var out struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return "", fmt.Errorf("decode whatsapp response: %w", err)
+	}
+	if len(out.Messages) == 0 {
+		return "", fmt.Errorf("whatsapp response has no message id")
+	}
+	return out.Messages[0].ID, nil
+}
+
+// VerifyCallback checks X-Hub-Signature-256, an HMAC-SHA256 of the raw
+// body keyed with the app secret.
+func (c *WhatsAppCloud) VerifyCallback(r *http.Request, body []byte) bool {
+	sig := strings.TrimPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256=")
+	got, err := hex.DecodeString(sig)
+	if err != nil || c.appSecret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(c.appSecret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func (c *WhatsAppCloud) ParseStatusCallback(body []byte) ([]StatusUpdate, error) {
+	var payload struct {
+		Entry []struct {
+			Changes []struct {
+				Value struct {
+					Statuses []struct {
+						ID        string `json:"id"`
+						Status    string `json:"status"`
+						Timestamp string `json:"timestamp"`
+						Errors    []struct {
+							Code  int    `json:"code"`
+							Title string `json:"title"`
+						} `json:"errors"`
+					} `json:"statuses"`
+				} `json:"value"`
+			} `json:"changes"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	var out []StatusUpdate
+	for _, e := range payload.Entry {
+		for _, ch := range e.Changes {
+			for _, st := range ch.Value.Statuses {
+				u := StatusUpdate{ProviderMessageID: st.ID, Status: st.Status, Timestamp: time.Now()}
+				if sec, err := strconv.ParseInt(st.Timestamp, 10, 64); err == nil {
+					u.Timestamp = time.Unix(sec, 0)
+				}
+				if len(st.Errors) > 0 {
+					u.Error = fmt.Sprintf("%d: %s", st.Errors[0].Code, st.Errors[0].Title)
+				}
+				out = append(out, u)
+			}
+		}
+	}
+	return out, nil
+}
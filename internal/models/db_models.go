@@ -37,7 +37,8 @@ type UserDetails struct {
 	Bio               string            `json:"bio"`
 	ProfilePictureURL string            `json:"profile_picture_url"`
 	SyllabusURL       string            `json:"syllabus_url"`
-	AddedInWhatsapp   bool              `gorm:"default:false" json:"added_in_whatsapp"`
+	CurriculumLevelID *uint             `gorm:"index" json:"curriculum_level_id"`
+	AddedInWhatsapp   bool              `gorm:"default:false" json:"added_in_whatsapp"` // kept in sync with WhatsApp group membership
 	PersonalMeetLink  string            `json:"personal_meet_link"`
 	AdditionalInfo    datatypes.JSONMap `gorm:"type:jsonb" json:"additional_info"`
 	UpdatedAt         time.Time         `json:"updated_at"`
@@ -72,12 +73,184 @@ type LessonPlan struct {
 	EndDate     time.Time      `json:"end_date"`
 	Result      string         `json:"result"`
 	Active      bool           `gorm:"default:true;index" json:"active"`
+	ArchivedAt  *time.Time     `json:"archived_at,omitempty"` // replaced by a newer plan
 	CreatedBy   string         `gorm:"size:10" json:"created_by"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Items    []LessonPlanItem    `gorm:"foreignKey:LessonPlanID" json:"items,omitempty"`
+	Progress *LessonPlanProgress `gorm:"-" json:"progress,omitempty"`
+}
+
+type LessonPlanItemStatus string
+
+const (
+	LessonPlanItemPending    LessonPlanItemStatus = "pending"
+	LessonPlanItemInProgress LessonPlanItemStatus = "in_progress"
+	LessonPlanItemCompleted  LessonPlanItemStatus = "completed"
+	LessonPlanItemSkipped    LessonPlanItemStatus = "skipped"
+)
+
+// LessonPlanItem is one curriculum step of a lesson plan.
+type LessonPlanItem struct {
+	ID           uint                 `gorm:"primaryKey" json:"id"`
+	LessonPlanID uint                 `gorm:"index;not null" json:"lesson_plan_id"`
+	Position     int                  `gorm:"not null" json:"position"`
+	TopicID      *uint                `gorm:"index" json:"topic_id,omitempty"` // curriculum topic it was created from
+	Topic        string               `gorm:"not null" json:"topic"`
+	Objective    string               `gorm:"type:text" json:"objective"`
+	TargetDate   *time.Time           `gorm:"type:date" json:"target_date"`
+	Status       LessonPlanItemStatus `gorm:"type:text;not null;default:'pending'" json:"status"`
+	CompletedAt  *time.Time           `json:"completed_at"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt       `gorm:"index" json:"-"`
+
+	Sessions []LessonPlanItemSession `gorm:"foreignKey:LessonPlanItemID" json:"sessions,omitempty"`
+}
+
+// LessonPlanItemSession ties an attendance (a taught class) to the item it
+// covered.
+type LessonPlanItemSession struct {
+	LessonPlanItemID uint      `gorm:"primaryKey" json:"lesson_plan_item_id"`
+	AttendanceID     uint      `gorm:"primaryKey;index" json:"attendance_id"`
+	SessionDate      time.Time `gorm:"type:date" json:"session_date"`
+	LinkedBy         string    `gorm:"size:10" json:"linked_by"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// LessonPlanProgress summarizes item statuses. Skipped items don't count
+// towards Percent.
+type LessonPlanProgress struct {
+	Total      int     `json:"total"`
+	Pending    int     `json:"pending"`
+	InProgress int     `json:"in_progress"`
+	Completed  int     `json:"completed"`
+	Skipped    int     `json:"skipped"`
+	Percent    float64 `json:"percent"`
+}
+
+// CurriculumLevel is one stage of the academy syllabus (beginner, ...).
+// Levels, modules and topics are ordered by Position.
+type CurriculumLevel struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"uniqueIndex;not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	Position    int            `gorm:"not null;default:0" json:"position"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Modules []CurriculumModule `gorm:"foreignKey:LevelID" json:"modules,omitempty"`
+}
+
+type CurriculumModule struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	LevelID     uint           `gorm:"index;not null" json:"level_id"`
+	Title       string         `gorm:"not null" json:"title"`
+	Description string         `gorm:"type:text" json:"description"`
+	Position    int            `gorm:"not null;default:0" json:"position"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Topics []CurriculumTopic `gorm:"foreignKey:ModuleID" json:"topics,omitempty"`
+}
+
+// CurriculumTopic is the unit a student masters. Objectives is a JSON array
+// of strings; Resources (reference material) a JSON array of {"title", "url"}.
+type CurriculumTopic struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	ModuleID   uint           `gorm:"index;not null" json:"module_id"`
+	Title      string         `gorm:"not null" json:"title"`
+	Objectives datatypes.JSON `gorm:"type:jsonb" json:"objectives"`
+	Resources  datatypes.JSON `gorm:"type:jsonb" json:"resources"`
+	Position   int            `gorm:"not null;default:0" json:"position"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// AssessmentType names who is assessed. The values match the note tags that
+// carried assessments before, so the same authoring restrictions apply.
+type AssessmentType string
+
+const (
+	AssessmentTypeStudent AssessmentType = "StudentAssessment"
+	AssessmentTypeCoach   AssessmentType = "CoachAssessment"
+)
+
+// Rubric is an admin-defined scoring sheet: a set of criteria each scored
+// from MinScore to MaxScore.
+type Rubric struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"uniqueIndex;not null" json:"name"`
+	Type        AssessmentType `gorm:"type:text;not null;index" json:"type"`
+	Description string         `gorm:"type:text" json:"description"`
+	MinScore    int            `gorm:"not null;default:1" json:"min_score"`
+	MaxScore    int            `gorm:"not null;default:5" json:"max_score"`
+	Active      bool           `gorm:"default:true" json:"active"`
+	CreatedBy   string         `gorm:"size:10" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+
+	Criteria []RubricCriterion `gorm:"foreignKey:RubricID" json:"criteria,omitempty"`
+}
+
+type RubricCriterion struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	RubricID    uint   `gorm:"index;not null" json:"rubric_id"`
+	Name        string `gorm:"not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	Position    int    `gorm:"not null;default:0" json:"position"`
+}
+
+// Assessment is one filled-in rubric for a student (by a coach or mentor)
+// or a coach (by a mentor).
+type Assessment struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	RubricID   uint           `gorm:"index;not null" json:"rubric_id"`
+	Type       AssessmentType `gorm:"type:text;not null" json:"type"`
+	SubjectID  string         `gorm:"index;size:10;not null" json:"subject_id"`
+	AssessorID string         `gorm:"index;size:10;not null" json:"assessor_id"`
+	AssessedOn time.Time      `gorm:"type:date;index;not null" json:"assessed_on"`
+	Comment    string         `gorm:"type:text" json:"comment"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Scores []AssessmentScore `gorm:"foreignKey:AssessmentID" json:"scores"`
+}
+
+type AssessmentScore struct {
+	AssessmentID uint   `gorm:"primaryKey" json:"assessment_id"`
+	CriterionID  uint   `gorm:"primaryKey;index" json:"criterion_id"`
+	Score        int    `gorm:"not null" json:"score"`
+	Comment      string `gorm:"type:text" json:"comment,omitempty"`
+}
+
+// TopicMastery records that a student has mastered a curriculum topic.
+type TopicMastery struct {
+	StudentID  string    `gorm:"primaryKey;size:10" json:"student_id"`
+	TopicID    uint      `gorm:"primaryKey;index" json:"topic_id"`
+	MasteredAt time.Time `json:"mastered_at"`
+	MarkedBy   string    `gorm:"size:10" json:"marked_by"`
+	Note       string    `gorm:"type:text" json:"note"`
 }
 
+// NoteVisibility names who may read a note besides admins and the users it
+// is explicitly shared with.
+type NoteVisibility string
+
+const (
+	NoteVisibilityAdminOnly       NoteVisibility = "admin_only"
+	NoteVisibilityMentors         NoteVisibility = "mentors"           // the student's mentor
+	NoteVisibilityStaff           NoteVisibility = "staff"             // the student's mentor and coach
+	NoteVisibilityStudentAndStaff NoteVisibility = "student_and_staff" // plus the student
+	NoteVisibilityParents         NoteVisibility = "parents"           // plus family linked in the referral network
+)
+
 type Note struct {
 	ID     uint   `gorm:"primaryKey" json:"id"`
 	UserID string `gorm:"index;size:10" json:"user_id"`
@@ -88,13 +261,300 @@ type Note struct {
 	Tags           datatypes.JSON `gorm:"type:jsonb" json:"tags"` // JSON array
 	IsStarred      bool           `gorm:"default:false" json:"is_starred"`
 	AdditionalInfo datatypes.JSON `gorm:"type:jsonb" json:"additional_info"`
-	Visibility     int            `gorm:"not null" json:"visibility"`
+	Visibility     NoteVisibility `gorm:"type:text;not null;default:'student_and_staff'" json:"visibility"`
 	CreatedBy      string         `gorm:"size:10" json:"created_by"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// Revision actions
+const (
+	RevisionCreated   = "created"
+	RevisionImported  = "imported" // content that predates revision tracking
+	RevisionUpdated   = "updated"
+	RevisionRestored  = "restored"
+	RevisionDeleted   = "deleted"
+	RevisionUndeleted = "undeleted"
+)
+
+// NoteRevision is a full copy of a note's content after one change.
+type NoteRevision struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	NoteID         uint           `gorm:"uniqueIndex:idx_note_revisions_note_rev;not null" json:"note_id"`
+	Revision       int            `gorm:"uniqueIndex:idx_note_revisions_note_rev;not null" json:"revision"`
+	Action         string         `gorm:"type:text;not null" json:"action"`
+	RestoredFrom   *int           `json:"restored_from,omitempty"`
+	Title          string         `json:"title"`
+	Description    string         `gorm:"type:text" json:"description"`
+	PrimaryTag     string         `json:"primary_tag"`
+	Tags           datatypes.JSON `gorm:"type:jsonb" json:"tags"`
+	IsStarred      bool           `json:"is_starred"`
+	AdditionalInfo datatypes.JSON `gorm:"type:jsonb" json:"additional_info"`
+	Visibility     NoteVisibility `gorm:"type:text" json:"visibility"`
+	EditedBy       string         `gorm:"size:10" json:"edited_by"`
+	EditedAt       time.Time      `json:"edited_at"`
+}
+
+// LessonPlanRevision is a full copy of a lesson plan after one change.
+type LessonPlanRevision struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	LessonPlanID uint           `gorm:"uniqueIndex:idx_lesson_plan_revisions_plan_rev;not null" json:"lesson_plan_id"`
+	Revision     int            `gorm:"uniqueIndex:idx_lesson_plan_revisions_plan_rev;not null" json:"revision"`
+	Action       string         `gorm:"type:text;not null" json:"action"`
+	RestoredFrom *int           `json:"restored_from,omitempty"`
+	Title        string         `json:"title"`
+	Description  datatypes.JSON `gorm:"type:jsonb" json:"description"`
+	StartDate    time.Time      `json:"start_date"`
+	EndDate      time.Time      `json:"end_date"`
+	Result       string         `json:"result"`
+	Active       bool           `json:"active"`
+	EditedBy     string         `gorm:"size:10" json:"edited_by"`
+	EditedAt     time.Time      `json:"edited_at"`
+}
+
+// NoteShare grants one user read access to a note regardless of its
+// visibility.
+type NoteShare struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	NoteID    uint      `gorm:"uniqueIndex:idx_note_shares_note_user;not null" json:"note_id"`
+	UserID    string    `gorm:"uniqueIndex:idx_note_shares_note_user;index;size:10;not null" json:"user_id"`
+	SharedBy  string    `gorm:"size:10" json:"shared_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NoteAttachment is a file attached to a note (board screenshot, worksheet
+// PDF, PGN). StorageKey is the object key in the upload storage; Metadata
+// holds what was read from the file, e.g. image size or PGN headers.
+type NoteAttachment struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	NoteID      uint              `gorm:"index;not null" json:"note_id"`
+	FileName    string            `gorm:"not null" json:"file_name"`
+	ContentType string            `gorm:"not null" json:"content_type"`
+	SizeBytes   int64             `gorm:"not null" json:"size_bytes"`
+	StorageKey  string            `gorm:"not null" json:"-"`
+	Caption     string            `gorm:"type:text" json:"caption"`
+	Metadata    datatypes.JSONMap `gorm:"type:jsonb" json:"metadata"`
+	UploadedBy  string            `gorm:"size:10" json:"uploaded_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// NoteComment is one comment in a note's discussion. ParentID makes it a
+// reply; Replies is filled in when a thread is listed.
+type NoteComment struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	NoteID    uint           `gorm:"index;not null" json:"note_id"`
+	ParentID  *uint          `gorm:"index" json:"parent_id,omitempty"`
+	AuthorID  string         `gorm:"size:10;not null" json:"author_id"`
+	Body      string         `gorm:"type:text;not null" json:"body"`
+	EditedAt  *time.Time     `json:"edited_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	Deleted   bool           `gorm:"-" json:"deleted,omitempty"`
+	Mentions  []NoteMention  `gorm:"foreignKey:CommentID" json:"mentions,omitempty"`
+	Replies   []*NoteComment `gorm:"-" json:"replies,omitempty"`
+}
+
+// NoteMention records that a comment @mentioned a user; it is that user's
+// inbox entry until ReadAt is set.
+type NoteMention struct {
+	CommentID uint       `gorm:"primaryKey" json:"comment_id"`
+	UserID    string     `gorm:"primaryKey;size:10;index" json:"user_id"`
+	NoteID    uint       `gorm:"index;not null" json:"note_id"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Notification delivery channels.
+const (
+	ChannelInApp    = "in_app"
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+	ChannelWhatsApp = "whatsapp"
+)
+
+// Notification is one in-app notification for UserID; Type is the event
+// that produced it and Data carries the ids the client links to.
+type Notification struct {
+	ID        uint              `gorm:"primaryKey" json:"id"`
+	UserID    string            `gorm:"index;size:10;not null" json:"user_id"`
+	Type      string            `gorm:"index;not null" json:"type"`
+	Title     string            `gorm:"not null" json:"title"`
+	Body      string            `gorm:"type:text" json:"body"`
+	Data      datatypes.JSONMap `gorm:"type:jsonb" json:"data"`
+	ActorID   string            `gorm:"size:10" json:"actor_id,omitempty"`
+	EventID   string            `gorm:"size:32" json:"event_id,omitempty"`
+	ReadAt    *time.Time        `gorm:"index" json:"read_at"`
+	CreatedAt time.Time         `gorm:"index" json:"created_at"`
+}
+
+// NotificationDelivery records that an event's notification went to a user
+// on a channel, so an event handled again is not sent twice.
+type NotificationDelivery struct {
+	EventID   string    `gorm:"primaryKey;size:32" json:"event_id"`
+	UserID    string    `gorm:"primaryKey;size:10" json:"user_id"`
+	Channel   string    `gorm:"primaryKey;size:20" json:"channel"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NotificationPreference picks the delivery channels for a user. Channels
+// is the default list; EventChannels overrides it per event type, with an
+// empty list muting that event.
+type NotificationPreference struct {
+	UserID        string            `gorm:"primaryKey;size:10" json:"user_id"`
+	Channels      datatypes.JSON    `gorm:"type:jsonb" json:"channels"`       // JSON array of channel names
+	EventChannels datatypes.JSONMap `gorm:"type:jsonb" json:"event_channels"` // event type -> channel names
+	WebhookURL    string            `json:"webhook_url"`
+	DigestOptOut  bool              `gorm:"not null;default:false" json:"digest_opt_out"` // no weekly digest email
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// MessagingGroup is an academy chat group, e.g. the WhatsApp group for a
+// batch's parents.
+type MessagingGroup struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex;not null" json:"name"`
+	Provider    string    `gorm:"not null;default:'whatsapp'" json:"provider"`
+	InviteLink  string    `json:"invite_link"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedBy   string    `gorm:"size:10" json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MessagingGroupMember records that a user was added to a group.
+type MessagingGroupMember struct {
+	GroupID uint      `gorm:"primaryKey" json:"group_id"`
+	UserID  string    `gorm:"primaryKey;size:10;index" json:"user_id"`
+	User    *User     `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+	AddedBy string    `gorm:"size:10" json:"added_by"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// OutboundMessage is one templated message sent through a messaging
+// provider, updated as the provider reports delivery.
+type OutboundMessage struct {
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	Provider          string         `gorm:"not null" json:"provider"`
+	ProviderMessageID string         `gorm:"index" json:"provider_message_id"`
+	UserID            string         `gorm:"index;size:10" json:"user_id"`
+	ToPhone           string         `json:"to_phone"`
+	Template          string         `gorm:"not null" json:"template"`
+	Params            datatypes.JSON `gorm:"type:jsonb" json:"params"`
+	Status            string         `gorm:"index;not null" json:"status"`
+	Error             string         `json:"error,omitempty"`
+	SentAt            *time.Time     `json:"sent_at"`
+	DeliveredAt       *time.Time     `json:"delivered_at"`
+	ReadAt            *time.Time     `json:"read_at"`
+	CreatedBy         string         `gorm:"size:10" json:"created_by,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+// WebhookEndpoint is an admin-configured URL that receives signed event
+// payloads, e.g. for the CRM or accounting tools.
+type WebhookEndpoint struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"not null" json:"name"`
+	URL       string         `gorm:"not null" json:"url"`
+	Secret    string         `gorm:"not null" json:"-"`                     // HMAC key for the signature header
+	Events    datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"events"` // event types, or ["*"] for all
+	Active    bool           `gorm:"not null;default:true" json:"active"`
+	CreatedBy string         `gorm:"size:10" json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Webhook delivery statuses.
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed" // gave up after the last retry
+)
+
+// WebhookDelivery is one event sent to one endpoint, with its retry state.
+// The payload is stored as sent so a delivery can be replayed verbatim.
+type WebhookDelivery struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	EndpointID     uint           `gorm:"index;uniqueIndex:idx_webhook_deliveries_endpoint_event,where:replay_of IS NULL;not null" json:"endpoint_id"`
+	EventID        string         `gorm:"index;uniqueIndex:idx_webhook_deliveries_endpoint_event,where:replay_of IS NULL;size:32;not null" json:"event_id"`
+	EventType      string         `gorm:"not null" json:"event_type"`
+	Payload        datatypes.JSON `gorm:"type:jsonb" json:"payload"`
+	Status         string         `gorm:"index;not null;default:'pending'" json:"status"`
+	Attempts       int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  *time.Time     `gorm:"index" json:"next_attempt_at"`
+	LastStatusCode int            `json:"last_status_code,omitempty"`
+	LastError      string         `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	ReplayOf       *uint          `json:"replay_of,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// Outbox message statuses.
+const (
+	OutboxPending = "pending"
+	OutboxDone    = "done"
+	OutboxDead    = "dead" // gave up; kept for the dead-letter view
+)
+
+// OutboxMessage is a side effect (publishing an event, deleting a stored
+// file) written in the same transaction as the change that caused it and
+// carried out by the dispatcher after commit.
+type OutboxMessage struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	Kind           string         `gorm:"index;not null" json:"kind"`
+	IdempotencyKey string         `gorm:"uniqueIndex;not null" json:"idempotency_key"`
+	Payload        datatypes.JSON `gorm:"type:jsonb" json:"payload"`
+	Status         string         `gorm:"index;not null;default:'pending'" json:"status"`
+	Attempts       int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  *time.Time     `gorm:"index" json:"next_attempt_at"`
+	LastError      string         `gorm:"type:text" json:"last_error,omitempty"`
+	ProcessedAt    *time.Time     `json:"processed_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// Job run triggers and statuses.
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// JobState is the shared state of one background job, so every replica
+// agrees on when it next runs and whether an admin paused or triggered it.
+type JobState struct {
+	Name           string     `gorm:"primaryKey;size:64" json:"name"`
+	Schedule       string     `gorm:"not null" json:"schedule"`
+	Paused         bool       `gorm:"not null;default:false" json:"paused"`
+	RunRequested   bool       `gorm:"not null;default:false" json:"run_requested"`
+	RunRequestedBy string     `gorm:"size:10" json:"run_requested_by,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at"`
+	LastRunAt      *time.Time `json:"last_run_at"`
+	LastStatus     string     `json:"last_status,omitempty"`
+	LastError      string     `gorm:"type:text" json:"last_error,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// JobRun is one execution of a job, kept as run history.
+type JobRun struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	JobName     string     `gorm:"index;size:64;not null" json:"job_name"`
+	Trigger     string     `gorm:"not null" json:"trigger"`
+	TriggeredBy string     `gorm:"size:10" json:"triggered_by,omitempty"`
+	Status      string     `gorm:"index;not null" json:"status"`
+	Instance    string     `json:"instance"` // host:pid of the replica that ran it
+	StartedAt   time.Time  `gorm:"index" json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	DurationMs  int64      `json:"duration_ms"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+}
+
 type AttendanceClassType string
 
 const (
@@ -121,6 +581,13 @@ type Attendance struct {
 	ClassHighlights string `gorm:"type:text" json:"class_highlights"`
 	Homework        string `gorm:"type:text" json:"homework"`
 
+	// Review by a mentor or admin. RejectedAt stays set after the record
+	// is fixed and verified, so rejections still count in the dashboard.
+	VerifiedAt      *time.Time `json:"verified_at,omitempty"`
+	RejectedAt      *time.Time `json:"rejected_at,omitempty"`
+	RejectionReason string     `gorm:"type:text" json:"rejection_reason,omitempty"`
+	ReviewedBy      string     `gorm:"size:10" json:"reviewed_by,omitempty"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -138,10 +605,22 @@ type Image struct {
 	Filename  string         `gorm:"null" json:"filename"`
 }
 
+// ZipcodeScrapeScope is one scrape job: a (zipcode, distance) search the
+// extension runs. Scrapers claim scopes under a lease so two instances don't
+// scrape the same one.
 type ZipcodeScrapeScope struct {
 	Zipcode     string     `gorm:"primaryKey;size:10" json:"zipcode"`
 	Distance    int        `gorm:"primaryKey" json:"distance"`
 	LastScraped *time.Time `json:"last_scraped"`
+
+	Priority        int        `gorm:"not null;default:0" json:"priority"` // active students served
+	Attempts        int        `gorm:"not null;default:0" json:"attempts"` // since the last success
+	LastError       string     `gorm:"type:text" json:"last_error,omitempty"`
+	LastAttemptAt   *time.Time `json:"last_attempt_at"`
+	NextAttemptAt   *time.Time `json:"next_attempt_at"` // retry backoff
+	LeaseOwner      string     `json:"lease_owner,omitempty"`
+	LeaseExpiresAt  *time.Time `json:"lease_expires_at"`
+	LastResultCount int        `gorm:"not null;default:0" json:"last_result_count"`
 }
 
 func (ZipcodeScrapeScope) TableName() string { return "zipcode_scrape_scopes" }
@@ -156,7 +635,62 @@ type Tournament struct {
 	StartDate   *time.Time `gorm:"type:date" json:"start_date"`
 	Organizer   string     `json:"organizer"`
 	Description string     `gorm:"type:text" json:"description"`
+	Zipcode     string     `gorm:"size:10" json:"zipcode,omitempty"`
+	APIKeyID    *uint      `gorm:"column:api_key_id" json:"api_key_id,omitempty"` // key that last submitted it
+	Latitude    *float64   `gorm:"index:idx_tournaments_lat_lng" json:"latitude"`
+	Longitude   *float64   `gorm:"index:idx_tournaments_lat_lng" json:"longitude"`
+	LastSeenAt  *time.Time `json:"last_seen_at"`
+
+	// Details parsed server-side from the captured event page
+	EndDate            *time.Time     `gorm:"type:date" json:"end_date"`
+	EntryFeeCents      *int           `json:"entry_fee_cents"`
+	TimeControl        string         `json:"time_control,omitempty"`
+	Sections           datatypes.JSON `gorm:"type:jsonb" json:"sections,omitempty"`            // JSON array of section names
+	RatingRequirements datatypes.JSON `gorm:"type:jsonb" json:"rating_requirements,omitempty"` // {"rated_by": [...], "sections": [...]}
+	VenueAddress       string         `json:"venue_address,omitempty"`
+
+	CancelledAt *time.Time `json:"cancelled_at"` // dropped from a scrape before its start date
 	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+const (
+	TournamentCreated    = "created"
+	TournamentUpdated    = "updated"
+	TournamentCancelled  = "cancelled"
+	TournamentReinstated = "reinstated"
+)
+
+// TournamentPage is a raw event page captured by the scraper extension and
+// the server-side parse of it.
+type TournamentPage struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	URLPath       string         `gorm:"column:url_path;index;not null" json:"url_path"`
+	TournamentID  *uint          `gorm:"index" json:"tournament_id"`
+	HTML          string         `gorm:"column:html;type:text" json:"html,omitempty"`
+	ContentHash   string         `gorm:"size:64" json:"content_hash"`
+	ParserVersion string         `json:"parser_version"`
+	Parsed        datatypes.JSON `gorm:"type:jsonb" json:"parsed"`
+	Report        datatypes.JSON `gorm:"type:jsonb" json:"report"`
+	Valid         bool           `gorm:"index" json:"valid"`
+	Applied       bool           `json:"applied"`
+	APIKeyID      *uint          `gorm:"column:api_key_id" json:"api_key_id,omitempty"`
+	CapturedAt    time.Time      `json:"captured_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// TournamentRevision records one detected change to a scraped tournament.
+// Changes maps field name to {"old": ..., "new": ...}.
+type TournamentRevision struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	TournamentID uint           `gorm:"index;not null" json:"tournament_id"`
+	ChangeType   string         `gorm:"type:text;not null" json:"change_type"`
+	DateChanged  bool           `gorm:"not null;default:false" json:"date_changed"`
+	Changes      datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"changes"`
+	Zipcode      string         `gorm:"size:10" json:"zipcode,omitempty"` // scope that observed the change
+	Distance     int            `json:"distance,omitempty"`
+	APIKeyID     *uint          `gorm:"column:api_key_id" json:"api_key_id,omitempty"`
+	DetectedAt   time.Time      `gorm:"index;not null" json:"detected_at"`
 }
 
 type TournamentWithinRadius struct {
@@ -167,13 +701,53 @@ type TournamentWithinRadius struct {
 
 func (TournamentWithinRadius) TableName() string { return "tournaments_within_radius" }
 
+// ZipCentroid is a US ZIP code's approximate center, loaded from the offline
+// GeoNames postal code dataset.
+type ZipCentroid struct {
+	Zipcode   string  `gorm:"primaryKey;size:10" json:"zipcode"`
+	PlaceName string  `gorm:"index:idx_zip_centroids_place" json:"place_name"`
+	State     string  `gorm:"size:2;index:idx_zip_centroids_place" json:"state"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
 type ClassSchedule struct {
 	ID        uint   `gorm:"primaryKey" json:"id"`
 	StudentID string `gorm:"index;size:10;not null" json:"student_id"`
 	Student   User   `gorm:"foreignKey:StudentID;references:ID" json:"student,omitempty"`
-	DayOfWeek int    `gorm:"not null" json:"day_of_week"`          // 0=Sun..6=Sat
-	StartTime string `gorm:"type:text;not null" json:"start_time"` // "HH:MM" in student's timezone
-	Timezone  string `gorm:"type:text;not null" json:"timezone"`   // IANA timezone e.g. "America/New_York"
+	CoachID   string `gorm:"index;size:10" json:"coach_id,omitempty"` // who teaches the class; reminded with the student
+	DayOfWeek int    `gorm:"not null" json:"day_of_week"`             // 0=Sun..6=Sat
+	StartTime string `gorm:"type:text;not null" json:"start_time"`    // "HH:MM" in student's timezone
+	Timezone  string `gorm:"type:text;not null" json:"timezone"`      // IANA timezone e.g. "America/New_York"
+}
+
+// ClassCancellation skips one occurrence of a weekly class. Date is the
+// class's local date in the schedule's timezone.
+type ClassCancellation struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ScheduleID uint      `gorm:"uniqueIndex:idx_class_cancellations_schedule_date;not null" json:"schedule_id"`
+	Date       time.Time `gorm:"type:date;uniqueIndex:idx_class_cancellations_schedule_date;not null" json:"date"`
+	Reason     string    `json:"reason"`
+	CreatedBy  string    `gorm:"size:10" json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Holiday is a day without classes, matched against each class's local date.
+type Holiday struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Date      time.Time `gorm:"type:date;uniqueIndex;not null" json:"date"`
+	Name      string    `gorm:"not null" json:"name"`
+	CreatedBy string    `gorm:"size:10" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ClassReminder records that a reminder for one class occurrence went to
+// one recipient; the primary key is what stops repeats across restarts.
+type ClassReminder struct {
+	ScheduleID  uint      `gorm:"primaryKey" json:"schedule_id"`
+	OccursAt    time.Time `gorm:"primaryKey" json:"occurs_at"`
+	RecipientID string    `gorm:"primaryKey;size:10" json:"recipient_id"`
+	SentAt      time.Time `json:"sent_at"`
 }
 
 type ReferralRelationship struct {
@@ -188,5 +762,140 @@ type ReferralRelationship struct {
 }
 
 func (ReferralRelationship) TableName() string {
-	return "referral_relationships"                        // IANA timezone e.g. "America/New_York"
+	return "referral_relationships" // IANA timezone e.g. "America/New_York"
+}
+
+// ChessAccountSync tracks the incremental sync cursor for one external chess
+// account (lichess or chess.com) of a user.
+type ChessAccountSync struct {
+	UserID       string     `gorm:"primaryKey;size:10" json:"user_id"`
+	Source       string     `gorm:"primaryKey;size:16" json:"source"` // "lichess" | "chesscom"
+	Username     string     `json:"username"`
+	GamesCursor  *time.Time `json:"games_cursor"` // end time of the newest game already stored
+	LastSyncedAt *time.Time `json:"last_synced_at"`
+	LastError    string     `gorm:"type:text" json:"last_error,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (ChessAccountSync) TableName() string { return "chess_account_syncs" }
+
+// RatingSnapshot is one point of a student's rating time series for a
+// source and time control (bullet, blitz, rapid, classical, daily, ...).
+type RatingSnapshot struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      string    `gorm:"index:idx_rating_snapshots_series;size:10;not null" json:"user_id"`
+	Source      string    `gorm:"index:idx_rating_snapshots_series;size:16;not null" json:"source"`
+	TimeControl string    `gorm:"index:idx_rating_snapshots_series;size:16;not null" json:"time_control"`
+	Rating      int       `json:"rating"`
+	Games       int       `json:"games"`
+	RecordedAt  time.Time `gorm:"index" json:"recorded_at"`
+}
+
+// ExternalGame is a game pulled from lichess or chess.com for a student.
+type ExternalGame struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	UserID         string    `gorm:"uniqueIndex:idx_external_games_user_source_game;size:10;not null" json:"user_id"`
+	Source         string    `gorm:"uniqueIndex:idx_external_games_user_source_game;size:16;not null" json:"source"`
+	GameID         string    `gorm:"uniqueIndex:idx_external_games_user_source_game;not null" json:"game_id"`
+	PlayedAt       time.Time `gorm:"index" json:"played_at"`
+	TimeControl    string    `gorm:"size:16" json:"time_control"`
+	Rated          bool      `json:"rated"`
+	Color          string    `gorm:"size:5" json:"color"`  // "white" | "black"
+	Result         string    `gorm:"size:8" json:"result"` // "win" | "loss" | "draw"
+	OpponentName   string    `json:"opponent_name"`
+	OpponentRating int       `json:"opponent_rating"`
+	URL            string    `json:"url"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// OfficialRating is one imported rating-list row for a student: the FIDE or
+// USCF ratings and title as published for a list period (month).
+type OfficialRating struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	UserID         string    `gorm:"uniqueIndex:idx_official_ratings_period;size:10;not null" json:"user_id"`
+	Federation     string    `gorm:"uniqueIndex:idx_official_ratings_period;size:8;not null" json:"federation"` // "fide" | "uscf"
+	Period         time.Time `gorm:"uniqueIndex:idx_official_ratings_period;type:date;not null" json:"period"`
+	ExternalID     string    `gorm:"size:16" json:"external_id"`
+	ListedName     string    `json:"listed_name"`
+	StandardRating *int      `json:"standard_rating"`
+	RapidRating    *int      `json:"rapid_rating"`
+	BlitzRating    *int      `json:"blitz_rating"`
+	Title          string    `gorm:"size:8" json:"title"`
+	SourceFile     string    `json:"source_file"`
+	ImportedAt     time.Time `json:"imported_at"`
+}
+
+// RatingIDMismatch flags a stored FIDE/USCF ID that looks wrong for the
+// student, e.g. the listed name or birth year differs, or the ID is missing
+// from the list. Flags are re-evaluated on every import.
+type RatingIDMismatch struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     string     `gorm:"uniqueIndex:idx_rating_id_mismatch_field;size:10;not null" json:"user_id"`
+	Federation string     `gorm:"uniqueIndex:idx_rating_id_mismatch_field;size:8;not null" json:"federation"`
+	Field      string     `gorm:"uniqueIndex:idx_rating_id_mismatch_field;size:16;not null" json:"field"` // "name" | "birth_year" | "not_listed"
+	ExternalID string     `gorm:"size:16" json:"external_id"`
+	Expected   string     `json:"expected"`
+	Actual     string     `json:"actual"`
+	DetectedAt time.Time  `json:"detected_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+type TournamentRegistrationStatus string
+
+const (
+	TournamentRegistrationPlanning   TournamentRegistrationStatus = "planning"
+	TournamentRegistrationRegistered TournamentRegistrationStatus = "registered"
+	TournamentRegistrationWithdrawn  TournamentRegistrationStatus = "withdrawn"
+)
+
+// TournamentRegistration records a student's intent to play a scraped
+// tournament and, afterwards, their result.
+type TournamentRegistration struct {
+	ID           uint                         `gorm:"primaryKey" json:"id"`
+	TournamentID uint                         `gorm:"uniqueIndex:idx_tournament_registrations_student;not null" json:"tournament_id"`
+	Tournament   *Tournament                  `gorm:"foreignKey:TournamentID" json:"tournament,omitempty"`
+	StudentID    string                       `gorm:"uniqueIndex:idx_tournament_registrations_student;index;size:10;not null" json:"student_id"`
+	Student      *User                        `gorm:"foreignKey:StudentID;references:ID" json:"student,omitempty"`
+	Status       TournamentRegistrationStatus `gorm:"type:text;not null" json:"status"`
+	Section      string                       `json:"section"`
+
+	// Result, filled in after the event
+	Score            *float64   `json:"score"`
+	Place            *int       `json:"place"`
+	RatingChange     *int       `json:"rating_change"`
+	ResultNotes      string     `gorm:"type:text" json:"result_notes"`
+	ResultRecordedBy string     `gorm:"size:10" json:"result_recorded_by,omitempty"`
+	ResultRecordedAt *time.Time `json:"result_recorded_at"`
+
+	CreatedBy string    `gorm:"size:10" json:"created_by"`
+	UpdatedBy string    `gorm:"size:10" json:"updated_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// APIKey is a credential for machine clients such as the scraper extension.
+// Only a hash of the secret is stored; Prefix is shown to admins and used to
+// find the row.
+type APIKey struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	Name       string         `gorm:"not null" json:"name"`
+	Prefix     string         `gorm:"uniqueIndex;size:32;not null" json:"prefix"`
+	KeyHash    string         `gorm:"size:64;not null" json:"-"`
+	Scopes     datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"scopes"` // JSON array, e.g. ["scraper:read"]
+	CreatedBy  string         `gorm:"size:10" json:"created_by"`
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	RevokedAt  *time.Time     `json:"revoked_at"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	LastUsedIP string         `json:"last_used_ip,omitempty"`
+	RotatedAt  *time.Time     `json:"rotated_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+
+	// The secret replaced by the last rotation stays valid until
+	// PreviousExpiresAt so clients can be switched over.
+	PreviousPrefix    string     `gorm:"index;size:32" json:"previous_prefix,omitempty"`
+	PreviousKeyHash   string     `gorm:"size:64" json:"-"`
+	PreviousExpiresAt *time.Time `json:"previous_expires_at,omitempty"`
+}
+
+func (APIKey) TableName() string { return "api_keys" }
@@ -8,18 +8,20 @@ type APIResponse struct {
 }
 
 type PersonInfo struct {
-	Name  string `json:"name"`
+	Name              string `json:"name"`
 	ProfilePictureURL string `json:"profile_picture_url,omitempty"`
-	FIDEID	 string `json:"fide_id,omitempty"`
-	Bio		string `json:"bio"`
-	PersonalMeetLink string `json:"personal_meet_link,omitempty"`
+	FIDEID            string `json:"fide_id,omitempty"`
+	Bio               string `json:"bio"`
+	PersonalMeetLink  string `json:"personal_meet_link,omitempty"`
 }
 
 type UserResponse struct {
 	*User
-	Mentor   *PersonInfo      `json:"mentor,omitempty"`
-	Coach    *PersonInfo      `json:"coach,omitempty"`
-	Schedule []*ClassSchedule `json:"schedule,omitempty"`
+	Mentor          *PersonInfo        `json:"mentor,omitempty"`
+	Coach           *PersonInfo        `json:"coach,omitempty"`
+	Schedule        []*ClassSchedule   `json:"schedule,omitempty"`
+	OfficialRatings []OfficialRating   `json:"official_ratings,omitempty"` // latest FIDE/USCF list row per federation
+	RatingIDFlags   []RatingIDMismatch `json:"rating_id_flags,omitempty"`
 }
 
 type Role string
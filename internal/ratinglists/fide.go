@@ -0,0 +1,289 @@
+package ratinglists
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// FIDE publishes one combined list and one file per rating type. The
+// single-type files carry one rating column named after the month, so the
+// list type has to come from the caller.
+const (
+	ListCombined = ""
+	ListStandard = "standard"
+	ListRapid    = "rapid"
+	ListBlitz    = "blitz"
+)
+
+// fideMonthColumn matches the rating column of the single-list TXT files,
+// which is named after the list month, e.g. "OCT25".
+var fideMonthColumn = regexp.MustCompile(`^[A-Z]{3}\d{2}$`)
+
+// FIDEListFromFileName guesses the list type from a FIDE download name:
+// standard_rating_list.txt, rapid_oct25frl.txt, blitz_oct25frl_xml.xml and
+// so on. Anything else, e.g. players_list_foa.txt, is the combined list.
+func FIDEListFromFileName(name string) string {
+	base := strings.ToLower(filepath.Base(name))
+	for _, l := range []string{ListStandard, ListRapid, ListBlitz} {
+		if strings.HasPrefix(base, l+"_") {
+			return l
+		}
+	}
+	return ListCombined
+}
+
+// ParseFIDEList validates a list type given on the command line.
+func ParseFIDEList(v string) (string, error) {
+	switch l := strings.ToLower(strings.TrimSpace(v)); l {
+	case ListCombined, ListStandard, ListRapid, ListBlitz:
+		return l, nil
+	}
+	return "", fmt.Errorf("list must be standard, rapid or blitz, got %q", v)
+}
+
+// ParseFIDETXT parses a FIDE fixed-width TXT list (players_list_foa.txt or
+// one of the standard/rapid/blitz lists). Column boundaries are taken from
+// the header line, since FIDE has widened columns over the years. list is
+// ListCombined for players_list_foa.txt; a single-type file must name its
+// type, and only that rating is set on the parsed players.
+func ParseFIDETXT(r io.Reader, list string, fn HandlerFunc) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("empty FIDE list")
+	}
+	cols := fixedColumns(sc.Text())
+	if _, ok := cols["id number"]; !ok {
+		return fmt.Errorf("not a FIDE TXT list: header %q", sc.Text())
+	}
+	// The single-type lists name the rating column after the month.
+	monthCol := ""
+	for name := range cols {
+		if fideMonthColumn.MatchString(strings.ToUpper(name)) {
+			monthCol = name
+		}
+	}
+	if monthCol != "" && list == ListCombined {
+		return fmt.Errorf("single-type FIDE list (rating column %q): the list type (standard, rapid or blitz) is required", strings.ToUpper(monthCol))
+	}
+
+	line := 1
+	for sc.Scan() {
+		line++
+		row := sc.Text()
+		if strings.TrimSpace(row) == "" {
+			continue
+		}
+		get := func(name string) string { return cols.cell(row, name) }
+		p := Player{
+			Federation: FederationFIDE,
+			ID:         get("id number"),
+			Name:       get("name"),
+			Country:    get("fed"),
+			Sex:        get("sex"),
+			Title:      bestTitle(get("tit"), get("wtit")),
+			Standard:   parseRating(get("srtng")),
+			Rapid:      parseRating(get("rrtng")),
+			Blitz:      parseRating(get("brtng")),
+			BirthYear:  parseYear(get("b-day")),
+			Flag:       get("flag"),
+		}
+		if monthCol != "" {
+			p.Standard, p.Rapid, p.Blitz = nil, nil, nil
+			rating := parseRating(get(monthCol))
+			switch list {
+			case ListStandard:
+				p.Standard = rating
+			case ListRapid:
+				p.Rapid = rating
+			case ListBlitz:
+				p.Blitz = rating
+			}
+		}
+		if p.ID == "" {
+			continue
+		}
+		if err := fn(p); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return sc.Err()
+}
+
+type fixedCol struct{ start, end int } // end == -1 means to end of line
+
+type columnSet map[string]fixedCol
+
+func (cs columnSet) cell(row, name string) string {
+	c, ok := cs[name]
+	if !ok || c.start >= len(row) {
+		return ""
+	}
+	end := c.end
+	if end == -1 || end > len(row) {
+		end = len(row)
+	}
+	return strings.TrimSpace(row[c.start:end])
+}
+
+// fixedColumns derives column spans from a header line. Each column starts at
+// its label and runs to the start of the next label. "ID Number" is the only
+// label with an embedded space.
+func fixedColumns(header string) columnSet {
+	header = strings.Replace(header, "ID Number", "ID_Number", 1)
+	type label struct {
+		name  string
+		start int
+	}
+	var labels []label
+	for i := 0; i < len(header); {
+		if header[i] == ' ' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(header) && header[j] != ' ' {
+			j++
+		}
+		name := strings.ToLower(strings.Replace(header[i:j], "_", " ", 1))
+		labels = append(labels, label{name: name, start: i})
+		i = j
+	}
+	cols := columnSet{}
+	for k, l := range labels {
+		end := -1
+		if k+1 < len(labels) {
+			end = labels[k+1].start
+		}
+		cols[l.name] = fixedCol{start: l.start, end: end}
+	}
+	// Numeric columns are right-aligned under their label, so widen each one
+	// leftwards up to the end of the previous label text.
+	for k := 1; k < len(labels); k++ {
+		prev := labels[k-1]
+		prevEnd := prev.start + len(prev.name)
+		c := cols[labels[k].name]
+		if isNumericFIDEColumn(labels[k].name) && prevEnd < c.start {
+			c.start = prevEnd
+			cols[labels[k].name] = c
+			p := cols[prev.name]
+			p.end = prevEnd
+			cols[prev.name] = p
+		}
+	}
+	return cols
+}
+
+func isNumericFIDEColumn(name string) bool {
+	switch name {
+	case "srtng", "sgm", "sk", "rrtng", "rgm", "rk", "brtng", "bgm", "bk", "gms", "k":
+		return true
+	}
+	return fideMonthColumn.MatchString(strings.ToUpper(name))
+}
+
+type fideXMLPlayer struct {
+	FIDEID      string `xml:"fideid"`
+	Name        string `xml:"name"`
+	Country     string `xml:"country"`
+	Sex         string `xml:"sex"`
+	Title       string `xml:"title"`
+	WTitle      string `xml:"w_title"`
+	Rating      string `xml:"rating"`
+	RapidRating string `xml:"rapid_rating"`
+	BlitzRating string `xml:"blitz_rating"`
+	Birthday    string `xml:"birthday"`
+	Flag        string `xml:"flag"`
+}
+
+// fideCharsetReader decodes the ISO-8859-1 declared by FIDE's XML files.
+func fideCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1":
+		return &latin1Reader{r: input}, nil
+	}
+	return nil, fmt.Errorf("unsupported XML encoding %q", charset)
+}
+
+// latin1Reader streams Latin-1 as UTF-8: each byte is the code point of the
+// same value, and those above 0x7f take two bytes.
+type latin1Reader struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	if len(p) < 2 {
+		return 0, io.ErrShortBuffer
+	}
+	if cap(l.buf) < len(p)/2 {
+		l.buf = make([]byte, len(p)/2)
+	}
+	n, err := l.r.Read(l.buf[:len(p)/2])
+	out := 0
+	for _, c := range l.buf[:n] {
+		out += utf8.EncodeRune(p[out:], rune(c))
+	}
+	return out, err
+}
+
+// ParseFIDEXML streams a FIDE XML list (players_list_xml_foa.xml) without
+// loading the whole document. The single-type XML lists put their rating in
+// <rating> too, so list says which type it is, as for ParseFIDETXT.
+func ParseFIDEXML(r io.Reader, list string, fn HandlerFunc) error {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = fideCharsetReader
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "player" {
+			continue
+		}
+		var xp fideXMLPlayer
+		if err := dec.DecodeElement(&xp, &se); err != nil {
+			return err
+		}
+		p := Player{
+			Federation: FederationFIDE,
+			ID:         strings.TrimSpace(xp.FIDEID),
+			Name:       strings.TrimSpace(xp.Name),
+			Country:    strings.TrimSpace(xp.Country),
+			Sex:        strings.TrimSpace(xp.Sex),
+			Title:      bestTitle(xp.Title, xp.WTitle),
+			Standard:   parseRating(xp.Rating),
+			Rapid:      parseRating(xp.RapidRating),
+			Blitz:      parseRating(xp.BlitzRating),
+			BirthYear:  parseYear(xp.Birthday),
+			Flag:       strings.TrimSpace(xp.Flag),
+		}
+		switch list {
+		case ListRapid:
+			p.Standard, p.Rapid, p.Blitz = nil, p.Standard, nil
+		case ListBlitz:
+			p.Standard, p.Rapid, p.Blitz = nil, nil, p.Standard
+		case ListStandard:
+			p.Rapid, p.Blitz = nil, nil
+		}
+		if p.ID == "" {
+			continue
+		}
+		if err := fn(p); err != nil {
+			return fmt.Errorf("player %s: %w", p.ID, err)
+		}
+	}
+}
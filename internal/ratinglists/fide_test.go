@@ -0,0 +1,141 @@
+package ratinglists
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func intp(n int) *int { return &n }
+
+// parseFile runs parse over testdata/name and collects the players.
+func parseFile(t *testing.T, name string, parse func(*os.File, HandlerFunc) error) []Player {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var out []Player
+	if err := parse(f, func(p Player) error {
+		out = append(out, p)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+var (
+	carlsen = Player{
+		Federation: FederationFIDE, ID: "1503014", Name: "Carlsen, Magnus", Country: "NOR", Sex: "M",
+		Title: "GM", Standard: intp(2831), Rapid: intp(2818), Blitz: intp(2886), BirthYear: 1990,
+	}
+	doe = Player{
+		Federation: FederationFIDE, ID: "25059530", Name: "Doe, Jane", Country: "USA", Sex: "F",
+		Title: "WFM", Standard: intp(1987), Blitz: intp(1850), BirthYear: 2008, Flag: "w",
+	}
+)
+
+// only keeps one rating of p, as the single-type lists report it.
+func only(p Player, list string) Player {
+	std, rapid, blitz := p.Standard, p.Rapid, p.Blitz
+	p.Standard, p.Rapid, p.Blitz = nil, nil, nil
+	switch list {
+	case ListStandard:
+		p.Standard = std
+	case ListRapid:
+		p.Rapid = rapid
+	case ListBlitz:
+		p.Blitz = blitz
+	}
+	return p
+}
+
+func TestParseFIDETXT(t *testing.T) {
+	tests := []struct {
+		file string
+		want []Player
+	}{
+		{"players_list_foa.txt", []Player{
+			carlsen, doe,
+			{Federation: FederationFIDE, ID: "30999999", Name: "Newcomer, Sam", Country: "IND", Sex: "M", Flag: "i"},
+		}},
+		{"standard_oct25frl.txt", []Player{only(carlsen, ListStandard)}},
+		{"rapid_oct25frl.txt", []Player{only(carlsen, ListRapid)}},
+		{"blitz_oct25frl.txt", []Player{only(doe, ListBlitz)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			list := FIDEListFromFileName(tc.file)
+			got := parseFile(t, tc.file, func(f *os.File, fn HandlerFunc) error { return ParseFIDETXT(f, list, fn) })
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseFIDETXTRequiresListType(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "rapid_oct25frl.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	err = ParseFIDETXT(f, ListCombined, func(Player) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "OCT25") {
+		t.Errorf("err = %v, want the single-type list refused", err)
+	}
+
+	err = ParseFIDETXT(strings.NewReader("Mem_ID\tMem_Name\n"), ListCombined, func(Player) error { return nil })
+	if err == nil {
+		t.Error("non-FIDE header accepted")
+	}
+}
+
+func TestFixedColumns(t *testing.T) {
+	header := "ID Number      Name      Fed  SRtng SGm B-day Flag"
+	row := "1503014        Carlsen   NOR   2831   9 1990  w"
+	cols := fixedColumns(header)
+	for name, want := range map[string]string{
+		"id number": "1503014", "name": "Carlsen", "fed": "NOR",
+		"srtng": "2831", "sgm": "9", "b-day": "1990", "flag": "w",
+	} {
+		if got := cols.cell(row, name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	// Short rows leave trailing columns empty.
+	if got := cols.cell("1503014", "flag"); got != "" {
+		t.Errorf("flag of a short row = %q", got)
+	}
+}
+
+func TestParseFIDEXML(t *testing.T) {
+	// The combined fixture is Latin-1 encoded, as FIDE publishes it.
+	jose := doe
+	jose.Name = "Doe, José"
+	tests := []struct {
+		file string
+		list string
+		want []Player
+	}{
+		{"players_list_xml_foa.xml", ListCombined, []Player{carlsen, jose}},
+		{"players_list_xml_foa.xml", ListStandard, []Player{only(carlsen, ListStandard), only(jose, ListStandard)}},
+		// Single-type XML lists carry their rating in <rating>.
+		{"blitz_oct25frl_xml.xml", ListBlitz, []Player{only(doe, ListBlitz)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.file+"/"+tc.list, func(t *testing.T) {
+			if l := FIDEListFromFileName(tc.file); tc.list != ListStandard && l != tc.list {
+				t.Errorf("FIDEListFromFileName = %q, want %q", l, tc.list)
+			}
+			got := parseFile(t, tc.file, func(f *os.File, fn HandlerFunc) error { return ParseFIDEXML(f, tc.list, fn) })
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tc.want)
+			}
+		})
+	}
+}
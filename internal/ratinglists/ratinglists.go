@@ -0,0 +1,106 @@
+// Package ratinglists parses the rating list files published by FIDE and
+// US Chess so they can be imported from local disk.
+package ratinglists
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	FederationFIDE = "fide"
+	FederationUSCF = "uscf"
+)
+
+// Player is one row of a rating list, normalized across formats.
+// Ratings are nil when the player has no rating of that type.
+type Player struct {
+	Federation string
+	ID         string
+	Name       string
+	Country    string // FIDE federation code or USCF state
+	Sex        string
+	Title      string // highest of open/women's title, e.g. "GM", "WFM"
+	Standard   *int
+	Rapid      *int
+	Blitz      *int
+	BirthYear  int // 0 when unknown
+	Flag       string
+	FIDEID     string // USCF supplements carry a cross-reference
+}
+
+// HandlerFunc receives each parsed player. Returning an error stops parsing.
+type HandlerFunc func(Player) error
+
+// parseRating reads the leading digits of a rating cell. USCF cells can carry
+// provisional suffixes ("1432P12", "1432/12"); empty or zero means unrated.
+func parseRating(s string) *int {
+	s = strings.TrimSpace(s)
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return nil
+	}
+	n, err := strconv.Atoi(s[:end])
+	if err != nil || n == 0 {
+		return nil
+	}
+	return &n
+}
+
+func parseYear(s string) int {
+	s = strings.TrimSpace(s)
+	if len(s) >= 4 {
+		s = s[:4]
+	}
+	y, err := strconv.Atoi(s)
+	if err != nil || y < 1900 {
+		return 0
+	}
+	return y
+}
+
+// bestTitle picks the title to record from FIDE's open/women's title columns.
+func bestTitle(open, women string) string {
+	if t := strings.TrimSpace(open); t != "" {
+		return strings.ToUpper(t)
+	}
+	return strings.ToUpper(strings.TrimSpace(women))
+}
+
+// NameTokens lowercases a name, drops punctuation and splits it into words.
+// "Carlsen, Magnus" and "Magnus CARLSEN" yield the same token set.
+func NameTokens(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NamesMatch reports whether a listed name plausibly belongs to the user with
+// the given first and last name: every last-name word must appear, and the
+// first name must appear in full or as an initial.
+func NamesMatch(listed, firstName, lastName string) bool {
+	have := map[string]bool{}
+	initials := map[byte]bool{}
+	for _, t := range NameTokens(listed) {
+		have[t] = true
+		initials[t[0]] = true
+	}
+	last := NameTokens(lastName)
+	if len(last) == 0 {
+		return false
+	}
+	for _, t := range last {
+		if !have[t] {
+			return false
+		}
+	}
+	first := NameTokens(firstName)
+	if len(first) == 0 {
+		return true
+	}
+	return have[first[0]] || initials[first[0][0]]
+}
@@ -0,0 +1,123 @@
+package ratinglists
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// uscfAliases maps the column names seen in US Chess supplement exports to
+// our fields. Matching is case-insensitive.
+var uscfAliases = map[string]string{
+	"id":             "id",
+	"uscf id":        "id",
+	"uscf_id":        "id",
+	"mem_id":         "id",
+	"member id":      "id",
+	"name":           "name",
+	"mem_name":       "name",
+	"state":          "state",
+	"st":             "state",
+	"mem_state":      "state",
+	"regular":        "regular",
+	"regular rating": "regular",
+	"reg rating":     "regular",
+	"r_rating":       "regular",
+	"rating":         "regular",
+	"quick":          "quick",
+	"quick rating":   "quick",
+	"q_rating":       "quick",
+	"blitz":          "blitz",
+	"blitz rating":   "blitz",
+	"b_rating":       "blitz",
+	"fide id":        "fide_id",
+	"fide_id":        "fide_id",
+	"title":          "title",
+	"birth year":     "birth_year",
+	"birth_year":     "birth_year",
+}
+
+// ParseUSCFSupplement parses a delimited US Chess rating supplement with a
+// header row. The delimiter (tab, comma, or pipe) is sniffed from the header.
+// US Chess quick ratings are recorded as rapid.
+func ParseUSCFSupplement(r io.Reader, fn HandlerFunc) error {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+	first := string(head)
+	if i := strings.IndexByte(first, '\n'); i >= 0 {
+		first = first[:i]
+	}
+	delim := ','
+	switch {
+	case strings.Contains(first, "\t"):
+		delim = '\t'
+	case strings.Contains(first, "|"):
+		delim = '|'
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	// Tabs count as leading space, which would fold empty cells of a
+	// tab-separated file into the next column.
+	cr.TrimLeadingSpace = delim != '\t'
+
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+	idx := map[string]int{}
+	for i, h := range header {
+		if f, ok := uscfAliases[strings.ToLower(strings.TrimSpace(h))]; ok {
+			if _, dup := idx[f]; !dup {
+				idx[f] = i
+			}
+		}
+	}
+	if _, ok := idx["id"]; !ok {
+		return fmt.Errorf("not a USCF supplement: no member id column in %v", header)
+	}
+
+	line := 1
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		line++
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		get := func(f string) string {
+			i, ok := idx[f]
+			if !ok || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		p := Player{
+			Federation: FederationUSCF,
+			ID:         get("id"),
+			Name:       get("name"),
+			Country:    get("state"),
+			Title:      strings.ToUpper(get("title")),
+			Standard:   parseRating(get("regular")),
+			Rapid:      parseRating(get("quick")),
+			Blitz:      parseRating(get("blitz")),
+			BirthYear:  parseYear(get("birth_year")),
+			FIDEID:     get("fide_id"),
+		}
+		if p.ID == "" {
+			continue
+		}
+		if err := fn(p); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package ratinglists
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseUSCFSupplement(t *testing.T) {
+	tests := []struct {
+		file string
+		want []Player
+	}{
+		{"uscf_supplement.tsv", []Player{
+			{
+				Federation: FederationUSCF, ID: "12345678", Name: "DOE, JANE", Country: "NJ",
+				Standard: intp(1987), Rapid: intp(1432), BirthYear: 2008, FIDEID: "25059530",
+			},
+			// 0 is unrated; provisional ratings keep their leading digits.
+			{Federation: FederationUSCF, ID: "23456789", Name: "ROE, RICHARD", Country: "TX", Rapid: intp(1200), Blitz: intp(1100)},
+		}},
+		{"uscf_supplement_pipe.txt", []Player{
+			{Federation: FederationUSCF, ID: "12345678", Name: "Doe, Jane", Country: "NJ", Title: "WFM", Standard: intp(1987), Rapid: intp(1432)},
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			got := parseFile(t, tc.file, func(f *os.File, fn HandlerFunc) error { return ParseUSCFSupplement(f, fn) })
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tc.want)
+			}
+		})
+	}
+
+	var got []Player
+	err := ParseUSCFSupplement(strings.NewReader("ID,Name,Regular\n12345678,\"Doe, Jane\",1987\n"), func(p Player) error {
+		got = append(got, p)
+		return nil
+	})
+	if err != nil || len(got) != 1 || got[0].Name != "Doe, Jane" || *got[0].Standard != 1987 {
+		t.Errorf("comma list: %+v, %v", got, err)
+	}
+
+	if err := ParseUSCFSupplement(strings.NewReader("Name,Rating\nDoe,1987\n"), func(Player) error { return nil }); err == nil {
+		t.Error("list without a member id column accepted")
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -8,16 +9,29 @@ import (
 	"github.com/go-chi/cors"
 	v1 "github.com/madhava-poojari/dashboard-api/internal/api/v1"
 	"github.com/madhava-poojari/dashboard-api/internal/config"
+	"github.com/madhava-poojari/dashboard-api/internal/service"
 	"github.com/madhava-poojari/dashboard-api/internal/store"
 )
 
 type Server struct {
-	cfg *config.Config
-	db  *store.Store
+	cfg  *config.Config
+	db   *store.Store
+	svcs *service.Services
 }
 
 func NewServer(cfg *config.Config, pool *store.Store) *Server {
-	return &Server{cfg: cfg, db: pool}
+	return &Server{cfg: cfg, db: pool, svcs: service.NewServices(cfg, pool)}
+}
+
+// StartWorkers runs the background workers until ctx is cancelled.
+func (s *Server) StartWorkers(ctx context.Context) error {
+	return s.svcs.StartWorkers(ctx, s.cfg)
+}
+
+// WaitWorkers waits for running jobs to finish after the workers' context
+// is cancelled, giving up when ctx is done.
+func (s *Server) WaitWorkers(ctx context.Context) error {
+	return s.svcs.Wait(ctx)
 }
 
 func (s *Server) NewHTTPServer() *http.Server {
@@ -38,7 +52,7 @@ func (s *Server) NewHTTPServer() *http.Server {
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
-	api := v1.NewAPI(s.cfg, s.db)
+	api := v1.NewAPI(s.cfg, s.db, s.svcs)
 	r.Mount("/api/v1", api.Routes())
 
 	srv := &http.Server{
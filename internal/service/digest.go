@@ -0,0 +1,544 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+const (
+	digestTournamentDays  = 30 // tournaments starting within this many days are listed
+	digestTournamentLimit = 5  // per student
+)
+
+// Digest is one recipient's weekly summary. Students are the children (or,
+// for a student, the student themselves) the recipient follows; Coaches is
+// filled for mentors.
+type Digest struct {
+	Recipient DigestUser      `json:"recipient"`
+	From      time.Time       `json:"from"` // the week covered is [From, To)
+	To        time.Time       `json:"to"`
+	Students  []StudentDigest `json:"students"`
+	Coaches   []CoachDigest   `json:"coaches,omitempty"`
+}
+
+type DigestUser struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// StudentDigest is a student's week: classes held, new notes, classes in
+// the coming week and nearby tournaments.
+type StudentDigest struct {
+	StudentID   string             `json:"student_id"`
+	Name        string             `json:"name"`
+	Classes     []DigestClass      `json:"classes"`
+	Notes       []DigestNote       `json:"notes"`
+	Upcoming    []time.Time        `json:"upcoming"` // in the schedule's timezone
+	Tournaments []DigestTournament `json:"tournaments"`
+}
+
+type DigestClass struct {
+	Date       time.Time `json:"date"`
+	Coach      string    `json:"coach"`
+	ClassType  string    `json:"class_type"`
+	Highlights string    `json:"highlights,omitempty"`
+	Homework   string    `json:"homework,omitempty"`
+}
+
+type DigestNote struct {
+	ID         uint      `json:"id"`
+	Title      string    `json:"title"`
+	PrimaryTag string    `json:"primary_tag,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type DigestTournament struct {
+	ID        uint       `json:"id"`
+	Title     string     `json:"title"`
+	City      string     `json:"city"`
+	State     string     `json:"state"`
+	Dates     string     `json:"dates"`
+	StartDate *time.Time `json:"start_date"`
+	Distance  int        `json:"distance"` // distance bucket in miles
+}
+
+// CoachDigest summarises a coach's week for their mentor.
+type CoachDigest struct {
+	CoachID    string                `json:"coach_id"`
+	Name       string                `json:"name"`
+	Classes    int                   `json:"classes"`
+	Unverified int                   `json:"unverified"`
+	Students   []CoachStudentSummary `json:"students"`
+}
+
+type CoachStudentSummary struct {
+	StudentID    string `json:"student_id"`
+	Name         string `json:"name"`
+	Classes      int    `json:"classes"`
+	Notes        int    `json:"notes"`
+	Upcoming     int    `json:"upcoming"`
+	LastHomework string `json:"last_homework,omitempty"`
+}
+
+// Empty reports whether there is nothing to send.
+func (d *Digest) Empty() bool {
+	if len(d.Coaches) > 0 {
+		return false
+	}
+	for _, sd := range d.Students {
+		if len(sd.Classes)+len(sd.Notes)+len(sd.Upcoming)+len(sd.Tournaments) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// DigestService builds and emails the weekly digest.
+type DigestService struct {
+	store  *store.Store
+	mailer *SMTPSender // nil when SMTP isn't configured; digests are then only previewed
+	loc    *time.Location
+	now    func() time.Time
+}
+
+func NewDigestService(s *store.Store, mailer *SMTPSender, loc *time.Location) *DigestService {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &DigestService{store: s, mailer: mailer, loc: loc, now: time.Now}
+}
+
+// SendWeekly emails every recipient their digest for the week ending
+// today. Recipients with nothing to report are skipped; one failure
+// doesn't stop the rest.
+func (ds *DigestService) SendWeekly(ctx context.Context) error {
+	if ds.mailer == nil {
+		log.Printf("[digest] SMTP is not configured; skipping")
+		return nil
+	}
+	users, err := ds.store.ListDigestRecipients(ctx)
+	if err != nil {
+		return err
+	}
+	now := ds.now()
+	sent, failed := 0, 0
+	for _, u := range users {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		d, err := ds.Build(ctx, u, now)
+		if err == nil && d.Empty() {
+			continue
+		}
+		if err == nil {
+			err = ds.send(ctx, d)
+		}
+		if err != nil {
+			failed++
+			log.Printf("[digest] %s: %v", u.ID, err)
+			continue
+		}
+		sent++
+	}
+	log.Printf("[digest] sent %d digests, %d failed", sent, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d digests failed", failed, sent+failed)
+	}
+	return nil
+}
+
+func (ds *DigestService) send(ctx context.Context, d *Digest) error {
+	text, err := RenderDigestText(d)
+	if err != nil {
+		return err
+	}
+	html, err := RenderDigestHTML(d)
+	if err != nil {
+		return err
+	}
+	return ds.mailer.SendEmail(ctx, d.Recipient.Email, DigestSubject(d), text, html)
+}
+
+// Build gathers u's digest for the week ending on now's date. Students get
+// their own week; other users get the weeks of the students in their
+// family, and mentors also get a summary per coach.
+func (ds *DigestService) Build(ctx context.Context, u *models.User, now time.Time) (*Digest, error) {
+	local := now.In(ds.loc)
+	to := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, ds.loc)
+	from := to.AddDate(0, 0, -7)
+	d := &Digest{
+		Recipient: DigestUser{ID: u.ID, Name: displayName(u), Email: u.Email},
+		From:      from,
+		To:        to,
+		Students:  []StudentDigest{},
+	}
+
+	var students []*models.User
+	if u.Role == models.RoleStudent {
+		students = append(students, u)
+	} else {
+		family, err := ds.store.FamilyMembers(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range family {
+			if m.Role == models.RoleStudent {
+				students = append(students, m)
+			}
+		}
+		sort.Slice(students, func(i, j int) bool { return displayName(students[i]) < displayName(students[j]) })
+	}
+	for _, st := range students {
+		sd, err := ds.studentDigest(ctx, u, st, from, to, now)
+		if err != nil {
+			return nil, fmt.Errorf("student %s: %w", st.ID, err)
+		}
+		d.Students = append(d.Students, *sd)
+	}
+
+	if u.Role == models.RoleMentor {
+		coaches, err := ds.coachDigests(ctx, u, from, to, now)
+		if err != nil {
+			return nil, err
+		}
+		d.Coaches = coaches
+	}
+	return d, nil
+}
+
+func (ds *DigestService) studentDigest(ctx context.Context, viewer, st *models.User, from, to, now time.Time) (*StudentDigest, error) {
+	sd := &StudentDigest{
+		StudentID:   st.ID,
+		Name:        displayName(st),
+		Classes:     []DigestClass{},
+		Notes:       []DigestNote{},
+		Upcoming:    []time.Time{},
+		Tournaments: []DigestTournament{},
+	}
+
+	atts, err := ds.weekAttendances(ctx, store.AttendanceListFilter{StudentID: &st.ID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(atts) - 1; i >= 0; i-- { // oldest first
+		a := atts[i]
+		sd.Classes = append(sd.Classes, DigestClass{
+			Date:       a.Date,
+			Coach:      displayName(&a.Coach),
+			ClassType:  string(a.ClassType),
+			Highlights: strings.TrimSpace(a.ClassHighlights),
+			Homework:   strings.TrimSpace(a.Homework),
+		})
+	}
+
+	notes, err := ds.store.ListNotesCreatedBetween(ctx, viewer, []string{st.ID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		sd.Notes = append(sd.Notes, DigestNote{ID: n.ID, Title: n.Title, PrimaryTag: n.PrimaryTag, CreatedAt: n.CreatedAt})
+	}
+
+	upcoming, err := ds.upcomingClasses(ctx, []string{st.ID}, now)
+	if err != nil {
+		return nil, err
+	}
+	sd.Upcoming = append(sd.Upcoming, upcoming[st.ID]...)
+
+	groups, err := ds.store.GetTournamentsByUserID(ctx, st.ID)
+	if err != nil && !store.IsNotFound(err) {
+		return nil, err
+	}
+	sd.Tournaments = nearbyTournaments(groups, now)
+	return sd, nil
+}
+
+// coachDigests summarises the week of each coach under mentor, the mentor
+// included when they teach students of their own.
+func (ds *DigestService) coachDigests(ctx context.Context, mentor *models.User, from, to, now time.Time) ([]CoachDigest, error) {
+	rels, err := ds.store.ListRelationsForMentor(ctx, mentor.ID)
+	if err != nil || len(rels) == 0 {
+		return nil, err
+	}
+	students, err := ds.store.ListStudentsForCoachOrMentor(ctx, mentor.ID)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]*models.User{}
+	ids := make([]string, 0, len(students))
+	for _, st := range students {
+		byID[st.ID] = st
+		ids = append(ids, st.ID)
+	}
+
+	atts, err := ds.weekAttendances(ctx, store.AttendanceListFilter{MentorID: &mentor.ID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := ds.store.ListNotesCreatedBetween(ctx, mentor, ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+	upcoming, err := ds.upcomingClasses(ctx, ids, now)
+	if err != nil {
+		return nil, err
+	}
+	noteCount := map[string]int{}
+	for _, n := range notes {
+		noteCount[n.UserID]++
+	}
+
+	var out []CoachDigest
+	index := map[string]int{}
+	for _, r := range rels {
+		st, ok := byID[r.UserID]
+		if !ok {
+			continue // inactive student
+		}
+		i, ok := index[r.CoachID]
+		if !ok {
+			coach, err := ds.store.GetUserByID(ctx, r.CoachID)
+			if err != nil {
+				if store.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			i = len(out)
+			index[r.CoachID] = i
+			out = append(out, CoachDigest{CoachID: coach.ID, Name: displayName(coach), Students: []CoachStudentSummary{}})
+		}
+		sum := CoachStudentSummary{StudentID: st.ID, Name: displayName(st), Notes: noteCount[st.ID], Upcoming: len(upcoming[st.ID])}
+		for _, a := range atts { // newest first
+			if a.StudentID != st.ID || a.CoachID != r.CoachID {
+				continue
+			}
+			sum.Classes++
+			if sum.LastHomework == "" {
+				sum.LastHomework = strings.TrimSpace(a.Homework)
+			}
+		}
+		out[i].Students = append(out[i].Students, sum)
+	}
+	for _, a := range atts {
+		if i, ok := index[a.CoachID]; ok {
+			out[i].Classes++
+			if !a.IsVerified {
+				out[i].Unverified++
+			}
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Name < out[b].Name })
+	return out, nil
+}
+
+// weekAttendances lists the attendances matching f dated within [from, to).
+// Attendance dates are calendar dates, so the range is compared as dates.
+func (ds *DigestService) weekAttendances(ctx context.Context, f store.AttendanceListFilter, from, to time.Time) ([]*models.Attendance, error) {
+	f.StartDate = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	f.EndDate = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return ds.store.ListAttendances(ctx, f)
+}
+
+// upcomingClasses returns, per student, the class starts in the seven days
+// after now, leaving out cancelled classes and holidays.
+func (ds *DigestService) upcomingClasses(ctx context.Context, studentIDs []string, now time.Time) (map[string][]time.Time, error) {
+	schedules, err := ds.store.ListSchedulesForStudents(ctx, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	until := now.AddDate(0, 0, 7)
+	out := map[string][]time.Time{}
+	for _, cs := range schedules {
+		loc, err := time.LoadLocation(cs.Timezone)
+		if err != nil {
+			continue
+		}
+		start, ok := NextClassOccurrence(cs, loc, now)
+		if !ok || !start.Before(until) {
+			continue
+		}
+		start = start.In(loc)
+		skipped, err := ds.store.IsClassSkipped(ctx, cs.ID, start)
+		if err != nil {
+			return nil, err
+		}
+		if !skipped {
+			out[cs.StudentID] = append(out[cs.StudentID], start)
+		}
+	}
+	for id := range out {
+		list := out[id]
+		sort.Slice(list, func(i, j int) bool { return list[i].Before(list[j]) })
+	}
+	return out, nil
+}
+
+// nearbyTournaments picks the soonest tournaments that start within the
+// next digestTournamentDays, nearest distance bucket first on ties.
+func nearbyTournaments(groups []store.TournamentsByDistance, now time.Time) []DigestTournament {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	until := today.AddDate(0, 0, digestTournamentDays)
+	out := []DigestTournament{}
+	for _, g := range groups {
+		for _, t := range g.Tournaments {
+			if t.CancelledAt != nil || t.StartDate == nil || t.StartDate.Before(today) || !t.StartDate.Before(until) {
+				continue
+			}
+			out = append(out, DigestTournament{
+				ID:        t.ID,
+				Title:     t.Title,
+				City:      t.City,
+				State:     t.State,
+				Dates:     t.Dates,
+				StartDate: t.StartDate,
+				Distance:  g.Distance,
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartDate.Before(*out[j].StartDate) })
+	if len(out) > digestTournamentLimit {
+		out = out[:digestTournamentLimit]
+	}
+	return out
+}
+
+func displayName(u *models.User) string {
+	if name := strings.TrimSpace(u.FirstName + " " + u.LastName); name != "" {
+		return name
+	}
+	return u.Email
+}
+
+// DigestSubject is the email subject line for d.
+func DigestSubject(d *Digest) string {
+	return fmt.Sprintf("Your week at BRS Chess: %s - %s", d.From.Format("Jan 2"), d.To.AddDate(0, 0, -1).Format("Jan 2"))
+}
+
+var digestFuncs = map[string]interface{}{
+	"date":      func(t time.Time) string { return t.Format("Mon, Jan 2") },
+	"classTime": func(t time.Time) string { return t.Format("Mon, Jan 2 at 3:04 PM MST") },
+	"lastDay":   func(t time.Time) time.Time { return t.AddDate(0, 0, -1) },
+	"where": func(t DigestTournament) string {
+		return strings.Trim(strings.TrimSpace(t.City)+", "+strings.TrimSpace(t.State), ", ")
+	},
+	"when": func(t DigestTournament) string {
+		if t.Dates != "" {
+			return t.Dates
+		}
+		return t.StartDate.Format("Mon, Jan 2")
+	},
+}
+
+const digestTextTmpl = `Hi {{.Recipient.Name}},
+
+Here is the week of {{date .From}} - {{date (lastDay .To)}}.
+{{range .Students}}
+== {{.Name}} ==
+
+Classes this week:
+{{- range .Classes}}
+- {{date .Date}} with {{.Coach}} ({{.ClassType}})
+{{- if .Highlights}}
+  Highlights: {{.Highlights}}{{end}}
+{{- if .Homework}}
+  Homework: {{.Homework}}{{end}}
+{{- else}}
+- No classes this week.
+{{- end}}
+{{if .Notes}}
+New notes:
+{{- range .Notes}}
+- {{.Title}}{{if .PrimaryTag}} [{{.PrimaryTag}}]{{end}}
+{{- end}}
+{{end}}
+Coming up:
+{{- range .Upcoming}}
+- {{classTime .}}
+{{- else}}
+- No classes scheduled in the next 7 days.
+{{- end}}
+{{if .Tournaments}}
+Tournaments nearby:
+{{- range .Tournaments}}
+- {{.Title}}, {{when .}}{{with where .}}, {{.}}{{end}} (within {{.Distance}} miles)
+{{- end}}
+{{end}}{{end}}
+{{- if .Coaches}}
+== Your coaches ==
+{{range .Coaches}}
+{{.Name}}: {{.Classes}} classes{{if .Unverified}}, {{.Unverified}} not verified{{end}}
+{{- range .Students}}
+- {{.Name}}: {{.Classes}} classes, {{.Notes}} new notes, {{.Upcoming}} upcoming
+{{- if .LastHomework}}
+  Last homework: {{.LastHomework}}{{end}}
+{{- end}}
+{{end}}{{end}}
+You can turn off this weekly email in your notification preferences.
+`
+
+const digestHTMLTmpl = `<!DOCTYPE html>
+<html><body style="font-family: Arial, sans-serif; color: #222; max-width: 640px;">
+<p>Hi {{.Recipient.Name}},</p>
+<p>Here is the week of {{date .From}} - {{date (lastDay .To)}}.</p>
+{{range .Students}}
+<h2 style="border-bottom: 1px solid #ddd;">{{.Name}}</h2>
+<h3>Classes this week</h3>
+{{if .Classes}}<ul>
+{{range .Classes}}<li><strong>{{date .Date}}</strong> with {{.Coach}} ({{.ClassType}})
+{{if .Highlights}}<br>Highlights: {{.Highlights}}{{end}}
+{{if .Homework}}<br>Homework: {{.Homework}}{{end}}</li>
+{{end}}</ul>{{else}}<p>No classes this week.</p>{{end}}
+{{if .Notes}}<h3>New notes</h3>
+<ul>
+{{range .Notes}}<li>{{.Title}}{{if .PrimaryTag}} <em>[{{.PrimaryTag}}]</em>{{end}}</li>
+{{end}}</ul>{{end}}
+<h3>Coming up</h3>
+{{if .Upcoming}}<ul>
+{{range .Upcoming}}<li>{{classTime .}}</li>
+{{end}}</ul>{{else}}<p>No classes scheduled in the next 7 days.</p>{{end}}
+{{if .Tournaments}}<h3>Tournaments nearby</h3>
+<ul>
+{{range .Tournaments}}<li><strong>{{.Title}}</strong>, {{when .}}{{with where .}}, {{.}}{{end}} (within {{.Distance}} miles)</li>
+{{end}}</ul>{{end}}
+{{end}}
+{{if .Coaches}}<h2 style="border-bottom: 1px solid #ddd;">Your coaches</h2>
+{{range .Coaches}}<h3>{{.Name}}: {{.Classes}} classes{{if .Unverified}}, {{.Unverified}} not verified{{end}}</h3>
+<table style="border-collapse: collapse;" cellpadding="4">
+<tr><th align="left">Student</th><th>Classes</th><th>New notes</th><th>Upcoming</th><th align="left">Last homework</th></tr>
+{{range .Students}}<tr><td>{{.Name}}</td><td align="center">{{.Classes}}</td><td align="center">{{.Notes}}</td><td align="center">{{.Upcoming}}</td><td>{{.LastHomework}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}
+<p style="color: #888; font-size: 12px;">You can turn off this weekly email in your notification preferences.</p>
+</body></html>
+`
+
+var (
+	digestText = template.Must(template.New("digest").Funcs(digestFuncs).Parse(digestTextTmpl))
+	digestHTML = htmltemplate.Must(htmltemplate.New("digest").Funcs(digestFuncs).Parse(digestHTMLTmpl))
+)
+
+// RenderDigestText renders d as the plain text email body.
+func RenderDigestText(d *Digest) (string, error) {
+	var b bytes.Buffer
+	err := digestText.Execute(&b, d)
+	return b.String(), err
+}
+
+// RenderDigestHTML renders d as the HTML email body.
+func RenderDigestHTML(d *Digest) (string, error) {
+	var b bytes.Buffer
+	err := digestHTML.Execute(&b, d)
+	return b.String(), err
+}
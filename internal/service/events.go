@@ -0,0 +1,137 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// Event types published on the bus.
+const (
+	EventUserApproved       = "user.approved"
+	EventCoachAssigned      = "coach.assigned"
+	EventMentorAssigned     = "mentor.assigned"
+	EventAttendanceVerified = "attendance.verified"
+	EventAttendanceRejected = "attendance.rejected"
+	EventNoteAddressed      = "note.addressed"
+	EventNoteShared         = "note.shared"
+	EventNoteMentioned      = "note.mentioned"
+
+	EventUserAssigned      = "user.assigned"
+	EventAttendanceCreated = "attendance.created"
+	EventNoteCreated       = "note.created"
+	EventTournamentNew     = "tournament.new"
+)
+
+// Event is something that happened in the app. UserIDs are the users it
+// concerns (the audience, not the actor); Data holds the ids involved. ID
+// is assigned by Publish and identifies the event to external consumers.
+type Event struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	ActorID    string                 `json:"actor_id,omitempty"`
+	UserIDs    []string               `json:"user_ids,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// EventHandler handles one event. Events from the outbox are redelivered
+// while any handler fails, so a handler must be safe to run again for an
+// event it already handled.
+type EventHandler func(ctx context.Context, e Event) error
+
+// EventBus fans events out to subscribers in process. Handlers run
+// synchronously in Publish, so they must hand slow work off themselves.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]EventHandler
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: map[string][]EventHandler{}}
+}
+
+// Subscribe registers h for eventType; "*" receives every event.
+func (b *EventBus) Subscribe(eventType string, h EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], h)
+}
+
+// Publish delivers e to its subscribers and returns their errors joined. A
+// nil bus drops events, so callers built without one keep working. A failing
+// or panicking handler does not stop the others.
+func (b *EventBus) Publish(ctx context.Context, e Event) error {
+	if b == nil {
+		return nil
+	}
+	e = stamp(e)
+	b.mu.RLock()
+	hs := append(append([]EventHandler{}, b.handlers[e.Type]...), b.handlers["*"]...)
+	b.mu.RUnlock()
+	var errs []error
+	for _, h := range hs {
+		if err := callHandler(ctx, h, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// callHandler runs h, turning a panic into an error.
+func callHandler(ctx context.Context, h EventHandler, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[events] %s handler panicked: %v", e.Type, r)
+			err = fmt.Errorf("%s handler panicked: %v", e.Type, r)
+		}
+	}()
+	return h(ctx, e)
+}
+
+// stamp fills in the id and time of a new event.
+func stamp(e Event) Event {
+	if e.ID == "" {
+		e.ID = utils.RandomToken()[:24]
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	return e
+}
+
+// Enqueue writes e to the outbox through tx, so it is published only if
+// the transaction that raised it commits, and still published if the
+// process dies right after. Use it instead of Publish for events that
+// follow a DB change. A nil bus drops events, as Publish does.
+func (b *EventBus) Enqueue(ctx context.Context, tx *store.Store, e Event) error {
+	if b == nil {
+		return nil
+	}
+	e = stamp(e)
+	return tx.Enqueue(ctx, store.OutboxEvent, store.OutboxEvent+":"+e.ID, e)
+}
+
+// OutboxHandler publishes events queued by Enqueue. Numbers in Data are
+// kept as json.Number so ids keep their exact value. If any subscriber
+// fails the message is retried, and every subscriber sees the event again.
+func (b *EventBus) OutboxHandler() OutboxHandler {
+	return func(ctx context.Context, m *models.OutboxMessage) error {
+		var e Event
+		dec := json.NewDecoder(bytes.NewReader(m.Payload))
+		dec.UseNumber()
+		if err := dec.Decode(&e); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+		return b.Publish(ctx, e)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/madhava-poojari/dashboard-api/internal/geo"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+// GeoService loads the offline ZIP centroid dataset and keeps tournament
+// coordinates filled in.
+type GeoService struct {
+	store *store.Store
+}
+
+func NewGeoService(s *store.Store) *GeoService {
+	return &GeoService{store: s}
+}
+
+// LoadCentroids reads a GeoNames postal code file into zip_centroids and then
+// geocodes tournaments that had no coordinates yet. It returns the number of
+// rows loaded.
+func (gs *GeoService) LoadCentroids(ctx context.Context, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	const batchSize = 1000
+	batch := make([]models.ZipCentroid, 0, batchSize)
+	total := 0
+	flush := func() error {
+		if err := gs.store.SaveZipCentroids(ctx, batch); err != nil {
+			return err
+		}
+		total += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	err = geo.ParseGeoNames(f, func(c geo.Centroid) error {
+		batch = append(batch, models.ZipCentroid{
+			Zipcode:   c.Zipcode,
+			PlaceName: c.PlaceName,
+			State:     c.State,
+			Latitude:  c.Lat,
+			Longitude: c.Lng,
+		})
+		if len(batch) == batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return total, fmt.Errorf("load %s: %w", path, err)
+	}
+	if err := gs.store.GeocodeTournaments(ctx); err != nil {
+		return total, fmt.Errorf("geocode tournaments: %w", err)
+	}
+	return total, nil
+}
+
+// CheckCentroids fails when zip_centroids is empty and the dataset file to
+// load it from is missing, so a deploy without the dataset stops at startup
+// instead of silently serving distance search with no results. An empty
+// path means the dataset is deliberately not used.
+func (gs *GeoService) CheckCentroids(ctx context.Context, path string) error {
+	if path == "" {
+		return nil
+	}
+	n, err := gs.store.CountZipCentroids(ctx)
+	if err != nil {
+		return fmt.Errorf("count zip centroids: %w", err)
+	}
+	if n > 0 {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("zip_centroids is empty and %s can't be read (run zip-centroids-import -fetch, or set ZIP_CENTROIDS_FILE=none to run without distance search): %w", path, err)
+	}
+	return nil
+}
+
+// LoadCentroidsIfEmpty loads the dataset on first start only.
+func (gs *GeoService) LoadCentroidsIfEmpty(ctx context.Context, path string) {
+	if path == "" {
+		return
+	}
+	n, err := gs.store.CountZipCentroids(ctx)
+	if err != nil {
+		log.Printf("[geo] count zip centroids: %v", err)
+		return
+	}
+	if n > 0 {
+		return
+	}
+	loaded, err := gs.LoadCentroids(ctx, path)
+	if err != nil {
+		log.Printf("[geo] %v", err)
+		return
+	}
+	log.Printf("[geo] loaded %d zip centroids from %s", loaded, path)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/jobs"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+// outboxRetention is how long handled outbox messages are kept.
+const outboxRetention = 7 * 24 * time.Hour
+
+// jobRunRetention is how long job run history is kept.
+const jobRunRetention = 30 * 24 * time.Hour
+
+// registerJobs wires the periodic jobs.
+func registerJobs(sc *jobs.Scheduler, s *store.Store, digest *DigestService) {
+	sc.Register(jobs.Job{
+		Name:        "auth.purge-expired-tokens",
+		Schedule:    "0 3 * * *",
+		Description: "Delete expired refresh tokens",
+		Timeout:     5 * time.Minute,
+		Run:         s.DeleteExpiredTokens,
+	})
+	sc.Register(jobs.Job{
+		Name:        "scraper.sync-scopes",
+		Schedule:    "*/30 * * * *",
+		Description: "Queue scrape scopes for zipcodes students have added",
+		Timeout:     10 * time.Minute,
+		Run:         s.SyncZipcodesFromUserDetails,
+	})
+	sc.Register(jobs.Job{
+		Name:        "outbox.purge",
+		Schedule:    "30 3 * * *",
+		Description: "Delete outbox messages handled more than a week ago",
+		Timeout:     10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			n, err := s.PurgeOutboxMessages(ctx, time.Now().Add(-outboxRetention))
+			if n > 0 {
+				log.Printf("[jobs] outbox.purge: deleted %d messages", n)
+			}
+			return err
+		},
+	})
+	sc.Register(jobs.Job{
+		Name:        "jobs.purge-runs",
+		Schedule:    "45 3 * * *",
+		Description: "Delete job run history older than 30 days",
+		Timeout:     10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			n, err := s.PurgeJobRuns(ctx, time.Now().Add(-jobRunRetention))
+			if n > 0 {
+				log.Printf("[jobs] jobs.purge-runs: deleted %d runs", n)
+			}
+			return err
+		},
+	})
+	sc.Register(jobs.Job{
+		Name:        "digest.weekly",
+		Schedule:    "0 18 * * SUN",
+		Description: "Email the weekly digest to students, families and mentors",
+		Timeout:     30 * time.Minute,
+		Run:         digest.SendWeekly,
+	})
+}
+
+// registerWorkerJobs wires the syncs that used to run as a loop on every
+// replica. A zero interval or lead leaves the job out, as it disabled the
+// loop.
+func registerWorkerJobs(sc *jobs.Scheduler, ratingSync *RatingSyncService, syncInterval time.Duration, reminders *ReminderService, reminderLead time.Duration) {
+	if syncInterval > 0 {
+		sc.Register(jobs.Job{
+			Name:        "ratings.sync",
+			Schedule:    fmt.Sprintf("@every %s", syncInterval),
+			Description: "Pull ratings and games for linked Lichess and Chess.com accounts",
+			Timeout:     syncInterval,
+			Run:         ratingSync.SyncAll,
+		})
+	}
+	if reminderLead > 0 {
+		sc.Register(jobs.Job{
+			Name:        "reminders.send",
+			Schedule:    "@every 1m",
+			Description: "Remind students and coaches of upcoming classes",
+			Timeout:     5 * time.Minute,
+			Run:         reminders.SendDue,
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/messaging"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+// Template names as registered with the provider.
+const (
+	TemplateClassReminder = "class_reminder"
+	TemplateHomework      = "homework"
+	TemplateNotification  = "academy_notification"
+)
+
+var (
+	ErrNoPhone          = errors.New("user has no usable phone number")
+	ErrInvalidSignature = errors.New("invalid callback signature")
+)
+
+// MessagingService sends templated messages through the configured
+// provider and keeps the outbound log in step with delivery callbacks.
+type MessagingService struct {
+	store          *store.Store
+	provider       messaging.Provider
+	language       string
+	defaultCountry string
+}
+
+func NewMessagingService(s *store.Store, p messaging.Provider, language, defaultCountry string) *MessagingService {
+	return &MessagingService{store: s, provider: p, language: language, defaultCountry: defaultCountry}
+}
+
+func (ms *MessagingService) Provider() messaging.Provider { return ms.provider }
+
+// SendTemplate sends one template to the user's phone and logs it.
+func (ms *MessagingService) SendTemplate(ctx context.Context, to *models.User, template string, params []string, createdBy string) (*models.OutboundMessage, error) {
+	phone := messaging.NormalizePhone(to.UserDetails.Phone, ms.defaultCountry)
+	if phone == "" {
+		return nil, ErrNoPhone
+	}
+	rawParams, _ := json.Marshal(params)
+	m := &models.OutboundMessage{
+		Provider:  ms.provider.Name(),
+		UserID:    to.ID,
+		ToPhone:   phone,
+		Template:  template,
+		Params:    rawParams,
+		Status:    messaging.StatusQueued,
+		CreatedBy: createdBy,
+	}
+	if err := ms.store.CreateOutboundMessage(ctx, m); err != nil {
+		return nil, err
+	}
+	id, err := ms.provider.SendTemplate(ctx, messaging.TemplateMessage{To: phone, Template: template, Language: ms.language, Params: params})
+	updates := map[string]interface{}{}
+	if err != nil {
+		m.Status, m.Error = messaging.StatusFailed, err.Error()
+		updates["status"], updates["error"] = m.Status, m.Error
+	} else {
+		now := time.Now()
+		m.Status, m.ProviderMessageID, m.SentAt = messaging.StatusSent, id, &now
+		updates["status"], updates["provider_message_id"], updates["sent_at"] = m.Status, id, now
+	}
+	if uerr := ms.store.UpdateOutboundMessage(ctx, m.ID, updates); uerr != nil {
+		return m, uerr
+	}
+	return m, err
+}
+
+// SendToFamily sends the template to the student and each family member
+// with a phone, skipping duplicate numbers. It returns the messages sent
+// and the last error seen.
+func (ms *MessagingService) SendToFamily(ctx context.Context, student *models.User, template string, params []string, createdBy string) ([]*models.OutboundMessage, error) {
+	family, err := ms.store.FamilyMembers(ctx, student.ID)
+	if err != nil {
+		return nil, err
+	}
+	var out []*models.OutboundMessage
+	var lastErr error
+	seen := map[string]bool{}
+	for _, u := range append([]*models.User{student}, family...) {
+		phone := messaging.NormalizePhone(u.UserDetails.Phone, ms.defaultCountry)
+		if phone == "" || seen[phone] {
+			continue
+		}
+		seen[phone] = true
+		m, err := ms.SendTemplate(ctx, u, template, params, createdBy)
+		if m != nil {
+			out = append(out, m)
+		}
+		if err != nil {
+			lastErr = err
+		}
+	}
+	if len(out) == 0 && lastErr == nil {
+		lastErr = ErrNoPhone
+	}
+	return out, lastErr
+}
+
+// SendHomework sends a class's homework to the student and their family.
+func (ms *MessagingService) SendHomework(ctx context.Context, a *models.Attendance, createdBy string) ([]*models.OutboundMessage, error) {
+	if a.Homework == "" {
+		return nil, errors.New("this class has no homework")
+	}
+	student, err := ms.store.GetUserByID(ctx, a.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	params := []string{student.FirstName, a.Date.Format("Mon Jan 2"), a.Homework}
+	return ms.SendToFamily(ctx, student, TemplateHomework, params, createdBy)
+}
+
+// HandleStatusCallback verifies and applies a provider delivery callback.
+// It returns how many known messages were updated.
+func (ms *MessagingService) HandleStatusCallback(ctx context.Context, r *http.Request, body []byte) (int, error) {
+	if !ms.provider.VerifyCallback(r, body) {
+		return 0, ErrInvalidSignature
+	}
+	updates, err := ms.provider.ParseStatusCallback(body)
+	if err != nil {
+		return 0, fmt.Errorf("parse callback: %w", err)
+	}
+	n := 0
+	for _, u := range updates {
+		ok, err := ms.store.ApplyMessageStatus(ctx, ms.provider.Name(), u.ProviderMessageID, u.Status, u.Error, u.Timestamp)
+		if err != nil {
+			return n, err
+		}
+		if ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// WhatsAppSender is the notification channel for chat messages. Students'
+// notifications also go to their family.
+type WhatsAppSender struct {
+	messaging *MessagingService
+}
+
+func NewWhatsAppSender(ms *MessagingService) *WhatsAppSender {
+	return &WhatsAppSender{messaging: ms}
+}
+
+func (s *WhatsAppSender) Channel() string { return models.ChannelWhatsApp }
+
+func (s *WhatsAppSender) Send(ctx context.Context, to Recipient, n *models.Notification) error {
+	template, params := TemplateNotification, []string{n.Title, n.Body}
+	if n.Type == EventClassReminder {
+		str := func(k string) string {
+			if v, _ := n.Data[k].(string); v != "" {
+				return v
+			}
+			return "-"
+		}
+		template, params = TemplateClassReminder, []string{n.Title, str("meet_link"), str("homework")}
+	}
+	if to.User.Role == models.RoleStudent {
+		_, err := s.messaging.SendToFamily(ctx, to.User, template, params, "")
+		return err
+	}
+	_, err := s.messaging.SendTemplate(ctx, to.User, template, params, "")
+	return err
+}
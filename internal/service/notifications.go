@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+// Recipient is who a notification is delivered to, with the preferences
+// that picked the channel.
+type Recipient struct {
+	User *models.User
+	Pref *models.NotificationPreference
+}
+
+// Sender delivers notifications on one channel. The in-app channel is a
+// Sender too, so tests can swap every channel for a MemorySender.
+type Sender interface {
+	Channel() string
+	Send(ctx context.Context, to Recipient, n *models.Notification) error
+}
+
+// NotificationStore is the part of the store NotificationService reads.
+// *store.Store implements it; tests use a fake.
+type NotificationStore interface {
+	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	GetNote(ctx context.Context, id uint) (*models.Note, error)
+	CanAccessNoteForRequester(ctx context.Context, requester *models.User, n *models.Note) bool
+	GetNotificationPreference(ctx context.Context, userID string) (*models.NotificationPreference, error)
+	ClaimNotificationDelivery(ctx context.Context, eventID, userID, channel string) (bool, error)
+	ReleaseNotificationDelivery(ctx context.Context, eventID, userID, channel string) error
+}
+
+// NotificationService turns bus events into notifications and delivers
+// them on the channels each recipient chose.
+type NotificationService struct {
+	store   NotificationStore
+	senders map[string]Sender
+}
+
+func NewNotificationService(s NotificationStore, senders ...Sender) *NotificationService {
+	ns := &NotificationService{store: s, senders: map[string]Sender{}}
+	for _, snd := range senders {
+		ns.senders[snd.Channel()] = snd
+	}
+	return ns
+}
+
+// Subscribe registers the service for the events users are told about.
+func (ns *NotificationService) Subscribe(bus *EventBus) {
+	for _, t := range []string{
+		EventUserApproved,
+		EventCoachAssigned,
+		EventMentorAssigned,
+		EventAttendanceVerified,
+		EventAttendanceRejected,
+		EventNoteAddressed,
+		EventNoteShared,
+		EventNoteMentioned,
+	} {
+		bus.Subscribe(t, ns.HandleEvent)
+	}
+}
+
+// HandleEvent notifies every user in e.UserIDs except the actor. For note
+// events, users who can't read the note are skipped. Delivery is keyed by
+// event, user and channel, so handling e again only retries what failed.
+func (ns *NotificationService) HandleEvent(ctx context.Context, e Event) error {
+	var note *models.Note
+	if id, ok := e.Data["note_id"]; ok {
+		noteID, err := strconv.ParseUint(fmt.Sprint(id), 10, 64)
+		if err != nil {
+			log.Printf("[notify] %s: bad note id %v", e.Type, id)
+			return nil
+		}
+		if note, err = ns.store.GetNote(ctx, uint(noteID)); err != nil {
+			if store.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("notify: load note %d: %w", noteID, err)
+		}
+	}
+	var errs []error
+	for _, uid := range e.UserIDs {
+		if uid == "" || uid == e.ActorID {
+			continue
+		}
+		u, err := ns.store.GetUserByID(ctx, uid)
+		if err != nil {
+			if !store.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("notify: load user %s: %w", uid, err))
+			}
+			continue
+		}
+		if !u.Active {
+			continue
+		}
+		if note != nil && !ns.store.CanAccessNoteForRequester(ctx, u, note) {
+			continue
+		}
+		title, body := ns.render(ctx, e, u)
+		n := &models.Notification{
+			UserID:    uid,
+			Type:      e.Type,
+			Title:     title,
+			Body:      body,
+			Data:      e.Data,
+			ActorID:   e.ActorID,
+			EventID:   e.ID,
+			CreatedAt: e.OccurredAt,
+		}
+		if err := ns.Deliver(ctx, u, n); err != nil {
+			errs = append(errs, fmt.Errorf("notify: %s to %s: %w", e.Type, uid, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Deliver sends n to u on the channels their preferences pick for n.Type.
+// The in-app channel runs inline; the others run in the background so a
+// slow mail server doesn't hold up the request that raised the event. When
+// n.EventID is set, a channel that already got the event is skipped; a
+// failed in-app send is released for the next attempt, while background
+// sends are only logged, as before.
+func (ns *NotificationService) Deliver(ctx context.Context, u *models.User, n *models.Notification) error {
+	pref, err := ns.store.GetNotificationPreference(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	to := Recipient{User: u, Pref: pref}
+	var errs []string
+	for _, ch := range store.ChannelsForEvent(pref, n.Type) {
+		snd, ok := ns.senders[ch]
+		if !ok {
+			continue
+		}
+		if n.EventID != "" {
+			claimed, err := ns.store.ClaimNotificationDelivery(ctx, n.EventID, u.ID, ch)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", ch, err))
+				continue
+			}
+			if !claimed {
+				continue
+			}
+		}
+		if ch == models.ChannelInApp {
+			if err := snd.Send(ctx, to, n); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", ch, err))
+				if n.EventID != "" {
+					if err := ns.store.ReleaseNotificationDelivery(ctx, n.EventID, u.ID, ch); err != nil {
+						log.Printf("[notify] release %s via %s to %s: %v", n.EventID, ch, u.ID, err)
+					}
+				}
+			}
+			continue
+		}
+		msg := *n
+		go func(snd Sender) {
+			bg, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+			if err := snd.Send(bg, to, &msg); err != nil {
+				log.Printf("[notify] %s via %s to %s: %v", msg.Type, snd.Channel(), u.ID, err)
+			}
+		}(snd)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// render writes the title and body for one recipient of e.
+func (ns *NotificationService) render(ctx context.Context, e Event, to *models.User) (string, string) {
+	actor := ns.userName(ctx, e.ActorID)
+	str := func(k string) string {
+		s, _ := e.Data[k].(string)
+		return s
+	}
+	switch e.Type {
+	case EventUserApproved:
+		return "Your account has been approved", "You can now sign in and use the dashboard."
+	case EventCoachAssigned:
+		if to.ID == str("coach_id") {
+			return "New student assigned", fmt.Sprintf("%s is now your student.", ns.userName(ctx, str("student_id")))
+		}
+		return "Your coach has been assigned", fmt.Sprintf("%s is now your coach.", ns.userName(ctx, str("coach_id")))
+	case EventMentorAssigned:
+		return "Mentor assigned", fmt.Sprintf("%s is now your mentor.", ns.userName(ctx, str("mentor_id")))
+	case EventAttendanceVerified:
+		return "Class attendance verified", fmt.Sprintf("The %s class on %s was verified by %s.", str("class_type"), str("date"), actor)
+	case EventAttendanceRejected:
+		return "Class attendance rejected", fmt.Sprintf("%s rejected the %s class on %s: %s", actor, str("class_type"), str("date"), str("reason"))
+	case EventNoteAddressed:
+		return "New note: " + str("title"), fmt.Sprintf("%s added a note for you.", actor)
+	case EventNoteShared:
+		return "Note shared with you: " + str("title"), fmt.Sprintf("%s shared a note with you.", actor)
+	case EventNoteMentioned:
+		return "You were mentioned on " + str("title"), fmt.Sprintf("%s mentioned you in a comment.", actor)
+	}
+	return e.Type, ""
+}
+
+func (ns *NotificationService) userName(ctx context.Context, id string) string {
+	if id == "" {
+		return "Someone"
+	}
+	u, err := ns.store.GetUserByID(ctx, id)
+	if err != nil {
+		return "Someone"
+	}
+	if name := strings.TrimSpace(u.FirstName + " " + u.LastName); name != "" {
+		return name
+	}
+	return u.Email
+}
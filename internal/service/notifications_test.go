@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// fakeNotificationStore serves users, notes and preferences from maps.
+// readers lists, per note, the users allowed to read it.
+type fakeNotificationStore struct {
+	users   map[string]*models.User
+	notes   map[uint]*models.Note
+	readers map[uint][]string
+	prefs   map[string]*models.NotificationPreference
+	prefErr error
+
+	mu     sync.Mutex
+	claims map[string]bool
+}
+
+func (f *fakeNotificationStore) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	if u, ok := f.users[id]; ok {
+		return u, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeNotificationStore) GetNote(ctx context.Context, id uint) (*models.Note, error) {
+	if n, ok := f.notes[id]; ok {
+		return n, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeNotificationStore) CanAccessNoteForRequester(ctx context.Context, requester *models.User, n *models.Note) bool {
+	for _, id := range f.readers[n.ID] {
+		if id == requester.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeNotificationStore) GetNotificationPreference(ctx context.Context, userID string) (*models.NotificationPreference, error) {
+	if f.prefErr != nil {
+		return nil, f.prefErr
+	}
+	if p, ok := f.prefs[userID]; ok {
+		return p, nil
+	}
+	return pref(userID, models.ChannelInApp), nil
+}
+
+func (f *fakeNotificationStore) ClaimNotificationDelivery(ctx context.Context, eventID, userID, channel string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k := eventID + "/" + userID + "/" + channel
+	if f.claims[k] {
+		return false, nil
+	}
+	f.claims[k] = true
+	return true, nil
+}
+
+func (f *fakeNotificationStore) ReleaseNotificationDelivery(ctx context.Context, eventID, userID, channel string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.claims, eventID+"/"+userID+"/"+channel)
+	return nil
+}
+
+func pref(userID string, channels ...string) *models.NotificationPreference {
+	b, _ := json.Marshal(channels)
+	return &models.NotificationPreference{UserID: userID, Channels: datatypes.JSON(b), EventChannels: datatypes.JSONMap{}}
+}
+
+func user(id string) *models.User {
+	return &models.User{ID: id, FirstName: id, Active: true}
+}
+
+// outboxEvent runs e through Enqueue's encoding and the bus's outbox
+// handler, the way events reach subscribers in production.
+func outboxEvent(t *testing.T, bus *EventBus, e Event) error {
+	t.Helper()
+	payload, err := json.Marshal(stamp(e))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bus.OutboxHandler()(context.Background(), &models.OutboxMessage{Payload: payload})
+}
+
+// waitSent waits for background channels to record n messages.
+func waitSent(t *testing.T, s *MemorySender, n int) []MemoryMessage {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		sent := s.Sent()
+		if len(sent) >= n || time.Now().After(deadline) {
+			return sent
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func recipients(ms []MemoryMessage) map[string]int {
+	out := map[string]int{}
+	for _, m := range ms {
+		out[m.UserID]++
+	}
+	return out
+}
+
+func TestNotificationPreferenceRouting(t *testing.T) {
+	quiet := pref("quiet", models.ChannelInApp, models.ChannelEmail)
+	quiet.EventChannels = datatypes.JSONMap{EventCoachAssigned: []interface{}{}}
+	fs := &fakeNotificationStore{
+		users: map[string]*models.User{
+			"admin": user("admin"), "both": user("both"), "wa": user("wa"),
+			"quiet": user("quiet"), "gone": {ID: "gone", Active: false},
+		},
+		prefs: map[string]*models.NotificationPreference{
+			"both": pref("both", models.ChannelInApp, models.ChannelEmail),
+			"wa": func() *models.NotificationPreference {
+				p := pref("wa", models.ChannelInApp)
+				p.EventChannels = datatypes.JSONMap{EventCoachAssigned: []interface{}{models.ChannelWhatsApp}}
+				return p
+			}(),
+			"quiet": quiet,
+		},
+		claims: map[string]bool{},
+	}
+	inApp := NewMemorySender(models.ChannelInApp)
+	email := NewMemorySender(models.ChannelEmail)
+	wa := NewMemorySender(models.ChannelWhatsApp)
+	bus := NewEventBus()
+	NewNotificationService(fs, inApp, email, wa).Subscribe(bus)
+
+	err := outboxEvent(t, bus, Event{
+		Type:    EventCoachAssigned,
+		ActorID: "admin",
+		UserIDs: []string{"admin", "both", "wa", "quiet", "gone", "nobody"},
+		Data:    map[string]interface{}{"coach_id": "admin", "student_id": "both"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := recipients(inApp.Sent()); len(got) != 1 || got["both"] != 1 {
+		t.Errorf("in-app went to %v, want only both", got)
+	}
+	if got := recipients(waitSent(t, email, 1)); len(got) != 1 || got["both"] != 1 {
+		t.Errorf("email went to %v, want only both", got)
+	}
+	if got := recipients(waitSent(t, wa, 1)); len(got) != 1 || got["wa"] != 1 {
+		t.Errorf("whatsapp went to %v, want only wa (event override)", got)
+	}
+
+	n := inApp.Sent()[0].Notification
+	if n.Type != EventCoachAssigned || n.Title != "Your coach has been assigned" || n.ActorID != "admin" || n.EventID == "" {
+		t.Errorf("notification = %+v", n)
+	}
+}
+
+func TestNotificationNoteVisibility(t *testing.T) {
+	fs := &fakeNotificationStore{
+		users: map[string]*models.User{
+			"coach": user("coach"), "student": user("student"), "parent": user("parent"),
+		},
+		notes:   map[uint]*models.Note{7: {ID: 7, UserID: "student", Visibility: models.NoteVisibilityStaff}},
+		readers: map[uint][]string{7: {"coach"}},
+		claims:  map[string]bool{},
+	}
+	inApp := NewMemorySender(models.ChannelInApp)
+	bus := NewEventBus()
+	NewNotificationService(fs, inApp).Subscribe(bus)
+
+	e := Event{
+		ID:      "evt-note",
+		Type:    EventNoteShared,
+		ActorID: "admin",
+		UserIDs: []string{"coach", "student", "parent"},
+		Data:    map[string]interface{}{"note_id": 7, "title": "Endgames"},
+	}
+	if err := outboxEvent(t, bus, e); err != nil {
+		t.Fatal(err)
+	}
+	if got := recipients(inApp.Sent()); len(got) != 1 || got["coach"] != 1 {
+		t.Errorf("notified %v, want only coach (the others can't read the note)", got)
+	}
+
+	// A redelivered event is not sent again.
+	if err := outboxEvent(t, bus, e); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(inApp.Sent()); n != 1 {
+		t.Errorf("after redelivery sent %d notifications, want 1", n)
+	}
+
+	// A note that is gone has no one to tell.
+	e.ID, e.Data = "evt-gone", map[string]interface{}{"note_id": 8}
+	if err := outboxEvent(t, bus, e); err != nil {
+		t.Errorf("deleted note: err = %v", err)
+	}
+	if n := len(inApp.Sent()); n != 1 {
+		t.Errorf("deleted note: sent %d notifications, want 1", n)
+	}
+}
+
+func TestNotificationErrorsRetryOutbox(t *testing.T) {
+	fs := &fakeNotificationStore{
+		users:   map[string]*models.User{"student": user("student")},
+		prefErr: errors.New("db down"),
+		claims:  map[string]bool{},
+	}
+	inApp := NewMemorySender(models.ChannelInApp)
+	bus := NewEventBus()
+	NewNotificationService(fs, inApp).Subscribe(bus)
+
+	e := Event{ID: "evt-retry", Type: EventUserApproved, UserIDs: []string{"student"}}
+	if err := outboxEvent(t, bus, e); err == nil {
+		t.Fatal("want the store error back so the outbox retries")
+	}
+	fs.prefErr = nil
+	if err := outboxEvent(t, bus, e); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(inApp.Sent()); n != 1 {
+		t.Errorf("sent %d notifications after retry, want 1", n)
+	}
+}
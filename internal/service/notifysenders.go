@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+// InAppSender stores the notification for the /notifications endpoints.
+type InAppSender struct {
+	store *store.Store
+}
+
+func NewInAppSender(s *store.Store) *InAppSender {
+	return &InAppSender{store: s}
+}
+
+func (s *InAppSender) Channel() string { return models.ChannelInApp }
+
+func (s *InAppSender) Send(ctx context.Context, to Recipient, n *models.Notification) error {
+	n.ID = 0
+	n.UserID = to.User.ID
+	return s.store.CreateNotification(ctx, n)
+}
+
+// SMTPSender emails notifications through a plain SMTP relay.
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPSender{addr: net.JoinHostPort(host, strconv.Itoa(port)), auth: auth, from: from}
+}
+
+func (s *SMTPSender) Channel() string { return models.ChannelEmail }
+
+func (s *SMTPSender) Send(ctx context.Context, to Recipient, n *models.Notification) error {
+	if to.User.Email == "" {
+		return nil
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to.User.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Title))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.Body, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{to.User.Email}, msg.Bytes())
+}
+
+// SendEmail sends a multipart/alternative message with a plain text and an
+// HTML body.
+func (s *SMTPSender) SendEmail(ctx context.Context, to, subject, text, html string) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ ctype, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.ctype},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(strings.ReplaceAll(part.content, "\n", "\r\n"))); err != nil {
+			return err
+		}
+		if err := qw.Close(); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\nContent-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{to}, msg.Bytes())
+}
+
+// WebhookSender POSTs notifications as JSON to the URL in the recipient's
+// preferences.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender(client *http.Client) *WebhookSender {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookSender{client: client}
+}
+
+func (s *WebhookSender) Channel() string { return models.ChannelWebhook }
+
+func (s *WebhookSender) Send(ctx context.Context, to Recipient, n *models.Notification) error {
+	if to.Pref == nil || to.Pref.WebhookURL == "" {
+		return nil
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"type":       n.Type,
+		"user_id":    to.User.ID,
+		"title":      n.Title,
+		"body":       n.Body,
+		"data":       n.Data,
+		"created_at": n.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, to.Pref.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// MemorySender records what it is asked to send. Use it in place of a
+// real channel in tests.
+type MemorySender struct {
+	channel string
+	mu      sync.Mutex
+	sent    []MemoryMessage
+}
+
+type MemoryMessage struct {
+	UserID       string
+	Notification models.Notification
+}
+
+func NewMemorySender(channel string) *MemorySender {
+	return &MemorySender{channel: channel}
+}
+
+func (s *MemorySender) Channel() string { return s.channel }
+
+func (s *MemorySender) Send(ctx context.Context, to Recipient, n *models.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, MemoryMessage{UserID: to.User.ID, Notification: *n})
+	return nil
+}
+
+// Sent returns a copy of the messages recorded so far.
+func (s *MemorySender) Sent() []MemoryMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]MemoryMessage(nil), s.sent...)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+const (
+	outboxMaxAttempts = 10
+	outboxBatchSize   = 50
+	outboxLease       = time.Minute
+)
+
+// OutboxHandler performs the side effect of one outbox message. It may run
+// more than once for the same message, so it must be idempotent.
+type OutboxHandler func(ctx context.Context, m *models.OutboxMessage) error
+
+// OutboxDispatcher works through the outbox: messages are written in the
+// same transaction as the change that caused them, and Run hands each one
+// to the handler for its kind until it succeeds or runs out of attempts.
+// Messages that run out land in the dead letters for an admin to retry.
+type OutboxDispatcher struct {
+	store    *store.Store
+	handlers map[string]OutboxHandler
+}
+
+func NewOutboxDispatcher(s *store.Store) *OutboxDispatcher {
+	return &OutboxDispatcher{store: s, handlers: map[string]OutboxHandler{}}
+}
+
+// Handle registers h for messages of kind. Register before Run starts.
+func (d *OutboxDispatcher) Handle(kind string, h OutboxHandler) {
+	d.handlers[kind] = h
+}
+
+// Run dispatches due messages every interval until ctx is cancelled.
+func (d *OutboxDispatcher) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		for ctx.Err() == nil && d.DispatchDue(ctx) == outboxBatchSize {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// DispatchDue claims and handles one batch of messages. It returns how many
+// were claimed.
+func (d *OutboxDispatcher) DispatchDue(ctx context.Context) int {
+	ms, err := d.store.ClaimOutboxMessages(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("[outbox] claim messages: %v", err)
+		}
+		return 0
+	}
+	for i := range ms {
+		m := &ms[i]
+		err := d.dispatch(ctx, m)
+		var retryAt *time.Time
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+			if m.Attempts < outboxMaxAttempts {
+				next := time.Now().Add(OutboxBackoff(m.Attempts))
+				retryAt = &next
+			} else {
+				log.Printf("[outbox] %s message %d dead after %d attempts: %v", m.Kind, m.ID, m.Attempts, err)
+			}
+		}
+		if err := d.store.FinishOutboxMessage(ctx, m.ID, errMsg, retryAt); err != nil {
+			log.Printf("[outbox] message %d: record result: %v", m.ID, err)
+		}
+	}
+	return len(ms)
+}
+
+func (d *OutboxDispatcher) dispatch(ctx context.Context, m *models.OutboxMessage) (err error) {
+	h, ok := d.handlers[m.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for kind %q", m.Kind)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return h(ctx, m)
+}
+
+// OutboxBackoff is the wait after the given failed attempt: 10s doubling
+// each time, capped at an hour.
+func OutboxBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 16 {
+		attempt = 16
+	}
+	d := 10 * time.Second << (attempt - 1)
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
+
+// StorageDeleteHandler removes objects named by OutboxStorageDelete
+// messages from the matching storage. Deleting a missing object succeeds,
+// so repeats are harmless.
+func StorageDeleteHandler(storages map[string]utils.Storage) OutboxHandler {
+	return func(ctx context.Context, m *models.OutboxMessage) error {
+		var p store.StorageDelete
+		if err := json.Unmarshal(m.Payload, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		st, ok := storages[p.Storage]
+		if !ok || st == nil {
+			return fmt.Errorf("unknown storage %q", p.Storage)
+		}
+		return st.DeleteFile(p.Key)
+	}
+}
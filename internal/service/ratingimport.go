@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/ratinglists"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+// Mismatch fields recorded on rating_id_mismatches.
+const (
+	MismatchName      = "name"
+	MismatchBirthYear = "birth_year"
+	MismatchNotListed = "not_listed"
+)
+
+type RatingImportService struct {
+	store *store.Store
+}
+
+func NewRatingImportService(s *store.Store) *RatingImportService {
+	return &RatingImportService{store: s}
+}
+
+// RatingImportResult summarizes one import run.
+type RatingImportResult struct {
+	Federation string `json:"federation"`
+	Period     string `json:"period"`
+	List       string `json:"list,omitempty"` // FIDE single-type list; empty for a full list
+	SourceFile string `json:"source_file"`
+	Parsed     int    `json:"parsed"`
+	Matched    int    `json:"matched"`
+	Flagged    int    `json:"flagged"`
+	NotListed  int    `json:"not_listed"`
+}
+
+// ParseFunc feeds every player of a list to the handler; it is one of the
+// ratinglists.Parse* functions bound to an open file.
+type ParseFunc func(ratinglists.HandlerFunc) error
+
+// Import matches a published rating list against stored FIDE/USCF IDs,
+// records a rating row per matched student for the period, and flags IDs
+// whose listed name or birth year doesn't fit, or that are missing from a
+// combined list entirely. list is one of the ratinglists.List* types; importing a
+// single-type list only touches that rating of the period's rows.
+func (ri *RatingImportService) Import(ctx context.Context, federation, list string, period time.Time, sourceFile string, parse ParseFunc) (*RatingImportResult, error) {
+	var ratingColumns []string
+	switch list {
+	case ratinglists.ListCombined:
+	case ratinglists.ListStandard:
+		ratingColumns = []string{"standard_rating"}
+	case ratinglists.ListRapid:
+		ratingColumns = []string{"rapid_rating"}
+	case ratinglists.ListBlitz:
+		ratingColumns = []string{"blitz_rating"}
+	default:
+		return nil, fmt.Errorf("unknown list type %q", list)
+	}
+
+	users, err := ri.store.ListUsersWithFederationIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	byExtID := map[string][]store.FederationStudent{}
+	for _, u := range users {
+		id := u.FIDEID
+		if federation == ratinglists.FederationUSCF {
+			id = u.USCFID
+		}
+		id = strings.TrimSpace(id)
+		if id != "" {
+			byExtID[id] = append(byExtID[id], u)
+		}
+	}
+
+	res := &RatingImportResult{
+		Federation: federation,
+		Period:     period.Format("2006-01"),
+		List:       list,
+		SourceFile: filepath.Base(sourceFile),
+	}
+	seen := map[string]bool{}
+	now := time.Now()
+
+	err = parse(func(p ratinglists.Player) error {
+		res.Parsed++
+		matches, ok := byExtID[p.ID]
+		if !ok {
+			return nil
+		}
+		seen[p.ID] = true
+		for _, u := range matches {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			res.Matched++
+			row := &models.OfficialRating{
+				UserID:         u.UserID,
+				Federation:     federation,
+				Period:         period,
+				ExternalID:     p.ID,
+				ListedName:     p.Name,
+				StandardRating: p.Standard,
+				RapidRating:    p.Rapid,
+				BlitzRating:    p.Blitz,
+				Title:          p.Title,
+				SourceFile:     res.SourceFile,
+				ImportedAt:     now,
+			}
+			if err := ri.store.SaveOfficialRating(ctx, row, ratingColumns...); err != nil {
+				return err
+			}
+			flagged, err := ri.checkIdentity(ctx, federation, u, p)
+			if err != nil {
+				return err
+			}
+			if flagged {
+				res.Flagged++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+
+	// IDs we hold that the list doesn't know about are likely typos. Only the
+	// combined list has every player; the single-type lists leave out anyone
+	// without that rating.
+	if list != ratinglists.ListCombined {
+		return res, nil
+	}
+	for extID, us := range byExtID {
+		if seen[extID] {
+			continue
+		}
+		for _, u := range us {
+			res.NotListed++
+			if err := ri.store.FlagRatingIDMismatch(ctx, &models.RatingIDMismatch{
+				UserID:     u.UserID,
+				Federation: federation,
+				Field:      MismatchNotListed,
+				ExternalID: extID,
+				Expected:   "listed in " + res.SourceFile,
+				Actual:     "not found",
+			}); err != nil {
+				return res, err
+			}
+			if err := ri.store.ResolveRatingIDMismatches(ctx, u.UserID, federation, []string{MismatchNotListed}); err != nil {
+				return res, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// checkIdentity flags name and birth year differences for a matched row and
+// resolves flags that no longer apply.
+func (ri *RatingImportService) checkIdentity(ctx context.Context, federation string, u store.FederationStudent, p ratinglists.Player) (bool, error) {
+	var failing []string
+	flag := func(field, expected, actual string) error {
+		failing = append(failing, field)
+		return ri.store.FlagRatingIDMismatch(ctx, &models.RatingIDMismatch{
+			UserID:     u.UserID,
+			Federation: federation,
+			Field:      field,
+			ExternalID: p.ID,
+			Expected:   expected,
+			Actual:     actual,
+		})
+	}
+
+	if p.Name != "" && !ratinglists.NamesMatch(p.Name, u.FirstName, u.LastName) {
+		if err := flag(MismatchName, strings.TrimSpace(u.FirstName+" "+u.LastName), p.Name); err != nil {
+			return false, err
+		}
+	}
+	if p.BirthYear != 0 && u.DOB != nil && u.DOB.Year() != p.BirthYear {
+		if err := flag(MismatchBirthYear, strconv.Itoa(u.DOB.Year()), strconv.Itoa(p.BirthYear)); err != nil {
+			return false, err
+		}
+	}
+	if err := ri.store.ResolveRatingIDMismatches(ctx, u.UserID, federation, failing); err != nil {
+		return false, err
+	}
+	return len(failing) > 0, nil
+}
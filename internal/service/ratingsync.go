@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/chessapi"
+	"github.com/madhava-poojari/dashboard-api/internal/config"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+const (
+	SourceLichess  = "lichess"
+	SourceChesscom = "chesscom"
+
+	// maxGamesPerSync caps how many games one account pulls per run; the
+	// cursor picks up the rest on the next run.
+	maxGamesPerSync = 200
+)
+
+// chessSource is implemented by both chessapi clients.
+type chessSource interface {
+	GetRatings(ctx context.Context, username string) ([]chessapi.Rating, error)
+	GetGamesSince(ctx context.Context, username string, since time.Time, max int) ([]chessapi.Game, error)
+}
+
+type RatingSyncService struct {
+	store   *store.Store
+	sources map[string]chessSource
+}
+
+// NewRatingSyncService builds the service with real HTTP clients. Pass a
+// different doer to point it at a stub.
+func NewRatingSyncService(s *store.Store, cfg *config.Config, doer chessapi.HTTPDoer) *RatingSyncService {
+	if doer == nil {
+		doer = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &RatingSyncService{
+		store: s,
+		sources: map[string]chessSource{
+			SourceLichess:  chessapi.NewLichessClient(doer, cfg.LichessBaseURL, cfg.ChessAPIUserAgent, chessapi.NewRateLimiter(cfg.ChessAPIInterval)),
+			SourceChesscom: chessapi.NewChesscomClient(doer, cfg.ChesscomBaseURL, cfg.ChessAPIUserAgent, chessapi.NewRateLimiter(cfg.ChessAPIInterval)),
+		},
+	}
+}
+
+// SyncAll syncs every active student with an external username. Per-account
+// failures are recorded on the cursor row and don't stop the run.
+func (rs *RatingSyncService) SyncAll(ctx context.Context) error {
+	accounts, err := rs.store.ListChessAccountsForSync(ctx)
+	if err != nil {
+		return fmt.Errorf("list accounts: %w", err)
+	}
+	for _, acc := range accounts {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rs.syncAccount(ctx, acc)
+	}
+	return nil
+}
+
+// SyncUser syncs one user's accounts immediately and returns the resulting
+// cursor rows.
+func (rs *RatingSyncService) SyncUser(ctx context.Context, userID string) ([]models.ChessAccountSync, error) {
+	acc, err := rs.store.GetChessAccountForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	rs.syncAccount(ctx, *acc)
+	return rs.store.ListChessAccountSyncs(ctx, userID)
+}
+
+// OutboxHandler runs the syncs queued by POST /users/{id}/ratings/sync.
+// Per-account failures are recorded on the cursor rows, as in SyncAll, and
+// the next scheduled run retries them.
+func (rs *RatingSyncService) OutboxHandler() OutboxHandler {
+	return func(ctx context.Context, m *models.OutboxMessage) error {
+		var p store.RatingSync
+		if err := json.Unmarshal(m.Payload, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		_, err := rs.SyncUser(ctx, p.UserID)
+		if store.IsNotFound(err) {
+			return nil // no user details, nothing to sync
+		}
+		return err
+	}
+}
+
+func (rs *RatingSyncService) syncAccount(ctx context.Context, acc store.ChessAccount) {
+	usernames := map[string]string{
+		SourceLichess:  strings.TrimSpace(acc.LichessUsername),
+		SourceChesscom: strings.TrimSpace(acc.ChesscomUsername),
+	}
+	for source, username := range usernames {
+		if username == "" {
+			continue
+		}
+		if err := rs.syncSource(ctx, acc.UserID, source, username); err != nil {
+			log.Printf("[rating-sync] user=%s source=%s: %v", acc.UserID, source, err)
+		}
+	}
+}
+
+func (rs *RatingSyncService) syncSource(ctx context.Context, userID, source, username string) error {
+	client := rs.sources[source]
+	cursor, err := rs.store.GetChessAccountSync(ctx, userID, source)
+	if err != nil {
+		return err
+	}
+	// A changed username is a different account; start its games from scratch.
+	if !strings.EqualFold(cursor.Username, username) {
+		cursor.Username = username
+		cursor.GamesCursor = nil
+	}
+
+	syncErr := rs.pull(ctx, client, cursor, userID, source, username)
+	now := time.Now()
+	cursor.LastSyncedAt = &now
+	cursor.LastError = ""
+	if syncErr != nil {
+		cursor.LastError = syncErr.Error()
+		if errors.Is(syncErr, chessapi.ErrNotFound) {
+			cursor.LastError = fmt.Sprintf("%s account %q not found", source, username)
+		}
+	}
+	if err := rs.store.SaveChessAccountSync(ctx, cursor); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+func (rs *RatingSyncService) pull(ctx context.Context, client chessSource, cursor *models.ChessAccountSync, userID, source, username string) error {
+	ratings, err := client.GetRatings(ctx, username)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	snaps := make([]models.RatingSnapshot, 0, len(ratings))
+	for _, r := range ratings {
+		snaps = append(snaps, models.RatingSnapshot{
+			UserID:      userID,
+			Source:      source,
+			TimeControl: r.TimeControl,
+			Rating:      r.Rating,
+			Games:       r.Games,
+			RecordedAt:  now,
+		})
+	}
+	if err := rs.store.RecordRatingSnapshots(ctx, snaps); err != nil {
+		return err
+	}
+
+	var since time.Time
+	if cursor.GamesCursor != nil {
+		since = *cursor.GamesCursor
+	}
+	games, err := client.GetGamesSince(ctx, username, since, maxGamesPerSync)
+	if err != nil {
+		return err
+	}
+	rows := make([]models.ExternalGame, 0, len(games))
+	newest := cursor.GamesCursor
+	for _, g := range games {
+		rows = append(rows, models.ExternalGame{
+			UserID:         userID,
+			Source:         source,
+			GameID:         g.ID,
+			PlayedAt:       g.PlayedAt,
+			TimeControl:    g.TimeControl,
+			Rated:          g.Rated,
+			Color:          g.Color,
+			Result:         g.Result,
+			OpponentName:   g.OpponentName,
+			OpponentRating: g.OpponentRating,
+			URL:            g.URL,
+			CreatedAt:      now,
+		})
+		if newest == nil || g.PlayedAt.After(*newest) {
+			played := g.PlayedAt
+			newest = &played
+		}
+	}
+	if err := rs.store.SaveExternalGames(ctx, rows); err != nil {
+		return err
+	}
+	// only advance the cursor once the games are stored
+	cursor.GamesCursor = newest
+	return nil
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"github.com/madhava-poojari/dashboard-api/internal/models"
 	"github.com/madhava-poojari/dashboard-api/internal/store"
@@ -75,7 +74,6 @@ func (rs *ReferralService) UpdateRelationship(ctx context.Context, relationshipI
 	// Validate relationship exists
 	_, err := rs.store.GetReferralRelationship(ctx, relationshipID)
 	if err != nil {
-		fmt.Println("relationship not found")
 		return err
 	}
 
@@ -121,6 +119,11 @@ func (rs *ReferralService) GetNetworkGraph(ctx context.Context, stateFilter stri
 	return rs.store.GetFullReferralGraph(ctx, stateFilter)
 }
 
+// GetNetworkGraphPage fetches one page of nodes with the edges touching them
+func (rs *ReferralService) GetNetworkGraphPage(ctx context.Context, stateFilter string, lq store.ListQuery) (map[string]interface{}, error) {
+	return rs.store.GetReferralGraphPage(ctx, stateFilter, lq)
+}
+
 // GetNodeDetail fetches all details about a specific node for hover/popover
 func (rs *ReferralService) GetNodeDetail(ctx context.Context, userID string) (map[string]interface{}, error) {
 	return rs.store.GetNodeDetail(ctx, userID)
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+const EventClassReminder = "class.reminder"
+
+// ReminderService sends each student and the class's coach a reminder
+// lead before every weekly class in ClassSchedule.
+type ReminderService struct {
+	store         *store.Store
+	notifications *NotificationService
+	lead          time.Duration
+	now           func() time.Time
+}
+
+func NewReminderService(s *store.Store, ns *NotificationService, lead time.Duration) *ReminderService {
+	return &ReminderService{store: s, notifications: ns, lead: lead, now: time.Now}
+}
+
+// SendDue sends reminders for classes starting within the lead time that
+// haven't had one yet. A run after downtime still catches classes that
+// haven't started; ones already under way are left alone.
+func (rs *ReminderService) SendDue(ctx context.Context) error {
+	now := rs.now()
+	schedules, err := rs.store.ListRemindableSchedules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, cs := range schedules {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		loc, err := time.LoadLocation(cs.Timezone)
+		if err != nil {
+			log.Printf("[reminders] schedule %d: bad timezone %q", cs.ID, cs.Timezone)
+			continue
+		}
+		startsAt, ok := NextClassOccurrence(cs, loc, now)
+		if !ok || startsAt.Sub(now) > rs.lead {
+			continue
+		}
+		if err := rs.remind(ctx, cs, startsAt.In(loc)); err != nil {
+			log.Printf("[reminders] schedule %d at %s: %v", cs.ID, startsAt.Format(time.RFC3339), err)
+		}
+	}
+	return nil
+}
+
+func (rs *ReminderService) remind(ctx context.Context, cs *models.ClassSchedule, startsAt time.Time) error {
+	skipped, err := rs.store.IsClassSkipped(ctx, cs.ID, startsAt)
+	if err != nil || skipped {
+		return err
+	}
+	homework, err := rs.store.LastClassHomework(ctx, cs.StudentID, startsAt)
+	if err != nil {
+		return err
+	}
+	var meetLink string
+	recipients := []string{cs.StudentID}
+	if cs.CoachID != "" {
+		recipients = append(recipients, cs.CoachID)
+		if coach, err := rs.store.GetUserByID(ctx, cs.CoachID); err == nil {
+			meetLink = coach.UserDetails.PersonalMeetLink
+		}
+	}
+
+	for _, uid := range recipients {
+		u, err := rs.store.GetUserByID(ctx, uid)
+		if err != nil || !u.Active {
+			continue
+		}
+		claimed, err := rs.store.ClaimClassReminder(ctx, cs.ID, startsAt, uid)
+		if err != nil || !claimed {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		n := &models.Notification{
+			UserID: uid,
+			Type:   EventClassReminder,
+			Title:  rs.title(ctx, cs, u, startsAt),
+			Body:   reminderBody(startsAt, meetLink, homework),
+			Data: map[string]interface{}{
+				"schedule_id": cs.ID,
+				"student_id":  cs.StudentID,
+				"starts_at":   startsAt.Format(time.RFC3339),
+				"meet_link":   meetLink,
+				"homework":    homework,
+			},
+			CreatedAt: time.Now(),
+		}
+		if err := rs.notifications.Deliver(ctx, u, n); err != nil {
+			log.Printf("[reminders] deliver to %s: %v", uid, err)
+		}
+	}
+	return nil
+}
+
+func (rs *ReminderService) title(ctx context.Context, cs *models.ClassSchedule, to *models.User, startsAt time.Time) string {
+	when := startsAt.Format("Mon Jan 2, 3:04 PM MST")
+	if to.ID == cs.StudentID {
+		return "Class reminder: " + when
+	}
+	return fmt.Sprintf("Class with %s: %s", rs.notifications.userName(ctx, cs.StudentID), when)
+}
+
+func reminderBody(startsAt time.Time, meetLink, homework string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Class starts at %s.", startsAt.Format("3:04 PM MST on Monday, January 2"))
+	if meetLink != "" {
+		fmt.Fprintf(&b, "\nJoin: %s", meetLink)
+	}
+	if homework != "" {
+		fmt.Fprintf(&b, "\nHomework from last class: %s", homework)
+	}
+	return b.String()
+}
+
+// NextClassOccurrence returns the first start of cs at or after from. The
+// date walk and wall-clock time are done in loc, so a 17:00 class stays at
+// 17:00 local across DST changes. A start inside a spring-forward gap
+// comes out shifted by the gap (02:30 becomes 03:30); an ambiguous
+// fall-back time resolves to its first instance.
+func NextClassOccurrence(cs *models.ClassSchedule, loc *time.Location, from time.Time) (time.Time, bool) {
+	hh, mm, ok := parseClock(cs.StartTime)
+	if !ok || cs.DayOfWeek < 0 || cs.DayOfWeek > 6 {
+		return time.Time{}, false
+	}
+	local := from.In(loc)
+	y, m, d := local.Date()
+	for i := 0; i <= 7; i++ {
+		day := time.Date(y, m, d+i, 12, 0, 0, 0, loc)
+		if int(day.Weekday()) != cs.DayOfWeek {
+			continue
+		}
+		start := wallClock(day.Year(), day.Month(), day.Day(), hh, mm, loc)
+		if !start.Before(from) {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// wallClock is time.Date for a wall-clock minute, but a time that doesn't
+// exist (spring-forward gap) moves forward by the gap instead of being left
+// to time.Date's unspecified choice.
+func wallClock(y int, m time.Month, d, hh, mm int, loc *time.Location) time.Time {
+	t := time.Date(y, m, d, hh, mm, 0, 0, loc)
+	if t.Hour() == hh && t.Minute() == mm {
+		return t
+	}
+	_, offBefore := t.Add(-6 * time.Hour).Zone()
+	return time.Date(y, m, d, hh, mm, 0, 0, time.UTC).Add(-time.Duration(offBefore) * time.Second).In(loc)
+}
+
+// parseClock reads "HH:MM" or "HH:MM:SS".
+func parseClock(v string) (int, int, bool) {
+	parts := strings.Split(v, ":")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	h, err1 := strconv.Atoi(parts[0])
+	m, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, 0, false
+	}
+	return h, m, true
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("no tzdata for %s: %v", name, err)
+	}
+	return loc
+}
+
+func utc(y int, m time.Month, d, hh, mm int) time.Time {
+	return time.Date(y, m, d, hh, mm, 0, 0, time.UTC)
+}
+
+func TestWallClock(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	london := mustLoad(t, "Europe/London")
+	tests := []struct {
+		name   string
+		loc    *time.Location
+		y      int
+		m      time.Month
+		d      int
+		hh, mm int
+		want   time.Time
+	}{
+		{"ordinary", ny, 2026, time.March, 2, 17, 0, utc(2026, time.March, 2, 22, 0)},
+		// Clocks jump 02:00 -> 03:00; 02:30 doesn't exist and moves forward an hour.
+		{"spring-forward gap", ny, 2026, time.March, 8, 2, 30, utc(2026, time.March, 8, 7, 30)},
+		{"gap start", ny, 2026, time.March, 8, 2, 0, utc(2026, time.March, 8, 7, 0)},
+		{"just after gap", ny, 2026, time.March, 8, 3, 0, utc(2026, time.March, 8, 7, 0)},
+		{"london gap", london, 2026, time.March, 29, 1, 30, utc(2026, time.March, 29, 1, 30)},
+		// Clocks fall 02:00 -> 01:00; 01:30 happens twice and the first (EDT) wins.
+		{"fall-back overlap", ny, 2026, time.November, 1, 1, 30, utc(2026, time.November, 1, 5, 30)},
+		{"after overlap", ny, 2026, time.November, 1, 2, 30, utc(2026, time.November, 1, 7, 30)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := wallClock(tc.y, tc.m, tc.d, tc.hh, tc.mm, tc.loc)
+			if !got.Equal(tc.want) {
+				t.Errorf("wallClock = %s (%s), want %s", got.UTC(), got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNextClassOccurrenceDST(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	class := func(day time.Weekday, start string) *models.ClassSchedule {
+		return &models.ClassSchedule{DayOfWeek: int(day), StartTime: start, Timezone: "America/New_York"}
+	}
+	tests := []struct {
+		name string
+		cs   *models.ClassSchedule
+		from time.Time
+		want time.Time
+	}{
+		// 17:00 local stays 17:00 local on both sides of the change.
+		{"before spring forward", class(time.Monday, "17:00"), utc(2026, time.February, 28, 12, 0), utc(2026, time.March, 2, 22, 0)},
+		{"after spring forward", class(time.Monday, "17:00"), utc(2026, time.March, 3, 12, 0), utc(2026, time.March, 9, 21, 0)},
+		{"before fall back", class(time.Monday, "17:00"), utc(2026, time.October, 27, 12, 0), utc(2026, time.November, 2, 22, 0)},
+		{"in the gap", class(time.Sunday, "02:30"), utc(2026, time.March, 7, 12, 0), utc(2026, time.March, 8, 7, 30)},
+		{"in the overlap", class(time.Sunday, "01:30"), utc(2026, time.October, 31, 12, 0), utc(2026, time.November, 1, 5, 30)},
+		// Past the first 01:30 the class doesn't fire again at the repeated
+		// 01:30 EST; the next one is a week on.
+		{"between overlap instances", class(time.Sunday, "01:30:00"), utc(2026, time.November, 1, 5, 45), utc(2026, time.November, 8, 6, 30)},
+		{"at the start", class(time.Monday, "17:00"), utc(2026, time.March, 9, 21, 0), utc(2026, time.March, 9, 21, 0)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := NextClassOccurrence(tc.cs, ny, tc.from)
+			if !ok {
+				t.Fatal("no occurrence")
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("next = %s (%s), want %s", got.UTC(), got.In(ny), tc.want)
+			}
+		})
+	}
+
+	for _, cs := range []*models.ClassSchedule{class(time.Monday, "25:00"), class(time.Monday, "noon"), {DayOfWeek: 7, StartTime: "17:00"}} {
+		if got, ok := NextClassOccurrence(cs, ny, utc(2026, time.March, 1, 0, 0)); ok {
+			t.Errorf("%+v: got %s, want no occurrence", cs, got)
+		}
+	}
+}
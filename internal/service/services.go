@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/config"
+	"github.com/madhava-poojari/dashboard-api/internal/jobs"
+	"github.com/madhava-poojari/dashboard-api/internal/messaging"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+)
+
+// Services bundles the long-lived services shared by the HTTP API and the
+// background workers started from main.
+type Services struct {
+	RatingSync *RatingSyncService
+	Geo        *GeoService
+	Ingest     *TournamentIngestService
+
+	Events        *EventBus
+	Notifications *NotificationService
+	Reminders     *ReminderService
+	Messaging     *MessagingService
+	Webhooks      *WebhookService
+	Outbox        *OutboxDispatcher
+	Jobs          *jobs.Scheduler
+	Digest        *DigestService
+
+	// Images holds profile pictures and the gallery; Uploads holds note
+	// attachments.
+	Images  utils.Storage
+	Uploads utils.Storage
+
+	workers sync.WaitGroup
+}
+
+func NewServices(cfg *config.Config, s *store.Store) *Services {
+	// Without WhatsApp credentials messages are only logged. Outside
+	// development the log provider's status callbacks need
+	// WHATSAPP_APP_SECRET as a shared secret, or are refused.
+	var provider messaging.Provider = messaging.NewFake(cfg.WhatsAppAppSecret, cfg.Development())
+	if cfg.WhatsAppAccessToken != "" {
+		provider = messaging.NewWhatsAppCloud(nil, cfg.WhatsAppAPIBaseURL, cfg.WhatsAppPhoneNumberID, cfg.WhatsAppAccessToken, cfg.WhatsAppAppSecret)
+	}
+	msgs := NewMessagingService(s, provider, cfg.WhatsAppTemplateLanguage, cfg.PhoneDefaultCountry)
+
+	senders := []Sender{NewInAppSender(s), NewWebhookSender(nil), NewWhatsAppSender(msgs)}
+	var mailer *SMTPSender
+	if cfg.SMTPHost != "" {
+		mailer = NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+		senders = append(senders, mailer)
+	}
+	bus := NewEventBus()
+	notifications := NewNotificationService(s, senders...)
+	notifications.Subscribe(bus)
+	webhooks := NewWebhookService(s, nil)
+	webhooks.Subscribe(bus)
+
+	images := utils.Storage(utils.NewR2Storage(cfg.R2AccessKeyID, cfg.R2SecretAccessKey, cfg.R2Endpoint, cfg.R2BucketName))
+	uploads := newUploadStorage(cfg)
+	outbox := NewOutboxDispatcher(s)
+	ratingSync := NewRatingSyncService(s, cfg, nil)
+	outbox.Handle(store.OutboxEvent, bus.OutboxHandler())
+	outbox.Handle(store.OutboxRatingSync, ratingSync.OutboxHandler())
+	outbox.Handle(store.OutboxStorageDelete, StorageDeleteHandler(map[string]utils.Storage{
+		store.StorageImages:  images,
+		store.StorageUploads: uploads,
+	}))
+	loc, err := time.LoadLocation(cfg.JobsTimezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	digest := NewDigestService(s, mailer, loc)
+	reminders := NewReminderService(s, notifications, cfg.ClassReminderLead)
+	scheduler := jobs.NewScheduler(s, loc)
+	registerJobs(scheduler, s, digest)
+	registerWorkerJobs(scheduler, ratingSync, cfg.RatingSyncInterval, reminders, cfg.ClassReminderLead)
+	return &Services{
+		RatingSync:    ratingSync,
+		Geo:           NewGeoService(s),
+		Ingest:        NewTournamentIngestService(s),
+		Events:        bus,
+		Notifications: notifications,
+		Reminders:     reminders,
+		Messaging:     msgs,
+		Webhooks:      webhooks,
+		Outbox:        outbox,
+		Jobs:          scheduler,
+		Digest:        digest,
+		Images:        images,
+		Uploads:       uploads,
+	}
+}
+
+// newUploadStorage returns R2 when it is configured, else local disk under
+// UploadDir (development).
+func newUploadStorage(cfg *config.Config) utils.Storage {
+	if cfg.R2Endpoint != "" && cfg.R2BucketName != "" {
+		return utils.NewR2Storage(cfg.R2AccessKeyID, cfg.R2SecretAccessKey, cfg.R2Endpoint, cfg.R2BucketName)
+	}
+	return utils.NewFileStorage(cfg.UploadDir)
+}
+
+// StartWorkers launches the background loops; they stop when ctx is cancelled.
+// It fails if the ZIP centroid dataset is neither loaded nor on disk.
+//
+// Periodic work runs as scheduler jobs, once per due run across replicas.
+// The outbox and webhook senders poll too often for that, so each runs on
+// whichever replica holds its lock; their claims keep a brief overlap safe.
+func (sv *Services) StartWorkers(ctx context.Context, cfg *config.Config) error {
+	if err := sv.Geo.CheckCentroids(ctx, cfg.ZipCentroidsFile); err != nil {
+		return err
+	}
+	sv.goWorker(func() { sv.Geo.LoadCentroidsIfEmpty(ctx, cfg.ZipCentroidsFile) })
+	sv.goWorker(func() {
+		sv.Jobs.RunExclusive(ctx, "outbox.dispatch", 15*time.Second, func(ctx context.Context) { sv.Outbox.Run(ctx, 5*time.Second) })
+	})
+	sv.goWorker(func() {
+		sv.Jobs.RunExclusive(ctx, "webhooks.send", 15*time.Second, func(ctx context.Context) { sv.Webhooks.Run(ctx, 15*time.Second) })
+	})
+	if cfg.JobsEnabled {
+		sv.goWorker(func() { sv.Jobs.Run(ctx, 15*time.Second) })
+	}
+	return nil
+}
+
+// goWorker runs fn in the background, tracked by Wait.
+func (sv *Services) goWorker(fn func()) {
+	sv.workers.Add(1)
+	go func() {
+		defer sv.workers.Done()
+		fn()
+	}()
+}
+
+// Wait blocks until background work started by StartWorkers has wound down
+// after its context was cancelled, or ctx is done.
+func (sv *Services) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		sv.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return sv.Jobs.Wait(ctx)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+	"github.com/madhava-poojari/dashboard-api/internal/tournamentparse"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+	"gorm.io/datatypes"
+)
+
+// minApplyConfidence is the lowest field confidence written to a tournament.
+// Lower-scored fields stay in the stored report only.
+const minApplyConfidence = 0.5
+
+// TournamentIngestService parses raw event pages and applies the trusted
+// fields to the tournament they describe.
+type TournamentIngestService struct {
+	store *store.Store
+}
+
+func NewTournamentIngestService(s *store.Store) *TournamentIngestService {
+	return &TournamentIngestService{store: s}
+}
+
+// IngestResult is returned to the scraper for every submitted page.
+type IngestResult struct {
+	PageID       uint                        `json:"page_id"`
+	TournamentID *uint                       `json:"tournament_id"`
+	Applied      bool                        `json:"applied"`
+	ApplyError   string                      `json:"apply_error,omitempty"`
+	Parsed       *tournamentparse.Tournament `json:"parsed"`
+	Report       *tournamentparse.Report     `json:"report"`
+}
+
+// IngestPage parses html, stores the page and its report, and, when the
+// parse is valid, updates (or creates) the tournament at urlPath.
+func (ti *TournamentIngestService) IngestPage(ctx context.Context, urlPath, html string, capturedAt time.Time, keyID *uint) (*IngestResult, error) {
+	parsed, rep, err := tournamentparse.Parse(strings.NewReader(html), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	res := &IngestResult{Parsed: parsed, Report: rep}
+
+	if rep.Valid {
+		id, err := ti.store.ApplyTournamentDetails(ctx, urlPath, detailUpdates(parsed, rep), keyID)
+		if err != nil {
+			res.ApplyError = err.Error()
+		} else {
+			res.TournamentID = &id
+			res.Applied = true
+		}
+	}
+
+	sum := sha256.Sum256([]byte(html))
+	parsedJSON, _ := json.Marshal(parsed)
+	reportJSON, _ := json.Marshal(rep)
+	page := &models.TournamentPage{
+		URLPath:       urlPath,
+		TournamentID:  res.TournamentID,
+		HTML:          html,
+		ContentHash:   hex.EncodeToString(sum[:]),
+		ParserVersion: rep.ParserVersion,
+		Parsed:        parsedJSON,
+		Report:        reportJSON,
+		Valid:         rep.Valid,
+		Applied:       res.Applied,
+		APIKeyID:      keyID,
+		CapturedAt:    capturedAt,
+	}
+	if err := ti.store.SaveTournamentPage(ctx, page); err != nil {
+		return nil, err
+	}
+	res.PageID = page.ID
+	return res, nil
+}
+
+// detailUpdates maps the confident parsed fields to tournament columns.
+func detailUpdates(t *tournamentparse.Tournament, rep *tournamentparse.Report) map[string]interface{} {
+	ok := func(field string) bool { return rep.Fields[field].Score >= minApplyConfidence }
+	u := map[string]interface{}{}
+
+	if ok(tournamentparse.FieldTitle) && t.Title != "" {
+		u["title"] = t.Title
+	}
+	if ok(tournamentparse.FieldDates) && t.StartDate != nil {
+		u["start_date"] = *t.StartDate
+		if t.EndDate != nil {
+			u["end_date"] = *t.EndDate
+		}
+		if t.DatesText != "" {
+			u["dates"] = t.DatesText
+		}
+	}
+	if ok(tournamentparse.FieldEntryFee) && t.EntryFeeCents != nil {
+		u["entry_fee_cents"] = *t.EntryFeeCents
+	}
+	if ok(tournamentparse.FieldTimeControl) && t.TimeControl != "" {
+		u["time_control"] = t.TimeControl
+	}
+	if ok(tournamentparse.FieldSections) && len(t.Sections) > 0 {
+		u["sections"] = utils.DatatypesJSONFromStrings(t.Sections)
+	}
+	if ok(tournamentparse.FieldRatings) && t.RatingRequirements != nil {
+		b, _ := json.Marshal(t.RatingRequirements)
+		u["rating_requirements"] = datatypes.JSON(b)
+	}
+	if ok(tournamentparse.FieldVenueAddress) && t.VenueAddress != "" {
+		u["venue_address"] = t.VenueAddress
+		if t.City != "" && t.State != "" {
+			u["city"] = t.City
+			u["state"] = t.State
+		}
+		if t.Zipcode != "" {
+			u["zipcode"] = t.Zipcode
+		}
+	}
+	if ok(tournamentparse.FieldOrganizer) && t.Organizer != "" {
+		u["organizer"] = t.Organizer
+	}
+	return u
+}
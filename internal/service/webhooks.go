@@ -0,0 +1,202 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/store"
+)
+
+// WebhookEvents are the event types outbound webhooks can subscribe to.
+var WebhookEvents = []string{
+	EventUserApproved,
+	EventUserAssigned,
+	EventAttendanceCreated,
+	EventAttendanceVerified,
+	EventAttendanceRejected,
+	EventNoteCreated,
+	EventTournamentNew,
+}
+
+const (
+	webhookMaxAttempts = 8
+	webhookBatchSize   = 20
+	webhookLease       = 2 * time.Minute // longer than the client timeout
+)
+
+// WebhookService delivers events to admin-configured endpoints. Handling an
+// event only queues a delivery per subscribed endpoint; Run sends them and
+// retries failures with exponential backoff, so a slow or broken endpoint
+// never holds up the request that raised the event.
+type WebhookService struct {
+	store  *store.Store
+	client *http.Client
+}
+
+func NewWebhookService(s *store.Store, client *http.Client) *WebhookService {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &WebhookService{store: s, client: client}
+}
+
+// Subscribe queues deliveries for every webhook event published on bus.
+func (ws *WebhookService) Subscribe(bus *EventBus) {
+	for _, t := range WebhookEvents {
+		bus.Subscribe(t, ws.HandleEvent)
+	}
+}
+
+// WebhookPayload is the JSON body POSTed to endpoints.
+type WebhookPayload struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	ActorID    string                 `json:"actor_id,omitempty"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// HandleEvent queues a delivery of e for each subscribed endpoint. An
+// endpoint that already has e queued is skipped, so a redelivered event is
+// sent once.
+func (ws *WebhookService) HandleEvent(ctx context.Context, e Event) error {
+	endpoints, err := ws.store.WebhookEndpointsFor(ctx, e.Type)
+	if err != nil {
+		return fmt.Errorf("webhooks: load endpoints: %w", err)
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(WebhookPayload{ID: e.ID, Type: e.Type, OccurredAt: e.OccurredAt, ActorID: e.ActorID, Data: e.Data})
+	if err != nil {
+		return fmt.Errorf("webhooks: encode payload: %w", err)
+	}
+	ds := make([]models.WebhookDelivery, 0, len(endpoints))
+	for _, ep := range endpoints {
+		ds = append(ds, models.WebhookDelivery{EndpointID: ep.ID, EventID: e.ID, EventType: e.Type, Payload: body})
+	}
+	if err := ws.store.CreateWebhookDeliveries(ctx, ds); err != nil {
+		return fmt.Errorf("webhooks: queue deliveries: %w", err)
+	}
+	return nil
+}
+
+// Run sends due deliveries every interval until ctx is cancelled.
+func (ws *WebhookService) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		// A full batch means more are waiting; keep going until it drains.
+		for ctx.Err() == nil && ws.SendDue(ctx) == webhookBatchSize {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// SendDue claims and attempts one batch of deliveries. It returns how many
+// were attempted.
+func (ws *WebhookService) SendDue(ctx context.Context) int {
+	ds, err := ws.store.ClaimWebhookDeliveries(ctx, webhookBatchSize, webhookLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("[webhooks] claim deliveries: %v", err)
+		}
+		return 0
+	}
+	endpoints := map[uint]*models.WebhookEndpoint{}
+	for i := range ds {
+		d := &ds[i]
+		ep, ok := endpoints[d.EndpointID]
+		if !ok {
+			if ep, err = ws.store.GetWebhookEndpoint(ctx, d.EndpointID); err != nil {
+				log.Printf("[webhooks] delivery %d: load endpoint: %v", d.ID, err)
+				continue
+			}
+			endpoints[d.EndpointID] = ep
+		}
+		code, err := ws.post(ctx, ep, d)
+		var retryAt *time.Time
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+			if d.Attempts < webhookMaxAttempts && ep.Active {
+				next := time.Now().Add(WebhookBackoff(d.Attempts))
+				retryAt = &next
+			} else {
+				log.Printf("[webhooks] delivery %d to %s failed for good after %d attempts: %v", d.ID, ep.URL, d.Attempts, err)
+			}
+		}
+		if err := ws.store.FinishWebhookDelivery(ctx, d.ID, code, errMsg, retryAt); err != nil {
+			log.Printf("[webhooks] delivery %d: record result: %v", d.ID, err)
+		}
+	}
+	return len(ds)
+}
+
+// WebhookBackoff is the wait after the given failed attempt: 30s doubling
+// each time, capped at six hours.
+func WebhookBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 16 {
+		attempt = 16
+	}
+	d := 30 * time.Second << (attempt - 1)
+	if d > 6*time.Hour {
+		d = 6 * time.Hour
+	}
+	return d
+}
+
+// SignWebhook returns the X-Webhook-Signature value for body sent at ts:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Including the
+// timestamp lets receivers reject old, replayed requests.
+func SignWebhook(secret string, ts time.Time, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (ws *WebhookService) post(ctx context.Context, ep *models.WebhookEndpoint, d *models.WebhookDelivery) (int, error) {
+	if !ep.Active {
+		return 0, fmt.Errorf("endpoint is disabled")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "brschess-dashboard-webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set("X-Webhook-Id", d.EventID)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set("X-Webhook-Signature", SignWebhook(ep.Secret, time.Now(), d.Payload))
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s: %s", resp.Status, bytes.TrimSpace(snippet))
+	}
+	return resp.StatusCode, nil
+}
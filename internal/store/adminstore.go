@@ -24,7 +24,20 @@ func (s *Store) AddCoachStudent(ctx context.Context, coachID, studentID, mentorI
 }
 
 func (s *Store) RemoveCoachStudent(ctx context.Context, coachID, studentID string) error {
-	return s.DB.WithContext(ctx).Where("coach_id = ? AND user_id = ?", coachID, studentID).Delete(&models.Relation{}).Error
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("coach_id = ? AND user_id = ?", coachID, studentID).Delete(&models.Relation{}).Error; err != nil {
+			return err
+		}
+		return moveScheduleCoachTx(tx, studentID, coachID, "")
+	})
+}
+
+// moveScheduleCoachTx hands the student's classes taught by from to to, or
+// leaves them without a coach when to is empty.
+func moveScheduleCoachTx(tx *gorm.DB, studentID, from, to string) error {
+	return tx.Model(&models.ClassSchedule{}).
+		Where("student_id = ? AND coach_id = ?", studentID, from).
+		Update("coach_id", to).Error
 }
 
 // ListUnapprovedUsers returns users that are not approved, sorted by newest first
@@ -192,7 +205,12 @@ func (s *Store) ListCoachesWithAssignments(ctx context.Context) ([]*CoachWithAss
 	if err := s.DB.WithContext(ctx).Find(&relations).Error; err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
+	return coachAssignments(coaches, relations), nil
+}
 
+// coachAssignments expands coaches into one row per assigned student,
+// unassigned coaches first.
+func coachAssignments(coaches []models.User, relations []models.Relation) []*CoachWithAssignment {
 	assignmentMap := make(map[string][]struct {
 		StudentID     string
 		IsMentor      bool
@@ -265,7 +283,7 @@ func (s *Store) ListCoachesWithAssignments(ctx context.Context) ([]*CoachWithAss
 	result = append(result, unassigned...)
 	result = append(result, assigned...)
 
-	return result, nil
+	return result
 }
 
 // GetPendingApprovals is an alias for ListUnapprovedUsers (for backward compatibility)
@@ -356,7 +374,10 @@ func (s *Store) SetStudentCoachAssignment(ctx context.Context, studentID, coachI
 		}
 		if coachID == "" {
 			if found {
-				return tx.Where("coach_id = ? AND user_id = ?", existing.CoachID, studentID).Delete(&models.Relation{}).Error
+				if err := tx.Where("coach_id = ? AND user_id = ?", existing.CoachID, studentID).Delete(&models.Relation{}).Error; err != nil {
+					return err
+				}
+				return moveScheduleCoachTx(tx, studentID, existing.CoachID, "")
 			}
 			return nil
 		}
@@ -371,6 +392,9 @@ func (s *Store) SetStudentCoachAssignment(ctx context.Context, studentID, coachI
 			if err := tx.Where("coach_id = ? AND user_id = ?", existing.CoachID, studentID).Delete(&models.Relation{}).Error; err != nil {
 				return err
 			}
+			if err := moveScheduleCoachTx(tx, studentID, existing.CoachID, coachID); err != nil {
+				return err
+			}
 		}
 		if err := tx.Create(&models.Relation{CoachID: coachID, UserID: studentID, MentorID: defaultMentor}).Error; err != nil {
 			return err
@@ -430,3 +454,98 @@ func (s *Store) SetCoachMentorAssignment(ctx context.Context, coachID, mentorCoa
 		return err
 	})
 }
+
+func withFilters(base map[string]Column, extra map[string]Column) map[string]Column {
+	out := make(map[string]Column, len(base)+len(extra))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range extra {
+		out[k] = v
+	}
+	return out
+}
+
+// StudentAssignmentListSpec covers GET /admin/students. assigned=false
+// replaces the "unassigned first" order of the full list.
+var StudentAssignmentListSpec = ListSpec{
+	Sorts: userSorts,
+	Filters: withFilters(userFilters, map[string]Column{
+		"assigned": {Expr: "EXISTS (SELECT 1 FROM relations r WHERE r.user_id = users.id AND r.coach_id != '')", Kind: KindBool},
+	}),
+	DefaultSort: []SortField{{Field: "created_at", Desc: true}},
+	Key:         Column{Expr: "users.id", Kind: KindString},
+	Preload:     []string{"UserDetails"},
+}
+
+// ListStudentsWithAssignmentsPage is the paged form of ListStudentsWithAssignments.
+func (s *Store) ListStudentsWithAssignmentsPage(ctx context.Context, lq ListQuery) (*Page[*StudentWithAssignment], error) {
+	q := s.DB.WithContext(ctx).Model(&models.User{}).Where("users.role = ?", models.RoleStudent)
+	users, err := Paginate[models.User](q, StudentAssignmentListSpec, lq)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(users.Items))
+	for i, u := range users.Items {
+		ids[i] = u.ID
+	}
+	var relations []models.Relation
+	if len(ids) > 0 {
+		if err := s.DB.WithContext(ctx).Where("user_id IN ? AND coach_id != ''", ids).Find(&relations).Error; err != nil {
+			return nil, err
+		}
+	}
+	byStudent := make(map[string]models.Relation, len(relations))
+	for _, r := range relations {
+		byStudent[r.UserID] = r
+	}
+
+	page := &Page[*StudentWithAssignment]{Items: make([]*StudentWithAssignment, 0, len(users.Items)), NextCursor: users.NextCursor, Total: users.Total}
+	for i := range users.Items {
+		swa := &StudentWithAssignment{User: &users.Items[i]}
+		if r, ok := byStudent[users.Items[i].ID]; ok {
+			coachID := r.CoachID
+			swa.CoachID = &coachID
+			if r.MentorID != "" {
+				mentorID := r.MentorID
+				swa.MentorCoachID = &mentorID
+			}
+		}
+		page.Items = append(page.Items, swa)
+	}
+	return page, nil
+}
+
+// CoachAssignmentListSpec covers GET /admin/coaches. Pages count coaches;
+// each coach still expands to one row per assigned student.
+var CoachAssignmentListSpec = ListSpec{
+	Sorts:       userSorts,
+	Filters:     userFilters,
+	DefaultSort: []SortField{{Field: "created_at", Desc: true}},
+	Key:         Column{Expr: "users.id", Kind: KindString},
+	Preload:     []string{"UserDetails"},
+}
+
+// ListCoachesWithAssignmentsPage is the paged form of ListCoachesWithAssignments.
+func (s *Store) ListCoachesWithAssignmentsPage(ctx context.Context, lq ListQuery) (*Page[*CoachWithAssignment], error) {
+	q := s.DB.WithContext(ctx).Model(&models.User{}).Where("users.role IN ?", []models.Role{models.RoleCoach, models.RoleMentor})
+	coaches, err := Paginate[models.User](q, CoachAssignmentListSpec, lq)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(coaches.Items))
+	for i, c := range coaches.Items {
+		ids[i] = c.ID
+	}
+	var relations []models.Relation
+	if len(ids) > 0 {
+		if err := s.DB.WithContext(ctx).Where("coach_id IN ? OR mentor_id IN ?", ids, ids).Find(&relations).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &Page[*CoachWithAssignment]{
+		Items:      coachAssignments(coaches.Items, relations),
+		NextCursor: coaches.NextCursor,
+		Total:      coaches.Total,
+	}, nil
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAPIKeyInvalid = errors.New("invalid api key")
+	ErrAPIKeyExpired = errors.New("api key expired")
+	ErrAPIKeyRevoked = errors.New("api key revoked")
+)
+
+// apiKeyTouchInterval limits last-used writes to one per key per interval.
+const apiKeyTouchInterval = time.Minute
+
+// CreateAPIKey stores a new key; only the hash of plaintext is kept.
+func (s *Store) CreateAPIKey(ctx context.Context, k *models.APIKey, plaintext string) error {
+	k.KeyHash = hashTokenPlain(plaintext)
+	return s.DB.WithContext(ctx).Create(k).Error
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var out []models.APIKey
+	err := s.DB.WithContext(ctx).Order("created_at desc").Find(&out).Error
+	return out, err
+}
+
+func (s *Store) GetAPIKey(ctx context.Context, id uint) (*models.APIKey, error) {
+	var k models.APIKey
+	if err := s.DB.WithContext(ctx).First(&k, id).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// UpdateAPIKey applies name/scopes/expiry changes.
+func (s *Store) UpdateAPIKey(ctx context.Context, id uint, fields map[string]interface{}) (*models.APIKey, error) {
+	fields["updated_at"] = time.Now()
+	res := s.DB.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Updates(fields)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.GetAPIKey(ctx, id)
+}
+
+// RotateAPIKey replaces the secret (and prefix) of a key in place. The old
+// secret keeps working for grace, so clients can be moved to the new one;
+// with no grace it stops working immediately. A secret still in its grace
+// window from an earlier rotation is dropped.
+func (s *Store) RotateAPIKey(ctx context.Context, id uint, prefix, plaintext string, grace time.Duration) (*models.APIKey, error) {
+	now := time.Now()
+	var out *models.APIKey
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var k models.APIKey
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&k, id).Error; err != nil {
+			return err
+		}
+		fields := map[string]interface{}{
+			"prefix":              prefix,
+			"key_hash":            hashTokenPlain(plaintext),
+			"rotated_at":          now,
+			"updated_at":          now,
+			"previous_prefix":     "",
+			"previous_key_hash":   "",
+			"previous_expires_at": nil,
+		}
+		if grace > 0 {
+			fields["previous_prefix"] = k.Prefix
+			fields["previous_key_hash"] = k.KeyHash
+			fields["previous_expires_at"] = now.Add(grace)
+		}
+		if err := tx.Model(&models.APIKey{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+			return err
+		}
+		k = models.APIKey{}
+		if err := tx.First(&k, id).Error; err != nil {
+			return err
+		}
+		out = &k
+		return nil
+	})
+	return out, err
+}
+
+// RevokeAPIKey disables a key; the row is kept for the audit trail.
+func (s *Store) RevokeAPIKey(ctx context.Context, id uint) error {
+	res := s.DB.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPIKey resolves a "<prefix>.<secret>" key. The prefix finds the
+// row and the hash is compared in constant time. The secret a rotation
+// replaced is accepted until its grace window ends.
+func (s *Store) AuthenticateAPIKey(ctx context.Context, plaintext, remoteIP string) (*models.APIKey, error) {
+	prefix, _, ok := strings.Cut(plaintext, ".")
+	if !ok || prefix == "" {
+		return nil, ErrAPIKeyInvalid
+	}
+	var k models.APIKey
+	err := s.DB.WithContext(ctx).Where("prefix = ? OR previous_prefix = ?", prefix, prefix).First(&k).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	hash := []byte(hashTokenPlain(plaintext))
+	if k.Prefix == prefix {
+		if subtle.ConstantTimeCompare(hash, []byte(k.KeyHash)) != 1 {
+			return nil, ErrAPIKeyInvalid
+		}
+	} else {
+		if subtle.ConstantTimeCompare(hash, []byte(k.PreviousKeyHash)) != 1 {
+			return nil, ErrAPIKeyInvalid
+		}
+		if k.PreviousExpiresAt == nil || !now.Before(*k.PreviousExpiresAt) {
+			return nil, ErrAPIKeyExpired
+		}
+	}
+	if k.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+	if k.ExpiresAt != nil && now.After(*k.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= apiKeyTouchInterval || k.LastUsedIP != remoteIP {
+		if err := s.DB.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", k.ID).
+			UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": remoteIP}).Error; err != nil {
+			return nil, err
+		}
+		k.LastUsedAt = &now
+		k.LastUsedIP = remoteIP
+	}
+	return &k, nil
+}
@@ -0,0 +1,379 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidAssessment = errors.New("invalid assessment")
+
+// ValidAssessmentType reports whether t is a known assessment type.
+func ValidAssessmentType(t models.AssessmentType) bool {
+	return t == models.AssessmentTypeStudent || t == models.AssessmentTypeCoach
+}
+
+func preloadCriteria(q *gorm.DB) *gorm.DB {
+	return q.Preload("Criteria", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, id ASC")
+	})
+}
+
+// ListRubrics returns rubrics with their criteria. An empty typ lists every
+// type; inactive rubrics are only included on request.
+func (s *Store) ListRubrics(ctx context.Context, typ models.AssessmentType, includeInactive bool) ([]models.Rubric, error) {
+	q := preloadCriteria(s.DB.WithContext(ctx))
+	if typ != "" {
+		q = q.Where("type = ?", typ)
+	}
+	if !includeInactive {
+		q = q.Where("active = true")
+	}
+	var out []models.Rubric
+	if err := q.Order("name ASC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Store) GetRubric(ctx context.Context, id uint) (*models.Rubric, error) {
+	var r models.Rubric
+	if err := preloadCriteria(s.DB.WithContext(ctx)).First(&r, id).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// CreateRubric creates a rubric with its criteria, numbering them in the
+// given order.
+func (s *Store) CreateRubric(ctx context.Context, r *models.Rubric) error {
+	if !ValidAssessmentType(r.Type) {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidAssessment, r.Type)
+	}
+	if r.MinScore >= r.MaxScore {
+		return fmt.Errorf("%w: min_score must be below max_score", ErrInvalidAssessment)
+	}
+	if len(r.Criteria) == 0 {
+		return fmt.Errorf("%w: a rubric needs at least one criterion", ErrInvalidAssessment)
+	}
+	for i := range r.Criteria {
+		r.Criteria[i].Position = i + 1
+	}
+	return s.DB.WithContext(ctx).Create(r).Error
+}
+
+// UpdateRubric changes a rubric's name, description or active flag. Score
+// ranges and criteria stay fixed so earlier assessments remain comparable.
+func (s *Store) UpdateRubric(ctx context.Context, id uint, updates map[string]interface{}) error {
+	res := s.DB.WithContext(ctx).Model(&models.Rubric{}).Where("id = ?", id).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AddRubricCriterion appends a criterion to a rubric.
+func (s *Store) AddRubricCriterion(ctx context.Context, c *models.RubricCriterion) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.Rubric{}, c.RubricID).Error; err != nil {
+			return err
+		}
+		var max int
+		if err := tx.Model(&models.RubricCriterion{}).Where("rubric_id = ?", c.RubricID).
+			Select("COALESCE(MAX(position), 0)").Scan(&max).Error; err != nil {
+			return err
+		}
+		c.Position = max + 1
+		return tx.Create(c).Error
+	})
+}
+
+// CreateAssessment validates the scores against the rubric and stores the
+// assessment. The rubric must be active and of the assessment's type.
+func (s *Store) CreateAssessment(ctx context.Context, a *models.Assessment) error {
+	rubric, err := s.GetRubric(ctx, a.RubricID)
+	if err != nil {
+		return err
+	}
+	if !rubric.Active {
+		return fmt.Errorf("%w: rubric is inactive", ErrInvalidAssessment)
+	}
+	if a.Type == "" {
+		a.Type = rubric.Type
+	}
+	if a.Type != rubric.Type {
+		return fmt.Errorf("%w: rubric is for %s", ErrInvalidAssessment, rubric.Type)
+	}
+	if len(a.Scores) == 0 {
+		return fmt.Errorf("%w: at least one score is required", ErrInvalidAssessment)
+	}
+	criteria := make(map[uint]bool, len(rubric.Criteria))
+	for _, c := range rubric.Criteria {
+		criteria[c.ID] = true
+	}
+	seen := make(map[uint]bool, len(a.Scores))
+	for _, sc := range a.Scores {
+		if !criteria[sc.CriterionID] {
+			return fmt.Errorf("%w: criterion %d is not part of the rubric", ErrInvalidAssessment, sc.CriterionID)
+		}
+		if seen[sc.CriterionID] {
+			return fmt.Errorf("%w: criterion %d scored twice", ErrInvalidAssessment, sc.CriterionID)
+		}
+		seen[sc.CriterionID] = true
+		if sc.Score < rubric.MinScore || sc.Score > rubric.MaxScore {
+			return fmt.Errorf("%w: scores must be between %d and %d", ErrInvalidAssessment, rubric.MinScore, rubric.MaxScore)
+		}
+	}
+	return s.DB.WithContext(ctx).Create(a).Error
+}
+
+func (s *Store) GetAssessment(ctx context.Context, id uint) (*models.Assessment, error) {
+	var a models.Assessment
+	if err := s.DB.WithContext(ctx).Preload("Scores").First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// AssessmentFilter narrows assessment lists and trends. Zero fields don't
+// filter.
+type AssessmentFilter struct {
+	SubjectID string
+	RubricID  uint
+	From      *time.Time
+	To        *time.Time
+}
+
+func (f AssessmentFilter) apply(q *gorm.DB, alias string) *gorm.DB {
+	if f.SubjectID != "" {
+		q = q.Where(alias+".subject_id = ?", f.SubjectID)
+	}
+	if f.RubricID != 0 {
+		q = q.Where(alias+".rubric_id = ?", f.RubricID)
+	}
+	if f.From != nil {
+		q = q.Where(alias+".assessed_on >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where(alias+".assessed_on <= ?", *f.To)
+	}
+	return q
+}
+
+// ListAssessments returns matching assessments with scores, newest first.
+func (s *Store) ListAssessments(ctx context.Context, f AssessmentFilter) ([]models.Assessment, error) {
+	var out []models.Assessment
+	q := f.apply(s.DB.WithContext(ctx).Preload("Scores"), "assessments")
+	if err := q.Order("assessed_on DESC, id DESC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Store) DeleteAssessment(ctx context.Context, id uint) error {
+	res := s.DB.WithContext(ctx).Delete(&models.Assessment{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TrendPoint is one score (or, for Overall, the mean of one assessment's
+// scores) on a date.
+type TrendPoint struct {
+	AssessmentID uint      `json:"assessment_id"`
+	AssessedOn   time.Time `json:"assessed_on"`
+	Score        float64   `json:"score"`
+}
+
+type CriterionTrend struct {
+	CriterionID uint         `json:"criterion_id"`
+	Name        string       `json:"name"`
+	Points      []TrendPoint `json:"points"`
+	Change      *float64     `json:"change"` // last minus first score
+}
+
+// AssessmentTrends is a subject's score history on one rubric.
+type AssessmentTrends struct {
+	RubricID  uint             `json:"rubric_id"`
+	SubjectID string           `json:"subject_id"`
+	Criteria  []CriterionTrend `json:"criteria"`
+	Overall   []TrendPoint     `json:"overall"`
+}
+
+type scoreRow struct {
+	AssessmentID uint
+	SubjectID    string
+	AssessedOn   time.Time
+	CriterionID  uint
+	Score        int
+}
+
+// GetAssessmentTrends returns per-criterion score series, oldest first.
+func (s *Store) GetAssessmentTrends(ctx context.Context, f AssessmentFilter) (*AssessmentTrends, error) {
+	rubric, err := s.GetRubric(ctx, f.RubricID)
+	if err != nil {
+		return nil, err
+	}
+	var rows []scoreRow
+	q := s.DB.WithContext(ctx).Table("assessment_scores sc").
+		Select("a.id AS assessment_id, a.subject_id, a.assessed_on, sc.criterion_id, sc.score").
+		Joins("JOIN assessments a ON a.id = sc.assessment_id AND a.deleted_at IS NULL")
+	if err := f.apply(q, "a").Order("a.assessed_on ASC, a.id ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	out := &AssessmentTrends{RubricID: rubric.ID, SubjectID: f.SubjectID, Criteria: []CriterionTrend{}, Overall: []TrendPoint{}}
+	idx := make(map[uint]int, len(rubric.Criteria))
+	for _, c := range rubric.Criteria {
+		idx[c.ID] = len(out.Criteria)
+		out.Criteria = append(out.Criteria, CriterionTrend{CriterionID: c.ID, Name: c.Name, Points: []TrendPoint{}})
+	}
+	var sum, n int
+	for i, r := range rows {
+		if ci, ok := idx[r.CriterionID]; ok {
+			out.Criteria[ci].Points = append(out.Criteria[ci].Points, TrendPoint{AssessmentID: r.AssessmentID, AssessedOn: r.AssessedOn, Score: float64(r.Score)})
+		}
+		sum += r.Score
+		n++
+		if i == len(rows)-1 || rows[i+1].AssessmentID != r.AssessmentID {
+			out.Overall = append(out.Overall, TrendPoint{AssessmentID: r.AssessmentID, AssessedOn: r.AssessedOn, Score: float64(sum) / float64(n)})
+			sum, n = 0, 0
+		}
+	}
+	for i := range out.Criteria {
+		if pts := out.Criteria[i].Points; len(pts) > 1 {
+			d := pts[len(pts)-1].Score - pts[0].Score
+			out.Criteria[i].Change = &d
+		}
+	}
+	return out, nil
+}
+
+// CriterionStats summarizes one criterion across a cohort.
+type CriterionStats struct {
+	CriterionID uint    `json:"criterion_id"`
+	Name        string  `json:"name"`
+	Count       int     `json:"count"`
+	Average     float64 `json:"average"`
+	Min         int     `json:"min"`
+	Max         int     `json:"max"`
+}
+
+// CohortMember is one subject's latest assessment in the window. Delta is
+// the member's score minus the cohort average, per criterion ID.
+type CohortMember struct {
+	SubjectID    string           `json:"subject_id"`
+	SubjectName  string           `json:"subject_name"`
+	AssessmentID uint             `json:"assessment_id"`
+	AssessedOn   time.Time        `json:"assessed_on"`
+	Scores       map[uint]int     `json:"scores"`
+	Delta        map[uint]float64 `json:"delta"`
+	Average      float64          `json:"average"`
+}
+
+type CohortComparison struct {
+	RubricID uint             `json:"rubric_id"`
+	Criteria []CriterionStats `json:"criteria"`
+	Members  []CohortMember   `json:"members"`
+}
+
+// CompareCohort compares the latest assessment of each subject on a rubric.
+// With subjectIDs nil every assessed subject is included; an empty slice
+// yields an empty cohort.
+func (s *Store) CompareCohort(ctx context.Context, rubricID uint, subjectIDs []string, from, to *time.Time) (*CohortComparison, error) {
+	rubric, err := s.GetRubric(ctx, rubricID)
+	if err != nil {
+		return nil, err
+	}
+	out := &CohortComparison{RubricID: rubric.ID, Criteria: []CriterionStats{}, Members: []CohortMember{}}
+	for _, c := range rubric.Criteria {
+		out.Criteria = append(out.Criteria, CriterionStats{CriterionID: c.ID, Name: c.Name})
+	}
+	if subjectIDs != nil && len(subjectIDs) == 0 {
+		return out, nil
+	}
+
+	latest := s.DB.WithContext(ctx).Table("assessments a").
+		Select("DISTINCT ON (a.subject_id) a.id").
+		Where("a.deleted_at IS NULL")
+	latest = AssessmentFilter{RubricID: rubricID, From: from, To: to}.apply(latest, "a")
+	if subjectIDs != nil {
+		latest = latest.Where("a.subject_id IN ?", subjectIDs)
+	}
+	latest = latest.Order("a.subject_id, a.assessed_on DESC, a.id DESC")
+
+	var rows []struct {
+		scoreRow
+		SubjectName string
+	}
+	if err := s.DB.WithContext(ctx).Table("assessment_scores sc").
+		Select("a.id AS assessment_id, a.subject_id, a.assessed_on, sc.criterion_id, sc.score, u.first_name || ' ' || u.last_name AS subject_name").
+		Joins("JOIN assessments a ON a.id = sc.assessment_id").
+		Joins("JOIN users u ON u.id = a.subject_id").
+		Where("a.id IN (?)", latest).
+		Order("u.first_name, u.last_name, a.subject_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make(map[uint]*CriterionStats, len(out.Criteria))
+	for i := range out.Criteria {
+		stats[out.Criteria[i].CriterionID] = &out.Criteria[i]
+	}
+	sums := make(map[uint]int)
+	for _, r := range rows {
+		if len(out.Members) == 0 || out.Members[len(out.Members)-1].AssessmentID != r.AssessmentID {
+			out.Members = append(out.Members, CohortMember{
+				SubjectID:    r.SubjectID,
+				SubjectName:  r.SubjectName,
+				AssessmentID: r.AssessmentID,
+				AssessedOn:   r.AssessedOn,
+				Scores:       map[uint]int{},
+				Delta:        map[uint]float64{},
+			})
+		}
+		out.Members[len(out.Members)-1].Scores[r.CriterionID] = r.Score
+		st, ok := stats[r.CriterionID]
+		if !ok {
+			continue
+		}
+		if st.Count == 0 || r.Score < st.Min {
+			st.Min = r.Score
+		}
+		if r.Score > st.Max {
+			st.Max = r.Score
+		}
+		st.Count++
+		sums[r.CriterionID] += r.Score
+	}
+	for id, st := range stats {
+		if st.Count > 0 {
+			st.Average = float64(sums[id]) / float64(st.Count)
+		}
+	}
+	for i := range out.Members {
+		m := &out.Members[i]
+		total := 0
+		for id, score := range m.Scores {
+			total += score
+			if st, ok := stats[id]; ok {
+				m.Delta[id] = float64(score) - st.Average
+			}
+		}
+		m.Average = float64(total) / float64(len(m.Scores))
+	}
+	sort.SliceStable(out.Members, func(i, j int) bool { return out.Members[i].Average > out.Members[j].Average })
+	return out, nil
+}
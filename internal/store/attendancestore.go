@@ -47,9 +47,38 @@ func (s *Store) DeleteAttendanceByID(ctx context.Context, id uint) error {
 }
 
 func (s *Store) ListAttendances(ctx context.Context, f AttendanceListFilter) ([]*models.Attendance, error) {
-	q := s.DB.WithContext(ctx).Model(&models.Attendance{}).
+	var out []*models.Attendance
+	if err := s.attendanceQuery(ctx, f).
 		Preload("Student").Preload("Coach").
-		Where("date >= ? AND date < ?", f.StartDate, f.EndDate)
+		Order("date desc, id desc").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AttendanceListSpec covers GET /attendances; the filters of
+// AttendanceListFilter stay plain query parameters.
+var AttendanceListSpec = ListSpec{
+	Sorts: map[string]Column{
+		"date":       {Expr: "attendances.date", Kind: KindTime},
+		"created_at": {Expr: "attendances.created_at", Kind: KindTime},
+	},
+	DefaultSort: []SortField{{Field: "date", Desc: true}},
+	Key:         Column{Expr: "attendances.id", Kind: KindInt},
+	Preload:     []string{"Student", "Coach"},
+}
+
+// ListAttendancesPage is the paged form of ListAttendances. A zero date range
+// lists all dates.
+func (s *Store) ListAttendancesPage(ctx context.Context, f AttendanceListFilter, lq ListQuery) (*Page[models.Attendance], error) {
+	return Paginate[models.Attendance](s.attendanceQuery(ctx, f), AttendanceListSpec, lq)
+}
+
+func (s *Store) attendanceQuery(ctx context.Context, f AttendanceListFilter) *gorm.DB {
+	q := s.DB.WithContext(ctx).Model(&models.Attendance{})
+	if !f.StartDate.IsZero() {
+		q = q.Where("date >= ? AND date < ?", f.StartDate, f.EndDate)
+	}
 
 	if f.StudentID != nil && *f.StudentID != "" {
 		q = q.Where("student_id = ?", *f.StudentID)
@@ -89,12 +118,7 @@ func (s *Store) ListAttendances(ctx context.Context, f AttendanceListFilter) ([]
 			userID, userID,
 		)
 	}
-
-	var out []*models.Attendance
-	if err := q.Order("date desc, id desc").Find(&out).Error; err != nil {
-		return nil, err
-	}
-	return out, nil
+	return q
 }
 
 func (s *Store) IsMentorOfCoach(ctx context.Context, mentorID string, coachID string) (bool, error) {
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListRemindableSchedules returns the class slots of active, approved
+// students.
+func (s *Store) ListRemindableSchedules(ctx context.Context) ([]*models.ClassSchedule, error) {
+	var out []*models.ClassSchedule
+	err := s.DB.WithContext(ctx).
+		Preload("Student").
+		Joins("JOIN users u ON u.id = class_schedules.student_id AND u.active = ? AND u.approved = ?", true, true).
+		Order("class_schedules.id").
+		Find(&out).Error
+	return out, err
+}
+
+// IsClassSkipped reports whether the schedule's class on the local date is
+// cancelled or falls on a holiday.
+func (s *Store) IsClassSkipped(ctx context.Context, scheduleID uint, date time.Time) (bool, error) {
+	day := date.Format("2006-01-02")
+	var n int64
+	err := s.DB.WithContext(ctx).Raw(`SELECT
+		(SELECT COUNT(*) FROM class_cancellations WHERE schedule_id = ? AND date = ?) +
+		(SELECT COUNT(*) FROM holidays WHERE date = ?)`, scheduleID, day, day).Scan(&n).Error
+	return n > 0, err
+}
+
+// ClaimClassReminder records the reminder for (schedule, occurrence,
+// recipient) and reports whether this caller claimed it. Only the claimer
+// sends, so a reminder goes out at most once however many times or places
+// the scheduler runs.
+func (s *Store) ClaimClassReminder(ctx context.Context, scheduleID uint, occursAt time.Time, recipientID string) (bool, error) {
+	res := s.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ClassReminder{
+		ScheduleID:  scheduleID,
+		OccursAt:    occursAt.UTC(),
+		RecipientID: recipientID,
+		SentAt:      time.Now(),
+	})
+	return res.RowsAffected == 1, res.Error
+}
+
+// LastClassHomework returns the homework from the student's most recent
+// class before the given date, or "" when there is none.
+func (s *Store) LastClassHomework(ctx context.Context, studentID string, before time.Time) (string, error) {
+	var a models.Attendance
+	err := s.DB.WithContext(ctx).
+		Where("student_id = ? AND date < ?", studentID, before.Format("2006-01-02")).
+		Order("date DESC, id DESC").
+		First(&a).Error
+	if IsNotFound(err) {
+		return "", nil
+	}
+	return a.Homework, err
+}
+
+// ListClassCancellations returns the schedule's cancelled dates from the
+// given local date on.
+func (s *Store) ListClassCancellations(ctx context.Context, scheduleID uint, from time.Time) ([]models.ClassCancellation, error) {
+	var out []models.ClassCancellation
+	err := s.DB.WithContext(ctx).
+		Where("schedule_id = ? AND date >= ?", scheduleID, from.Format("2006-01-02")).
+		Order("date").
+		Find(&out).Error
+	return out, err
+}
+
+// CancelClass skips one occurrence; cancelling twice keeps the first.
+func (s *Store) CancelClass(ctx context.Context, c *models.ClassCancellation) error {
+	c.CreatedAt = time.Now()
+	if err := s.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "schedule_id"}, {Name: "date"}}, DoNothing: true}).
+		Create(c).Error; err != nil {
+		return err
+	}
+	return s.DB.WithContext(ctx).Where("schedule_id = ? AND date = ?", c.ScheduleID, c.Date.Format("2006-01-02")).First(c).Error
+}
+
+// UncancelClass restores a cancelled occurrence.
+func (s *Store) UncancelClass(ctx context.Context, scheduleID uint, date time.Time) error {
+	res := s.DB.WithContext(ctx).Where("schedule_id = ? AND date = ?", scheduleID, date.Format("2006-01-02")).Delete(&models.ClassCancellation{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListHolidays returns holidays from the given date on.
+func (s *Store) ListHolidays(ctx context.Context, from time.Time) ([]models.Holiday, error) {
+	var out []models.Holiday
+	err := s.DB.WithContext(ctx).Where("date >= ?", from.Format("2006-01-02")).Order("date").Find(&out).Error
+	return out, err
+}
+
+func (s *Store) CreateHoliday(ctx context.Context, h *models.Holiday) error {
+	h.CreatedAt = time.Now()
+	return s.DB.WithContext(ctx).Create(h).Error
+}
+
+func (s *Store) DeleteHoliday(ctx context.Context, id uint) error {
+	res := s.DB.WithContext(ctx).Delete(&models.Holiday{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func preloadCurriculum(q *gorm.DB) *gorm.DB {
+	return q.Preload("Modules", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, id ASC")
+	}).Preload("Modules.Topics", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, id ASC")
+	})
+}
+
+// ListCurriculum returns every level with its modules and topics, in order.
+func (s *Store) ListCurriculum(ctx context.Context) ([]models.CurriculumLevel, error) {
+	var levels []models.CurriculumLevel
+	if err := preloadCurriculum(s.DB.WithContext(ctx)).Order("position ASC, id ASC").Find(&levels).Error; err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// GetCurriculumLevel returns one level with its modules and topics.
+func (s *Store) GetCurriculumLevel(ctx context.Context, id uint) (*models.CurriculumLevel, error) {
+	var l models.CurriculumLevel
+	if err := preloadCurriculum(s.DB.WithContext(ctx)).First(&l, id).Error; err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (s *Store) CreateCurriculumLevel(ctx context.Context, l *models.CurriculumLevel) error {
+	return s.DB.WithContext(ctx).Omit("Modules").Create(l).Error
+}
+
+func (s *Store) CreateCurriculumModule(ctx context.Context, m *models.CurriculumModule) error {
+	if err := s.DB.WithContext(ctx).First(&models.CurriculumLevel{}, m.LevelID).Error; err != nil {
+		return err
+	}
+	return s.DB.WithContext(ctx).Omit("Topics").Create(m).Error
+}
+
+func (s *Store) CreateCurriculumTopic(ctx context.Context, t *models.CurriculumTopic) error {
+	if err := s.DB.WithContext(ctx).First(&models.CurriculumModule{}, t.ModuleID).Error; err != nil {
+		return err
+	}
+	return s.DB.WithContext(ctx).Create(t).Error
+}
+
+// updateCurriculumRow applies updates to one row of model's table, returning
+// gorm.ErrRecordNotFound when it doesn't exist.
+func (s *Store) updateCurriculumRow(ctx context.Context, model interface{}, id uint, updates map[string]interface{}) error {
+	res := s.DB.WithContext(ctx).Model(model).Where("id = ?", id).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *Store) UpdateCurriculumLevel(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return s.updateCurriculumRow(ctx, &models.CurriculumLevel{}, id, updates)
+}
+
+func (s *Store) UpdateCurriculumModule(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return s.updateCurriculumRow(ctx, &models.CurriculumModule{}, id, updates)
+}
+
+func (s *Store) UpdateCurriculumTopic(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return s.updateCurriculumRow(ctx, &models.CurriculumTopic{}, id, updates)
+}
+
+// DeleteCurriculumLevel soft-deletes a level with its modules and topics.
+// Mastery records and lesson plan items made from its topics are kept.
+func (s *Store) DeleteCurriculumLevel(ctx context.Context, id uint) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.CurriculumLevel{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		modules := tx.Model(&models.CurriculumModule{}).Select("id").Where("level_id = ?", id)
+		if err := tx.Where("module_id IN (?)", modules).Delete(&models.CurriculumTopic{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("level_id = ?", id).Delete(&models.CurriculumModule{}).Error
+	})
+}
+
+// DeleteCurriculumModule soft-deletes a module with its topics.
+func (s *Store) DeleteCurriculumModule(ctx context.Context, id uint) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.CurriculumModule{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("module_id = ?", id).Delete(&models.CurriculumTopic{}).Error
+	})
+}
+
+func (s *Store) DeleteCurriculumTopic(ctx context.Context, id uint) error {
+	res := s.DB.WithContext(ctx).Delete(&models.CurriculumTopic{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateLessonPlanFromLevel fills lp with one item per topic of the level
+// (or only of moduleIDs, when given) and makes it the student's active plan.
+// The student's current level is set to the template's level.
+func (s *Store) CreateLessonPlanFromLevel(ctx context.Context, lp *models.LessonPlan, levelID uint, moduleIDs []uint) error {
+	level, err := s.GetCurriculumLevel(ctx, levelID)
+	if err != nil {
+		return err
+	}
+	wanted := make(map[uint]bool, len(moduleIDs))
+	for _, id := range moduleIDs {
+		wanted[id] = true
+	}
+	var lines []string
+	lp.Items = nil
+	for _, m := range level.Modules {
+		if len(wanted) > 0 && !wanted[m.ID] {
+			continue
+		}
+		for _, t := range m.Topics {
+			var objectives []string
+			_ = json.Unmarshal(t.Objectives, &objectives)
+			topicID := t.ID
+			lp.Items = append(lp.Items, models.LessonPlanItem{
+				TopicID:   &topicID,
+				Topic:     t.Title,
+				Objective: strings.Join(objectives, "\n"),
+			})
+			lines = append(lines, t.Title)
+		}
+	}
+	if lp.Title == "" {
+		lp.Title = level.Name
+	}
+	if len(lp.Description) == 0 {
+		b, _ := json.Marshal(lines)
+		lp.Description = b
+	}
+
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createLessonPlan(tx, lp); err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserDetails{UserID: lp.UserID}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.UserDetails{}).Where("user_id = ?", lp.UserID).
+			Update("curriculum_level_id", levelID).Error
+	})
+}
+
+// ListTopicMastery returns the topics a student has mastered, newest first.
+func (s *Store) ListTopicMastery(ctx context.Context, studentID string) ([]models.TopicMastery, error) {
+	var out []models.TopicMastery
+	if err := s.DB.WithContext(ctx).Where("student_id = ?", studentID).Order("mastered_at DESC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MarkTopicMastered records (or re-records) a mastered topic.
+func (s *Store) MarkTopicMastered(ctx context.Context, m *models.TopicMastery) error {
+	if err := s.DB.WithContext(ctx).First(&models.CurriculumTopic{}, m.TopicID).Error; err != nil {
+		return err
+	}
+	if m.MasteredAt.IsZero() {
+		m.MasteredAt = time.Now()
+	}
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "student_id"}, {Name: "topic_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"mastered_at", "marked_by", "note"}),
+	}).Create(m).Error
+}
+
+func (s *Store) UnmarkTopicMastered(ctx context.Context, studentID string, topicID uint) error {
+	res := s.DB.WithContext(ctx).Where("student_id = ? AND topic_id = ?", studentID, topicID).Delete(&models.TopicMastery{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetStudentCurriculumLevel sets (or, with nil, clears) a student's level.
+func (s *Store) SetStudentCurriculumLevel(ctx context.Context, studentID string, levelID *uint) error {
+	if levelID != nil {
+		if err := s.DB.WithContext(ctx).First(&models.CurriculumLevel{}, *levelID).Error; err != nil {
+			return err
+		}
+	}
+	return s.UpdateUserDetailsFields(ctx, studentID, map[string]interface{}{"curriculum_level_id": levelID})
+}
+
+// ModuleProgress counts a student's mastered topics in one module.
+type ModuleProgress struct {
+	ModuleID uint   `json:"module_id"`
+	Title    string `json:"title"`
+	Total    int    `json:"total"`
+	Mastered int    `json:"mastered"`
+}
+
+// LevelProgress counts a student's mastered topics in one level.
+type LevelProgress struct {
+	LevelID  uint             `json:"level_id"`
+	Name     string           `json:"name"`
+	Total    int              `json:"total"`
+	Mastered int              `json:"mastered"`
+	Percent  float64          `json:"percent"`
+	Modules  []ModuleProgress `json:"modules"`
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part*100) / float64(total)
+}
+
+type moduleCountRow struct {
+	LevelID     uint
+	LevelName   string
+	ModuleID    uint
+	ModuleTitle string
+	Total       int
+	Mastered    int
+}
+
+// moduleCountsSQL counts topics and mastered topics per module. Callers bind
+// the student ID used for mastery.
+const moduleCountsSQL = `
+	SELECT l.id AS level_id, l.name AS level_name, m.id AS module_id, m.title AS module_title,
+		COUNT(t.id) AS total, COUNT(tm.topic_id) AS mastered
+	FROM curriculum_levels l
+	JOIN curriculum_modules m ON m.level_id = l.id AND m.deleted_at IS NULL
+	LEFT JOIN curriculum_topics t ON t.module_id = m.id AND t.deleted_at IS NULL
+	LEFT JOIN topic_masteries tm ON tm.topic_id = t.id AND tm.student_id = ?
+	WHERE l.deleted_at IS NULL`
+
+// StudentCurriculumProgress returns the student's mastery per level and
+// module across the whole curriculum.
+func (s *Store) StudentCurriculumProgress(ctx context.Context, studentID string) ([]LevelProgress, error) {
+	var rows []moduleCountRow
+	if err := s.DB.WithContext(ctx).Raw(moduleCountsSQL+`
+		GROUP BY l.id, l.name, l.position, m.id, m.title, m.position
+		ORDER BY l.position, l.id, m.position, m.id`, studentID).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := []LevelProgress{}
+	for _, r := range rows {
+		if len(out) == 0 || out[len(out)-1].LevelID != r.LevelID {
+			out = append(out, LevelProgress{LevelID: r.LevelID, Name: r.LevelName, Modules: []ModuleProgress{}})
+		}
+		lp := &out[len(out)-1]
+		lp.Modules = append(lp.Modules, ModuleProgress{ModuleID: r.ModuleID, Title: r.ModuleTitle, Total: r.Total, Mastered: r.Mastered})
+		lp.Total += r.Total
+		lp.Mastered += r.Mastered
+	}
+	for i := range out {
+		out[i].Percent = percent(out[i].Mastered, out[i].Total)
+	}
+	return out, nil
+}
+
+// SyllabusReportRow is one of a coach's students measured against their
+// current curriculum level. Level is nil for students without a level.
+type SyllabusReportRow struct {
+	StudentID      string         `json:"student_id"`
+	StudentName    string         `json:"student_name"`
+	Level          *LevelProgress `json:"level"`
+	LastMasteredAt *time.Time     `json:"last_mastered_at"`
+}
+
+// SyllabusReport lists the coach's active students with their mastery of
+// their current level.
+func (s *Store) SyllabusReport(ctx context.Context, coachID string) ([]SyllabusReportRow, error) {
+	var students []struct {
+		ID             string
+		Name           string
+		LevelID        *uint
+		LevelName      string
+		LastMasteredAt *time.Time
+	}
+	if err := s.DB.WithContext(ctx).Raw(`
+		SELECT u.id, u.first_name || ' ' || u.last_name AS name, l.id AS level_id, l.name AS level_name,
+			(SELECT MAX(tm.mastered_at) FROM topic_masteries tm WHERE tm.student_id = u.id) AS last_mastered_at
+		FROM relations r
+		JOIN users u ON u.id = r.user_id AND u.active = true
+		LEFT JOIN user_details ud ON ud.user_id = u.id
+		LEFT JOIN curriculum_levels l ON l.id = ud.curriculum_level_id AND l.deleted_at IS NULL
+		WHERE r.coach_id = ?
+		ORDER BY u.first_name, u.last_name`, coachID).
+		Scan(&students).Error; err != nil {
+		return nil, err
+	}
+
+	out := make([]SyllabusReportRow, 0, len(students))
+	for _, st := range students {
+		row := SyllabusReportRow{StudentID: st.ID, StudentName: st.Name, LastMasteredAt: st.LastMasteredAt}
+		if st.LevelID != nil {
+			var rows []moduleCountRow
+			if err := s.DB.WithContext(ctx).Raw(moduleCountsSQL+` AND l.id = ?
+				GROUP BY l.id, l.name, m.id, m.title, m.position
+				ORDER BY m.position, m.id`, st.ID, *st.LevelID).
+				Scan(&rows).Error; err != nil {
+				return nil, err
+			}
+			lp := &LevelProgress{LevelID: *st.LevelID, Name: st.LevelName, Modules: []ModuleProgress{}}
+			for _, r := range rows {
+				lp.Modules = append(lp.Modules, ModuleProgress{ModuleID: r.ModuleID, Title: r.ModuleTitle, Total: r.Total, Mastered: r.Mastered})
+				lp.Total += r.Total
+				lp.Mastered += r.Mastered
+			}
+			lp.Percent = percent(lp.Mastered, lp.Total)
+			row.Level = lp
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
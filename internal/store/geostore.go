@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/geo"
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NormalizeZipcode trims a ZIP+4 down to the five digit ZIP used by the
+// centroid table.
+func NormalizeZipcode(zip string) string {
+	zip = strings.TrimSpace(zip)
+	if i := strings.IndexAny(zip, "- "); i > 0 {
+		zip = zip[:i]
+	}
+	return zip
+}
+
+// SaveZipCentroids upserts a batch of centroid rows.
+func (s *Store) SaveZipCentroids(ctx context.Context, rows []models.ZipCentroid) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "zipcode"}},
+		DoUpdates: clause.AssignmentColumns([]string{"place_name", "state", "latitude", "longitude"}),
+	}).CreateInBatches(&rows, 1000).Error
+}
+
+// CountZipCentroids returns how many centroids are loaded.
+func (s *Store) CountZipCentroids(ctx context.Context) (int64, error) {
+	var n int64
+	err := s.DB.WithContext(ctx).Model(&models.ZipCentroid{}).Count(&n).Error
+	return n, err
+}
+
+// GetZipCentroid returns the centroid of a zipcode.
+func (s *Store) GetZipCentroid(ctx context.Context, zip string) (*models.ZipCentroid, error) {
+	var c models.ZipCentroid
+	if err := s.DB.WithContext(ctx).Where("zipcode = ?", NormalizeZipcode(zip)).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetZipCentroids returns the known centroids among zips, keyed by the zip as
+// passed in.
+func (s *Store) GetZipCentroids(ctx context.Context, zips []string) (map[string]geo.Point, error) {
+	out := make(map[string]geo.Point, len(zips))
+	if len(zips) == 0 {
+		return out, nil
+	}
+	norm := make([]string, 0, len(zips))
+	for _, z := range zips {
+		norm = append(norm, NormalizeZipcode(z))
+	}
+	var rows []models.ZipCentroid
+	if err := s.DB.WithContext(ctx).Where("zipcode IN ?", norm).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	byZip := make(map[string]geo.Point, len(rows))
+	for _, r := range rows {
+		byZip[r.Zipcode] = geo.Point{Lat: r.Latitude, Lng: r.Longitude}
+	}
+	for _, z := range zips {
+		if p, ok := byZip[NormalizeZipcode(z)]; ok {
+			out[z] = p
+		}
+	}
+	return out, nil
+}
+
+// migrateGeo adds the expression index the city fallback of
+// geocodeTournaments looks centroids up by; AutoMigrate can't declare it.
+func migrateGeo(db *gorm.DB) error {
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_zip_centroids_city ON zip_centroids(LOWER(place_name), state)").Error
+}
+
+// GeocodeTournaments fills latitude/longitude on tournaments that have none,
+// from their zipcode if known, else from the average centroid of their
+// city and state.
+func (s *Store) GeocodeTournaments(ctx context.Context) error {
+	return geocodeTournaments(s.DB.WithContext(ctx), nil)
+}
+
+// geocodeTournaments geocodes the tournaments in ids that lack coordinates,
+// or every such tournament when ids is nil. The city fallback only averages
+// the centroids of cities those tournaments are in, so a scrape batch costs
+// a handful of index lookups rather than a pass over the whole table.
+func geocodeTournaments(tx *gorm.DB, ids []uint) error {
+	if ids != nil && len(ids) == 0 {
+		return nil
+	}
+	only, args := "", []interface{}{}
+	if ids != nil {
+		only, args = "AND t.id IN ?", []interface{}{ids}
+	}
+	if err := tx.Exec(`
+		UPDATE tournaments t
+		SET latitude = z.latitude, longitude = z.longitude
+		FROM zip_centroids z
+		WHERE t.latitude IS NULL
+		  AND COALESCE(t.zipcode, '') != ''
+		  AND z.zipcode = LEFT(TRIM(t.zipcode), 5)
+		  `+only, args...).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`
+		WITH pending AS (
+			SELECT t.id, LOWER(TRIM(t.city)) AS city, UPPER(TRIM(t.state)) AS state
+			FROM tournaments t
+			WHERE t.latitude IS NULL AND COALESCE(t.city, '') != ''
+			  `+only+`
+		), c AS (
+			SELECT LOWER(z.place_name) AS city, z.state, AVG(z.latitude) AS latitude, AVG(z.longitude) AS longitude
+			FROM zip_centroids z
+			WHERE (LOWER(z.place_name), z.state) IN (SELECT city, state FROM pending)
+			GROUP BY LOWER(z.place_name), z.state
+		)
+		UPDATE tournaments t
+		SET latitude = c.latitude, longitude = c.longitude
+		FROM pending p JOIN c ON c.city = p.city AND c.state = p.state
+		WHERE t.id = p.id
+	`, args...).Error
+}
+
+// TournamentNear is a tournament with its distance from the search center.
+type TournamentNear struct {
+	models.Tournament
+	DistanceMiles float64 `json:"distance_miles"`
+}
+
+// SearchTournamentsNear returns geocoded tournaments within radius miles of
+// center, starting in [from, to) when set, nearest first and then by date.
+func (s *Store) SearchTournamentsNear(ctx context.Context, center geo.Point, radiusMiles float64, from, to *time.Time) ([]TournamentNear, error) {
+	minLat, maxLat, minLng, maxLng := geo.BoundingBox(center, radiusMiles)
+	q := s.DB.WithContext(ctx).
+		Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng)
+	if from != nil {
+		q = q.Where("start_date >= ?", *from)
+	}
+	if to != nil {
+		q = q.Where("start_date < ?", *to)
+	}
+	var candidates []models.Tournament
+	if err := q.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	out := make([]TournamentNear, 0, len(candidates))
+	for _, t := range candidates {
+		d := geo.HaversineMiles(center, geo.Point{Lat: *t.Latitude, Lng: *t.Longitude})
+		if d > radiusMiles {
+			continue
+		}
+		out = append(out, TournamentNear{Tournament: t, DistanceMiles: math.Round(d*10) / 10})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].DistanceMiles != out[j].DistanceMiles {
+			return out[i].DistanceMiles < out[j].DistanceMiles
+		}
+		return startsBefore(out[i].StartDate, out[j].StartDate)
+	})
+	return out, nil
+}
+
+// startsBefore orders start dates ascending with unknown dates last.
+func startsBefore(a, b *time.Time) bool {
+	if a == nil {
+		return false
+	}
+	if b == nil {
+		return true
+	}
+	return a.Before(*b)
+}
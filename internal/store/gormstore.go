@@ -25,6 +25,12 @@ func NewGormStore(cfg *config.Config) (*Store, error) {
 		return nil, err
 	}
 	// AutoMigrate (non-destructive: creates tables/columns/indexes)
+	if err := migrateNoteVisibility(db); err != nil {
+		return nil, err
+	}
+	if err := migrateExternalGamesKey(db); err != nil {
+		return nil, err
+	}
 
 	if err := db.Set("gorm:DisableForeignKeyConstraintWhenMigrating", true).AutoMigrate(
 		&models.User{},
@@ -32,17 +38,69 @@ func NewGormStore(cfg *config.Config) (*Store, error) {
 		&models.RefreshToken{},
 		&models.Relation{},
 		&models.LessonPlan{},
+		&models.LessonPlanItem{},
+		&models.LessonPlanItemSession{},
+		&models.CurriculumLevel{},
+		&models.CurriculumModule{},
+		&models.CurriculumTopic{},
+		&models.TopicMastery{},
+		&models.Rubric{},
+		&models.RubricCriterion{},
+		&models.Assessment{},
+		&models.AssessmentScore{},
 		&models.Note{},
+		&models.NoteShare{},
+		&models.NoteAttachment{},
+		&models.NoteComment{},
+		&models.NoteMention{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.NotificationDelivery{},
+		&models.ClassCancellation{},
+		&models.Holiday{},
+		&models.ClassReminder{},
+		&models.MessagingGroup{},
+		&models.MessagingGroupMember{},
+		&models.OutboundMessage{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.OutboxMessage{},
+		&models.JobState{},
+		&models.JobRun{},
+		&models.NoteRevision{},
+		&models.LessonPlanRevision{},
 		&models.Attendance{},
 		&models.Image{},
 		&models.ZipcodeScrapeScope{},
 		&models.Tournament{},
 		&models.TournamentWithinRadius{},
+		&models.ZipCentroid{},
 		&models.ClassSchedule{},
 		&models.ReferralRelationship{},
+		&models.ChessAccountSync{},
+		&models.RatingSnapshot{},
+		&models.ExternalGame{},
+		&models.OfficialRating{},
+		&models.RatingIDMismatch{},
+		&models.TournamentRegistration{},
+		&models.APIKey{},
+		&models.TournamentRevision{},
+		&models.TournamentPage{},
 	); err != nil {
 		return nil, err
 	}
+	if err := migrateSearch(db); err != nil {
+		return nil, err
+	}
+	if err := migrateLessonPlanItems(db); err != nil {
+		return nil, err
+	}
+	if err := migrateGeo(db); err != nil {
+		return nil, err
+	}
+	if err := migrateSchedules(db); err != nil {
+		return nil, err
+	}
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
@@ -57,3 +57,22 @@ func (s *Store) ListAllPublicImages(ctx context.Context, page, pageSize int) ([]
 		Find(&images).Error
 	return images, total, err
 }
+
+// ImageListSpec covers GET /users/{id}/gallery.
+var ImageListSpec = ListSpec{
+	Sorts: map[string]Column{
+		"created_at": {Expr: "images.created_at", Kind: KindTime},
+		"title":      {Expr: "images.title", Kind: KindString},
+	},
+	Filters: map[string]Column{
+		"is_private": {Expr: "images.is_private", Kind: KindBool},
+	},
+	DefaultSort: []SortField{{Field: "created_at", Desc: true}},
+	Key:         Column{Expr: "images.id", Kind: KindInt},
+}
+
+// ListGalleryImagesPage is the paged form of ListGalleryImages.
+func (s *Store) ListGalleryImagesPage(ctx context.Context, userID string, lq ListQuery) (*Page[models.Image], error) {
+	q := s.DB.WithContext(ctx).Model(&models.Image{}).Where("user_id = ?", userID)
+	return Paginate[models.Image](q, ImageListSpec, lq)
+}
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AdvisoryLock is a held Postgres session advisory lock. It lives on one
+// pooled connection, which is kept out of the pool until Release; if the
+// process dies the connection drops and Postgres frees the lock.
+type AdvisoryLock struct {
+	conn *sql.Conn
+	key  int64
+}
+
+// TryAdvisoryLock takes the lock for key without waiting. It returns nil
+// and no error when another session holds it.
+func (s *Store) TryAdvisoryLock(ctx context.Context, key int64) (*AdvisoryLock, error) {
+	sqlDB, err := s.DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, nil
+	}
+	return &AdvisoryLock{conn: conn, key: key}, nil
+}
+
+// Ping checks the session holding the lock is still alive; if it isn't,
+// Postgres has already released the lock.
+func (l *AdvisoryLock) Ping(ctx context.Context) error {
+	return l.conn.PingContext(ctx)
+}
+
+// Release unlocks and returns the connection to the pool. It does not take
+// a context so it still runs during shutdown.
+func (l *AdvisoryLock) Release() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	if cerr := l.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// EnsureJobStates adds a state row for each job. A job whose schedule
+// changed since it was saved gets the new schedule and next run time; the
+// rest keep theirs.
+func (s *Store) EnsureJobStates(ctx context.Context, states []models.JobState) error {
+	if len(states) == 0 {
+		return nil
+	}
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"next_run_at": gorm.Expr("CASE WHEN job_states.schedule <> EXCLUDED.schedule THEN EXCLUDED.next_run_at ELSE job_states.next_run_at END"),
+			"schedule":    gorm.Expr("EXCLUDED.schedule"),
+		}),
+	}).Create(&states).Error
+}
+
+func (s *Store) ListJobStates(ctx context.Context) ([]models.JobState, error) {
+	var out []models.JobState
+	err := s.DB.WithContext(ctx).Order("name").Find(&out).Error
+	return out, err
+}
+
+func (s *Store) GetJobState(ctx context.Context, name string) (*models.JobState, error) {
+	var st models.JobState
+	if err := s.DB.WithContext(ctx).First(&st, "name = ?", name).Error; err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (s *Store) UpdateJobState(ctx context.Context, name string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	res := s.DB.WithContext(ctx).Model(&models.JobState{}).Where("name = ?", name).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// StartJobRun records run as started and moves the job's state on: the
+// manual request is cleared, and a scheduled run sets the next run time.
+// A nil next leaves it alone.
+func (s *Store) StartJobRun(ctx context.Context, run *models.JobRun, next *time.Time) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"last_run_at":      run.StartedAt,
+			"last_status":      run.Status,
+			"last_error":       "",
+			"updated_at":       time.Now(),
+			"run_requested":    false,
+			"run_requested_by": "",
+		}
+		if next != nil {
+			updates["next_run_at"] = *next
+		}
+		return tx.Model(&models.JobState{}).Where("name = ?", run.JobName).Updates(updates).Error
+	})
+}
+
+// FinishJobRun records the outcome of run and copies it to the job state.
+func (s *Store) FinishJobRun(ctx context.Context, run *models.JobRun) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.JobRun{}).Where("id = ?", run.ID).Updates(map[string]interface{}{
+			"status":      run.Status,
+			"finished_at": run.FinishedAt,
+			"duration_ms": run.DurationMs,
+			"error":       run.Error,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.JobState{}).Where("name = ?", run.JobName).Updates(map[string]interface{}{
+			"last_status": run.Status,
+			"last_error":  run.Error,
+			"updated_at":  time.Now(),
+		}).Error
+	})
+}
+
+// AbandonJobRuns marks a job's unfinished runs as failed. Call it while
+// holding the job's lock: any run still marked running then belongs to a
+// replica that died mid-run.
+func (s *Store) AbandonJobRuns(ctx context.Context, name string) error {
+	now := time.Now()
+	return s.DB.WithContext(ctx).Model(&models.JobRun{}).
+		Where("job_name = ? AND status = ?", name, models.JobRunRunning).
+		Updates(map[string]interface{}{
+			"status":      models.JobRunFailed,
+			"finished_at": now,
+			"error":       "abandoned: the runner stopped before finishing",
+		}).Error
+}
+
+// ListJobRuns returns a job's run history, newest first, optionally
+// filtered by status.
+func (s *Store) ListJobRuns(ctx context.Context, name, status string, limit, offset int) ([]models.JobRun, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	q := s.DB.WithContext(ctx).Model(&models.JobRun{}).Where("job_name = ?", name)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []models.JobRun
+	err := q.Order("started_at DESC, id DESC").Limit(limit).Offset(offset).Find(&out).Error
+	return out, total, err
+}
+
+// PurgeJobRuns deletes finished runs that started before cutoff. Each job's
+// last outcome stays on its state row.
+func (s *Store) PurgeJobRuns(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := s.DB.WithContext(ctx).
+		Where("status <> ? AND started_at < ?", models.JobRunRunning, cutoff).
+		Delete(&models.JobRun{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,336 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidItemStatus  = errors.New("invalid item status")
+	ErrInvalidItemOrder   = errors.New("item order must list every item of the plan exactly once")
+	ErrAttendanceMismatch = errors.New("attendance belongs to a different student")
+)
+
+// ValidLessonPlanItemStatus reports whether s is a known item status.
+func ValidLessonPlanItemStatus(s models.LessonPlanItemStatus) bool {
+	switch s {
+	case models.LessonPlanItemPending, models.LessonPlanItemInProgress,
+		models.LessonPlanItemCompleted, models.LessonPlanItemSkipped:
+		return true
+	}
+	return false
+}
+
+// lessonPlanItemsBackfill turns the description lines of plans created before
+// items existed into pending items (see migration 000022). Plans that already
+// have items are left alone, so it is safe to run on every start.
+const lessonPlanItemsBackfill = `
+	INSERT INTO lesson_plan_items (lesson_plan_id, position, topic, status, created_at, updated_at)
+	SELECT lp.id, t.ord, t.topic, 'pending', NOW(), NOW()
+	FROM lesson_plans lp
+	CROSS JOIN LATERAL jsonb_array_elements_text(
+		CASE WHEN jsonb_typeof(lp.description) = 'array' THEN lp.description ELSE '[]'::jsonb END
+	) WITH ORDINALITY AS t(topic, ord)
+	WHERE NOT EXISTS (SELECT 1 FROM lesson_plan_items i WHERE i.lesson_plan_id = lp.id)`
+
+// lessonPlanArchivedBackfill dates the archive of plans deactivated before
+// archived_at existed (see migration 000038). Deactivating a plan always
+// sets archived_at now, so this only ever touches old rows.
+const lessonPlanArchivedBackfill = `
+	UPDATE lesson_plans SET archived_at = updated_at WHERE NOT active AND archived_at IS NULL`
+
+func migrateLessonPlanItems(db *gorm.DB) error {
+	if err := db.Exec(lessonPlanItemsBackfill).Error; err != nil {
+		return err
+	}
+	return db.Exec(lessonPlanArchivedBackfill).Error
+}
+
+// itemsFromDescription seeds items from a plan's description lines.
+func itemsFromDescription(description []byte) []models.LessonPlanItem {
+	var lines []string
+	_ = json.Unmarshal(description, &lines)
+	items := make([]models.LessonPlanItem, 0, len(lines))
+	for _, l := range lines {
+		if l == "" {
+			continue
+		}
+		items = append(items, models.LessonPlanItem{Topic: l})
+	}
+	return items
+}
+
+// ComputeLessonPlanProgress fills lp.Progress from its loaded items.
+func ComputeLessonPlanProgress(lp *models.LessonPlan) {
+	p := &models.LessonPlanProgress{Total: len(lp.Items)}
+	for _, it := range lp.Items {
+		switch it.Status {
+		case models.LessonPlanItemInProgress:
+			p.InProgress++
+		case models.LessonPlanItemCompleted:
+			p.Completed++
+		case models.LessonPlanItemSkipped:
+			p.Skipped++
+		default:
+			p.Pending++
+		}
+	}
+	if counted := p.Total - p.Skipped; counted > 0 {
+		p.Percent = float64(p.Completed*100) / float64(counted)
+	}
+	lp.Progress = p
+}
+
+func preloadLessonPlanItems(q *gorm.DB) *gorm.DB {
+	return q.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, id ASC")
+	}).Preload("Items.Sessions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("session_date ASC")
+	})
+}
+
+// GetLessonPlan returns a plan with its items, linked sessions and progress.
+func (s *Store) GetLessonPlan(ctx context.Context, planID uint) (*models.LessonPlan, error) {
+	var lp models.LessonPlan
+	if err := preloadLessonPlanItems(s.DB.WithContext(ctx)).First(&lp, planID).Error; err != nil {
+		return nil, err
+	}
+	ComputeLessonPlanProgress(&lp)
+	return &lp, nil
+}
+
+// ListLessonPlans returns the student's plans, newest first. archived
+// restricts the list to replaced (true) or current (false) plans.
+func (s *Store) ListLessonPlans(ctx context.Context, userID string, archived *bool) ([]models.LessonPlan, error) {
+	q := preloadLessonPlanItems(s.DB.WithContext(ctx)).Where("user_id = ?", userID)
+	if archived != nil {
+		if *archived {
+			q = q.Where("archived_at IS NOT NULL")
+		} else {
+			q = q.Where("archived_at IS NULL")
+		}
+	}
+	var plans []models.LessonPlan
+	if err := q.Order("created_at DESC").Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	for i := range plans {
+		ComputeLessonPlanProgress(&plans[i])
+	}
+	return plans, nil
+}
+
+func nextItemPosition(tx *gorm.DB, planID uint) (int, error) {
+	var max int
+	err := tx.Model(&models.LessonPlanItem{}).
+		Where("lesson_plan_id = ?", planID).
+		Select("COALESCE(MAX(position), 0)").Scan(&max).Error
+	return max + 1, err
+}
+
+// AddLessonPlanItem appends an item to the plan, or inserts it at
+// item.Position when that is set, shifting later items down.
+func (s *Store) AddLessonPlanItem(ctx context.Context, planID uint, item *models.LessonPlanItem) error {
+	if item.Status == "" {
+		item.Status = models.LessonPlanItemPending
+	}
+	if !ValidLessonPlanItemStatus(item.Status) {
+		return ErrInvalidItemStatus
+	}
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		next, err := nextItemPosition(tx, planID)
+		if err != nil {
+			return err
+		}
+		if item.Position <= 0 || item.Position >= next {
+			item.Position = next
+		} else if err := tx.Model(&models.LessonPlanItem{}).
+			Where("lesson_plan_id = ? AND position >= ?", planID, item.Position).
+			Update("position", gorm.Expr("position + 1")).Error; err != nil {
+			return err
+		}
+		item.LessonPlanID = planID
+		if item.Status == models.LessonPlanItemCompleted && item.CompletedAt == nil {
+			now := time.Now()
+			item.CompletedAt = &now
+		}
+		return tx.Omit("Sessions").Create(item).Error
+	})
+}
+
+// GetLessonPlanItem returns one item of the plan.
+func (s *Store) GetLessonPlanItem(ctx context.Context, planID, itemID uint) (*models.LessonPlanItem, error) {
+	var item models.LessonPlanItem
+	if err := s.DB.WithContext(ctx).
+		Preload("Sessions", func(db *gorm.DB) *gorm.DB { return db.Order("session_date ASC") }).
+		Where("id = ? AND lesson_plan_id = ?", itemID, planID).
+		First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateLessonPlanItem applies updates to an item. A status change to
+// completed stamps completed_at; any other status clears it.
+func (s *Store) UpdateLessonPlanItem(ctx context.Context, planID, itemID uint, updates map[string]interface{}) (*models.LessonPlanItem, error) {
+	if st, ok := updates["status"].(models.LessonPlanItemStatus); ok {
+		if !ValidLessonPlanItemStatus(st) {
+			return nil, ErrInvalidItemStatus
+		}
+		if st == models.LessonPlanItemCompleted {
+			updates["completed_at"] = gorm.Expr("COALESCE(completed_at, ?)", time.Now())
+		} else {
+			updates["completed_at"] = nil
+		}
+	}
+	res := s.DB.WithContext(ctx).Model(&models.LessonPlanItem{}).
+		Where("id = ? AND lesson_plan_id = ?", itemID, planID).
+		Updates(updates)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.GetLessonPlanItem(ctx, planID, itemID)
+}
+
+// DeleteLessonPlanItem soft-deletes an item and closes the gap it leaves in
+// the ordering.
+func (s *Store) DeleteLessonPlanItem(ctx context.Context, planID, itemID uint) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var item models.LessonPlanItem
+		if err := tx.Where("id = ? AND lesson_plan_id = ?", itemID, planID).First(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.LessonPlanItem{}).
+			Where("lesson_plan_id = ? AND position > ?", planID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+}
+
+// ReorderLessonPlanItems renumbers the plan's items in the given order, which
+// must contain every item exactly once.
+func (s *Store) ReorderLessonPlanItems(ctx context.Context, planID uint, itemIDs []uint) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		if err := tx.Model(&models.LessonPlanItem{}).
+			Where("lesson_plan_id = ?", planID).
+			Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) != len(itemIDs) {
+			return ErrInvalidItemOrder
+		}
+		want := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			want[id] = true
+		}
+		for _, id := range itemIDs {
+			if !want[id] {
+				return ErrInvalidItemOrder
+			}
+			delete(want, id)
+		}
+		for i, id := range itemIDs {
+			if err := tx.Model(&models.LessonPlanItem{}).
+				Where("id = ?", id).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LinkAttendanceToItem records that a class covered the item. A pending item
+// moves to in_progress; with complete set it is marked completed as of the
+// class date. The attendance must be for the plan's student.
+func (s *Store) LinkAttendanceToItem(ctx context.Context, lp *models.LessonPlan, itemID, attendanceID uint, complete bool, linkedBy string) (*models.LessonPlanItem, error) {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var item models.LessonPlanItem
+		if err := tx.Where("id = ? AND lesson_plan_id = ?", itemID, lp.ID).First(&item).Error; err != nil {
+			return err
+		}
+		var a models.Attendance
+		if err := tx.First(&a, attendanceID).Error; err != nil {
+			return err
+		}
+		if a.StudentID != lp.UserID {
+			return ErrAttendanceMismatch
+		}
+		link := models.LessonPlanItemSession{
+			LessonPlanItemID: item.ID,
+			AttendanceID:     a.ID,
+			SessionDate:      a.Date,
+			LinkedBy:         linkedBy,
+			CreatedAt:        time.Now(),
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if complete {
+			if item.Status != models.LessonPlanItemCompleted {
+				updates["status"] = models.LessonPlanItemCompleted
+				updates["completed_at"] = a.Date
+			}
+		} else if item.Status == models.LessonPlanItemPending {
+			updates["status"] = models.LessonPlanItemInProgress
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&item).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetLessonPlanItem(ctx, lp.ID, itemID)
+}
+
+// UnlinkAttendanceFromItem removes a class link. An in_progress item with no
+// classes left goes back to pending; completed items keep their status.
+func (s *Store) UnlinkAttendanceFromItem(ctx context.Context, planID, itemID, attendanceID uint) (*models.LessonPlanItem, error) {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var item models.LessonPlanItem
+		if err := tx.Where("id = ? AND lesson_plan_id = ?", itemID, planID).First(&item).Error; err != nil {
+			return err
+		}
+		res := tx.Where("lesson_plan_item_id = ? AND attendance_id = ?", itemID, attendanceID).
+			Delete(&models.LessonPlanItemSession{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if item.Status != models.LessonPlanItemInProgress {
+			return nil
+		}
+		var left int64
+		if err := tx.Model(&models.LessonPlanItemSession{}).
+			Where("lesson_plan_item_id = ?", itemID).
+			Count(&left).Error; err != nil {
+			return err
+		}
+		if left > 0 {
+			return nil
+		}
+		return tx.Model(&item).Update("status", models.LessonPlanItemPending).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetLessonPlanItem(ctx, planID, itemID)
+}
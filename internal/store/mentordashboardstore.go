@@ -0,0 +1,288 @@
+package store
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+)
+
+// lateLogAfter is how long after the start of the class day an attendance
+// can be logged before it counts as late: anything after the following day.
+const lateLogAfter = 2 * 24 * time.Hour
+
+// CoachMonthStats is one month of a coach's (or the whole team's) work.
+// Classes and reviews count by class date, notes by creation time.
+type CoachMonthStats struct {
+	Month         string         `json:"month,omitempty"` // YYYY-MM; empty in totals
+	Classes       int            `json:"classes"`
+	ClassesByType map[string]int `json:"classes_by_type"`
+
+	// Attendance logging latency: time from the class date to CreatedAt
+	AvgLogDelayDays    float64 `json:"avg_log_delay_days"`
+	MedianLogDelayDays float64 `json:"median_log_delay_days"`
+	LoggedLate         int     `json:"logged_late"`
+
+	// Review by mentors/admins; an attendance is reviewed once verified or rejected
+	Reviewed         int     `json:"reviewed"`
+	Rejected         int     `json:"rejected"`
+	RejectionPercent float64 `json:"rejection_percent"`
+
+	// Students taught at least once this month, compared with the month before
+	ActiveStudents   int     `json:"active_students"`
+	NewStudents      int     `json:"new_students"`
+	RetainedStudents int     `json:"retained_students"`
+	ChurnedStudents  int     `json:"churned_students"`
+	RetentionPercent float64 `json:"retention_percent"` // retained / active the month before
+
+	NotesWritten int `json:"notes_written"`
+}
+
+// CoachDashboard is a coach's metrics over the range: Totals for the whole
+// range and Months as the trend.
+type CoachDashboard struct {
+	CoachID          string            `json:"coach_id"`
+	Name             string            `json:"name"`
+	AssignedStudents int               `json:"assigned_students"` // active students assigned now
+	Totals           CoachMonthStats   `json:"totals"`
+	Months           []CoachMonthStats `json:"months"`
+}
+
+// MentorDashboard covers a mentor's coaches for the months [From, To).
+// Team sums every coach's numbers, students counted once.
+type MentorDashboard struct {
+	MentorID string           `json:"mentor_id"`
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Months   []string         `json:"months"`
+	Team     CoachDashboard   `json:"team"`
+	Coaches  []CoachDashboard `json:"coaches"`
+}
+
+// dashboardAcc accumulates one coach's (or the team's) raw data per month.
+type dashboardAcc struct {
+	months   []CoachMonthStats
+	delays   [][]float64
+	students []map[string]bool // per month; index 0 is the month before the range
+	total    CoachMonthStats
+	allDelay []float64
+	allSeen  map[string]bool
+}
+
+func newDashboardAcc(months []string) *dashboardAcc {
+	a := &dashboardAcc{
+		months:   make([]CoachMonthStats, len(months)),
+		delays:   make([][]float64, len(months)),
+		students: make([]map[string]bool, len(months)+1),
+		total:    CoachMonthStats{ClassesByType: map[string]int{}},
+		allSeen:  map[string]bool{},
+	}
+	for i, m := range months {
+		a.months[i] = CoachMonthStats{Month: m, ClassesByType: map[string]int{}}
+	}
+	for i := range a.students {
+		a.students[i] = map[string]bool{}
+	}
+	return a
+}
+
+type dashboardAttendance struct {
+	CoachID    string
+	StudentID  string
+	ClassType  string
+	Date       time.Time
+	CreatedAt  time.Time
+	IsVerified bool
+	VerifiedAt *time.Time
+	RejectedAt *time.Time
+}
+
+// addClass counts a in month i; i is -1 for the month before the range,
+// which only feeds retention.
+func (acc *dashboardAcc) addClass(i int, a *dashboardAttendance) {
+	acc.students[i+1][a.StudentID] = true
+	if i < 0 {
+		return
+	}
+	delay := math.Max(a.CreatedAt.Sub(a.Date).Hours()/24, 0)
+	for _, m := range []*CoachMonthStats{&acc.months[i], &acc.total} {
+		m.Classes++
+		m.ClassesByType[a.ClassType]++
+		if a.CreatedAt.Sub(a.Date) > lateLogAfter {
+			m.LoggedLate++
+		}
+		if a.RejectedAt != nil {
+			m.Rejected++
+		}
+		if a.RejectedAt != nil || a.IsVerified || a.VerifiedAt != nil {
+			m.Reviewed++
+		}
+	}
+	acc.delays[i] = append(acc.delays[i], delay)
+	acc.allDelay = append(acc.allDelay, delay)
+	acc.allSeen[a.StudentID] = true
+}
+
+func (acc *dashboardAcc) addNote(i int) {
+	acc.months[i].NotesWritten++
+	acc.total.NotesWritten++
+}
+
+func (acc *dashboardAcc) finish() (CoachMonthStats, []CoachMonthStats) {
+	prevActive := 0
+	for i := range acc.months {
+		m := &acc.months[i]
+		m.AvgLogDelayDays, m.MedianLogDelayDays = delayStats(acc.delays[i])
+		m.RejectionPercent = percent(m.Rejected, m.Reviewed)
+		before, now := acc.students[i], acc.students[i+1]
+		m.ActiveStudents = len(now)
+		for id := range now {
+			if before[id] {
+				m.RetainedStudents++
+			} else {
+				m.NewStudents++
+			}
+		}
+		m.ChurnedStudents = len(before) - m.RetainedStudents
+		m.RetentionPercent = percent(m.RetainedStudents, len(before))
+
+		acc.total.NewStudents += m.NewStudents
+		acc.total.RetainedStudents += m.RetainedStudents
+		acc.total.ChurnedStudents += m.ChurnedStudents
+		prevActive += len(before)
+	}
+	t := acc.total
+	t.AvgLogDelayDays, t.MedianLogDelayDays = delayStats(acc.allDelay)
+	t.RejectionPercent = percent(t.Rejected, t.Reviewed)
+	t.ActiveStudents = len(acc.allSeen)
+	t.RetentionPercent = percent(t.RetainedStudents, prevActive)
+	return t, acc.months
+}
+
+// delayStats returns the mean and median of ds, rounded to a tenth of a day.
+func delayStats(ds []float64) (float64, float64) {
+	if len(ds) == 0 {
+		return 0, 0
+	}
+	sorted := append([]float64(nil), ds...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, d := range sorted {
+		sum += d
+	}
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	return round(sum / float64(n)), round(median)
+}
+
+// GetMentorDashboard computes the metrics of coaches for the calendar
+// months from the month of from up to, but not including, the month of to.
+func (s *Store) GetMentorDashboard(ctx context.Context, mentorID string, coaches []*models.User, from, to time.Time) (*MentorDashboard, error) {
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC)
+	months := []string{}
+	for m := start; m.Before(end); m = m.AddDate(0, 1, 0) {
+		months = append(months, m.Format("2006-01"))
+	}
+	monthIndex := func(t time.Time) int {
+		t = t.UTC()
+		return (t.Year()-start.Year())*12 + int(t.Month()) - int(start.Month())
+	}
+
+	ids := make([]string, 0, len(coaches))
+	for _, c := range coaches {
+		ids = append(ids, c.ID)
+	}
+	out := &MentorDashboard{
+		MentorID: mentorID,
+		From:     start.Format("2006-01-02"),
+		To:       end.Format("2006-01-02"),
+		Months:   months,
+		Coaches:  []CoachDashboard{},
+	}
+
+	var atts []dashboardAttendance
+	var notes []struct {
+		CreatedBy string
+		CreatedAt time.Time
+	}
+	var assigned []struct {
+		CoachID string
+		N       int
+	}
+	if len(ids) > 0 {
+		// One extra month before the range gives the first month's retention.
+		if err := s.DB.WithContext(ctx).Model(&models.Attendance{}).
+			Select("coach_id, student_id, class_type, date, created_at, is_verified, verified_at, rejected_at").
+			Where("coach_id IN ? AND date >= ? AND date < ?", ids, start.AddDate(0, -1, 0), end).
+			Scan(&atts).Error; err != nil {
+			return nil, err
+		}
+		if err := s.DB.WithContext(ctx).Model(&models.Note{}).
+			Select("created_by, created_at").
+			Where("created_by IN ? AND created_at >= ? AND created_at < ?", ids, start, end).
+			Scan(&notes).Error; err != nil {
+			return nil, err
+		}
+		if err := s.DB.WithContext(ctx).Raw(`
+			SELECT r.coach_id, COUNT(DISTINCT r.user_id) AS n
+			FROM relations r JOIN users u ON u.id = r.user_id AND u.active = true
+			WHERE r.coach_id IN ?
+			GROUP BY r.coach_id`, ids).
+			Scan(&assigned).Error; err != nil {
+			return nil, err
+		}
+		// A student with several of these coaches counts once for the team.
+		if err := s.DB.WithContext(ctx).Raw(`
+			SELECT COUNT(DISTINCT r.user_id)
+			FROM relations r JOIN users u ON u.id = r.user_id AND u.active = true
+			WHERE r.coach_id IN ?`, ids).
+			Scan(&out.Team.AssignedStudents).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	team := newDashboardAcc(months)
+	accs := map[string]*dashboardAcc{}
+	for _, id := range ids {
+		accs[id] = newDashboardAcc(months)
+	}
+	for i := range atts {
+		a := &atts[i]
+		mi := monthIndex(a.Date)
+		if mi < -1 || mi >= len(months) {
+			continue
+		}
+		accs[a.CoachID].addClass(mi, a)
+		team.addClass(mi, a)
+	}
+	for _, n := range notes {
+		mi := monthIndex(n.CreatedAt)
+		if mi < 0 || mi >= len(months) {
+			continue
+		}
+		accs[n.CreatedBy].addNote(mi)
+		team.addNote(mi)
+	}
+	assignedBy := map[string]int{}
+	for _, a := range assigned {
+		assignedBy[a.CoachID] = a.N
+	}
+
+	for _, c := range coaches {
+		name := c.FirstName + " " + c.LastName
+		cd := CoachDashboard{CoachID: c.ID, Name: name, AssignedStudents: assignedBy[c.ID]}
+		cd.Totals, cd.Months = accs[c.ID].finish()
+		out.Coaches = append(out.Coaches, cd)
+	}
+	sort.SliceStable(out.Coaches, func(i, j int) bool { return out.Coaches[i].Name < out.Coaches[j].Name })
+	out.Team.Name = "All coaches"
+	out.Team.Totals, out.Team.Months = team.finish()
+	return out, nil
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListMessagingGroups returns all groups, or only userID's groups when it
+// is set.
+func (s *Store) ListMessagingGroups(ctx context.Context, userID string) ([]models.MessagingGroup, error) {
+	q := s.DB.WithContext(ctx).Model(&models.MessagingGroup{})
+	if userID != "" {
+		q = q.Where("id IN (SELECT group_id FROM messaging_group_members WHERE user_id = ?)", userID)
+	}
+	var out []models.MessagingGroup
+	err := q.Order("name").Find(&out).Error
+	return out, err
+}
+
+func (s *Store) GetMessagingGroup(ctx context.Context, id uint) (*models.MessagingGroup, error) {
+	var g models.MessagingGroup
+	if err := s.DB.WithContext(ctx).First(&g, id).Error; err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (s *Store) CreateMessagingGroup(ctx context.Context, g *models.MessagingGroup) error {
+	return s.DB.WithContext(ctx).Create(g).Error
+}
+
+func (s *Store) UpdateMessagingGroup(ctx context.Context, id uint, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	return s.DB.WithContext(ctx).Model(&models.MessagingGroup{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteMessagingGroup removes the group and its memberships.
+func (s *Store) DeleteMessagingGroup(ctx context.Context, id uint) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var userIDs []string
+		if err := tx.Model(&models.MessagingGroupMember{}).Where("group_id = ?", id).Pluck("user_id", &userIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.MessagingGroupMember{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.MessagingGroup{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		for _, uid := range userIDs {
+			if err := syncAddedInWhatsapp(tx, uid); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) ListGroupMembers(ctx context.Context, groupID uint) ([]models.MessagingGroupMember, error) {
+	var out []models.MessagingGroupMember
+	err := s.DB.WithContext(ctx).Preload("User").Where("group_id = ?", groupID).Order("added_at").Find(&out).Error
+	return out, err
+}
+
+// AddGroupMember records userID as a member; adding twice is a no-op.
+func (s *Store) AddGroupMember(ctx context.Context, groupID uint, userID, addedBy string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		m := models.MessagingGroupMember{GroupID: groupID, UserID: userID, AddedBy: addedBy, AddedAt: time.Now()}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&m).Error; err != nil {
+			return err
+		}
+		return syncAddedInWhatsapp(tx, userID)
+	})
+}
+
+func (s *Store) RemoveGroupMember(ctx context.Context, groupID uint, userID string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&models.MessagingGroupMember{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return syncAddedInWhatsapp(tx, userID)
+	})
+}
+
+// syncAddedInWhatsapp keeps UserDetails.AddedInWhatsapp equal to "is in at
+// least one WhatsApp group", now that membership is tracked per group.
+func syncAddedInWhatsapp(tx *gorm.DB, userID string) error {
+	return tx.Exec(`UPDATE user_details SET added_in_whatsapp = EXISTS (
+		SELECT 1 FROM messaging_group_members m JOIN messaging_groups g ON g.id = m.group_id
+		WHERE m.user_id = ? AND g.provider = 'whatsapp') WHERE user_id = ?`, userID, userID).Error
+}
+
+// FamilyMembers returns the users linked to userID by a family
+// relationship in the referral network (parents, siblings).
+func (s *Store) FamilyMembers(ctx context.Context, userID string) ([]*models.User, error) {
+	var out []*models.User
+	err := s.DB.WithContext(ctx).Preload("UserDetails").
+		Where(`id IN (SELECT CASE WHEN f.referrer_id = ? THEN f.referee_id ELSE f.referrer_id END
+			FROM referral_relationships f
+			WHERE f.relationship_type = 'family' AND (f.referrer_id = ? OR f.referee_id = ?))`, userID, userID, userID).
+		Where("active = ?", true).
+		Find(&out).Error
+	return out, err
+}
+
+func (s *Store) CreateOutboundMessage(ctx context.Context, m *models.OutboundMessage) error {
+	return s.DB.WithContext(ctx).Create(m).Error
+}
+
+func (s *Store) UpdateOutboundMessage(ctx context.Context, id uint, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	return s.DB.WithContext(ctx).Model(&models.OutboundMessage{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ListOutboundMessages returns the newest messages, optionally for one user.
+func (s *Store) ListOutboundMessages(ctx context.Context, userID string, limit int) ([]models.OutboundMessage, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	q := s.DB.WithContext(ctx).Order("created_at DESC, id DESC").Limit(limit)
+	if userID != "" {
+		q = q.Where("user_id = ?", userID)
+	}
+	var out []models.OutboundMessage
+	err := q.Find(&out).Error
+	return out, err
+}
+
+var messageStatusRank = map[string]int{"queued": 0, "sent": 1, "delivered": 2, "read": 3, "failed": 4}
+
+// ApplyMessageStatus records a provider status report. Reports can arrive
+// out of order, so a message never moves back (read stays read when a late
+// "delivered" shows up). It returns false when no message matched.
+func (s *Store) ApplyMessageStatus(ctx context.Context, provider, providerMessageID, status, errMsg string, at time.Time) (bool, error) {
+	rank, ok := messageStatusRank[status]
+	if !ok {
+		return false, nil
+	}
+	var m models.OutboundMessage
+	err := s.DB.WithContext(ctx).Where("provider = ? AND provider_message_id = ?", provider, providerMessageID).First(&m).Error
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	updates := map[string]interface{}{}
+	switch status {
+	case "sent":
+		if m.SentAt == nil {
+			updates["sent_at"] = at
+		}
+	case "delivered":
+		if m.DeliveredAt == nil {
+			updates["delivered_at"] = at
+		}
+	case "read":
+		if m.ReadAt == nil {
+			updates["read_at"] = at
+		}
+	case "failed":
+		updates["error"] = errMsg
+	}
+	if rank > messageStatusRank[m.Status] {
+		updates["status"] = status
+	}
+	if len(updates) == 0 {
+		return true, nil
+	}
+	return true, s.UpdateOutboundMessage(ctx, m.ID, updates)
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"context"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+)
+
+func (s *Store) CreateNoteAttachment(ctx context.Context, a *models.NoteAttachment) error {
+	return s.DB.WithContext(ctx).Create(a).Error
+}
+
+// ListNoteAttachments returns a note's attachments, oldest first.
+func (s *Store) ListNoteAttachments(ctx context.Context, noteID uint) ([]models.NoteAttachment, error) {
+	var out []models.NoteAttachment
+	if err := s.DB.WithContext(ctx).Where("note_id = ?", noteID).Order("created_at ASC, id ASC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Store) GetNoteAttachment(ctx context.Context, noteID, id uint) (*models.NoteAttachment, error) {
+	var a models.NoteAttachment
+	if err := s.DB.WithContext(ctx).Where("id = ? AND note_id = ?", id, noteID).First(&a).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// DeleteNoteAttachment removes the attachment row. The stored file is
+// removed by the caller.
+func (s *Store) DeleteNoteAttachment(ctx context.Context, noteID, id uint) error {
+	res := s.DB.WithContext(ctx).Where("id = ? AND note_id = ?", id, noteID).Delete(&models.NoteAttachment{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
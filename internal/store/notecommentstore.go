@@ -0,0 +1,234 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidParentComment = errors.New("parent comment not found on this note")
+
+// mentionRe matches @USR00XXXXX user ids in a comment body.
+var mentionRe = regexp.MustCompile(`(?i)@(USR00[0-9A-Za-z]{5})\b`)
+
+// ParseMentions returns the distinct user ids @mentioned in body, in order
+// of first appearance.
+func ParseMentions(body string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, m := range mentionRe.FindAllStringSubmatch(body, -1) {
+		id := strings.ToUpper(m[1])
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// UnmentionableUsers returns the ids in userIDs that can't be mentioned on
+// n: unknown or inactive users, and users who can't read the note.
+func (s *Store) UnmentionableUsers(ctx context.Context, n *models.Note, userIDs []string) ([]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var users []*models.User
+	if err := s.DB.WithContext(ctx).Where("id IN ? AND active = ?", userIDs, true).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	ok := map[string]bool{}
+	for _, u := range users {
+		ok[u.ID] = s.CanAccessNoteForRequester(ctx, u, n)
+	}
+	var bad []string
+	for _, id := range userIDs {
+		if !ok[id] {
+			bad = append(bad, id)
+		}
+	}
+	return bad, nil
+}
+
+// addMentions records inbox entries for the mentioned users. The author is
+// never notified of their own mention, and re-adding one is a no-op.
+func addMentions(tx *gorm.DB, c *models.NoteComment, userIDs []string) error {
+	now := time.Now()
+	var rows []models.NoteMention
+	for _, id := range userIDs {
+		if id == c.AuthorID {
+			continue
+		}
+		rows = append(rows, models.NoteMention{CommentID: c.ID, UserID: id, NoteID: c.NoteID, CreatedAt: now})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+// CreateNoteComment adds c to its note's discussion along with its
+// mentions. A reply's parent must be a live comment on the same note.
+func (s *Store) CreateNoteComment(ctx context.Context, c *models.NoteComment, mentions []string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if c.ParentID != nil {
+			var cnt int64
+			if err := tx.Model(&models.NoteComment{}).Where("id = ? AND note_id = ?", *c.ParentID, c.NoteID).Count(&cnt).Error; err != nil {
+				return err
+			}
+			if cnt == 0 {
+				return ErrInvalidParentComment
+			}
+		}
+		if err := tx.Omit("Mentions").Create(c).Error; err != nil {
+			return err
+		}
+		if err := addMentions(tx, c, mentions); err != nil {
+			return err
+		}
+		return tx.Where("comment_id = ?", c.ID).Find(&c.Mentions).Error
+	})
+}
+
+func (s *Store) GetNoteComment(ctx context.Context, noteID, id uint) (*models.NoteComment, error) {
+	var c models.NoteComment
+	if err := s.DB.WithContext(ctx).Preload("Mentions").Where("id = ? AND note_id = ?", id, noteID).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// UpdateNoteComment replaces the comment body. Mentions that were removed
+// leave the inbox; new ones are added, while existing ones keep their read
+// state.
+func (s *Store) UpdateNoteComment(ctx context.Context, c *models.NoteComment, body string, mentions []string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(c).Updates(map[string]interface{}{"body": body, "edited_at": now}).Error; err != nil {
+			return err
+		}
+		c.Body = body
+		c.EditedAt = &now
+		del := tx.Where("comment_id = ?", c.ID)
+		if len(mentions) > 0 {
+			del = del.Where("user_id NOT IN ?", mentions)
+		}
+		if err := del.Delete(&models.NoteMention{}).Error; err != nil {
+			return err
+		}
+		if err := addMentions(tx, c, mentions); err != nil {
+			return err
+		}
+		c.Mentions = nil
+		return tx.Where("comment_id = ?", c.ID).Find(&c.Mentions).Error
+	})
+}
+
+// DeleteNoteComment soft-deletes the comment and clears its mentions.
+// Replies stay; the thread shows a placeholder in its place.
+func (s *Store) DeleteNoteComment(ctx context.Context, c *models.NoteComment) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("comment_id = ?", c.ID).Delete(&models.NoteMention{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(c).Error
+	})
+}
+
+// ListNoteComments returns the note's discussion as threads, oldest first.
+// A deleted comment is kept, with its body blanked, only while it has
+// live replies.
+func (s *Store) ListNoteComments(ctx context.Context, noteID uint) ([]*models.NoteComment, error) {
+	var all []*models.NoteComment
+	if err := s.DB.WithContext(ctx).Unscoped().Preload("Mentions").
+		Where("note_id = ?", noteID).Order("created_at ASC, id ASC").Find(&all).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.NoteComment, len(all))
+	for _, c := range all {
+		if c.DeletedAt.Valid {
+			c.Deleted = true
+			c.Body = ""
+			c.Mentions = nil
+		}
+		byID[c.ID] = c
+	}
+	var roots []*models.NoteComment
+	for _, c := range all {
+		if c.ParentID != nil {
+			if p, ok := byID[*c.ParentID]; ok {
+				p.Replies = append(p.Replies, c)
+				continue
+			}
+		}
+		roots = append(roots, c)
+	}
+	return pruneDeletedComments(roots), nil
+}
+
+// pruneDeletedComments drops deleted comments that have no live replies.
+func pruneDeletedComments(list []*models.NoteComment) []*models.NoteComment {
+	out := list[:0]
+	for _, c := range list {
+		c.Replies = pruneDeletedComments(c.Replies)
+		if c.Deleted && len(c.Replies) == 0 {
+			continue
+		}
+		out = append(out, c)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// MentionInboxItem is one entry of a user's mentions inbox.
+type MentionInboxItem struct {
+	CommentID uint       `json:"comment_id"`
+	NoteID    uint       `json:"note_id"`
+	NoteTitle string     `json:"note_title"`
+	StudentID string     `json:"student_id"`
+	AuthorID  string     `json:"author_id"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ListMentions returns the user's mentions, newest first. Mentions on notes
+// the user can no longer read are left out.
+func (s *Store) ListMentions(ctx context.Context, user *models.User, unreadOnly bool, limit, offset int) ([]MentionInboxItem, error) {
+	vis, args := noteVisibilitySQL("notes", user)
+	q := s.DB.WithContext(ctx).Table("note_mentions nm").
+		Select("nm.comment_id, nm.note_id, notes.title AS note_title, notes.user_id AS student_id, c.author_id, c.body, nm.read_at, nm.created_at").
+		Joins("JOIN note_comments c ON c.id = nm.comment_id AND c.deleted_at IS NULL").
+		Joins("JOIN notes ON notes.id = nm.note_id AND notes.deleted_at IS NULL").
+		Where("nm.user_id = ?", user.ID).
+		Where(vis, args...)
+	if unreadOnly {
+		q = q.Where("nm.read_at IS NULL")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	var out []MentionInboxItem
+	if err := q.Order("nm.created_at DESC, nm.comment_id DESC").Limit(limit).Offset(offset).Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MarkMentionsRead marks the given mentions of userID as read, or all of
+// them when commentIDs is empty. It returns how many changed.
+func (s *Store) MarkMentionsRead(ctx context.Context, userID string, commentIDs []uint) (int64, error) {
+	q := s.DB.WithContext(ctx).Model(&models.NoteMention{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(commentIDs) > 0 {
+		q = q.Where("comment_id IN ?", commentIDs)
+	}
+	res := q.Update("read_at", time.Now())
+	return res.RowsAffected, res.Error
+}
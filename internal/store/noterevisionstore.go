@@ -0,0 +1,336 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"github.com/madhava-poojari/dashboard-api/internal/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func nextRevision(tx *gorm.DB, model interface{}, column string, id uint) (int, error) {
+	var n int
+	err := tx.Model(model).Where(column+" = ?", id).Select("COALESCE(MAX(revision), 0)").Scan(&n).Error
+	return n + 1, err
+}
+
+// revisionInsertAttempts bounds how often createRevision renumbers a row
+// that lost a race for its revision number.
+const revisionInsertAttempts = 5
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate key.
+func isUniqueViolation(err error) bool {
+	var pg interface{ SQLState() string }
+	return errors.As(err, &pg) && pg.SQLState() == "23505"
+}
+
+// createRevision inserts row as the next revision of the parent id.
+// Concurrent edits can compute the same MAX+1; the unique (parent,
+// revision) index rejects all but one, and the others renumber and retry.
+// Each try runs under a savepoint so a conflict doesn't abort tx.
+func createRevision(tx *gorm.DB, row interface{}, setRevision func(int), model interface{}, column string, id uint) error {
+	for attempt := 1; ; attempt++ {
+		rev, err := nextRevision(tx, model, column, id)
+		if err != nil {
+			return err
+		}
+		setRevision(rev)
+		sp := fmt.Sprintf("revision_%d", attempt)
+		if err := tx.SavePoint(sp).Error; err != nil {
+			return err
+		}
+		err = tx.Create(row).Error
+		if err == nil {
+			return nil
+		}
+		if !isUniqueViolation(err) || attempt == revisionInsertAttempts {
+			return err
+		}
+		if err := tx.RollbackTo(sp).Error; err != nil {
+			return err
+		}
+	}
+}
+
+// recordNoteRevision snapshots the note's current content as its next revision.
+func recordNoteRevision(tx *gorm.DB, noteID uint, action, editor string, restoredFrom *int) error {
+	var n models.Note
+	if err := tx.Unscoped().First(&n, noteID).Error; err != nil {
+		return err
+	}
+	row := &models.NoteRevision{
+		NoteID:         n.ID,
+		Action:         action,
+		RestoredFrom:   restoredFrom,
+		Title:          n.Title,
+		Description:    n.Description,
+		PrimaryTag:     n.PrimaryTag,
+		Tags:           n.Tags,
+		IsStarred:      n.IsStarred,
+		AdditionalInfo: n.AdditionalInfo,
+		Visibility:     n.Visibility,
+		EditedBy:       editor,
+		EditedAt:       time.Now(),
+	}
+	return createRevision(tx, row, func(rev int) { row.Revision = rev }, &models.NoteRevision{}, "note_id", noteID)
+}
+
+// ensureNoteBaseline records the untouched content of notes written before
+// revisions were kept, so the first tracked edit can still be undone.
+func ensureNoteBaseline(tx *gorm.DB, noteID uint) error {
+	var cnt int64
+	if err := tx.Model(&models.NoteRevision{}).Where("note_id = ?", noteID).Count(&cnt).Error; err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return nil
+	}
+	var n models.Note
+	if err := tx.Unscoped().Select("created_by").First(&n, noteID).Error; err != nil {
+		return err
+	}
+	return recordNoteRevision(tx, noteID, models.RevisionImported, n.CreatedBy, nil)
+}
+
+func recordLessonPlanRevision(tx *gorm.DB, planID uint, action, editor string, restoredFrom *int) error {
+	var lp models.LessonPlan
+	if err := tx.Unscoped().First(&lp, planID).Error; err != nil {
+		return err
+	}
+	row := &models.LessonPlanRevision{
+		LessonPlanID: lp.ID,
+		Action:       action,
+		RestoredFrom: restoredFrom,
+		Title:        lp.Title,
+		Description:  lp.Description,
+		StartDate:    lp.StartDate,
+		EndDate:      lp.EndDate,
+		Result:       lp.Result,
+		Active:       lp.Active,
+		EditedBy:     editor,
+		EditedAt:     time.Now(),
+	}
+	return createRevision(tx, row, func(rev int) { row.Revision = rev }, &models.LessonPlanRevision{}, "lesson_plan_id", planID)
+}
+
+func ensureLessonPlanBaseline(tx *gorm.DB, planID uint) error {
+	var cnt int64
+	if err := tx.Model(&models.LessonPlanRevision{}).Where("lesson_plan_id = ?", planID).Count(&cnt).Error; err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return nil
+	}
+	var lp models.LessonPlan
+	if err := tx.Unscoped().Select("created_by").First(&lp, planID).Error; err != nil {
+		return err
+	}
+	return recordLessonPlanRevision(tx, planID, models.RevisionImported, lp.CreatedBy, nil)
+}
+
+// noteRevisionsFor scopes a query to the revisions requester could have
+// edited when they were written: each revision carries the visibility the
+// note had then, which may be narrower than the note's current level. The
+// revisions are aliased rv.
+func noteRevisionsFor(tx *gorm.DB, requester *models.User) *gorm.DB {
+	vis, args := noteAudienceSQL("rv", requester)
+	return tx.Table("(SELECT nr.*, n.user_id FROM note_revisions nr JOIN notes n ON n.id = nr.note_id) AS rv").
+		Where(vis, args...)
+}
+
+// ListNoteRevisions returns the note's revisions requester may see, newest
+// first.
+func (s *Store) ListNoteRevisions(ctx context.Context, requester *models.User, noteID uint) ([]models.NoteRevision, error) {
+	revs := []models.NoteRevision{}
+	if err := noteRevisionsFor(s.DB.WithContext(ctx), requester).
+		Where("rv.note_id = ?", noteID).
+		Order("rv.revision DESC").
+		Find(&revs).Error; err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// GetNoteRevision returns one revision, or not found when requester may not
+// see it.
+func (s *Store) GetNoteRevision(ctx context.Context, requester *models.User, noteID uint, revision int) (*models.NoteRevision, error) {
+	var rev models.NoteRevision
+	if err := noteRevisionsFor(s.DB.WithContext(ctx), requester).
+		Where("rv.note_id = ? AND rv.revision = ?", noteID, revision).
+		First(&rev).Error; err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// RestoreNoteRevision copies a revision's content back onto the note and
+// records that as a new revision, so restores can be undone too. A revision
+// requester may not see is not found.
+func (s *Store) RestoreNoteRevision(ctx context.Context, requester *models.User, noteID uint, revision int) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rev models.NoteRevision
+		if err := noteRevisionsFor(tx, requester).
+			Where("rv.note_id = ? AND rv.revision = ?", noteID, revision).
+			First(&rev).Error; err != nil {
+			return err
+		}
+		if err := ensureNoteBaseline(tx, noteID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Note{}).Where("id = ?", noteID).Updates(map[string]interface{}{
+			"title":           rev.Title,
+			"description":     rev.Description,
+			"primary_tag":     rev.PrimaryTag,
+			"tags":            rev.Tags,
+			"is_starred":      rev.IsStarred,
+			"additional_info": rev.AdditionalInfo,
+			"visibility":      rev.Visibility,
+			"updated_at":      time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return recordNoteRevision(tx, noteID, models.RevisionRestored, requester.ID, &revision)
+	})
+}
+
+// ListLessonPlanRevisions returns a lesson plan's revisions, newest first.
+func (s *Store) ListLessonPlanRevisions(ctx context.Context, planID uint) ([]models.LessonPlanRevision, error) {
+	var revs []models.LessonPlanRevision
+	if err := s.DB.WithContext(ctx).Where("lesson_plan_id = ?", planID).Order("revision DESC").Find(&revs).Error; err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+func (s *Store) GetLessonPlanRevision(ctx context.Context, planID uint, revision int) (*models.LessonPlanRevision, error) {
+	var rev models.LessonPlanRevision
+	if err := s.DB.WithContext(ctx).Where("lesson_plan_id = ? AND revision = ?", planID, revision).First(&rev).Error; err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// RestoreLessonPlanRevision copies a revision's content back onto the plan.
+// The active flag is left alone: activation goes through CreateLessonPlan.
+func (s *Store) RestoreLessonPlanRevision(ctx context.Context, planID uint, revision int, editor string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rev models.LessonPlanRevision
+		if err := tx.Where("lesson_plan_id = ? AND revision = ?", planID, revision).First(&rev).Error; err != nil {
+			return err
+		}
+		if err := ensureLessonPlanBaseline(tx, planID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.LessonPlan{}).Where("id = ?", planID).Updates(map[string]interface{}{
+			"title":       rev.Title,
+			"description": rev.Description,
+			"start_date":  rev.StartDate,
+			"end_date":    rev.EndDate,
+			"result":      rev.Result,
+			"updated_at":  time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return recordLessonPlanRevision(tx, planID, models.RevisionRestored, editor, &revision)
+	})
+}
+
+// RevisionDiff compares two revisions. Fields lists every changed field
+// except the body, which is diffed line by line in Description.
+type RevisionDiff struct {
+	From        int                    `json:"from"`
+	To          int                    `json:"to"`
+	Fields      map[string]FieldChange `json:"fields"`
+	Description []utils.DiffLine       `json:"description"`
+}
+
+func jsonValue(j datatypes.JSON) interface{} {
+	var v interface{}
+	if len(j) == 0 || json.Unmarshal(j, &v) != nil {
+		return nil
+	}
+	return v
+}
+
+func diffField(fields map[string]FieldChange, name string, a, b interface{}) {
+	if !reflect.DeepEqual(a, b) {
+		fields[name] = FieldChange{Old: a, New: b}
+	}
+}
+
+func DiffNoteRevisions(a, b *models.NoteRevision) RevisionDiff {
+	d := RevisionDiff{From: a.Revision, To: b.Revision, Fields: map[string]FieldChange{}}
+	diffField(d.Fields, "title", a.Title, b.Title)
+	diffField(d.Fields, "primary_tag", a.PrimaryTag, b.PrimaryTag)
+	diffField(d.Fields, "tags", jsonValue(a.Tags), jsonValue(b.Tags))
+	diffField(d.Fields, "is_starred", a.IsStarred, b.IsStarred)
+	diffField(d.Fields, "additional_info", jsonValue(a.AdditionalInfo), jsonValue(b.AdditionalInfo))
+	diffField(d.Fields, "visibility", a.Visibility, b.Visibility)
+	d.Description = utils.DiffLines(a.Description, b.Description)
+	return d
+}
+
+func lessonPlanLines(j datatypes.JSON) string {
+	var items []string
+	_ = json.Unmarshal(j, &items)
+	return strings.Join(items, "\n")
+}
+
+func DiffLessonPlanRevisions(a, b *models.LessonPlanRevision) RevisionDiff {
+	d := RevisionDiff{From: a.Revision, To: b.Revision, Fields: map[string]FieldChange{}}
+	diffField(d.Fields, "title", a.Title, b.Title)
+	diffField(d.Fields, "start_date", a.StartDate, b.StartDate)
+	diffField(d.Fields, "end_date", a.EndDate, b.EndDate)
+	diffField(d.Fields, "result", a.Result, b.Result)
+	diffField(d.Fields, "active", a.Active, b.Active)
+	d.Description = utils.DiffLines(lessonPlanLines(a.Description), lessonPlanLines(b.Description))
+	return d
+}
+
+// DeletedNote is a soft-deleted note as shown to admins.
+type DeletedNote struct {
+	models.Note
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// ListDeletedNotes returns soft-deleted notes, most recently deleted first,
+// optionally for one student.
+func (s *Store) ListDeletedNotes(ctx context.Context, userID string) ([]DeletedNote, error) {
+	q := s.DB.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL")
+	if userID != "" {
+		q = q.Where("user_id = ?", userID)
+	}
+	var notes []models.Note
+	if err := q.Order("deleted_at DESC").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	out := make([]DeletedNote, len(notes))
+	for i, n := range notes {
+		out[i] = DeletedNote{Note: n, DeletedAt: n.DeletedAt.Time}
+	}
+	return out, nil
+}
+
+// UndeleteNote clears a note's soft delete. It returns
+// gorm.ErrRecordNotFound when the note doesn't exist or isn't deleted.
+func (s *Store) UndeleteNote(ctx context.Context, noteID uint, editor string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Model(&models.Note{}).
+			Where("id = ? AND deleted_at IS NOT NULL", noteID).
+			Update("deleted_at", nil)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return recordNoteRevision(tx, noteID, models.RevisionUndeleted, editor, nil)
+	})
+}
@@ -6,9 +6,9 @@ import (
 	"time"
 
 	"github.com/madhava-poojari/dashboard-api/internal/models"
-	utils "github.com/madhava-poojari/dashboard-api/internal/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Tag restriction map (sensible defaults)
@@ -52,114 +52,327 @@ func (s *Store) IsMentorOf(ctx context.Context, mentorID, studentID string) (boo
 func (s *Store) CreateNote(ctx context.Context, n *models.Note) error {
 	n.CreatedAt = time.Now()
 	n.UpdatedAt = time.Now()
-	return s.DB.WithContext(ctx).Create(n).Error
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(n).Error; err != nil {
+			return err
+		}
+		return recordNoteRevision(tx, n.ID, models.RevisionCreated, n.CreatedBy, nil)
+	})
 }
 
-// CreateLessonPlan (archives existing active plan into a Note)
+// CreateLessonPlan makes lp the student's active plan. The previous active
+// plan is archived in place, keeping its items and linked sessions. Without
+// explicit items, one pending item is created per description line.
 func (s *Store) CreateLessonPlan(ctx context.Context, lp *models.LessonPlan) error {
 	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// find active plan
-		var old models.LessonPlan
-		if err := tx.Where("user_id = ? AND active = true", lp.UserID).First(&old).Error; err == nil {
-			// convert old -> Note
-			note := models.Note{
-				UserID:      old.UserID,
-				Title:       old.Title + " (archived)",
-				Description: utils.StringifyJSON(old.Description), // helper to convert JSON array to text
-				PrimaryTag:  "LessonPlanArchive",
-				Tags:        utils.DatatypesJSONFromStrings([]string{"LessonPlanArchive"}),
-				IsStarred:   false,
-				Visibility:  4, // default visibility; adjust if needed
-				CreatedBy:   old.CreatedBy,
-				CreatedAt:   time.Now(),
-				UpdatedAt:   time.Now(),
-			}
-			if err := tx.Create(&note).Error; err != nil {
-				return err
-			}
-			// deactivate old
-			if err := tx.Model(&models.LessonPlan{}).Where("id = ?", old.ID).Updates(map[string]interface{}{"active": false, "updated_at": time.Now()}).Error; err != nil {
-				return err
-			}
-		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return createLessonPlan(tx, lp)
+	})
+}
+
+func createLessonPlan(tx *gorm.DB, lp *models.LessonPlan) error {
+	now := time.Now()
+	var old models.LessonPlan
+	if err := tx.Where("user_id = ? AND active = true", lp.UserID).First(&old).Error; err == nil {
+		if err := ensureLessonPlanBaseline(tx, old.ID); err != nil {
 			return err
 		}
-		// create new plan
-		lp.CreatedAt = time.Now()
-		lp.UpdatedAt = time.Now()
-		lp.Active = true
-		return tx.Create(lp).Error
-	})
+		if err := tx.Model(&models.LessonPlan{}).Where("id = ?", old.ID).Updates(map[string]interface{}{"active": false, "archived_at": now, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		if err := recordLessonPlanRevision(tx, old.ID, models.RevisionUpdated, lp.CreatedBy, nil); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	// create new plan with its items
+	lp.CreatedAt = now
+	lp.UpdatedAt = now
+	lp.Active = true
+	if len(lp.Items) == 0 {
+		lp.Items = itemsFromDescription(lp.Description)
+	}
+	for i := range lp.Items {
+		lp.Items[i].Position = i + 1
+		if lp.Items[i].Status == "" {
+			lp.Items[i].Status = models.LessonPlanItemPending
+		}
+		if !ValidLessonPlanItemStatus(lp.Items[i].Status) {
+			return ErrInvalidItemStatus
+		}
+	}
+	if err := tx.Create(lp).Error; err != nil {
+		return err
+	}
+	ComputeLessonPlanProgress(lp)
+	return recordLessonPlanRevision(tx, lp.ID, models.RevisionCreated, lp.CreatedBy, nil)
 }
 
-// GetNotesByStudent returns active lesson plan + notes (paged)
-func (s *Store) GetNotesByStudent(ctx context.Context, userId string, limit, offset int) ([]*models.Note, *models.LessonPlan, error) {
+// GetNotesByStudent returns active lesson plan + the notes visible to
+// requester (offset paged). Visibility is applied in SQL so pages are full.
+func (s *Store) GetNotesByStudent(ctx context.Context, requester *models.User, userId string, limit, offset int) ([]*models.Note, *models.LessonPlan, error) {
 	var notes []*models.Note
 	if limit == 0 {
 		limit = 50
 	}
+	vis, args := noteVisibilitySQL("notes", requester)
 	if err := s.DB.WithContext(ctx).
 		Where("user_id = ?", userId).
+		Where(vis, args...).
 		Order("created_at desc").
 		Limit(limit).Offset(offset).
 		Find(&notes).Error; err != nil {
 		return nil, nil, err
 	}
+	lp, err := s.GetActiveLessonPlan(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return notes, lp, nil
+}
+
+// GetActiveLessonPlan returns the student's active plan with its items, or
+// nil without one.
+func (s *Store) GetActiveLessonPlan(ctx context.Context, userId string) (*models.LessonPlan, error) {
 	var lp models.LessonPlan
-	if err := s.DB.WithContext(ctx).Where("user_id = ? AND active = true", userId).First(&lp).Error; err != nil {
+	if err := preloadLessonPlanItems(s.DB.WithContext(ctx)).Where("user_id = ? AND active = true", userId).First(&lp).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return notes, nil, nil
+			return nil, nil
 		}
-		return nil, nil, err
+		return nil, err
 	}
-	return notes, &lp, nil
+	ComputeLessonPlanProgress(&lp)
+	return &lp, nil
 }
 
-func (s *Store) UpdateNoteFields(ctx context.Context, noteID string, updates map[string]interface{}) error {
-	updates["updated_at"] = time.Now()
-	return s.DB.WithContext(ctx).Model(&models.Note{}).Where("id = ?", noteID).Updates(updates).Error
+// NoteListSpec covers GET /notes when cursor paged.
+var NoteListSpec = ListSpec{
+	Sorts: map[string]Column{
+		"created_at": {Expr: "notes.created_at", Kind: KindTime},
+		"updated_at": {Expr: "notes.updated_at", Kind: KindTime},
+		"title":      {Expr: "notes.title", Kind: KindString},
+	},
+	Filters: map[string]Column{
+		"primary_tag": {Expr: "notes.primary_tag", Kind: KindString},
+		"is_starred":  {Expr: "notes.is_starred", Kind: KindBool},
+		"visibility":  {Expr: "notes.visibility", Kind: KindString}, // level name; handlers map legacy 1-4
+	},
+	DefaultSort: []SortField{{Field: "created_at", Desc: true}},
+	Key:         Column{Expr: "notes.id", Kind: KindInt},
 }
 
-func (s *Store) UpdateLessonPlanFields(ctx context.Context, planID string, updates map[string]interface{}) error {
-	updates["updated_at"] = time.Now()
-	// LessonPlan ID is uint
-	return s.DB.WithContext(ctx).Model(&models.LessonPlan{}).Where("id = ?", planID).Updates(updates).Error
+// ListNotesPage returns one page of the student's notes visible to requester.
+func (s *Store) ListNotesPage(ctx context.Context, requester *models.User, userID string, lq ListQuery) (*Page[models.Note], error) {
+	vis, args := noteVisibilitySQL("notes", requester)
+	q := s.DB.WithContext(ctx).Model(&models.Note{}).
+		Where("notes.user_id = ?", userID).
+		Where(vis, args...)
+	return Paginate[models.Note](q, NoteListSpec, lq)
 }
 
-func (s *Store) DeleteNoteSoft(ctx context.Context, noteID string) error {
-	return s.DB.WithContext(ctx).Where("id = ?", noteID).Delete(&models.Note{}).Error
+// ListNotesCreatedBetween returns the notes of the given students created
+// in [from, to) that requester may read, oldest first.
+func (s *Store) ListNotesCreatedBetween(ctx context.Context, requester *models.User, studentIDs []string, from, to time.Time) ([]*models.Note, error) {
+	if len(studentIDs) == 0 {
+		return nil, nil
+	}
+	vis, args := noteVisibilitySQL("notes", requester)
+	var out []*models.Note
+	err := s.DB.WithContext(ctx).
+		Where("notes.user_id IN ? AND notes.created_at >= ? AND notes.created_at < ?", studentIDs, from, to).
+		Where(vis, args...).
+		Order("notes.created_at, notes.id").
+		Find(&out).Error
+	return out, err
 }
 
-func (s *Store) CanAccessNoteForRequester(ctx context.Context, requester *models.User, n *models.Note) bool {
-	// visibility rules:
-	// 1 = admin only
-	// 2 = admin + mentor
-	// 3 = admin + mentor + coach
-	// 4 = student + their coach/mentor + admin
-	switch n.Visibility {
-	case 1:
-		return requester.Role == "admin"
-	case 2:
-		if requester.Role == "admin" {
-			return true
+// UpdateNoteFields applies updates and records the result as a revision by
+// editor.
+func (s *Store) UpdateNoteFields(ctx context.Context, noteID uint, updates map[string]interface{}, editor string) error {
+	updates["updated_at"] = time.Now()
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureNoteBaseline(tx, noteID); err != nil {
+			return err
 		}
-		ok, _ := s.IsMentorOf(ctx, requester.ID, n.UserID)
-		return ok
-	case 3:
-		if requester.Role == "admin" {
-			return true
+		if err := tx.Model(&models.Note{}).Where("id = ?", noteID).Updates(updates).Error; err != nil {
+			return err
 		}
-		isMentor, _ := s.IsMentorOf(ctx, requester.ID, n.UserID)
-		if isMentor {
-			return true
+		return recordNoteRevision(tx, noteID, models.RevisionUpdated, editor, nil)
+	})
+}
+
+// UpdateLessonPlanFields applies updates and records the result as a
+// revision by editor.
+func (s *Store) UpdateLessonPlanFields(ctx context.Context, planID uint, updates map[string]interface{}, editor string) error {
+	updates["updated_at"] = time.Now()
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureLessonPlanBaseline(tx, planID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.LessonPlan{}).Where("id = ?", planID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return recordLessonPlanRevision(tx, planID, models.RevisionUpdated, editor, nil)
+	})
+}
+
+// DeleteNoteSoft soft-deletes the note; its history is kept so admins can
+// undelete it.
+func (s *Store) DeleteNoteSoft(ctx context.Context, noteID uint, editor string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureNoteBaseline(tx, noteID); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", noteID).Delete(&models.Note{}).Error; err != nil {
+			return err
 		}
-		isCoach, _ := s.IsCoachOf(ctx, requester.ID, n.UserID)
-		return isCoach
-	case 4:
-		// any related (student themself or coach/mentor or admin)
-		ok, _ := s.IsRelatedStudent(ctx, requester.ID, n.UserID)
-		return ok
-	default:
+		return recordNoteRevision(tx, noteID, models.RevisionDeleted, editor, nil)
+	})
+}
+
+// GetNote loads a note by id, without any access check.
+func (s *Store) GetNote(ctx context.Context, id uint) (*models.Note, error) {
+	var n models.Note
+	if err := s.DB.WithContext(ctx).First(&n, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// CanAccessNoteForRequester reports whether requester may read n. The rules
+// live in noteVisibilitySQL so that single checks and list queries agree.
+func (s *Store) CanAccessNoteForRequester(ctx context.Context, requester *models.User, n *models.Note) bool {
+	if requester.Role == models.RoleAdmin {
+		return true
+	}
+	vis, args := noteVisibilitySQL("notes", requester)
+	var cnt int64
+	if err := s.DB.WithContext(ctx).Model(&models.Note{}).
+		Where("notes.id = ?", n.ID).
+		Where(vis, args...).
+		Count(&cnt).Error; err != nil {
 		return false
 	}
+	return cnt > 0
+}
+
+// CanModifyNoteForRequester reports whether requester may edit, delete or
+// share n: admins, and the student's staff at n's level (see noteStaffSQL).
+// The student, parents and users the note is shared with can only read it.
+func (s *Store) CanModifyNoteForRequester(ctx context.Context, requester *models.User, n *models.Note) bool {
+	if requester.Role == models.RoleAdmin {
+		return true
+	}
+	vis, args := noteStaffSQL("notes", requester)
+	var cnt int64
+	if err := s.DB.WithContext(ctx).Model(&models.Note{}).
+		Where("notes.id = ?", n.ID).
+		Where(vis, args...).
+		Count(&cnt).Error; err != nil {
+		return false
+	}
+	return cnt > 0
+}
+
+// noteStaffSQL matches the notes requester works on as the student's
+// staff: the mentor from "mentors" up, the coach from "staff" up.
+func noteStaffSQL(alias string, requester *models.User) (string, []interface{}) {
+	if requester.Role == models.RoleAdmin {
+		return "TRUE", nil
+	}
+	related := "EXISTS (SELECT 1 FROM relations r WHERE r.user_id = " + alias + ".user_id AND "
+	clause := "((" + alias + ".visibility = 'mentors' AND " + related + "r.mentor_id = ?))" +
+		" OR (" + alias + ".visibility IN ('staff', 'student_and_staff', 'parents') AND " + related + "(r.mentor_id = ? OR r.coach_id = ?))))"
+	id := requester.ID
+	return clause, []interface{}{id, id, id}
+}
+
+// noteAudienceSQL matches the notes whose visibility level includes
+// requester as staff or as the student (admins see every level):
+//
+//	admin_only        admins
+//	mentors           + the student's mentor
+//	staff             + the student's coach
+//	student_and_staff + the student
+//	parents           + family of the student (read only, see noteVisibilitySQL)
+func noteAudienceSQL(alias string, requester *models.User) (string, []interface{}) {
+	if requester.Role == models.RoleAdmin {
+		return "TRUE", nil
+	}
+	staff, args := noteStaffSQL(alias, requester)
+	clause := "(" + staff + " OR (" + alias + ".visibility IN ('student_and_staff', 'parents') AND " + alias + ".user_id = ?))"
+	return clause, append(args, requester.ID)
+}
+
+// noteVisibilitySQL is the read rule as a WHERE clause over the notes table
+// aliased as alias: the audience of the note's level, family for "parents"
+// notes, and anyone the note is shared with.
+func noteVisibilitySQL(alias string, requester *models.User) (string, []interface{}) {
+	if requester.Role == models.RoleAdmin {
+		return "TRUE", nil
+	}
+	audience, args := noteAudienceSQL(alias, requester)
+	clause := "(" + audience +
+		" OR (" + alias + ".visibility = 'parents' AND EXISTS (SELECT 1 FROM referral_relationships f WHERE f.relationship_type = 'family'" +
+		" AND ((f.referrer_id = ? AND f.referee_id = " + alias + ".user_id) OR (f.referee_id = ? AND f.referrer_id = " + alias + ".user_id))))" +
+		" OR EXISTS (SELECT 1 FROM note_shares ns WHERE ns.note_id = " + alias + ".id AND ns.user_id = ?))"
+	id := requester.ID
+	return clause, append(args, id, id, id)
+}
+
+// migrateNoteVisibility converts the old 1-4 visibility column to level
+// names before AutoMigrate sees it (see migration 000020). Values outside
+// 1-4 were readable by nobody and become admin_only.
+func migrateNoteVisibility(db *gorm.DB) error {
+	return db.Exec(`DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM information_schema.columns
+		WHERE table_name = 'notes' AND column_name = 'visibility' AND data_type = 'integer') THEN
+		ALTER TABLE notes ALTER COLUMN visibility DROP DEFAULT;
+		ALTER TABLE notes ALTER COLUMN visibility TYPE TEXT USING (
+			CASE visibility
+				WHEN 1 THEN 'admin_only'
+				WHEN 2 THEN 'mentors'
+				WHEN 3 THEN 'staff'
+				WHEN 4 THEN 'student_and_staff'
+				ELSE 'admin_only'
+			END);
+	END IF;
+END $$`).Error
+}
+
+// ListNoteShares returns who a note is shared with.
+func (s *Store) ListNoteShares(ctx context.Context, noteID uint) ([]models.NoteShare, error) {
+	var shares []models.NoteShare
+	if err := s.DB.WithContext(ctx).Where("note_id = ?", noteID).Order("created_at").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// ShareNote grants userID read access to the note; sharing twice is a no-op.
+func (s *Store) ShareNote(ctx context.Context, noteID uint, userID, sharedBy string) (*models.NoteShare, error) {
+	share := models.NoteShare{NoteID: noteID, UserID: userID, SharedBy: sharedBy, CreatedAt: time.Now()}
+	if err := s.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "note_id"}, {Name: "user_id"}}, DoNothing: true}).
+		Create(&share).Error; err != nil {
+		return nil, err
+	}
+	if err := s.DB.WithContext(ctx).Where("note_id = ? AND user_id = ?", noteID, userID).First(&share).Error; err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// UnshareNote revokes a share. It returns gorm.ErrRecordNotFound when the
+// note wasn't shared with userID.
+func (s *Store) UnshareNote(ctx context.Context, noteID uint, userID string) error {
+	res := s.DB.WithContext(ctx).Where("note_id = ? AND user_id = ?", noteID, userID).Delete(&models.NoteShare{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultNotificationChannels applies to users who never saved preferences.
+var DefaultNotificationChannels = []string{models.ChannelInApp, models.ChannelEmail}
+
+// ValidNotificationChannel reports whether c is a known delivery channel.
+func ValidNotificationChannel(c string) bool {
+	switch c {
+	case models.ChannelInApp, models.ChannelEmail, models.ChannelWebhook, models.ChannelWhatsApp:
+		return true
+	}
+	return false
+}
+
+func (s *Store) CreateNotification(ctx context.Context, n *models.Notification) error {
+	return s.DB.WithContext(ctx).Create(n).Error
+}
+
+// ClaimNotificationDelivery records that eventID is being sent to userID on
+// channel. It returns false if it already was, in which case the caller
+// must not send it again.
+func (s *Store) ClaimNotificationDelivery(ctx context.Context, eventID, userID, channel string) (bool, error) {
+	res := s.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.NotificationDelivery{EventID: eventID, UserID: userID, Channel: channel})
+	return res.RowsAffected == 1, res.Error
+}
+
+// ReleaseNotificationDelivery drops a claim whose send failed, so the next
+// attempt sends it.
+func (s *Store) ReleaseNotificationDelivery(ctx context.Context, eventID, userID, channel string) error {
+	return s.DB.WithContext(ctx).
+		Where("event_id = ? AND user_id = ? AND channel = ?", eventID, userID, channel).
+		Delete(&models.NotificationDelivery{}).Error
+}
+
+// ListNotifications returns the user's notifications, newest first.
+func (s *Store) ListNotifications(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	q := s.DB.WithContext(ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	var out []models.Notification
+	if err := q.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Store) CountUnreadNotifications(ctx context.Context, userID string) (int64, error) {
+	var n int64
+	err := s.DB.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&n).Error
+	return n, err
+}
+
+// MarkNotificationsRead marks the given notifications of userID as read, or
+// all of them when ids is empty. It returns how many changed.
+func (s *Store) MarkNotificationsRead(ctx context.Context, userID string, ids []uint) (int64, error) {
+	q := s.DB.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		q = q.Where("id IN ?", ids)
+	}
+	res := q.Update("read_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+// DeleteNotification removes one of userID's notifications.
+func (s *Store) DeleteNotification(ctx context.Context, userID string, id uint) error {
+	res := s.DB.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.Notification{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetNotificationPreference returns the user's saved preferences, or the
+// defaults when there are none.
+func (s *Store) GetNotificationPreference(ctx context.Context, userID string) (*models.NotificationPreference, error) {
+	var p models.NotificationPreference
+	err := s.DB.WithContext(ctx).Where("user_id = ?", userID).First(&p).Error
+	if err == nil {
+		return &p, nil
+	}
+	if !IsNotFound(err) {
+		return nil, err
+	}
+	b, _ := json.Marshal(DefaultNotificationChannels)
+	return &models.NotificationPreference{UserID: userID, Channels: datatypes.JSON(b), EventChannels: datatypes.JSONMap{}}, nil
+}
+
+func (s *Store) SaveNotificationPreference(ctx context.Context, p *models.NotificationPreference) error {
+	p.UpdatedAt = time.Now()
+	return s.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}}, UpdateAll: true}).
+		Create(p).Error
+}
+
+// ListDigestRecipients returns the active users who get the weekly digest:
+// students, mentors, and family of students, less those who opted out.
+func (s *Store) ListDigestRecipients(ctx context.Context) ([]*models.User, error) {
+	var out []*models.User
+	err := s.DB.WithContext(ctx).
+		Where("active = ? AND email <> ''", true).
+		Where(`role IN ? OR EXISTS (SELECT 1 FROM referral_relationships f JOIN users st
+			ON st.id = CASE WHEN f.referrer_id = users.id THEN f.referee_id ELSE f.referrer_id END
+			WHERE f.relationship_type = 'family' AND (f.referrer_id = users.id OR f.referee_id = users.id)
+			AND st.role = ? AND st.active = true)`,
+			[]models.Role{models.RoleStudent, models.RoleMentor}, models.RoleStudent).
+		Where("NOT EXISTS (SELECT 1 FROM notification_preferences p WHERE p.user_id = users.id AND p.digest_opt_out)").
+		Order("id").
+		Find(&out).Error
+	return out, err
+}
+
+// ChannelsForEvent resolves which channels p delivers eventType on.
+func ChannelsForEvent(p *models.NotificationPreference, eventType string) []string {
+	if v, ok := p.EventChannels[eventType]; ok {
+		return stringList(v)
+	}
+	var out []string
+	if len(p.Channels) > 0 {
+		_ = json.Unmarshal(p.Channels, &out)
+	}
+	return out
+}
+
+// stringList reads a JSON array decoded as []interface{}.
+func stringList(v interface{}) []string {
+	var out []string
+	switch vv := v.(type) {
+	case []interface{}:
+		for _, x := range vv {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+	case []string:
+		out = vv
+	}
+	return out
+}
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// FederationStudent is a user with a stored FIDE or USCF ID, as needed to
+// match rating list rows.
+type FederationStudent struct {
+	UserID    string
+	FirstName string
+	LastName  string
+	FIDEID    string
+	USCFID    string
+	DOB       *time.Time
+}
+
+// ListUsersWithFederationIDs returns users that have a FIDE or USCF ID stored.
+func (s *Store) ListUsersWithFederationIDs(ctx context.Context) ([]FederationStudent, error) {
+	var out []FederationStudent
+	err := s.DB.WithContext(ctx).
+		Table("users u").
+		Select("u.id AS user_id, u.first_name, u.last_name, ud.fide_id, ud.uscf_id, ud.dob").
+		Joins("JOIN user_details ud ON ud.user_id = u.id").
+		Where("COALESCE(ud.fide_id, '') != '' OR COALESCE(ud.uscf_id, '') != ''").
+		Scan(&out).Error
+	return out, err
+}
+
+// OfficialRatingColumns are the rating columns of official_ratings.
+var OfficialRatingColumns = []string{"standard_rating", "rapid_rating", "blitz_rating"}
+
+// SaveOfficialRating upserts the row for (user, federation, period). An
+// existing row only has ratingColumns overwritten, so a single-type list
+// doesn't clear the ratings another list recorded for the period; with no
+// ratingColumns every rating is replaced.
+func (s *Store) SaveOfficialRating(ctx context.Context, r *models.OfficialRating, ratingColumns ...string) error {
+	if len(ratingColumns) == 0 {
+		ratingColumns = OfficialRatingColumns
+	}
+	cols := append([]string{"external_id", "listed_name", "title", "source_file", "imported_at"}, ratingColumns...)
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "federation"}, {Name: "period"}},
+		DoUpdates: clause.AssignmentColumns(cols),
+	}).Create(r).Error
+}
+
+// FlagRatingIDMismatch records (or re-opens) a mismatch flag.
+func (s *Store) FlagRatingIDMismatch(ctx context.Context, m *models.RatingIDMismatch) error {
+	m.DetectedAt = time.Now()
+	m.ResolvedAt = nil
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "federation"}, {Name: "field"}},
+		DoUpdates: clause.AssignmentColumns([]string{"external_id", "expected", "actual", "detected_at", "resolved_at"}),
+	}).Create(m).Error
+}
+
+// ResolveRatingIDMismatches closes open flags for a user and federation
+// except the fields still failing.
+func (s *Store) ResolveRatingIDMismatches(ctx context.Context, userID, federation string, stillFailing []string) error {
+	q := s.DB.WithContext(ctx).Model(&models.RatingIDMismatch{}).
+		Where("user_id = ? AND federation = ? AND resolved_at IS NULL", userID, federation)
+	if len(stillFailing) > 0 {
+		q = q.Where("field NOT IN ?", stillFailing)
+	}
+	return q.Update("resolved_at", time.Now()).Error
+}
+
+// ListOfficialRatings returns a user's rating and title history, newest first.
+func (s *Store) ListOfficialRatings(ctx context.Context, userID string) ([]models.OfficialRating, error) {
+	var out []models.OfficialRating
+	err := s.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("federation, period desc").
+		Find(&out).Error
+	return out, err
+}
+
+// ListLatestOfficialRatings returns the newest row per federation for each user.
+func (s *Store) ListLatestOfficialRatings(ctx context.Context, userIDs []string) ([]models.OfficialRating, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var out []models.OfficialRating
+	err := s.DB.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (user_id, federation) *
+		FROM official_ratings
+		WHERE user_id IN ?
+		ORDER BY user_id, federation, period DESC
+	`, userIDs).Scan(&out).Error
+	return out, err
+}
+
+// ListOpenRatingIDMismatches returns unresolved mismatch flags for the users.
+func (s *Store) ListOpenRatingIDMismatches(ctx context.Context, userIDs []string) ([]models.RatingIDMismatch, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var out []models.RatingIDMismatch
+	err := s.DB.WithContext(ctx).
+		Where("user_id IN ? AND resolved_at IS NULL", userIDs).
+		Order("user_id, federation, field").
+		Find(&out).Error
+	return out, err
+}
+
+// OfficialRatingRosterRow is one student in the roster-wide official ratings report.
+type OfficialRatingRosterRow struct {
+	UserID    string                    `json:"user_id"`
+	FirstName string                    `json:"first_name"`
+	LastName  string                    `json:"last_name"`
+	FIDEID    string                    `json:"fide_id"`
+	USCFID    string                    `json:"uscf_id"`
+	FIDE      *models.OfficialRating    `json:"fide,omitempty"`
+	USCF      *models.OfficialRating    `json:"uscf,omitempty"`
+	Flags     []models.RatingIDMismatch `json:"flags"`
+}
+
+// OfficialRatingsRoster builds the report rows for the given students.
+func (s *Store) OfficialRatingsRoster(ctx context.Context, students []*models.User) ([]*OfficialRatingRosterRow, error) {
+	ids := make([]string, len(students))
+	for i, u := range students {
+		ids[i] = u.ID
+	}
+
+	var details []models.UserDetails
+	if len(ids) > 0 {
+		if err := s.DB.WithContext(ctx).Where("user_id IN ?", ids).Find(&details).Error; err != nil {
+			return nil, err
+		}
+	}
+	detailMap := make(map[string]models.UserDetails, len(details))
+	for _, d := range details {
+		detailMap[d.UserID] = d
+	}
+
+	latest, err := s.ListLatestOfficialRatings(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	flags, err := s.ListOpenRatingIDMismatches(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*OfficialRatingRosterRow, 0, len(students))
+	byID := make(map[string]*OfficialRatingRosterRow, len(students))
+	for _, u := range students {
+		d := detailMap[u.ID]
+		row := &OfficialRatingRosterRow{
+			UserID:    u.ID,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			FIDEID:    d.FIDEID,
+			USCFID:    d.USCFID,
+			Flags:     []models.RatingIDMismatch{},
+		}
+		rows = append(rows, row)
+		byID[u.ID] = row
+	}
+	for i := range latest {
+		r := &latest[i]
+		row := byID[r.UserID]
+		switch r.Federation {
+		case "fide":
+			row.FIDE = r
+		case "uscf":
+			row.USCF = r
+		}
+	}
+	for _, f := range flags {
+		byID[f.UserID].Flags = append(byID[f.UserID].Flags, f)
+	}
+	return rows, nil
+}
@@ -0,0 +1,180 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Outbox message kinds.
+const (
+	OutboxEvent         = "event"
+	OutboxStorageDelete = "storage.delete"
+	OutboxRatingSync    = "rating.sync"
+)
+
+// Named object stores a StorageDelete can target.
+const (
+	StorageImages  = "images"  // profile pictures and gallery
+	StorageUploads = "uploads" // note attachments
+)
+
+// StorageDelete is the payload of an OutboxStorageDelete message.
+type StorageDelete struct {
+	Storage string `json:"storage"`
+	Key     string `json:"key"`
+}
+
+// WithTx runs fn with a Store bound to one transaction, committing when fn
+// returns nil. Store methods called on tx (including Enqueue) share it.
+func (s *Store) WithTx(ctx context.Context, fn func(tx *Store) error) error {
+	return s.DB.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+		return fn(&Store{DB: db, Cfg: s.Cfg})
+	})
+}
+
+// Enqueue adds a message to the outbox. On a Store from WithTx it commits
+// or rolls back with the rest of the transaction. A key that is already
+// queued is ignored, so enqueueing the same side effect twice is harmless.
+func (s *Store) Enqueue(ctx context.Context, kind, key string, payload interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	m := models.OutboxMessage{
+		Kind:           kind,
+		IdempotencyKey: key,
+		Payload:        raw,
+		Status:         models.OutboxPending,
+		NextAttemptAt:  &now,
+	}
+	return s.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "idempotency_key"}}, DoNothing: true}).
+		Create(&m).Error
+}
+
+// EnqueueStorageDelete queues removal of key from the named storage.
+func (s *Store) EnqueueStorageDelete(ctx context.Context, storage, key string) error {
+	if key == "" {
+		return nil
+	}
+	return s.Enqueue(ctx, OutboxStorageDelete, OutboxStorageDelete+":"+storage+":"+key, StorageDelete{Storage: storage, Key: key})
+}
+
+// RatingSync is the payload of an OutboxRatingSync message.
+type RatingSync struct {
+	UserID string `json:"user_id"`
+}
+
+// EnqueueRatingSync queues an on-demand sync of the user's chess accounts.
+// Requests within the same minute share one message.
+func (s *Store) EnqueueRatingSync(ctx context.Context, userID string) error {
+	key := fmt.Sprintf("%s:%s:%d", OutboxRatingSync, userID, time.Now().Unix()/60)
+	return s.Enqueue(ctx, OutboxRatingSync, key, RatingSync{UserID: userID})
+}
+
+// ClaimOutboxMessages picks up to limit due messages, oldest first, and
+// pushes their next attempt out by lease so a dispatcher that dies
+// mid-message leaves it to be retried. Concurrent dispatchers skip each
+// other's rows.
+func (s *Store) ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	now := time.Now()
+	var out []models.OutboxMessage
+	err := s.DB.WithContext(ctx).Raw(`
+		UPDATE outbox_messages m
+		SET next_attempt_at = ?,
+		    attempts = m.attempts + 1,
+		    updated_at = ?
+		FROM (
+			SELECT id
+			FROM outbox_messages
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE m.id = due.id
+		RETURNING m.*
+	`, now.Add(lease), now, models.OutboxPending, now, limit).Scan(&out).Error
+	return out, err
+}
+
+// FinishOutboxMessage records the outcome of an attempt. A nil retryAt
+// with an error moves the message to the dead letters.
+func (s *Store) FinishOutboxMessage(ctx context.Context, id uint, errMsg string, retryAt *time.Time) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"last_error": errMsg,
+		"updated_at": now,
+	}
+	switch {
+	case errMsg == "":
+		updates["status"] = models.OutboxDone
+		updates["processed_at"] = now
+		updates["next_attempt_at"] = nil
+	case retryAt != nil:
+		updates["next_attempt_at"] = *retryAt
+	default:
+		updates["status"] = models.OutboxDead
+		updates["next_attempt_at"] = nil
+	}
+	return s.DB.WithContext(ctx).Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ListOutboxMessages returns messages newest first, optionally filtered by
+// status and kind.
+func (s *Store) ListOutboxMessages(ctx context.Context, status, kind string, limit, offset int) ([]models.OutboxMessage, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	q := s.DB.WithContext(ctx).Model(&models.OutboxMessage{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if kind != "" {
+		q = q.Where("kind = ?", kind)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []models.OutboxMessage
+	err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&out).Error
+	return out, total, err
+}
+
+// RetryOutboxMessage puts a dead message back in the queue with a fresh
+// set of attempts.
+func (s *Store) RetryOutboxMessage(ctx context.Context, id uint) error {
+	now := time.Now()
+	res := s.DB.WithContext(ctx).Model(&models.OutboxMessage{}).
+		Where("id = ? AND status = ?", id, models.OutboxDead).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeOutboxMessages deletes messages handled before cutoff. Dead letters
+// are kept until an admin retries them.
+func (s *Store) PurgeOutboxMessages(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := s.DB.WithContext(ctx).
+		Where("status = ? AND processed_at < ?", models.OutboxDone, cutoff).
+		Delete(&models.OutboxMessage{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,281 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+// ValueKind tells how filter values and cursor keys are converted before
+// they are bound to a column.
+type ValueKind int
+
+const (
+	KindString ValueKind = iota
+	KindInt
+	KindBool
+	KindTime
+)
+
+// Column is a sortable or filterable field of a list. Sortable columns must
+// not be NULL, since keyset comparisons never match NULL.
+type Column struct {
+	Expr string
+	Kind ValueKind
+}
+
+// ListSpec declares what a list endpoint accepts. Field names are the JSON
+// names of the listed items, which is also how cursor keys are read back.
+type ListSpec struct {
+	Sorts       map[string]Column
+	Filters     map[string]Column
+	DefaultSort []SortField
+	Key         Column   // unique tiebreaker, JSON field "id"
+	Select      string   // column list for the page query, when not the model's
+	Preload     []string // associations loaded for the page only
+}
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery is the paging, sorting and filtering part of a list request.
+type ListQuery struct {
+	Limit     int
+	Cursor    string
+	Sort      []SortField
+	Filters   map[string][]string // field -> accepted values (OR)
+	WithTotal bool
+}
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// Paged reports whether the client asked for a page. Endpoints keep
+// returning full lists when neither limit nor cursor is given.
+func (q ListQuery) Paged() bool {
+	return q.Limit > 0 || q.Cursor != ""
+}
+
+// Page is one page of a list. NextCursor is empty on the last page; Total is
+// only set when requested.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+type cursorPayload struct {
+	Sort string        `json:"s"`
+	Keys []interface{} `json:"k"`
+}
+
+func sortString(sort []SortField) string {
+	parts := make([]string, len(sort))
+	for i, f := range sort {
+		dir := "asc"
+		if f.Desc {
+			dir = "desc"
+		}
+		parts[i] = f.Field + ":" + dir
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseSort parses "field:dir,field:dir" against the spec. An empty string
+// yields the spec's default order.
+func (spec ListSpec) ParseSort(v string) ([]SortField, error) {
+	if v == "" {
+		return spec.DefaultSort, nil
+	}
+	var out []SortField
+	for _, part := range strings.Split(v, ",") {
+		field, dir, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if _, ok := spec.Sorts[field]; !ok {
+			return nil, fmt.Errorf("cannot sort by %q", field)
+		}
+		switch strings.ToLower(dir) {
+		case "", "asc":
+			out = append(out, SortField{Field: field})
+		case "desc":
+			out = append(out, SortField{Field: field, Desc: true})
+		default:
+			return nil, fmt.Errorf("invalid sort direction %q", dir)
+		}
+	}
+	return out, nil
+}
+
+func convertValue(kind ValueKind, v interface{}) (interface{}, error) {
+	s := fmt.Sprint(v)
+	switch kind {
+	case KindInt:
+		if f, ok := v.(float64); ok {
+			return int64(f), nil
+		}
+		return strconv.ParseInt(s, 10, 64)
+	case KindBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		return strconv.ParseBool(s)
+	case KindTime:
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", s)
+	default:
+		return s, nil
+	}
+}
+
+// Paginate applies the query's filters, sort and cursor to q and fetches one
+// page into T. q must already carry the endpoint's own scoping (role rules,
+// soft-delete, etc.) so that totals and pages agree, and no preloads or order.
+func Paginate[T any](q *gorm.DB, spec ListSpec, lq ListQuery) (*Page[T], error) {
+	for field, values := range lq.Filters {
+		col, ok := spec.Filters[field]
+		if !ok || len(values) == 0 {
+			continue
+		}
+		args := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			a, err := convertValue(col.Kind, v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidFilter, field, err)
+			}
+			args = append(args, a)
+		}
+		q = q.Where(col.Expr+" IN ?", args)
+	}
+
+	page := &Page[T]{Items: []T{}}
+	if lq.WithTotal {
+		var total int64
+		if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	sort := lq.Sort
+	if len(sort) == 0 {
+		sort = spec.DefaultSort
+	}
+	cols := make([]Column, 0, len(sort)+1)
+	desc := make([]bool, 0, len(sort)+1)
+	for _, f := range sort {
+		cols = append(cols, spec.Sorts[f.Field])
+		desc = append(desc, f.Desc)
+	}
+	// The tiebreaker follows the direction of the last sort field.
+	cols = append(cols, spec.Key)
+	desc = append(desc, len(desc) > 0 && desc[len(desc)-1])
+
+	if lq.Cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(lq.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		var c cursorPayload
+		if err := json.Unmarshal(raw, &c); err != nil || c.Sort != sortString(sort) || len(c.Keys) != len(cols) {
+			return nil, ErrInvalidCursor
+		}
+		keys := make([]interface{}, len(cols))
+		for i, k := range c.Keys {
+			if keys[i], err = convertValue(cols[i].Kind, k); err != nil {
+				return nil, ErrInvalidCursor
+			}
+		}
+		// (a > x) OR (a = x AND b > y) OR ... with per-column direction
+		var ors []string
+		var args []interface{}
+		for i := range cols {
+			var ands []string
+			for j := 0; j < i; j++ {
+				ands = append(ands, cols[j].Expr+" = ?")
+				args = append(args, keys[j])
+			}
+			op := " > ?"
+			if desc[i] {
+				op = " < ?"
+			}
+			ands = append(ands, cols[i].Expr+op)
+			args = append(args, keys[i])
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+		q = q.Where("("+strings.Join(ors, " OR ")+")", args...)
+	}
+
+	for i, c := range cols {
+		dir := " ASC"
+		if desc[i] {
+			dir = " DESC"
+		}
+		q = q.Order(c.Expr + dir)
+	}
+
+	limit := lq.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	if spec.Select != "" {
+		q = q.Select(spec.Select)
+	}
+	for _, assoc := range spec.Preload {
+		q = q.Preload(assoc)
+	}
+	var items []T
+	if err := q.Limit(limit + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) > limit {
+		items = items[:limit]
+		next, err := encodeCursor(items[len(items)-1], sort)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = next
+	}
+	page.Items = append(page.Items, items...)
+	return page, nil
+}
+
+// encodeCursor reads the sort keys of the last row back from its JSON form.
+func encodeCursor(last interface{}, sort []SortField) (string, error) {
+	b, err := json.Marshal(last)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return "", err
+	}
+	keys := make([]interface{}, 0, len(sort)+1)
+	for _, f := range sort {
+		keys = append(keys, fields[f.Field])
+	}
+	keys = append(keys, fields["id"])
+	b, err = json.Marshal(cursorPayload{Sort: sortString(sort), Keys: keys})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
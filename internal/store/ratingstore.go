@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ChessAccount is an active student with at least one external chess username.
+type ChessAccount struct {
+	UserID           string
+	LichessUsername  string
+	ChesscomUsername string
+}
+
+// ListChessAccountsForSync returns active, approved students that have a
+// lichess or chess.com username set.
+func (s *Store) ListChessAccountsForSync(ctx context.Context) ([]ChessAccount, error) {
+	var out []ChessAccount
+	err := s.DB.WithContext(ctx).
+		Table("user_details ud").
+		Select("ud.user_id, ud.lichess_username, ud.chesscom_username").
+		Joins("JOIN users u ON u.id = ud.user_id").
+		Where("u.active = true AND u.approved = true AND u.role = ?", models.RoleStudent).
+		Where("COALESCE(ud.lichess_username, '') != '' OR COALESCE(ud.chesscom_username, '') != ''").
+		Order("ud.user_id").
+		Scan(&out).Error
+	return out, err
+}
+
+// GetChessAccountForUser returns the external usernames of a single user.
+func (s *Store) GetChessAccountForUser(ctx context.Context, userID string) (*ChessAccount, error) {
+	var ud models.UserDetails
+	if err := s.DB.WithContext(ctx).Where("user_id = ?", userID).First(&ud).Error; err != nil {
+		return nil, err
+	}
+	return &ChessAccount{UserID: ud.UserID, LichessUsername: ud.LichessUsername, ChesscomUsername: ud.ChesscomUsername}, nil
+}
+
+// GetChessAccountSync returns the sync cursor row, or a fresh unsaved one.
+func (s *Store) GetChessAccountSync(ctx context.Context, userID, source string) (*models.ChessAccountSync, error) {
+	var cs models.ChessAccountSync
+	err := s.DB.WithContext(ctx).Where("user_id = ? AND source = ?", userID, source).First(&cs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.ChessAccountSync{UserID: userID, Source: source}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cs, nil
+}
+
+// SaveChessAccountSync upserts a sync cursor row.
+func (s *Store) SaveChessAccountSync(ctx context.Context, cs *models.ChessAccountSync) error {
+	cs.UpdatedAt = time.Now()
+	return s.DB.WithContext(ctx).Save(cs).Error
+}
+
+// ListChessAccountSyncs returns the sync state of every account of a user.
+func (s *Store) ListChessAccountSyncs(ctx context.Context, userID string) ([]models.ChessAccountSync, error) {
+	var out []models.ChessAccountSync
+	err := s.DB.WithContext(ctx).Where("user_id = ?", userID).Order("source").Find(&out).Error
+	return out, err
+}
+
+// RecordRatingSnapshots appends a point to each series whose rating or game
+// count changed since its latest snapshot, so idle accounts don't grow the table.
+func (s *Store) RecordRatingSnapshots(ctx context.Context, snaps []models.RatingSnapshot) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, snap := range snaps {
+			var last models.RatingSnapshot
+			err := tx.Where("user_id = ? AND source = ? AND time_control = ?", snap.UserID, snap.Source, snap.TimeControl).
+				Order("recorded_at desc").First(&last).Error
+			if err == nil && last.Rating == snap.Rating && last.Games == snap.Games {
+				continue
+			}
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			snap.ID = 0
+			if snap.RecordedAt.IsZero() {
+				snap.RecordedAt = time.Now()
+			}
+			if err := tx.Create(&snap).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SaveExternalGames inserts games, skipping ones already stored.
+func (s *Store) SaveExternalGames(ctx context.Context, games []models.ExternalGame) error {
+	if len(games) == 0 {
+		return nil
+	}
+	return s.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}, {Name: "source"}, {Name: "game_id"}}, DoNothing: true}).
+		CreateInBatches(&games, 100).Error
+}
+
+// migrateExternalGamesKey drops the old (source, game_id) key before
+// AutoMigrate adds the per-user one (see migration 000035): a game between
+// two of our students belongs to both of them.
+func migrateExternalGamesKey(db *gorm.DB) error {
+	return db.Exec("ALTER TABLE IF EXISTS external_games DROP CONSTRAINT IF EXISTS idx_external_games_source_game").Error
+}
+
+// RatingHistoryFilter narrows GetRatingHistory; empty fields match everything.
+type RatingHistoryFilter struct {
+	Source      string
+	TimeControl string
+	From        *time.Time
+	To          *time.Time
+}
+
+// GetRatingHistory returns a user's rating snapshots oldest first.
+func (s *Store) GetRatingHistory(ctx context.Context, userID string, f RatingHistoryFilter) ([]models.RatingSnapshot, error) {
+	q := s.DB.WithContext(ctx).Where("user_id = ?", userID)
+	if f.Source != "" {
+		q = q.Where("source = ?", f.Source)
+	}
+	if f.TimeControl != "" {
+		q = q.Where("time_control = ?", f.TimeControl)
+	}
+	if f.From != nil {
+		q = q.Where("recorded_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("recorded_at < ?", *f.To)
+	}
+	var out []models.RatingSnapshot
+	err := q.Order("source, time_control, recorded_at").Find(&out).Error
+	return out, err
+}
+
+// ListExternalGamesSince returns a user's games played at or after since, newest first.
+func (s *Store) ListExternalGamesSince(ctx context.Context, userID string, since time.Time) ([]models.ExternalGame, error) {
+	var out []models.ExternalGame
+	err := s.DB.WithContext(ctx).
+		Where("user_id = ? AND played_at >= ?", userID, since).
+		Order("played_at desc").
+		Find(&out).Error
+	return out, err
+}
+
+// GetLastAttendanceDate returns the date of the student's most recent class,
+// or nil if they have none.
+func (s *Store) GetLastAttendanceDate(ctx context.Context, studentID string) (*time.Time, error) {
+	var a models.Attendance
+	err := s.DB.WithContext(ctx).
+		Where("student_id = ?", studentID).
+		Order("date desc").
+		First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a.Date, nil
+}
@@ -238,3 +238,79 @@ func (s *Store) GetNodeDetail(ctx context.Context, userID string) (map[string]in
 		"referred_to":         referredToList,
 	}, nil
 }
+
+// ReferralGraphNode is one node of a paged referral graph, shaped like the
+// nodes of GetFullReferralGraph.
+type ReferralGraphNode struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	State             string `json:"state"`
+	City              string `json:"city"`
+	Role              string `json:"role"`
+	ProfilePictureURL string `json:"profile_picture_url"`
+}
+
+// ReferralGraphListSpec covers GET /referral-network/graph when paged.
+var ReferralGraphListSpec = ListSpec{
+	Sorts: map[string]Column{
+		"name":  {Expr: "users.first_name || ' ' || users.last_name", Kind: KindString},
+		"state": {Expr: "COALESCE(user_details.state, '')", Kind: KindString},
+		"city":  {Expr: "COALESCE(user_details.city, '')", Kind: KindString},
+	},
+	Filters: map[string]Column{
+		"role": {Expr: "users.role", Kind: KindString},
+		"city": {Expr: "user_details.city", Kind: KindString},
+	},
+	DefaultSort: []SortField{{Field: "name"}},
+	Key:         Column{Expr: "users.id", Kind: KindString},
+	Select: "users.id, users.first_name || ' ' || users.last_name AS name, users.role, " +
+		"COALESCE(user_details.profile_picture_url, '') AS profile_picture_url, " +
+		"COALESCE(user_details.state, '') AS state, COALESCE(user_details.city, '') AS city",
+}
+
+// GetReferralGraphPage returns one page of nodes and every edge touching
+// them, so a client can grow the graph page by page.
+func (s *Store) GetReferralGraphPage(ctx context.Context, stateFilter string, lq ListQuery) (map[string]interface{}, error) {
+	q := s.DB.WithContext(ctx).
+		Table("users").
+		Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
+		Where("users.active = true")
+	if stateFilter != "" {
+		q = q.Where("user_details.state = ?", stateFilter)
+	}
+	nodes, err := Paginate[ReferralGraphNode](q, ReferralGraphListSpec, lq)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(nodes.Items))
+	for i, n := range nodes.Items {
+		ids[i] = n.ID
+	}
+	var relationships []models.ReferralRelationship
+	if len(ids) > 0 {
+		if err := s.DB.WithContext(ctx).
+			Where("referrer_id IN ? OR referee_id IN ?", ids, ids).
+			Find(&relationships).Error; err != nil {
+			return nil, err
+		}
+	}
+	edges := make([]map[string]interface{}, len(relationships))
+	for i, rel := range relationships {
+		edges[i] = map[string]interface{}{
+			"id":                       rel.ID,
+			"source":                   rel.ReferrerID,
+			"target":                   rel.RefereeID,
+			"relationship_type":        rel.RelationshipType,
+			"relationship_description": rel.RelationshipDescription,
+			"created_at":               rel.CreatedAt,
+		}
+	}
+
+	return map[string]interface{}{
+		"nodes":       nodes.Items,
+		"edges":       edges,
+		"next_cursor": nodes.NextCursor,
+		"total":       nodes.Total,
+	}, nil
+}
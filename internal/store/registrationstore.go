@@ -0,0 +1,261 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// GetTournament returns a scraped tournament by id.
+func (s *Store) GetTournament(ctx context.Context, id uint) (*models.Tournament, error) {
+	var t models.Tournament
+	if err := s.DB.WithContext(ctx).First(&t, id).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTournamentRegistration returns the registration of a student for a tournament.
+func (s *Store) GetTournamentRegistration(ctx context.Context, tournamentID uint, studentID string) (*models.TournamentRegistration, error) {
+	var reg models.TournamentRegistration
+	if err := s.DB.WithContext(ctx).
+		Where("tournament_id = ? AND student_id = ?", tournamentID, studentID).
+		First(&reg).Error; err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+// SetTournamentRegistrationStatus creates the registration or updates its
+// status and section, leaving any recorded result untouched.
+func (s *Store) SetTournamentRegistrationStatus(ctx context.Context, tournamentID uint, studentID string, status models.TournamentRegistrationStatus, section, actorID string) (*models.TournamentRegistration, error) {
+	var out *models.TournamentRegistration
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var reg models.TournamentRegistration
+		err := tx.Where("tournament_id = ? AND student_id = ?", tournamentID, studentID).First(&reg).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			reg = models.TournamentRegistration{
+				TournamentID: tournamentID,
+				StudentID:    studentID,
+				Status:       status,
+				Section:      section,
+				CreatedBy:    actorID,
+				UpdatedBy:    actorID,
+			}
+			if err := tx.Create(&reg).Error; err != nil {
+				return err
+			}
+			out = &reg
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"status":     status,
+			"updated_by": actorID,
+			"updated_at": time.Now(),
+		}
+		if section != "" {
+			updates["section"] = section
+		}
+		if err := tx.Model(&reg).Updates(updates).Error; err != nil {
+			return err
+		}
+		out = &reg
+		return nil
+	})
+	return out, err
+}
+
+// TournamentResult is the post-event outcome of a registration.
+type TournamentResult struct {
+	Score        *float64
+	Place        *int
+	RatingChange *int
+	Section      string
+	Notes        string
+}
+
+// RecordTournamentResult stores a result on the registration, creating it as
+// "registered" if the student was never marked as going.
+func (s *Store) RecordTournamentResult(ctx context.Context, tournamentID uint, studentID string, res TournamentResult, actorID string) (*models.TournamentRegistration, error) {
+	var out *models.TournamentRegistration
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var reg models.TournamentRegistration
+		err := tx.Where("tournament_id = ? AND student_id = ?", tournamentID, studentID).First(&reg).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			reg = models.TournamentRegistration{
+				TournamentID: tournamentID,
+				StudentID:    studentID,
+				Status:       models.TournamentRegistrationRegistered,
+				CreatedBy:    actorID,
+			}
+		} else if err != nil {
+			return err
+		}
+		reg.Score = res.Score
+		reg.Place = res.Place
+		reg.RatingChange = res.RatingChange
+		reg.ResultNotes = res.Notes
+		if res.Section != "" {
+			reg.Section = res.Section
+		}
+		reg.ResultRecordedBy = actorID
+		reg.ResultRecordedAt = &now
+		reg.UpdatedBy = actorID
+		if err := tx.Save(&reg).Error; err != nil {
+			return err
+		}
+		out = &reg
+		return nil
+	})
+	return out, err
+}
+
+// DeleteTournamentRegistration removes a student's registration entirely.
+func (s *Store) DeleteTournamentRegistration(ctx context.Context, tournamentID uint, studentID string) error {
+	res := s.DB.WithContext(ctx).
+		Where("tournament_id = ? AND student_id = ?", tournamentID, studentID).
+		Delete(&models.TournamentRegistration{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListTournamentRegistrations returns the registrations for a tournament,
+// limited to studentIDs when non-nil.
+func (s *Store) ListTournamentRegistrations(ctx context.Context, tournamentID uint, studentIDs []string) ([]models.TournamentRegistration, error) {
+	q := s.DB.WithContext(ctx).
+		Preload("Student").
+		Where("tournament_id = ?", tournamentID)
+	if studentIDs != nil {
+		if len(studentIDs) == 0 {
+			return []models.TournamentRegistration{}, nil
+		}
+		q = q.Where("student_id IN ?", studentIDs)
+	}
+	var out []models.TournamentRegistration
+	err := q.Order("created_at").Find(&out).Error
+	return out, err
+}
+
+// ListStudentTournamentRegistrations returns a student's registrations and
+// results, most recent tournament first.
+func (s *Store) ListStudentTournamentRegistrations(ctx context.Context, studentID string) ([]models.TournamentRegistration, error) {
+	var out []models.TournamentRegistration
+	err := s.DB.WithContext(ctx).
+		Preload("Tournament").
+		Joins("JOIN tournaments t ON t.id = tournament_registrations.tournament_id").
+		Where("tournament_registrations.student_id = ?", studentID).
+		Order("t.start_date DESC NULLS LAST").
+		Find(&out).Error
+	return out, err
+}
+
+// RosterStudent is one student going to a tournament in the upcoming roster.
+type RosterStudent struct {
+	StudentID string                              `json:"student_id"`
+	FirstName string                              `json:"first_name"`
+	LastName  string                              `json:"last_name"`
+	CoachID   string                              `json:"coach_id"`  // first of CoachIDs, empty if none
+	CoachIDs  []string                            `json:"coach_ids"` // every coach assigned to the student
+	Status    models.TournamentRegistrationStatus `json:"status"`
+	Section   string                              `json:"section"`
+}
+
+// TournamentRoster groups the students going to one tournament.
+type TournamentRoster struct {
+	Tournament models.Tournament `json:"tournament"`
+	Students   []RosterStudent   `json:"students"`
+}
+
+// UpcomingTournamentRoster returns tournaments starting on or after from
+// (and before to, if set) with the students planning to attend or registered,
+// grouped by tournament. studentIDs limits the students when non-nil.
+func (s *Store) UpcomingTournamentRoster(ctx context.Context, from time.Time, to *time.Time, studentIDs []string) ([]TournamentRoster, error) {
+	if studentIDs != nil && len(studentIDs) == 0 {
+		return []TournamentRoster{}, nil
+	}
+	type row struct {
+		TournamentID uint
+		StudentID    string
+		FirstName    string
+		LastName     string
+		CoachIDs     string
+		Status       models.TournamentRegistrationStatus
+		Section      string
+	}
+	// A student can have several coaches; aggregate them so each student is
+	// listed once per tournament.
+	q := s.DB.WithContext(ctx).
+		Table("tournament_registrations tr").
+		Select("tr.tournament_id, tr.student_id, u.first_name, u.last_name, COALESCE(rc.coach_ids, '') AS coach_ids, tr.status, tr.section").
+		Joins("JOIN tournaments t ON t.id = tr.tournament_id").
+		Joins("JOIN users u ON u.id = tr.student_id").
+		Joins(`LEFT JOIN (
+			SELECT user_id, string_agg(DISTINCT coach_id, ',' ORDER BY coach_id) AS coach_ids
+			FROM relations GROUP BY user_id
+		) rc ON rc.user_id = tr.student_id`).
+		Where("tr.status IN ?", []models.TournamentRegistrationStatus{models.TournamentRegistrationPlanning, models.TournamentRegistrationRegistered}).
+		Where("t.start_date >= ?", from)
+	if to != nil {
+		q = q.Where("t.start_date < ?", *to)
+	}
+	if studentIDs != nil {
+		q = q.Where("tr.student_id IN ?", studentIDs)
+	}
+	var rows []row
+	if err := q.Order("t.start_date, t.id, u.first_name, u.last_name").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	byID := map[uint]*TournamentRoster{}
+	for _, r := range rows {
+		g, ok := byID[r.TournamentID]
+		if !ok {
+			g = &TournamentRoster{Students: []RosterStudent{}}
+			byID[r.TournamentID] = g
+			ids = append(ids, r.TournamentID)
+		}
+		st := RosterStudent{
+			StudentID: r.StudentID,
+			FirstName: r.FirstName,
+			LastName:  r.LastName,
+			CoachIDs:  []string{},
+			Status:    r.Status,
+			Section:   r.Section,
+		}
+		if r.CoachIDs != "" {
+			st.CoachIDs = strings.Split(r.CoachIDs, ",")
+			st.CoachID = st.CoachIDs[0]
+		}
+		g.Students = append(g.Students, st)
+	}
+	if len(ids) == 0 {
+		return []TournamentRoster{}, nil
+	}
+
+	var tournaments []models.Tournament
+	if err := s.DB.WithContext(ctx).Where("id IN ?", ids).Find(&tournaments).Error; err != nil {
+		return nil, err
+	}
+	for _, t := range tournaments {
+		byID[t.ID].Tournament = t
+	}
+	out := make([]TournamentRoster, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, *byID[id])
+	}
+	return out, nil
+}
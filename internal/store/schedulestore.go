@@ -7,8 +7,31 @@ import (
 	"strings"
 
 	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
 )
 
+// scheduleCoachBackfill gives schedules made before coach_id existed the
+// student's coach, where the student has exactly one (see migration
+// 000040). The others stay unset until someone picks the coach.
+const scheduleCoachBackfill = `
+	UPDATE class_schedules cs SET coach_id = r.coach_id
+	FROM (SELECT user_id, MIN(coach_id) AS coach_id FROM relations GROUP BY user_id HAVING COUNT(*) = 1) r
+	WHERE r.user_id = cs.student_id AND (cs.coach_id IS NULL OR cs.coach_id = '')`
+
+func migrateSchedules(db *gorm.DB) error {
+	return db.Exec(scheduleCoachBackfill).Error
+}
+
+// ListCoachIDsForStudent returns the ids of the student's coaches.
+func (s *Store) ListCoachIDsForStudent(ctx context.Context, studentID string) ([]string, error) {
+	var out []string
+	err := s.DB.WithContext(ctx).Table("relations").
+		Where("user_id = ? AND coach_id <> ''", studentID).
+		Order("coach_id").
+		Pluck("coach_id", &out).Error
+	return out, err
+}
+
 // addOneHour adds 1 hour to a "HH:MM" or "HH:MM:SS" time string and returns "HH:MM".
 func addOneHour(t string) string {
 	parts := strings.Split(t, ":")
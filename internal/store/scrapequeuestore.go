@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultScrapeStaleAfter    = 24 * time.Hour
+	defaultScrapeLeaseDuration = 15 * time.Minute
+	defaultScrapeMaxAttempts   = 5
+)
+
+func (s *Store) scrapeStaleAfter() time.Duration {
+	if s.Cfg != nil && s.Cfg.ScrapeStaleAfter > 0 {
+		return s.Cfg.ScrapeStaleAfter
+	}
+	return defaultScrapeStaleAfter
+}
+
+func (s *Store) scrapeLeaseDuration() time.Duration {
+	if s.Cfg != nil && s.Cfg.ScrapeLeaseDuration > 0 {
+		return s.Cfg.ScrapeLeaseDuration
+	}
+	return defaultScrapeLeaseDuration
+}
+
+func (s *Store) scrapeMaxAttempts() int {
+	if s.Cfg != nil && s.Cfg.ScrapeMaxAttempts > 0 {
+		return s.Cfg.ScrapeMaxAttempts
+	}
+	return defaultScrapeMaxAttempts
+}
+
+// scrapeRetryBackoff doubles from one minute per attempt, capped at the
+// staleness window.
+func (s *Store) scrapeRetryBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 20 {
+		attempts = 20
+	}
+	d := time.Minute << (attempts - 1)
+	if max := s.scrapeStaleAfter(); d > max {
+		d = max
+	}
+	return d
+}
+
+// ClaimScrapeScopes leases up to limit due scopes to owner, highest priority
+// (most students) first. A scope is due when it has never been scraped or its
+// last success is older than the staleness window, it isn't leased, its retry
+// backoff has passed and it hasn't used up its attempts. Concurrent claimers
+// skip each other's rows, so no scope is handed out twice.
+func (s *Store) ClaimScrapeScopes(ctx context.Context, owner string, limit int) ([]models.ZipcodeScrapeScope, error) {
+	now := time.Now()
+	var scopes []models.ZipcodeScrapeScope
+	err := s.DB.WithContext(ctx).Raw(`
+		UPDATE zipcode_scrape_scopes s
+		SET lease_owner = ?,
+		    lease_expires_at = ?,
+		    attempts = s.attempts + 1,
+		    last_attempt_at = ?,
+		    last_error = CASE WHEN s.lease_expires_at IS NOT NULL
+		                      THEN 'lease expired without a result'
+		                      ELSE s.last_error END
+		FROM (
+			SELECT zipcode, distance
+			FROM zipcode_scrape_scopes
+			WHERE (last_scraped IS NULL OR last_scraped < ?)
+			  AND (lease_expires_at IS NULL OR lease_expires_at < ?)
+			  AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
+			  AND attempts < ?
+			ORDER BY priority DESC, last_scraped ASC NULLS FIRST, zipcode, distance
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE s.zipcode = due.zipcode AND s.distance = due.distance
+		RETURNING s.*
+	`, owner, now.Add(s.scrapeLeaseDuration()), now,
+		now.Add(-s.scrapeStaleAfter()), now, now, s.scrapeMaxAttempts(), limit,
+	).Scan(&scopes).Error
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(scopes, func(i, j int) bool {
+		if scopes[i].Priority != scopes[j].Priority {
+			return scopes[i].Priority > scopes[j].Priority
+		}
+		if scopes[i].Zipcode != scopes[j].Zipcode {
+			return scopes[i].Zipcode < scopes[j].Zipcode
+		}
+		return scopes[i].Distance < scopes[j].Distance
+	})
+	return scopes, nil
+}
+
+// FailScrapeScope records a failed scrape, releases the lease and schedules
+// the retry. With owner set, only that owner's lease is released; a scope
+// leased to someone else is reported as not found.
+func (s *Store) FailScrapeScope(ctx context.Context, zipcode string, distance int, owner, message string) error {
+	var sc models.ZipcodeScrapeScope
+	if err := s.DB.WithContext(ctx).
+		Where("zipcode = ? AND distance = ?", zipcode, distance).
+		First(&sc).Error; err != nil {
+		return err
+	}
+	q := s.DB.WithContext(ctx).Model(&models.ZipcodeScrapeScope{}).
+		Where("zipcode = ? AND distance = ?", zipcode, distance)
+	if owner != "" {
+		q = q.Where("lease_owner = ? OR lease_owner IS NULL OR lease_owner = ''", owner)
+	}
+	now := time.Now()
+	next := now.Add(s.scrapeRetryBackoff(sc.Attempts))
+	res := q.Updates(map[string]interface{}{
+		"last_error":       message,
+		"last_attempt_at":  now,
+		"next_attempt_at":  next,
+		"lease_owner":      "",
+		"lease_expires_at": nil,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ResetScrapeScope clears attempts, errors and leases so the scope is due again.
+func (s *Store) ResetScrapeScope(ctx context.Context, zipcode string, distance int) error {
+	res := s.DB.WithContext(ctx).Model(&models.ZipcodeScrapeScope{}).
+		Where("zipcode = ? AND distance = ?", zipcode, distance).
+		Updates(map[string]interface{}{
+			"attempts":         0,
+			"last_error":       "",
+			"next_attempt_at":  nil,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"last_scraped":     nil,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ScraperHealth is the admin view of the scrape queue.
+type ScraperHealth struct {
+	Scopes        int64      `json:"scopes"`
+	Due           int64      `json:"due"`
+	Leased        int64      `json:"leased"`
+	Failing       int64      `json:"failing"`
+	Parked        int64      `json:"parked"` // out of attempts until reset
+	NeverScraped  int64      `json:"never_scraped"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	OldestScrape  *time.Time `json:"oldest_scrape_at"`
+
+	Tournaments         int64 `json:"tournaments"`
+	UpcomingTournaments int64 `json:"upcoming_tournaments"`
+	Ungeocoded          int64 `json:"ungeocoded_tournaments"`
+
+	StaleAfterHours   float64 `json:"stale_after_hours"`
+	LeaseMinutes      float64 `json:"lease_minutes"`
+	MaxAttempts       int     `json:"max_attempts"`
+	ScrapingDistances []int   `json:"scraping_distances"`
+	WideDistance      int     `json:"wide_distance"`
+
+	Backlog  []models.ZipcodeScrapeScope `json:"backlog"`
+	Leases   []models.ZipcodeScrapeScope `json:"leases"`
+	Failures []models.ZipcodeScrapeScope `json:"failures"`
+}
+
+// GetScraperHealth summarizes the queue: counts, the top of the backlog,
+// active leases and recent failures.
+func (s *Store) GetScraperHealth(ctx context.Context) (*ScraperHealth, error) {
+	now := time.Now()
+	cutoff := now.Add(-s.scrapeStaleAfter())
+	maxAttempts := s.scrapeMaxAttempts()
+	db := s.DB.WithContext(ctx)
+
+	h := &ScraperHealth{
+		StaleAfterHours:   s.scrapeStaleAfter().Hours(),
+		LeaseMinutes:      s.scrapeLeaseDuration().Minutes(),
+		MaxAttempts:       maxAttempts,
+		ScrapingDistances: s.ScrapingDistances(),
+		WideDistance:      s.WideScrapingDistance(),
+	}
+	dueClause := "(last_scraped IS NULL OR last_scraped < @cutoff) AND (lease_expires_at IS NULL OR lease_expires_at < @now) AND attempts < @max"
+	args := map[string]interface{}{"cutoff": cutoff, "now": now, "max": maxAttempts}
+
+	if err := db.Raw(`
+		SELECT
+			COUNT(*) AS scopes,
+			COUNT(*) FILTER (WHERE `+dueClause+`) AS due,
+			COUNT(*) FILTER (WHERE lease_expires_at >= @now) AS leased,
+			COUNT(*) FILTER (WHERE COALESCE(last_error, '') != '') AS failing,
+			COUNT(*) FILTER (WHERE attempts >= @max) AS parked,
+			COUNT(*) FILTER (WHERE last_scraped IS NULL) AS never_scraped,
+			MAX(last_scraped) AS last_success_at,
+			MIN(last_scraped) AS oldest_scrape
+		FROM zipcode_scrape_scopes
+	`, args).Scan(h).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Raw(`
+		SELECT
+			COUNT(*) AS tournaments,
+			COUNT(*) FILTER (WHERE start_date >= CURRENT_DATE) AS upcoming_tournaments,
+			COUNT(*) FILTER (WHERE latitude IS NULL) AS ungeocoded
+		FROM tournaments
+	`).Scan(h).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Where(dueClause, args).
+		Order("priority DESC, last_scraped ASC NULLS FIRST").
+		Limit(20).Find(&h.Backlog).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("lease_expires_at >= ?", now).
+		Order("lease_expires_at").Find(&h.Leases).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("COALESCE(last_error, '') != ''").
+		Order("last_attempt_at DESC NULLS LAST").
+		Limit(50).Find(&h.Failures).Error; err != nil {
+		return nil, err
+	}
+	return h, nil
+}
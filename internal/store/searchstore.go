@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// searchDDL adds the generated tsvector columns and GIN indexes used by
+// Search. AutoMigrate can't express generated columns, so NewGormStore runs
+// these after it; every statement is idempotent (see migration 000019).
+var searchDDL = []string{
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (to_tsvector('simple', coalesce(first_name, '') || ' ' || coalesce(last_name, ''))) STORED`,
+	`ALTER TABLE notes ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(primary_tag, '')), 'A') ||
+			setweight(jsonb_to_tsvector('english', coalesce(tags, '[]'::jsonb), '["string"]'), 'A') ||
+			setweight(to_tsvector('english', coalesce(description, '')), 'B')
+		) STORED`,
+	`ALTER TABLE attendances ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(class_highlights, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(homework, '')), 'B')
+		) STORED`,
+	`ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (to_tsvector('english', coalesce(title, ''))) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_users_search_vector ON users USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_notes_search_vector ON notes USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_attendances_search_vector ON attendances USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_tournaments_search_vector ON tournaments USING GIN (search_vector)`,
+}
+
+func migrateSearch(db *gorm.DB) error {
+	for _, stmt := range searchDDL {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search result types, also accepted by the types filter.
+const (
+	SearchStudents    = "students"
+	SearchNotes       = "notes"
+	SearchAttendance  = "attendance"
+	SearchTournaments = "tournaments"
+)
+
+var SearchTypes = []string{SearchStudents, SearchNotes, SearchAttendance, SearchTournaments}
+
+// headlineOptions marks matches with <mark>. The text is run through
+// htmlEscapeSQL first, so the markers are the only markup in a snippet and
+// clients can render it as HTML.
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" ... \""
+
+// htmlEscapeSQL wraps a text expression so it escapes &, < and >. Postgres
+// reads entities as single tokens, so ts_headline never cuts one in half.
+func htmlEscapeSQL(expr string) string {
+	return "replace(replace(replace(" + expr + ", '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+}
+
+// SearchHit is one match. StudentID/StudentName are set for notes and
+// attendance; Date is the note creation, class or tournament start date.
+type SearchHit struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Snippet     string     `json:"snippet"`
+	StudentID   string     `json:"student_id,omitempty"`
+	StudentName string     `json:"student_name,omitempty"`
+	Date        *time.Time `json:"date,omitempty"`
+	Rank        float64    `json:"rank"`
+}
+
+// SearchResults groups hits by type, best match first.
+type SearchResults struct {
+	Students    []SearchHit `json:"students"`
+	Notes       []SearchHit `json:"notes"`
+	Attendance  []SearchHit `json:"attendance"`
+	Tournaments []SearchHit `json:"tournaments"`
+}
+
+// Search runs a web-style query (quoted phrases, OR, -exclusions) over the
+// requested types, returning at most limit hits per type. Only rows the
+// requester could open through the regular endpoints are returned: students
+// and attendance follow the listing rules, notes their visibility level.
+func (s *Store) Search(ctx context.Context, requester *models.User, query string, types []string, limit int) (*SearchResults, error) {
+	want := make(map[string]bool, len(types))
+	for _, t := range types {
+		want[t] = true
+	}
+	res := &SearchResults{
+		Students:    []SearchHit{},
+		Notes:       []SearchHit{},
+		Attendance:  []SearchHit{},
+		Tournaments: []SearchHit{},
+	}
+	db := s.DB.WithContext(ctx)
+
+	if want[SearchStudents] && requester.Role != models.RoleStudent {
+		where, args := "TRUE", []interface{}{}
+		if requester.Role != models.RoleAdmin {
+			where = "u.active = true AND EXISTS (SELECT 1 FROM relations r WHERE r.user_id = u.id AND (r.coach_id = ? OR r.mentor_id = ?))"
+			args = append(args, requester.ID, requester.ID)
+		}
+		if err := db.Raw(`
+			SELECT u.id,
+				u.first_name || ' ' || u.last_name AS title,
+				ts_headline('simple', `+htmlEscapeSQL("u.first_name || ' ' || u.last_name")+`, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS snippet,
+				ts_rank(u.search_vector, q) AS rank
+			FROM users u, websearch_to_tsquery('simple', ?) q
+			WHERE u.search_vector @@ q AND u.role = ? AND `+where+`
+			ORDER BY rank DESC, u.first_name, u.last_name
+			LIMIT ?`,
+			append(append([]interface{}{query, models.RoleStudent}, args...), limit)...).
+			Scan(&res.Students).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if want[SearchNotes] {
+		where, args := noteVisibilitySQL("n", requester)
+		if err := db.Raw(`
+			SELECT n.id::text AS id, n.title,
+				ts_headline('english', `+htmlEscapeSQL("coalesce(n.description, '')")+`, q, ?) AS snippet,
+				n.user_id AS student_id, u.first_name || ' ' || u.last_name AS student_name,
+				n.created_at AS date,
+				ts_rank(n.search_vector, q) AS rank
+			FROM notes n
+			JOIN users u ON u.id = n.user_id,
+			websearch_to_tsquery('english', ?) q
+			WHERE n.deleted_at IS NULL AND n.search_vector @@ q AND `+where+`
+			ORDER BY rank DESC, n.created_at DESC
+			LIMIT ?`,
+			append(append([]interface{}{headlineOptions, query}, args...), limit)...).
+			Scan(&res.Notes).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if want[SearchAttendance] && requester.Role != models.RoleStudent {
+		where, args := attendanceVisibilitySQL("a", requester)
+		if err := db.Raw(`
+			SELECT a.id::text AS id, a.class_type AS title,
+				ts_headline('english', `+htmlEscapeSQL("coalesce(a.class_highlights, '') || ' ' || coalesce(a.homework, '')")+`, q, ?) AS snippet,
+				a.student_id, u.first_name || ' ' || u.last_name AS student_name,
+				a.date,
+				ts_rank(a.search_vector, q) AS rank
+			FROM attendances a
+			JOIN users u ON u.id = a.student_id,
+			websearch_to_tsquery('english', ?) q
+			WHERE a.deleted_at IS NULL AND a.search_vector @@ q AND `+where+`
+			ORDER BY rank DESC, a.date DESC
+			LIMIT ?`,
+			append(append([]interface{}{headlineOptions, query}, args...), limit)...).
+			Scan(&res.Attendance).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if want[SearchTournaments] {
+		if err := db.Raw(`
+			SELECT t.id::text AS id, t.title,
+				ts_headline('english', `+htmlEscapeSQL("t.title")+`, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS snippet,
+				t.start_date AS date,
+				ts_rank(t.search_vector, q) AS rank
+			FROM tournaments t, websearch_to_tsquery('english', ?) q
+			WHERE t.search_vector @@ q
+			ORDER BY t.cancelled_at IS NOT NULL, rank DESC, t.start_date DESC NULLS LAST
+			LIMIT ?`, query, limit).
+			Scan(&res.Tournaments).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// attendanceVisibilitySQL mirrors the role scoping of ListAttendances:
+// coaches see classes they taught, mentors their students' and coaches'
+// classes. Students have no attendance access.
+func attendanceVisibilitySQL(alias string, requester *models.User) (string, []interface{}) {
+	switch requester.Role {
+	case models.RoleAdmin:
+		return "TRUE", nil
+	case models.RoleCoach:
+		return alias + ".coach_id = ?", []interface{}{requester.ID}
+	case models.RoleMentor:
+		return "(" + alias + ".coach_id = ?" +
+				" OR EXISTS (SELECT 1 FROM relations r WHERE r.user_id = " + alias + ".student_id AND r.mentor_id = ?)" +
+				" OR EXISTS (SELECT 1 FROM relations r2 WHERE r2.coach_id = " + alias + ".coach_id AND r2.mentor_id = ?))",
+			[]interface{}{requester.ID, requester.ID, requester.ID}
+	default:
+		return "FALSE", nil
+	}
+}
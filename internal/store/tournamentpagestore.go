@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// SaveTournamentPage stores a captured page and its parse report.
+func (s *Store) SaveTournamentPage(ctx context.Context, p *models.TournamentPage) error {
+	return s.DB.WithContext(ctx).Create(p).Error
+}
+
+// TournamentPageFilter narrows ListTournamentPages.
+type TournamentPageFilter struct {
+	URLPath     string
+	InvalidOnly bool
+	Limit       int
+}
+
+// ListTournamentPages returns captured pages newest first, without their HTML.
+func (s *Store) ListTournamentPages(ctx context.Context, f TournamentPageFilter) ([]models.TournamentPage, error) {
+	q := s.DB.WithContext(ctx).Omit("html")
+	if f.URLPath != "" {
+		q = q.Where("url_path = ?", f.URLPath)
+	}
+	if f.InvalidOnly {
+		q = q.Where("valid = false")
+	}
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	var out []models.TournamentPage
+	err := q.Order("created_at desc").Limit(f.Limit).Find(&out).Error
+	return out, err
+}
+
+func (s *Store) GetTournamentPage(ctx context.Context, id uint) (*models.TournamentPage, error) {
+	var p models.TournamentPage
+	if err := s.DB.WithContext(ctx).First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// asJSONValue round-trips v through JSON so values read from the database and
+// freshly parsed ones compare equal regardless of Go type or JSON formatting.
+func asJSONValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	_ = json.Unmarshal(b, &out)
+	return out
+}
+
+// ApplyTournamentDetails sets parsed detail columns on the tournament with
+// urlPath, creating it if the scraper hasn't listed it yet (updates must then
+// carry a title). Changed fields are recorded as a revision. updates is keyed
+// by column name, which matches the JSON names of models.Tournament.
+func (s *Store) ApplyTournamentDetails(ctx context.Context, urlPath string, updates map[string]interface{}, keyID *uint) (uint, error) {
+	var id uint
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var existing models.Tournament
+		err := tx.Where("url_path = ?", urlPath).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			title, _ := updates["title"].(string)
+			if title == "" {
+				return fmt.Errorf("tournament %s is unknown and the page has no title", urlPath)
+			}
+			row := map[string]interface{}{"url_path": urlPath, "last_seen_at": now, "created_at": now, "updated_at": now, "api_key_id": keyID}
+			for k, v := range updates {
+				row[k] = v
+			}
+			if err := tx.Model(&models.Tournament{}).Create(row).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("url_path = ?", urlPath).First(&existing).Error; err != nil {
+				return err
+			}
+			id = existing.ID
+			if err := tx.Create(&models.TournamentRevision{
+				TournamentID: id,
+				ChangeType:   models.TournamentCreated,
+				Changes:      []byte("{}"),
+				APIKeyID:     keyID,
+				DetectedAt:   now,
+			}).Error; err != nil {
+				return err
+			}
+			return geocodeTournaments(tx, []uint{id})
+		}
+		if err != nil {
+			return err
+		}
+		id = existing.ID
+
+		old, _ := asJSONValue(existing).(map[string]interface{})
+		changes := map[string]FieldChange{}
+		changed := map[string]interface{}{}
+		for col, v := range updates {
+			nv := asJSONValue(v)
+			if reflect.DeepEqual(old[col], nv) {
+				continue
+			}
+			changes[col] = FieldChange{Old: old[col], New: nv}
+			changed[col] = v
+		}
+		if len(changed) == 0 {
+			return nil
+		}
+		_, cityChanged := changes["city"]
+		_, stateChanged := changes["state"]
+		_, zipChanged := changes["zipcode"]
+		if cityChanged || stateChanged || zipChanged {
+			changed["latitude"] = nil
+			changed["longitude"] = nil
+		}
+		changed["updated_at"] = now
+		if err := tx.Model(&models.Tournament{}).Where("id = ?", id).Updates(changed).Error; err != nil {
+			return err
+		}
+		_, startChanged := changes["start_date"]
+		_, endChanged := changes["end_date"]
+		_, datesChanged := changes["dates"]
+		if err := tx.Create(&models.TournamentRevision{
+			TournamentID: id,
+			ChangeType:   models.TournamentUpdated,
+			DateChanged:  startChanged || endChanged || datesChanged,
+			Changes:      changesJSON(changes),
+			APIKeyID:     keyID,
+			DetectedAt:   now,
+		}).Error; err != nil {
+			return err
+		}
+		return geocodeTournaments(tx, []uint{id})
+	})
+	return id, err
+}
@@ -0,0 +1,310 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// FieldChange is one entry of TournamentRevision.Changes.
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+func dateString(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
+
+// diffTournament compares the scraped fields of two versions.
+func diffTournament(old, cur models.Tournament) (map[string]FieldChange, bool) {
+	changes := map[string]FieldChange{}
+	str := func(name, a, b string) {
+		if a != b {
+			changes[name] = FieldChange{Old: a, New: b}
+		}
+	}
+	str("title", old.Title, cur.Title)
+	str("city", old.City, cur.City)
+	str("state", old.State, cur.State)
+	str("dates", old.Dates, cur.Dates)
+	str("organizer", old.Organizer, cur.Organizer)
+	str("description", old.Description, cur.Description)
+	str("zipcode", old.Zipcode, cur.Zipcode)
+	if a, b := dateString(old.StartDate), dateString(cur.StartDate); a != b {
+		changes["start_date"] = FieldChange{Old: a, New: b}
+	}
+	_, datesChanged := changes["dates"]
+	_, startChanged := changes["start_date"]
+	return changes, datesChanged || startChanged
+}
+
+// mergeListed fills what the search listing left out from the stored row, so
+// a missing value is never a change. Fields the event page parse owns keep
+// the page's value: the richer dates text while the start date agrees, and
+// city and state once the page has given a venue address.
+func mergeListed(existing, listed models.Tournament) models.Tournament {
+	keep := func(listed *string, stored string) {
+		if *listed == "" {
+			*listed = stored
+		}
+	}
+	keep(&listed.Title, existing.Title)
+	keep(&listed.Organizer, existing.Organizer)
+	keep(&listed.Description, existing.Description)
+	keep(&listed.Zipcode, existing.Zipcode)
+	if listed.StartDate == nil {
+		listed.StartDate = existing.StartDate
+	}
+	if existing.EndDate != nil && dateString(listed.StartDate) == dateString(existing.StartDate) {
+		listed.Dates = existing.Dates
+	}
+	keep(&listed.Dates, existing.Dates)
+	if existing.VenueAddress != "" || listed.City == "" || listed.State == "" {
+		listed.City, listed.State = existing.City, existing.State
+	}
+	return listed
+}
+
+func changesJSON(changes map[string]FieldChange) []byte {
+	b, _ := json.Marshal(changes)
+	return b
+}
+
+// upsertTournamentWithRevision inserts or updates a tournament by url_path and
+// records what changed. It returns the tournament id and whether it was new.
+func upsertTournamentWithRevision(tx *gorm.DB, t models.Tournament, zipcode string, distance int, now time.Time) (uint, bool, error) {
+	var existing models.Tournament
+	err := tx.Where("url_path = ?", t.URLPath).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		t.ID = 0
+		t.LastSeenAt = &now
+		if err := tx.Create(&t).Error; err != nil {
+			return 0, false, err
+		}
+		return t.ID, true, tx.Create(&models.TournamentRevision{
+			TournamentID: t.ID,
+			ChangeType:   models.TournamentCreated,
+			Changes:      []byte("{}"),
+			Zipcode:      zipcode,
+			Distance:     distance,
+			APIKeyID:     t.APIKeyID,
+			DetectedAt:   now,
+		}).Error
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	t = mergeListed(existing, t)
+	changes, dateChanged := diffTournament(existing, t)
+	updates := map[string]interface{}{
+		"last_seen_at": now,
+		"api_key_id":   t.APIKeyID,
+	}
+	if len(changes) > 0 {
+		updates["title"] = t.Title
+		updates["city"] = t.City
+		updates["state"] = t.State
+		updates["dates"] = t.Dates
+		updates["start_date"] = t.StartDate
+		updates["organizer"] = t.Organizer
+		updates["description"] = t.Description
+		updates["zipcode"] = t.Zipcode
+		updates["updated_at"] = now
+		_, zipChanged := changes["zipcode"]
+		_, cityChanged := changes["city"]
+		_, stateChanged := changes["state"]
+		if zipChanged || cityChanged || stateChanged {
+			// re-geocode from the new location
+			updates["latitude"] = nil
+			updates["longitude"] = nil
+		}
+	}
+	if existing.CancelledAt != nil {
+		updates["cancelled_at"] = nil
+		updates["updated_at"] = now
+	}
+	if err := tx.Model(&models.Tournament{}).Where("id = ?", existing.ID).Updates(updates).Error; err != nil {
+		return 0, false, err
+	}
+
+	if existing.CancelledAt != nil {
+		if err := tx.Create(&models.TournamentRevision{
+			TournamentID: existing.ID,
+			ChangeType:   models.TournamentReinstated,
+			Changes:      changesJSON(map[string]FieldChange{"cancelled_at": {Old: existing.CancelledAt, New: nil}}),
+			Zipcode:      zipcode,
+			Distance:     distance,
+			APIKeyID:     t.APIKeyID,
+			DetectedAt:   now,
+		}).Error; err != nil {
+			return 0, false, err
+		}
+	}
+	if len(changes) > 0 {
+		if err := tx.Create(&models.TournamentRevision{
+			TournamentID: existing.ID,
+			ChangeType:   models.TournamentUpdated,
+			DateChanged:  dateChanged,
+			Changes:      changesJSON(changes),
+			Zipcode:      zipcode,
+			Distance:     distance,
+			APIKeyID:     t.APIKeyID,
+			DetectedAt:   now,
+		}).Error; err != nil {
+			return 0, false, err
+		}
+	}
+	return existing.ID, false, nil
+}
+
+// cancelMissingTournaments marks tournaments that dropped out of a scope's
+// results before their start date as cancelled. Scopes overlap, so a
+// tournament another live scope still lists is only out of this scope's
+// radius and is kept; it is cancelled once the last scope covering it
+// stops listing it. The caller must have replaced this scope's junction
+// rows already. Past events simply age out of the listing and are left
+// alone.
+func cancelMissingTournaments(tx *gorm.DB, ids []uint, zipcode string, distance int, keyID *uint, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var gone []models.Tournament
+	if err := tx.Where("id IN ? AND cancelled_at IS NULL AND start_date > ?", ids, today).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM tournaments_within_radius twr
+			JOIN zipcode_scrape_scopes sc ON sc.zipcode = twr.zipcode AND sc.distance = twr.distance
+			WHERE twr.tournament_id = tournaments.id
+		)`).
+		Find(&gone).Error; err != nil {
+		return err
+	}
+	for _, t := range gone {
+		if err := tx.Model(&models.Tournament{}).Where("id = ?", t.ID).
+			Updates(map[string]interface{}{"cancelled_at": now, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.TournamentRevision{
+			TournamentID: t.ID,
+			ChangeType:   models.TournamentCancelled,
+			Changes:      changesJSON(map[string]FieldChange{"cancelled_at": {Old: nil, New: now}}),
+			Zipcode:      zipcode,
+			Distance:     distance,
+			APIKeyID:     keyID,
+			DetectedAt:   now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTournamentRevisions returns a tournament's history, newest first.
+func (s *Store) ListTournamentRevisions(ctx context.Context, tournamentID uint) ([]models.TournamentRevision, error) {
+	var out []models.TournamentRevision
+	err := s.DB.WithContext(ctx).
+		Where("tournament_id = ?", tournamentID).
+		Order("detected_at desc, id desc").
+		Find(&out).Error
+	return out, err
+}
+
+// TournamentChange is a tournament with its revisions since a point in time
+// and the visible students who planned to attend.
+type TournamentChange struct {
+	Tournament       models.Tournament               `json:"tournament"`
+	Cancelled        bool                            `json:"cancelled"`
+	DateChanged      bool                            `json:"date_changed"`
+	Revisions        []models.TournamentRevision     `json:"revisions"`
+	AffectedStudents []models.TournamentRegistration `json:"affected_students"`
+}
+
+// TournamentChangesFilter narrows ListTournamentChanges.
+type TournamentChangesFilter struct {
+	Since time.Time
+	// StudentIDs limits affected students; nil means all.
+	StudentIDs []string
+	// OnlyAffected drops tournaments none of the visible students plan to attend.
+	OnlyAffected bool
+	// ChangeTypes limits the revisions; empty means all but "created".
+	ChangeTypes []string
+}
+
+// ListTournamentChanges returns tournaments changed since f.Since, most
+// recently changed first, with the planning/registered students to warn.
+func (s *Store) ListTournamentChanges(ctx context.Context, f TournamentChangesFilter) ([]TournamentChange, error) {
+	db := s.DB.WithContext(ctx)
+	q := db.Where("detected_at > ?", f.Since)
+	if len(f.ChangeTypes) > 0 {
+		q = q.Where("change_type IN ?", f.ChangeTypes)
+	} else {
+		q = q.Where("change_type != ?", models.TournamentCreated)
+	}
+	var revs []models.TournamentRevision
+	if err := q.Order("detected_at desc, id desc").Find(&revs).Error; err != nil {
+		return nil, err
+	}
+	if len(revs) == 0 {
+		return []TournamentChange{}, nil
+	}
+
+	var ids []uint
+	byID := map[uint]*TournamentChange{}
+	for _, r := range revs {
+		c, ok := byID[r.TournamentID]
+		if !ok {
+			c = &TournamentChange{AffectedStudents: []models.TournamentRegistration{}}
+			byID[r.TournamentID] = c
+			ids = append(ids, r.TournamentID)
+		}
+		c.Revisions = append(c.Revisions, r)
+		if r.DateChanged {
+			c.DateChanged = true
+		}
+	}
+
+	var tournaments []models.Tournament
+	if err := db.Where("id IN ?", ids).Find(&tournaments).Error; err != nil {
+		return nil, err
+	}
+	for _, t := range tournaments {
+		byID[t.ID].Tournament = t
+		byID[t.ID].Cancelled = t.CancelledAt != nil
+	}
+
+	if f.StudentIDs == nil || len(f.StudentIDs) > 0 {
+		rq := db.Preload("Student").
+			Where("tournament_id IN ? AND status IN ?", ids, []models.TournamentRegistrationStatus{
+				models.TournamentRegistrationPlanning, models.TournamentRegistrationRegistered,
+			})
+		if f.StudentIDs != nil {
+			rq = rq.Where("student_id IN ?", f.StudentIDs)
+		}
+		var regs []models.TournamentRegistration
+		if err := rq.Find(&regs).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range regs {
+			byID[r.TournamentID].AffectedStudents = append(byID[r.TournamentID].AffectedStudents, r)
+		}
+	}
+
+	out := make([]TournamentChange, 0, len(ids))
+	for _, id := range ids {
+		c := byID[id]
+		if f.OnlyAffected && len(c.AffectedStudents) == 0 {
+			continue
+		}
+		out = append(out, *c)
+	}
+	return out, nil
+}
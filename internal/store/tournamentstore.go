@@ -3,71 +3,150 @@ package store
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/madhava-poojari/dashboard-api/internal/geo"
 	"github.com/madhava-poojari/dashboard-api/internal/models"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-// Hardcoded distances (miles) used for scraping
-var ScrapingDistances = []int{10, 20, 30}
+// Default scrape radii (miles), used when the store has no config.
+var defaultScrapingDistances = []int{10, 20, 30}
 
-// SyncZipcodesFromUserDetails ensures the zipcode_scrape_scopes table
-// contains 3 rows per active student zipcode (one per distance).
+const defaultWideScrapingDistance = 60
+
+// ScrapingDistances returns the radii scraped around zipcodes without a
+// centroid. They are also the buckets of the student-facing nearby view.
+func (s *Store) ScrapingDistances() []int {
+	if s.Cfg != nil && len(s.Cfg.ScrapeDistances) > 0 {
+		return s.Cfg.ScrapeDistances
+	}
+	return defaultScrapingDistances
+}
+
+// WideScrapingDistance is the single, wider radius scraped around geocoded
+// zipcodes. One wide scope serves every student zipcode within
+// WideScrapingDistance - max(ScrapingDistances) of its center, because
+// distances are then computed per student from tournament coordinates.
+func (s *Store) WideScrapingDistance() int {
+	if s.Cfg != nil && s.Cfg.ScrapeWideDistance > 0 {
+		return s.Cfg.ScrapeWideDistance
+	}
+	return defaultWideScrapingDistance
+}
+
+func (s *Store) maxScrapingDistance() int {
+	m := 0
+	for _, d := range s.ScrapingDistances() {
+		if d > m {
+			m = d
+		}
+	}
+	return m
+}
+
+// SyncZipcodesFromUserDetails rebuilds zipcode_scrape_scopes from active
+// student zipcodes. Zipcodes with a known centroid are clustered under as few
+// wide scopes as possible; the rest get one row per ScrapingDistances entry.
+// Each scope's priority is the number of active students it serves. Queue
+// state (attempts, leases, errors) of surviving scopes is kept.
 func (s *Store) SyncZipcodesFromUserDetails(ctx context.Context) error {
-	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		for _, dist := range ScrapingDistances {
-			if err := tx.Exec(`
-				INSERT INTO zipcode_scrape_scopes (zipcode, distance)
-				SELECT DISTINCT ud.zipcode, $1::INT
-				FROM user_details ud
-				JOIN users u ON u.id = ud.user_id
-				WHERE u.active = true AND u.approved = true
-				  AND ud.zipcode IS NOT NULL AND ud.zipcode != ''
-				ON CONFLICT (zipcode, distance) DO NOTHING
-			`, dist).Error; err != nil {
-				return err
+	var counts []struct {
+		Zipcode  string
+		Students int
+	}
+	if err := s.DB.WithContext(ctx).
+		Table("user_details ud").
+		Select("ud.zipcode, COUNT(*) AS students").
+		Joins("JOIN users u ON u.id = ud.user_id").
+		Where("u.active = true AND u.approved = true").
+		Where("ud.zipcode IS NOT NULL AND ud.zipcode != ''").
+		Group("ud.zipcode").
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	zips := make([]string, 0, len(counts))
+	students := make(map[string]int, len(counts))
+	for _, c := range counts {
+		zips = append(zips, c.Zipcode)
+		students[c.Zipcode] = c.Students
+	}
+	points, err := s.GetZipCentroids(ctx, zips)
+	if err != nil {
+		return err
+	}
+
+	distances := s.ScrapingDistances()
+	var scopes []models.ZipcodeScrapeScope
+	for _, z := range zips {
+		if _, ok := points[z]; ok {
+			continue
+		}
+		for _, dist := range distances {
+			scopes = append(scopes, models.ZipcodeScrapeScope{Zipcode: z, Distance: dist, Priority: students[z]})
+		}
+	}
+	wide := s.WideScrapingDistance()
+	coverRadius := float64(wide - s.maxScrapingDistance())
+	if coverRadius > 0 {
+		for _, center := range geo.CoverCenters(points, coverRadius) {
+			served := 0
+			for z, p := range points {
+				if geo.HaversineMiles(points[center], p) <= coverRadius {
+					served += students[z]
+				}
 			}
+			scopes = append(scopes, models.ZipcodeScrapeScope{Zipcode: center, Distance: wide, Priority: served})
 		}
+	} else {
+		for z := range points {
+			for _, dist := range distances {
+				scopes = append(scopes, models.ZipcodeScrapeScope{Zipcode: z, Distance: dist, Priority: students[z]})
+			}
+		}
+	}
 
-		// Remove scopes for zipcodes with no active students
-		if err := tx.Exec(`
-			DELETE FROM zipcode_scrape_scopes
-			WHERE zipcode NOT IN (
-				SELECT DISTINCT ud.zipcode
-				FROM user_details ud
-				JOIN users u ON u.id = ud.user_id
-				WHERE u.active = true AND u.approved = true
-				  AND ud.zipcode IS NOT NULL AND ud.zipcode != ''
-			)
-		`).Error; err != nil {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(scopes) == 0 {
+			return tx.Exec("DELETE FROM zipcode_scrape_scopes").Error
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "zipcode"}, {Name: "distance"}},
+			DoUpdates: clause.AssignmentColumns([]string{"priority"}),
+		}).Create(&scopes).Error; err != nil {
 			return err
 		}
 
-		return nil
+		// Remove scopes no longer needed (zipcode gone, or now covered by a wide scope)
+		keep := make([][]interface{}, 0, len(scopes))
+		for _, sc := range scopes {
+			keep = append(keep, []interface{}{sc.Zipcode, sc.Distance})
+		}
+		return tx.Where("(zipcode, distance) NOT IN ?", keep).
+			Delete(&models.ZipcodeScrapeScope{}).Error
 	})
 }
 
-// GetZipcodesForScraping returns (zipcode, distance) pairs that need scraping:
-// last_scraped is NULL or older than 24 hours.
-func (s *Store) GetZipcodesForScraping(ctx context.Context) ([]models.ZipcodeScrapeScope, error) {
-	var scopes []models.ZipcodeScrapeScope
-	cutoff := time.Now().Add(-24 * time.Hour)
+// UpsertTournaments upserts tournament records and links them to a (zipcode, distance).
+// Tournaments are deduplicated by url_path. Every field change is recorded as a
+// TournamentRevision, and future tournaments that were linked to this scope
+// but are missing from the new results are marked cancelled. It returns the
+// tournaments seen for the first time.
+func (s *Store) UpsertTournaments(ctx context.Context, zipcode string, distance int, tournaments []models.Tournament) ([]models.Tournament, error) {
+	var added []models.Tournament
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
 
-	if err := s.DB.WithContext(ctx).
-		Where("last_scraped IS NULL OR last_scraped < ?", cutoff).
-		Order("zipcode ASC, distance ASC").
-		Find(&scopes).Error; err != nil {
-		return nil, err
-	}
-	return scopes, nil
-}
+		// Remember what the scope listed last time before replacing it
+		var previousIDs []uint
+		if err := tx.Model(&models.TournamentWithinRadius{}).
+			Where("zipcode = ? AND distance = ?", zipcode, distance).
+			Pluck("tournament_id", &previousIDs).Error; err != nil {
+			return err
+		}
 
-// UpsertTournaments upserts tournament records and links them to a (zipcode, distance).
-// Uses ON CONFLICT (url_path) DO UPDATE to deduplicate tournaments.
-func (s *Store) UpsertTournaments(ctx context.Context, zipcode string, distance int, tournaments []models.Tournament) error {
-	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Remove existing junction rows for this (zipcode, distance)
 		if err := tx.Where("zipcode = ? AND distance = ?", zipcode, distance).
 			Delete(&models.TournamentWithinRadius{}).Error; err != nil {
@@ -75,35 +154,77 @@ func (s *Store) UpsertTournaments(ctx context.Context, zipcode string, distance
 		}
 
 		// Upsert each tournament and create junction rows
+		seen := make(map[uint]bool, len(tournaments))
 		for _, t := range tournaments {
-			t.ID = 0
-			if err := tx.Clauses(clause.OnConflict{
-				Columns:   []clause.Column{{Name: "url_path"}},
-				DoUpdates: clause.AssignmentColumns([]string{"title", "city", "state", "dates", "start_date", "organizer", "description"}),
-			}).Create(&t).Error; err != nil {
+			id, created, err := upsertTournamentWithRevision(tx, t, zipcode, distance, now)
+			if err != nil {
 				return err
 			}
+			seen[id] = true
+			if created {
+				t.ID = id
+				added = append(added, t)
+			}
 
 			junc := models.TournamentWithinRadius{
 				Zipcode:      zipcode,
 				Distance:     distance,
-				TournamentID: t.ID,
+				TournamentID: id,
 			}
 			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&junc).Error; err != nil {
 				return err
 			}
 		}
 
-		// Update last_scraped
-		now := time.Now()
+		// An empty result for a scope that listed events is more likely a
+		// broken scrape than every event being cancelled at once.
+		var missing []uint
+		for _, id := range previousIDs {
+			if len(tournaments) == 0 {
+				break
+			}
+			if !seen[id] {
+				missing = append(missing, id)
+			}
+		}
+		var keyID *uint
+		if len(tournaments) > 0 {
+			keyID = tournaments[0].APIKeyID
+		}
+		if err := cancelMissingTournaments(tx, missing, zipcode, distance, keyID, now); err != nil {
+			return err
+		}
+
+		// Geocode the whole batch at once rather than per tournament.
+		batch := make([]uint, 0, len(seen))
+		for id := range seen {
+			batch = append(batch, id)
+		}
+		if err := geocodeTournaments(tx, batch); err != nil {
+			return err
+		}
+
+		// Mark the scope done: update last_scraped and release any lease
 		if err := tx.Model(&models.ZipcodeScrapeScope{}).
 			Where("zipcode = ? AND distance = ?", zipcode, distance).
-			Update("last_scraped", now).Error; err != nil {
+			Updates(map[string]interface{}{
+				"last_scraped":      now,
+				"attempts":          0,
+				"last_error":        "",
+				"next_attempt_at":   nil,
+				"lease_owner":       "",
+				"lease_expires_at":  nil,
+				"last_result_count": len(tournaments),
+			}).Error; err != nil {
 			return err
 		}
 
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
 }
 
 // TournamentsByDistance is the response shape for grouped tournaments.
@@ -113,6 +234,8 @@ type TournamentsByDistance struct {
 }
 
 // GetTournamentsByUserID returns tournaments grouped by distance with exclusive grouping.
+// When the student's zipcode has a centroid, distances are computed from
+// tournament coordinates; otherwise the scraped junction rows are used.
 func (s *Store) GetTournamentsByUserID(ctx context.Context, userID string) ([]TournamentsByDistance, error) {
 	var ud models.UserDetails
 	if err := s.DB.WithContext(ctx).Where("user_id = ?", userID).First(&ud).Error; err != nil {
@@ -122,13 +245,53 @@ func (s *Store) GetTournamentsByUserID(ctx context.Context, userID string) ([]To
 		return []TournamentsByDistance{}, nil
 	}
 
+	c, err := s.GetZipCentroid(ctx, ud.Zipcode)
+	if IsNotFound(err) {
+		return s.tournamentsByJunction(ctx, ud.Zipcode, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	near, err := s.SearchTournamentsNear(ctx, geo.Point{Lat: c.Latitude, Lng: c.Longitude}, float64(s.maxScrapingDistance()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	distances := s.ScrapingDistances()
+	result := make([]TournamentsByDistance, 0, len(distances))
+	for _, dist := range distances {
+		result = append(result, TournamentsByDistance{Distance: dist})
+	}
+	for _, t := range near {
+		for i, dist := range distances {
+			if t.DistanceMiles <= float64(dist) {
+				result[i].Tournaments = append(result[i].Tournaments, t.Tournament)
+				break
+			}
+		}
+	}
+	for i := range result {
+		ts := result[i].Tournaments
+		sort.SliceStable(ts, func(a, b int) bool { return startsBefore(ts[a].StartDate, ts[b].StartDate) })
+	}
+
+	// Tournaments we couldn't geocode are still reachable through any
+	// junction rows scraped for this exact zipcode.
+	return s.tournamentsByJunction(ctx, ud.Zipcode, result)
+}
+
+// tournamentsByJunction groups the junction rows of a zipcode by distance.
+// With base set, only tournaments lacking coordinates are added to it.
+func (s *Store) tournamentsByJunction(ctx context.Context, zipcode string, base []TournamentsByDistance) ([]TournamentsByDistance, error) {
+	onlyUngeocoded := base != nil
 	seen := make(map[uint]bool)
-	result := make([]TournamentsByDistance, 0, len(ScrapingDistances))
+	distances := s.ScrapingDistances()
+	result := make([]TournamentsByDistance, 0, len(distances))
 
-	for _, dist := range ScrapingDistances {
+	for i, dist := range distances {
 		var junctions []models.TournamentWithinRadius
 		if err := s.DB.WithContext(ctx).
-			Where("zipcode = ? AND distance = ?", ud.Zipcode, dist).
+			Where("zipcode = ? AND distance = ?", zipcode, dist).
 			Find(&junctions).Error; err != nil {
 			return nil, err
 		}
@@ -142,9 +305,11 @@ func (s *Store) GetTournamentsByUserID(ctx context.Context, userID string) ([]To
 
 		var tournaments []models.Tournament
 		if len(ids) > 0 {
-			if err := s.DB.WithContext(ctx).
-				Where("id IN ?", ids).
-				Order("start_date ASC NULLS LAST").
+			q := s.DB.WithContext(ctx).Where("id IN ?", ids)
+			if onlyUngeocoded {
+				q = q.Where("latitude IS NULL")
+			}
+			if err := q.Order("start_date ASC NULLS LAST").
 				Find(&tournaments).Error; err != nil {
 				return nil, fmt.Errorf("fetching tournaments for distance %d: %w", dist, err)
 			}
@@ -153,11 +318,18 @@ func (s *Store) GetTournamentsByUserID(ctx context.Context, userID string) ([]To
 			}
 		}
 
+		if onlyUngeocoded {
+			base[i].Tournaments = append(base[i].Tournaments, tournaments...)
+			continue
+		}
 		result = append(result, TournamentsByDistance{
 			Distance:    dist,
 			Tournaments: tournaments,
 		})
 	}
 
+	if onlyUngeocoded {
+		return base, nil
+	}
 	return result, nil
 }
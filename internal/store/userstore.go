@@ -129,6 +129,13 @@ func (s *Store) GetCoachesByStudentID(ctx context.Context, studentID string) (st
 	return r.CoachID, r.MentorID, nil
 }
 
+// ListRelationsForMentor returns the coach/student pairs under a mentor.
+func (s *Store) ListRelationsForMentor(ctx context.Context, mentorID string) ([]models.Relation, error) {
+	var out []models.Relation
+	err := s.DB.WithContext(ctx).Where("mentor_id = ?", mentorID).Order("coach_id, user_id").Find(&out).Error
+	return out, err
+}
+
 // ListCoachesForMentor returns all coaches assigned to a mentor (via relations), plus the mentor themselves.
 func (s *Store) ListCoachesForMentor(ctx context.Context, mentorID string) ([]*models.User, error) {
 	// Get distinct coach IDs from relations where mentor_id matches
@@ -188,3 +195,35 @@ func GetMentorCoachDetails(db *gorm.DB, studentID string) (string, string, strin
 	return coachName, coachDetails.Phone,
 		mentorName, mentorDetails.Phone, nil
 }
+
+var userSorts = map[string]Column{
+	"created_at": {Expr: "users.created_at", Kind: KindTime},
+	"first_name": {Expr: "users.first_name", Kind: KindString},
+	"last_name":  {Expr: "users.last_name", Kind: KindString},
+	"email":      {Expr: "users.email", Kind: KindString},
+}
+
+var userFilters = map[string]Column{
+	"role":     {Expr: "users.role", Kind: KindString},
+	"active":   {Expr: "users.active", Kind: KindBool},
+	"approved": {Expr: "users.approved", Kind: KindBool},
+}
+
+// UserListSpec covers GET /users.
+var UserListSpec = ListSpec{
+	Sorts:       userSorts,
+	Filters:     userFilters,
+	DefaultSort: []SortField{{Field: "created_at", Desc: true}},
+	Key:         Column{Expr: "users.id", Kind: KindString},
+}
+
+// ListUsersPage is the paged form of ListUsersAdmin for admins and of
+// ListStudentsForCoachOrMentor for everyone else.
+func (s *Store) ListUsersPage(ctx context.Context, requester *models.User, lq ListQuery) (*Page[models.User], error) {
+	q := s.DB.WithContext(ctx).Model(&models.User{})
+	if requester.Role != models.RoleAdmin {
+		q = q.Where("users.active = true AND EXISTS (SELECT 1 FROM relations r WHERE r.user_id = users.id AND (r.coach_id = ? OR r.mentor_id = ?))",
+			requester.ID, requester.ID)
+	}
+	return Paginate[models.User](q, UserListSpec, lq)
+}
@@ -0,0 +1,198 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/madhava-poojari/dashboard-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *Store) ListWebhookEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	var out []models.WebhookEndpoint
+	err := s.DB.WithContext(ctx).Order("name, id").Find(&out).Error
+	return out, err
+}
+
+func (s *Store) GetWebhookEndpoint(ctx context.Context, id uint) (*models.WebhookEndpoint, error) {
+	var e models.WebhookEndpoint
+	if err := s.DB.WithContext(ctx).First(&e, id).Error; err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CreateWebhookEndpoint inserts e. GORM skips a false Active in favour of
+// the column default, so an endpoint created disabled is switched off after.
+func (s *Store) CreateWebhookEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	active := e.Active
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(e).Error; err != nil {
+			return err
+		}
+		if !active {
+			e.Active = false
+			return tx.Model(&models.WebhookEndpoint{}).Where("id = ?", e.ID).Update("active", false).Error
+		}
+		return nil
+	})
+}
+
+func (s *Store) UpdateWebhookEndpoint(ctx context.Context, id uint, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	res := s.DB.WithContext(ctx).Model(&models.WebhookEndpoint{}).Where("id = ?", id).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteWebhookEndpoint removes the endpoint and its delivery log.
+func (s *Store) DeleteWebhookEndpoint(ctx context.Context, id uint) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("endpoint_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.WebhookEndpoint{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// WebhookEndpointsFor returns the active endpoints subscribed to eventType,
+// either by name or with "*".
+func (s *Store) WebhookEndpointsFor(ctx context.Context, eventType string) ([]models.WebhookEndpoint, error) {
+	one, _ := json.Marshal([]string{eventType})
+	var out []models.WebhookEndpoint
+	err := s.DB.WithContext(ctx).
+		Where("active = ?", true).
+		Where("events @> ?::jsonb OR events @> '[\"*\"]'::jsonb", string(one)).
+		Find(&out).Error
+	return out, err
+}
+
+// CreateWebhookDeliveries queues deliveries; they are due immediately. An
+// event already queued for an endpoint is skipped, so handling the same
+// event twice (the outbox delivers at least once) sends it once. The
+// partial unique index idx_webhook_deliveries_endpoint_event enforces this;
+// replays set ReplayOf and fall outside it.
+func (s *Store) CreateWebhookDeliveries(ctx context.Context, ds []models.WebhookDelivery) error {
+	if len(ds) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for i := range ds {
+		ds[i].Status = models.WebhookPending
+		if ds[i].NextAttemptAt == nil {
+			ds[i].NextAttemptAt = &now
+		}
+	}
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&ds).Error
+}
+
+// ClaimWebhookDeliveries picks up to limit due deliveries and pushes their
+// next attempt out by lease, so a sender that dies mid-request leaves them
+// to be retried rather than stuck. Concurrent claimers skip each other's
+// rows.
+func (s *Store) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	now := time.Now()
+	var out []models.WebhookDelivery
+	err := s.DB.WithContext(ctx).Raw(`
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = ?,
+		    attempts = d.attempts + 1,
+		    updated_at = ?
+		FROM (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE d.id = due.id
+		RETURNING d.*
+	`, now.Add(lease), now, models.WebhookPending, now, limit).Scan(&out).Error
+	return out, err
+}
+
+// FinishWebhookDelivery records the outcome of an attempt. A nil retryAt
+// with an error gives up on the delivery.
+func (s *Store) FinishWebhookDelivery(ctx context.Context, id uint, statusCode int, errMsg string, retryAt *time.Time) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"last_status_code": statusCode,
+		"last_error":       errMsg,
+		"updated_at":       now,
+	}
+	switch {
+	case errMsg == "":
+		updates["status"] = models.WebhookDelivered
+		updates["delivered_at"] = now
+		updates["next_attempt_at"] = nil
+	case retryAt != nil:
+		updates["next_attempt_at"] = *retryAt
+	default:
+		updates["status"] = models.WebhookFailed
+		updates["next_attempt_at"] = nil
+	}
+	return s.DB.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ListWebhookDeliveries returns an endpoint's deliveries, newest first,
+// optionally filtered by status.
+func (s *Store) ListWebhookDeliveries(ctx context.Context, endpointID uint, status string, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	q := s.DB.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("endpoint_id = ?", endpointID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []models.WebhookDelivery
+	err := q.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&out).Error
+	return out, total, err
+}
+
+func (s *Store) GetWebhookDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	if err := s.DB.WithContext(ctx).First(&d, id).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ReplayWebhookDelivery queues a fresh copy of delivery id with the same
+// event id and payload, so receivers can recognise it as a repeat.
+func (s *Store) ReplayWebhookDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	orig, err := s.GetWebhookDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	d := models.WebhookDelivery{
+		EndpointID: orig.EndpointID,
+		EventID:    orig.EventID,
+		EventType:  orig.EventType,
+		Payload:    orig.Payload,
+		ReplayOf:   &orig.ID,
+	}
+	ds := []models.WebhookDelivery{d}
+	if err := s.CreateWebhookDeliveries(ctx, ds); err != nil {
+		return nil, err
+	}
+	return &ds[0], nil
+}
@@ -0,0 +1,141 @@
+package tournamentparse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+const monthRe = `(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sept?(?:ember)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)\.?`
+
+var (
+	// "March 11-12, 2026", "Mar 30 - Apr 1, 2026", "March 11, 2026"
+	namedRangeRe = regexp.MustCompile(`(?i)\b` + monthRe + `\s+(\d{1,2})(?:st|nd|rd|th)?(?:\s*(?:-|–|to|thru|through|&|and)\s*(?:` + monthRe + `\s+)?(\d{1,2})(?:st|nd|rd|th)?)?,?\s+(\d{4})\b`)
+	// "2026-03-11"
+	isoDateRe = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	// "3/11/2026", "03/11/26"
+	numericDateRe = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4}|\d{2})\b`)
+)
+
+func monthOf(s string) (time.Month, bool) {
+	s = strings.ToLower(strings.TrimSuffix(s, "."))
+	if len(s) < 3 {
+		return 0, false
+	}
+	m, ok := months[s[:3]]
+	return m, ok
+}
+
+func mkDate(y int, m time.Month, d int) (time.Time, bool) {
+	t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	// reject overflow such as Feb 30
+	if t.Day() != d || t.Month() != m {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+type dateSpan struct {
+	start, end  time.Time
+	explicitEnd bool
+	pos         int
+}
+
+// findDates returns every date or date range in s in order of appearance.
+func findDates(s string) []dateSpan {
+	var out []dateSpan
+	for _, m := range namedRangeRe.FindAllStringSubmatchIndex(s, -1) {
+		g := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return s[m[2*i]:m[2*i+1]]
+		}
+		year, _ := strconv.Atoi(g(5))
+		mon, ok := monthOf(g(1))
+		if !ok {
+			continue
+		}
+		day, _ := strconv.Atoi(g(2))
+		start, ok := mkDate(year, mon, day)
+		if !ok {
+			continue
+		}
+		span := dateSpan{start: start, end: start, pos: m[0]}
+		if g(4) != "" {
+			endMon := mon
+			if g(3) != "" {
+				if em, ok := monthOf(g(3)); ok {
+					endMon = em
+				}
+			}
+			endDay, _ := strconv.Atoi(g(4))
+			endYear := year
+			if endMon < mon {
+				// "Dec 30 - Jan 2, 2027": the year belongs to the end date
+				start, _ = mkDate(year-1, mon, day)
+				span.start = start
+			}
+			if end, ok := mkDate(endYear, endMon, endDay); ok && !end.Before(span.start) {
+				span.end = end
+				span.explicitEnd = true
+			}
+		}
+		out = append(out, span)
+	}
+	for _, m := range isoDateRe.FindAllStringSubmatchIndex(s, -1) {
+		y, _ := strconv.Atoi(s[m[2]:m[3]])
+		mo, _ := strconv.Atoi(s[m[4]:m[5]])
+		d, _ := strconv.Atoi(s[m[6]:m[7]])
+		if t, ok := mkDate(y, time.Month(mo), d); ok {
+			out = append(out, dateSpan{start: t, end: t, pos: m[0]})
+		}
+	}
+	for _, m := range numericDateRe.FindAllStringSubmatchIndex(s, -1) {
+		mo, _ := strconv.Atoi(s[m[2]:m[3]])
+		d, _ := strconv.Atoi(s[m[4]:m[5]])
+		y, _ := strconv.Atoi(s[m[6]:m[7]])
+		if y < 100 {
+			y += 2000
+		}
+		if t, ok := mkDate(y, time.Month(mo), d); ok {
+			out = append(out, dateSpan{start: t, end: t, pos: m[0]})
+		}
+	}
+	// order of appearance
+	for i := 1; i < len(out); i++ {
+		for j := i; j > 0 && out[j].pos < out[j-1].pos; j-- {
+			out[j], out[j-1] = out[j-1], out[j]
+		}
+	}
+	return out
+}
+
+// eventSpan turns the dates found in a dates field into a start and end: an
+// explicit range wins, otherwise consecutive single dates within two weeks
+// of the first one extend the event.
+func eventSpan(spans []dateSpan) (start, end time.Time, ok bool) {
+	if len(spans) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	start, end = spans[0].start, spans[0].end
+	if spans[0].explicitEnd {
+		return start, end, true
+	}
+	for _, sp := range spans[1:] {
+		if sp.start.Before(start) || sp.end.Sub(start) > 14*24*time.Hour {
+			break
+		}
+		if sp.end.After(end) {
+			end = sp.end
+		}
+	}
+	return start, end, true
+}
@@ -0,0 +1,476 @@
+// Package tournamentparse extracts structured tournament details from the raw
+// HTML pages the scraper extension captures. Every field comes with a
+// confidence score so ingestion can decide what to trust.
+package tournamentparse
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is stored with every parse so old reports can be told apart after
+// the parsers change.
+const Version = "1"
+
+// Field names used in Report.Fields and Issue.Field.
+const (
+	FieldTitle        = "title"
+	FieldDates        = "dates"
+	FieldEntryFee     = "entry_fee"
+	FieldTimeControl  = "time_control"
+	FieldSections     = "sections"
+	FieldRatings      = "rating_requirements"
+	FieldVenueAddress = "venue_address"
+	FieldOrganizer    = "organizer"
+)
+
+var allLabels = map[string][]string{
+	FieldDates:        {"event dates", "tournament dates", "dates", "date", "when"},
+	FieldEntryFee:     {"entry fees", "entry fee", "entry", "fees", "fee", "ef"},
+	FieldTimeControl:  {"time controls", "time control", "tc"},
+	FieldSections:     {"sections", "section"},
+	FieldRatings:      {"rating requirements", "eligibility", "ratings", "rating", "rated"},
+	FieldVenueAddress: {"playing site", "location", "address", "venue", "site", "where"},
+	FieldOrganizer:    {"organizer", "organized by", "sponsor", "affiliate", "tournament director", "chief td", "td"},
+}
+
+// Confidence describes how a field was found. Score is 0 when missing.
+type Confidence struct {
+	Score  float64 `json:"score"`
+	Source string  `json:"source"` // "labeled", "pattern", "head" or "missing"
+	Raw    string  `json:"raw,omitempty"`
+}
+
+// Issue is a validation finding; errors make the parse invalid.
+type Issue struct {
+	Field    string `json:"field"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Message  string `json:"message"`
+}
+
+// Report is the per-field confidence and validation outcome of one parse.
+type Report struct {
+	ParserVersion string                `json:"parser_version"`
+	Fields        map[string]Confidence `json:"fields"`
+	Issues        []Issue               `json:"issues"`
+	Valid         bool                  `json:"valid"`
+}
+
+// SectionRating is the rating band a section admits; nil bounds are open.
+type SectionRating struct {
+	Section   string `json:"section"`
+	MinRating *int   `json:"min_rating,omitempty"`
+	MaxRating *int   `json:"max_rating,omitempty"`
+}
+
+// RatingRequirements lists the rating systems an event is rated under and
+// the per-section bands.
+type RatingRequirements struct {
+	RatedBy  []string        `json:"rated_by"`
+	Sections []SectionRating `json:"sections"`
+}
+
+// Tournament holds the parsed fields.
+type Tournament struct {
+	Title              string              `json:"title"`
+	DatesText          string              `json:"dates_text"`
+	StartDate          *time.Time          `json:"start_date"`
+	EndDate            *time.Time          `json:"end_date"`
+	EntryFeeCents      *int                `json:"entry_fee_cents"`
+	TimeControl        string              `json:"time_control"`
+	Sections           []string            `json:"sections"`
+	RatingRequirements *RatingRequirements `json:"rating_requirements"`
+	VenueAddress       string              `json:"venue_address"`
+	City               string              `json:"city"`
+	State              string              `json:"state"`
+	Zipcode            string              `json:"zipcode"`
+	Organizer          string              `json:"organizer"`
+}
+
+// Parse reads an HTML tournament page. now anchors the date sanity checks.
+func Parse(r io.Reader, now time.Time) (*Tournament, *Report, error) {
+	p, err := readPage(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse html: %w", err)
+	}
+	t := &Tournament{}
+	rep := &Report{ParserVersion: Version, Fields: map[string]Confidence{}, Issues: []Issue{}}
+
+	parseTitle(p, t, rep)
+	parseDates(p, t, rep)
+	parseEntryFee(p, t, rep)
+	parseTimeControl(p, t, rep)
+	parseSections(p, t, rep)
+	parseRatings(p, t, rep)
+	parseVenue(p, t, rep)
+	parseOrganizer(p, t, rep)
+
+	validate(t, rep, now)
+	return t, rep, nil
+}
+
+func missing(rep *Report, field string) {
+	rep.Fields[field] = Confidence{Score: 0, Source: "missing"}
+}
+
+func parseTitle(p *page, t *Tournament, rep *Report) {
+	switch {
+	case p.h1 != "":
+		t.Title = p.h1
+		rep.Fields[FieldTitle] = Confidence{Score: 0.9, Source: "head", Raw: p.h1}
+	case p.ogTitle != "":
+		t.Title = p.ogTitle
+		rep.Fields[FieldTitle] = Confidence{Score: 0.8, Source: "head", Raw: p.ogTitle}
+	case p.title != "":
+		// page titles often carry the site name: "Spring Open | US Chess"
+		title := p.title
+		if i := strings.IndexAny(title, "|–"); i > 0 {
+			title = strings.TrimSpace(title[:i])
+		}
+		t.Title = title
+		rep.Fields[FieldTitle] = Confidence{Score: 0.6, Source: "head", Raw: p.title}
+	default:
+		missing(rep, FieldTitle)
+	}
+}
+
+func parseDates(p *page, t *Tournament, rep *Report) {
+	if v, _ := p.labeled(allLabels[FieldDates]...); v != "" {
+		if start, end, ok := eventSpan(findDates(v)); ok {
+			t.DatesText = v
+			t.StartDate, t.EndDate = &start, &end
+			rep.Fields[FieldDates] = Confidence{Score: 0.95, Source: "labeled", Raw: v}
+			return
+		}
+	}
+	// Unlabeled: the first date on the page, less sure if the page lists
+	// several unrelated ones (deadlines, past events).
+	var all []dateSpan
+	for _, line := range p.lines {
+		all = append(all, findDates(line)...)
+	}
+	if len(all) == 0 {
+		missing(rep, FieldDates)
+		return
+	}
+	start, end, _ := eventSpan(all)
+	t.StartDate, t.EndDate = &start, &end
+	score := 0.6
+	distinct := map[time.Time]bool{}
+	for _, d := range all {
+		distinct[d.start] = true
+	}
+	if len(distinct) > 3 {
+		score = 0.4
+	}
+	rep.Fields[FieldDates] = Confidence{Score: score, Source: "pattern", Raw: start.Format("2006-01-02")}
+}
+
+var (
+	moneyRe = regexp.MustCompile(`\$\s?(\d{1,4}(?:,\d{3})?(?:\.\d{2})?)`)
+	freeRe  = regexp.MustCompile(`(?i)\b(free|no entry fee|no fee)\b`)
+)
+
+func cents(s string) (int, bool) {
+	s = strings.ReplaceAll(s, ",", "")
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(f*100 + 0.5), true
+}
+
+func parseEntryFee(p *page, t *Tournament, rep *Report) {
+	if v, _ := p.labeled(allLabels[FieldEntryFee]...); v != "" {
+		// "EF: $60 if rec'd by 3/1, $70 at site" - the first amount is the advance fee
+		if m := moneyRe.FindStringSubmatch(v); m != nil {
+			if c, ok := cents(m[1]); ok {
+				t.EntryFeeCents = &c
+				rep.Fields[FieldEntryFee] = Confidence{Score: 0.9, Source: "labeled", Raw: v}
+				return
+			}
+		}
+		if freeRe.MatchString(v) {
+			zero := 0
+			t.EntryFeeCents = &zero
+			rep.Fields[FieldEntryFee] = Confidence{Score: 0.85, Source: "labeled", Raw: v}
+			return
+		}
+	}
+	for _, line := range p.lines {
+		lower := strings.ToLower(line)
+		if !strings.Contains(lower, "entry") && !strings.Contains(lower, "ef ") {
+			continue
+		}
+		if m := moneyRe.FindStringSubmatch(line); m != nil {
+			if c, ok := cents(m[1]); ok {
+				t.EntryFeeCents = &c
+				rep.Fields[FieldEntryFee] = Confidence{Score: 0.6, Source: "pattern", Raw: line}
+				return
+			}
+		}
+	}
+	missing(rep, FieldEntryFee)
+}
+
+// "G/90;d5", "G/45 +5", "40/90, SD/30 d10", "SD/60 inc30", "90+30"
+var (
+	timeControlRe = regexp.MustCompile(`(?i)\b(?:(?:\d{1,2}/\d{2,3}\s*[,;]?\s*)?(?:G|SD)\s*/\s*\d{1,3}(?:\s*[;,]?\s*(?:d|inc|\+)\s*\d{1,2})?|\d{1,2}/\d{2,3}\s*[,;]\s*(?:G|SD)/\d{1,3})`)
+	plusControlRe = regexp.MustCompile(`\b\d{1,3}\s*\+\s*\d{1,2}\b`)
+)
+
+func uniqueMatches(re *regexp.Regexp, s string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range re.FindAllString(s, -1) {
+		m = strings.Join(strings.Fields(m), " ")
+		if !seen[strings.ToLower(m)] {
+			seen[strings.ToLower(m)] = true
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func parseTimeControl(p *page, t *Tournament, rep *Report) {
+	if v, i := p.labeled(allLabels[FieldTimeControl]...); v != "" {
+		text := strings.Join(append([]string{v}, p.following(i, 3)...), " ")
+		controls := uniqueMatches(timeControlRe, text)
+		if len(controls) == 0 {
+			controls = uniqueMatches(plusControlRe, text)
+		}
+		if len(controls) > 0 {
+			t.TimeControl = strings.Join(controls, "; ")
+			rep.Fields[FieldTimeControl] = Confidence{Score: 0.9, Source: "labeled", Raw: v}
+			return
+		}
+		// labeled but not in a notation we know; keep the text
+		if len(v) > 120 {
+			v = v[:120]
+		}
+		t.TimeControl = v
+		rep.Fields[FieldTimeControl] = Confidence{Score: 0.5, Source: "labeled", Raw: v}
+		return
+	}
+	var controls []string
+	for _, line := range p.lines {
+		controls = append(controls, uniqueMatches(timeControlRe, line)...)
+	}
+	if len(controls) == 0 {
+		missing(rep, FieldTimeControl)
+		return
+	}
+	t.TimeControl = strings.Join(uniqueMatches(timeControlRe, strings.Join(controls, " ; ")), "; ")
+	rep.Fields[FieldTimeControl] = Confidence{Score: 0.7, Source: "pattern", Raw: controls[0]}
+}
+
+var (
+	sectionRe       = regexp.MustCompile(`(?i)\b(open|premier|championship|reserve|booster|novice|scholastic|amateur|U\s?\d{3,4}|under\s+\d{3,4}|\d{3,4}\s?\+|K-\d{1,2}|grades?\s+K?-?\d{1,2}(?:\s*-\s*\d{1,2})?)\b`)
+	ratedSectionRe  = regexp.MustCompile(`(?i)\b(U\s?\d{3,4}|under\s+\d{3,4}|\d{3,4}\s?\+)\b`)
+	underRe         = regexp.MustCompile(`(?i)^(?:U\s?|under\s+)(\d{3,4})$`)
+	overRe          = regexp.MustCompile(`^(\d{3,4})\s?\+$`)
+	ratedByPatterns = map[string]*regexp.Regexp{
+		"US Chess": regexp.MustCompile(`(?i)\b(us\s?chess|uscf)\b[^.]{0,20}\brated\b|\brated\b[^.]{0,20}\b(us\s?chess|uscf)\b|\bdual[- ]rated\b`),
+		"FIDE":     regexp.MustCompile(`(?i)\bfide\b[^.]{0,20}\brated\b|\brated\b[^.]{0,20}\bfide\b|\bdual[- ]rated\b`),
+	}
+)
+
+func normalizeSection(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if m := underRe.FindStringSubmatch(s); m != nil {
+		return "U" + m[1]
+	}
+	if m := overRe.FindStringSubmatch(s); m != nil {
+		return m[1] + "+"
+	}
+	if len(s) > 0 && !strings.ContainsAny(s, "0123456789") {
+		return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+	}
+	return strings.ToUpper(s)
+}
+
+func collectSections(re *regexp.Regexp, text string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range re.FindAllString(text, -1) {
+		n := normalizeSection(m)
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func parseSections(p *page, t *Tournament, rep *Report) {
+	if v, i := p.labeled(allLabels[FieldSections]...); v != "" {
+		text := strings.Join(append([]string{v}, p.following(i, 8)...), " ; ")
+		if secs := collectSections(sectionRe, text); len(secs) > 0 {
+			t.Sections = secs
+			rep.Fields[FieldSections] = Confidence{Score: 0.85, Source: "labeled", Raw: v}
+			return
+		}
+	}
+	// Unlabeled, only rating-band names are distinctive enough ("open" is
+	// everywhere in prose).
+	if secs := collectSections(ratedSectionRe, strings.Join(p.lines, " ; ")); len(secs) > 0 {
+		t.Sections = secs
+		rep.Fields[FieldSections] = Confidence{Score: 0.5, Source: "pattern", Raw: strings.Join(secs, ", ")}
+		return
+	}
+	missing(rep, FieldSections)
+}
+
+func parseRatings(p *page, t *Tournament, rep *Report) {
+	req := &RatingRequirements{RatedBy: []string{}, Sections: []SectionRating{}}
+	full := strings.Join(p.lines, " . ")
+	for _, name := range []string{"US Chess", "FIDE"} {
+		if ratedByPatterns[name].MatchString(full) {
+			req.RatedBy = append(req.RatedBy, name)
+		}
+	}
+	for _, s := range t.Sections {
+		sr := SectionRating{Section: s}
+		if m := underRe.FindStringSubmatch(s); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			max := n - 1
+			sr.MaxRating = &max
+		} else if m := overRe.FindStringSubmatch(s); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			sr.MinRating = &n
+		} else {
+			continue
+		}
+		req.Sections = append(req.Sections, sr)
+	}
+	if len(req.RatedBy) == 0 && len(req.Sections) == 0 {
+		missing(rep, FieldRatings)
+		return
+	}
+	t.RatingRequirements = req
+
+	score, source := 0.6, "pattern"
+	if v, _ := p.labeled(allLabels[FieldRatings]...); v != "" {
+		score, source = 0.85, "labeled"
+	}
+	if len(req.RatedBy) == 0 {
+		// bands only, taken from section names
+		score = rep.Fields[FieldSections].Score * 0.9
+	}
+	rep.Fields[FieldRatings] = Confidence{Score: score, Source: source, Raw: strings.Join(req.RatedBy, ", ")}
+}
+
+var (
+	cityStateZipRe = regexp.MustCompile(`([A-Za-z][A-Za-z .'-]+?),\s*([A-Z]{2})\s+(\d{5})(?:-\d{4})?\b`)
+	streetRe       = regexp.MustCompile(`(?i)\b\d{1,6}\s+[A-Za-z0-9 .'#-]+?\s(?:st|street|ave|avenue|rd|road|blvd|boulevard|dr|drive|ln|lane|way|pkwy|parkway|ct|court|pl|place|hwy|highway|cir|circle|ter|terrace)\b\.?[^,]*,\s*[A-Za-z .'-]+,\s*[A-Z]{2}\s+\d{5}(?:-\d{4})?`)
+)
+
+func setCityStateZip(t *Tournament, addr string) bool {
+	m := cityStateZipRe.FindAllStringSubmatch(addr, -1)
+	if m == nil {
+		return false
+	}
+	last := m[len(m)-1]
+	city := strings.TrimSpace(last[1])
+	// "123 Main St, Springfield" - keep only the part after the street
+	if i := strings.LastIndex(city, ","); i >= 0 {
+		city = strings.TrimSpace(city[i+1:])
+	}
+	t.City, t.State, t.Zipcode = city, last[2], last[3]
+	return true
+}
+
+func parseVenue(p *page, t *Tournament, rep *Report) {
+	if v, i := p.labeled(allLabels[FieldVenueAddress]...); v != "" {
+		addr := v
+		// venue name on one line, street and city on the next ones
+		if !cityStateZipRe.MatchString(addr) {
+			for _, next := range p.following(i, 3) {
+				addr += ", " + next
+				if cityStateZipRe.MatchString(next) {
+					break
+				}
+			}
+		}
+		if setCityStateZip(t, addr) {
+			t.VenueAddress = addr
+			rep.Fields[FieldVenueAddress] = Confidence{Score: 0.85, Source: "labeled", Raw: addr}
+			return
+		}
+		t.VenueAddress = v
+		rep.Fields[FieldVenueAddress] = Confidence{Score: 0.5, Source: "labeled", Raw: v}
+		return
+	}
+	for _, line := range p.lines {
+		if m := streetRe.FindString(line); m != "" {
+			t.VenueAddress = strings.TrimSpace(m)
+			setCityStateZip(t, m)
+			rep.Fields[FieldVenueAddress] = Confidence{Score: 0.65, Source: "pattern", Raw: m}
+			return
+		}
+	}
+	missing(rep, FieldVenueAddress)
+}
+
+func parseOrganizer(p *page, t *Tournament, rep *Report) {
+	if v, _ := p.labeled(allLabels[FieldOrganizer]...); v != "" {
+		if len(v) > 120 {
+			v = v[:120]
+		}
+		t.Organizer = v
+		rep.Fields[FieldOrganizer] = Confidence{Score: 0.8, Source: "labeled", Raw: v}
+		return
+	}
+	missing(rep, FieldOrganizer)
+}
+
+var usStates = map[string]bool{}
+
+func init() {
+	for _, s := range strings.Fields("AL AK AZ AR CA CO CT DE DC FL GA HI ID IL IN IA KS KY LA ME MD MA MI MN MS MO MT NE NV NH NJ NM NY NC ND OH OK OR PA RI SC SD TN TX UT VT VA WA WV WI WY PR VI GU") {
+		usStates[s] = true
+	}
+}
+
+func validate(t *Tournament, rep *Report, now time.Time) {
+	issue := func(field, severity, msg string) {
+		rep.Issues = append(rep.Issues, Issue{Field: field, Severity: severity, Message: msg})
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		issue(FieldTitle, "error", "no title found")
+	}
+	if t.StartDate == nil {
+		issue(FieldDates, "warning", "no event date found")
+	} else {
+		if t.EndDate != nil && t.EndDate.Before(*t.StartDate) {
+			issue(FieldDates, "error", "end date is before start date")
+			t.EndDate = nil
+		}
+		if t.EndDate != nil && t.EndDate.Sub(*t.StartDate) > 14*24*time.Hour {
+			issue(FieldDates, "warning", "event spans more than two weeks")
+		}
+		if t.StartDate.Before(now.AddDate(-1, 0, 0)) {
+			issue(FieldDates, "warning", "start date is more than a year ago")
+		}
+		if t.StartDate.After(now.AddDate(2, 0, 0)) {
+			issue(FieldDates, "warning", "start date is more than two years ahead")
+		}
+	}
+	if t.EntryFeeCents != nil && *t.EntryFeeCents > 100000 {
+		issue(FieldEntryFee, "warning", "entry fee above $1000")
+	}
+	if t.State != "" && !usStates[t.State] {
+		issue(FieldVenueAddress, "warning", "state "+t.State+" is not a US state code")
+		t.State = ""
+	}
+	rep.Valid = true
+	for _, i := range rep.Issues {
+		if i.Severity == "error" {
+			rep.Valid = false
+		}
+	}
+}
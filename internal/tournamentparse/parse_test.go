@@ -0,0 +1,214 @@
+package tournamentparse
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func intp(n int) *int { return &n }
+
+func date(y int, m time.Month, d int) *time.Time {
+	t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestParseFixtures(t *testing.T) {
+	now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		file    string
+		want    Tournament
+		sources map[string]string // Report.Fields[field].Source
+	}{
+		{
+			file: "tla_table.html",
+			want: Tournament{
+				Title:         "2026 Spring Scholastic Open",
+				DatesText:     "Sat-Sun, March 14-15, 2026",
+				StartDate:     date(2026, time.March, 14),
+				EndDate:       date(2026, time.March, 15),
+				EntryFeeCents: intp(6000),
+				TimeControl:   "G/90;d5; G/45;d5",
+				Sections:      []string{"Open", "U1600", "U1200", "U800"},
+				RatingRequirements: &RatingRequirements{
+					RatedBy: []string{"US Chess", "FIDE"},
+					Sections: []SectionRating{
+						{Section: "U1600", MaxRating: intp(1599)},
+						{Section: "U1200", MaxRating: intp(1199)},
+						{Section: "U800", MaxRating: intp(799)},
+					},
+				},
+				VenueAddress: "Holiday Inn Conference Center, 1200 Commerce Pkwy, Edison, NJ 08837",
+				City:         "Edison",
+				State:        "NJ",
+				Zipcode:      "08837",
+				Organizer:    "Garden State Chess Club",
+			},
+			sources: map[string]string{
+				FieldTitle:        "head",
+				FieldDates:        "labeled",
+				FieldEntryFee:     "labeled",
+				FieldTimeControl:  "labeled",
+				FieldSections:     "labeled",
+				FieldRatings:      "pattern",
+				FieldVenueAddress: "labeled",
+				FieldOrganizer:    "labeled",
+			},
+		},
+		{
+			file: "labels_next_line.html",
+			want: Tournament{
+				Title:         "Tuesday Night Rapid",
+				DatesText:     "2026-11-03",
+				StartDate:     date(2026, time.November, 3),
+				EndDate:       date(2026, time.November, 3),
+				EntryFeeCents: intp(0),
+				TimeControl:   "15+10",
+				Sections:      []string{"Open"},
+				VenueAddress:  "Main Library, Room B, 300 Library Way, Austin, TX 78701",
+				City:          "Austin",
+				State:         "TX",
+				Zipcode:       "78701",
+			},
+			sources: map[string]string{
+				FieldTitle:        "head",
+				FieldDates:        "labeled",
+				FieldEntryFee:     "labeled",
+				FieldTimeControl:  "labeled",
+				FieldSections:     "labeled",
+				FieldRatings:      "missing",
+				FieldVenueAddress: "labeled",
+				FieldOrganizer:    "missing",
+			},
+		},
+		{
+			// No labels at all; a date range across the new year.
+			file: "prose_page.html",
+			want: Tournament{
+				Title:         "Bay Area Winter Quads",
+				StartDate:     date(2026, time.December, 30),
+				EndDate:       date(2027, time.January, 2),
+				EntryFeeCents: intp(4500),
+				TimeControl:   "40/90, SD/30 d10",
+				RatingRequirements: &RatingRequirements{
+					RatedBy:  []string{"US Chess", "FIDE"},
+					Sections: []SectionRating{},
+				},
+				VenueAddress: "455 Oak Street, San Mateo, CA 94401",
+				City:         "San Mateo",
+				State:        "CA",
+				Zipcode:      "94401",
+			},
+			sources: map[string]string{
+				FieldTitle:        "head",
+				FieldDates:        "pattern",
+				FieldEntryFee:     "labeled",
+				FieldTimeControl:  "pattern",
+				FieldSections:     "missing",
+				FieldRatings:      "pattern",
+				FieldVenueAddress: "pattern",
+				FieldOrganizer:    "missing",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, rep, err := Parse(f, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check := func(field string, got, want interface{}) {
+				t.Helper()
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %#v, want %#v", field, got, want)
+				}
+			}
+			check("Title", got.Title, tc.want.Title)
+			check("DatesText", got.DatesText, tc.want.DatesText)
+			check("TimeControl", got.TimeControl, tc.want.TimeControl)
+			check("Sections", got.Sections, tc.want.Sections)
+			check("RatingRequirements", got.RatingRequirements, tc.want.RatingRequirements)
+			check("VenueAddress", got.VenueAddress, tc.want.VenueAddress)
+			check("City", got.City, tc.want.City)
+			check("State", got.State, tc.want.State)
+			check("Zipcode", got.Zipcode, tc.want.Zipcode)
+			check("Organizer", got.Organizer, tc.want.Organizer)
+
+			if got.EntryFeeCents == nil || *got.EntryFeeCents != *tc.want.EntryFeeCents {
+				t.Errorf("EntryFeeCents = %v, want %d", got.EntryFeeCents, *tc.want.EntryFeeCents)
+			}
+			if got.StartDate == nil || !got.StartDate.Equal(*tc.want.StartDate) {
+				t.Errorf("StartDate = %v, want %v", got.StartDate, tc.want.StartDate)
+			}
+			if got.EndDate == nil || !got.EndDate.Equal(*tc.want.EndDate) {
+				t.Errorf("EndDate = %v, want %v", got.EndDate, tc.want.EndDate)
+			}
+
+			if !rep.Valid || len(rep.Issues) != 0 {
+				t.Errorf("report invalid: %+v", rep.Issues)
+			}
+			if rep.ParserVersion != Version {
+				t.Errorf("ParserVersion = %q", rep.ParserVersion)
+			}
+			for field, want := range tc.sources {
+				c, ok := rep.Fields[field]
+				if !ok {
+					t.Errorf("%s missing from report", field)
+					continue
+				}
+				if c.Source != want {
+					t.Errorf("%s source = %q, want %q", field, c.Source, want)
+				}
+				if (want == "missing") != (c.Score == 0) {
+					t.Errorf("%s score = %v with source %q", field, c.Score, c.Source)
+				}
+			}
+		})
+	}
+}
+
+// A truncated page with no title and only scattered dates parses, but as
+// an invalid, low-confidence report nothing should be applied from.
+func TestParseMalformedPage(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "malformed_page.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, rep, err := Parse(f, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Valid {
+		t.Error("report is valid, want invalid")
+	}
+	hasTitleError := false
+	for _, i := range rep.Issues {
+		if i.Field == FieldTitle && i.Severity == "error" {
+			hasTitleError = true
+		}
+	}
+	if !hasTitleError {
+		t.Errorf("issues = %+v, want a title error", rep.Issues)
+	}
+	if got.Title != "" {
+		t.Errorf("Title = %q, want none", got.Title)
+	}
+	if c := rep.Fields[FieldDates]; c.Source != "pattern" || c.Score >= 0.5 {
+		t.Errorf("dates = %+v, want a low-scored pattern match", c)
+	}
+	for _, field := range []string{FieldTitle, FieldEntryFee, FieldVenueAddress, FieldOrganizer} {
+		if c := rep.Fields[field]; c.Score >= 0.5 {
+			t.Errorf("%s = %+v, want a low score", field, c)
+		}
+	}
+}
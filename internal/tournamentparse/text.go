@@ -0,0 +1,171 @@
+package tournamentparse
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// page is the text content of an HTML document as trimmed, non-empty lines,
+// plus the few head elements the parsers care about.
+type page struct {
+	title   string // <title>
+	ogTitle string // <meta property="og:title">
+	h1      string
+	lines   []string
+}
+
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "fieldset": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "tbody": true, "thead": true,
+	"tr": true, "ul": true,
+}
+
+var skipTags = map[string]bool{"script": true, "style": true, "noscript": true, "template": true, "svg": true}
+
+// readPage flattens the document into lines: block elements start a new line
+// and table cells on one row are joined with ": " so "Dates | March 1" rows
+// read like "Dates: March 1".
+func readPage(r io.Reader) (*page, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &page{}
+	var cur strings.Builder
+	flush := func() {
+		line := strings.Join(strings.Fields(cur.String()), " ")
+		line = strings.Trim(line, " :")
+		if line != "" {
+			p.lines = append(p.lines, line)
+		}
+		cur.Reset()
+	}
+
+	var walk func(n *html.Node, inHead bool)
+	walk = func(n *html.Node, inHead bool) {
+		if n.Type == html.ElementNode {
+			tag := n.Data
+			if skipTags[tag] {
+				return
+			}
+			switch tag {
+			case "head":
+				inHead = true
+			case "title":
+				p.title = strings.TrimSpace(nodeText(n))
+				return
+			case "meta":
+				if attr(n, "property") == "og:title" {
+					p.ogTitle = strings.TrimSpace(attr(n, "content"))
+				}
+				return
+			case "h1":
+				if p.h1 == "" {
+					p.h1 = strings.Join(strings.Fields(nodeText(n)), " ")
+				}
+			case "td", "th":
+				if cur.Len() > 0 {
+					cur.WriteString(": ")
+				}
+			}
+			if blockTags[tag] {
+				flush()
+			}
+		}
+		if n.Type == html.TextNode && !inHead {
+			cur.WriteString(n.Data)
+			cur.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inHead)
+		}
+		if n.Type == html.ElementNode && blockTags[n.Data] {
+			flush()
+		}
+	}
+	walk(doc, false)
+	flush()
+	return p, nil
+}
+
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// labeled finds the first line starting with one of the labels and returns
+// its value. When the label stands alone on its line the following line is
+// used. The returned index is the line the value came from.
+func (p *page) labeled(labels ...string) (string, int) {
+	for i, line := range p.lines {
+		lower := strings.ToLower(line)
+		for _, l := range labels {
+			if !strings.HasPrefix(lower, l) {
+				continue
+			}
+			rest := line[len(l):]
+			// the label must end at a word boundary
+			if rest != "" && !strings.ContainsAny(rest[:1], " :-–\t") {
+				continue
+			}
+			rest = strings.TrimLeft(rest, " :-–\t")
+			if rest != "" {
+				return rest, i
+			}
+			if i+1 < len(p.lines) {
+				return p.lines[i+1], i + 1
+			}
+		}
+	}
+	return "", -1
+}
+
+// isLabelLine reports whether a line starts with any known field label.
+func isLabelLine(line string) bool {
+	lower := strings.ToLower(line)
+	for _, group := range allLabels {
+		for _, l := range group {
+			if strings.HasPrefix(lower, l) && (len(lower) == len(l) || strings.ContainsAny(lower[len(l):len(l)+1], " :-–\t")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// following returns up to max lines after index i, stopping at the next label.
+func (p *page) following(i, max int) []string {
+	var out []string
+	for j := i + 1; j < len(p.lines) && len(out) < max; j++ {
+		if isLabelLine(p.lines[j]) {
+			break
+		}
+		out = append(out, p.lines[j])
+	}
+	return out
+}
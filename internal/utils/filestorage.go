@@ -57,3 +57,13 @@ func (fs *FileStorage) DeleteFile(urlSuffix string) error {
 	}
 	return nil
 }
+
+// Open opens the file at <BaseDir>/<urlSuffix> for reading.
+func (fs *FileStorage) Open(urlSuffix string) (io.ReadCloser, error) {
+	fullPath := filepath.Join(fs.BaseDir, filepath.FromSlash(urlSuffix))
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", fullPath, err)
+	}
+	return f, nil
+}
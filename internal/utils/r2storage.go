@@ -84,4 +84,30 @@ func (rs *R2Storage) DeleteFile(objectKey string) error {
 	return nil
 }
 
+// Open streams the object with the given key from R2.
+func (rs *R2Storage) Open(objectKey string) (io.ReadCloser, error) {
+	out, err := rs.client.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(rs.bucketName),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from R2: %w", err)
+	}
+	return out.Body, nil
+}
+
+// SignedURL returns a presigned GET URL for the object, valid for ttl. The
+// response is served as an attachment named downloadName.
+func (rs *R2Storage) SignedURL(objectKey, downloadName string, ttl time.Duration) (string, error) {
+	req, err := s3.NewPresignClient(rs.client).PresignGetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket:                     aws.String(rs.bucketName),
+		Key:                        aws.String(objectKey),
+		ResponseContentDisposition: aws.String(mime.FormatMediaType("attachment", map[string]string{"filename": downloadName})),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign R2 url: %w", err)
+	}
+	return req.URL, nil
+}
+
 
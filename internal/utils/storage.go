@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"io"
+	"time"
+)
+
+// Storage is the object store behind uploaded files. Keys are the
+// url_suffix values returned by SaveFile and kept in the DB.
+type Storage interface {
+	SaveFile(subDir, originalFilename string, reader io.Reader) (string, error)
+	DeleteFile(key string) error
+	Open(key string) (io.ReadCloser, error)
+}
+
+// URLSigner is implemented by stores that can hand out time-limited direct
+// download links, so file bytes don't have to pass through the API.
+type URLSigner interface {
+	SignedURL(key, downloadName string, ttl time.Duration) (string, error)
+}
+
+var (
+	_ Storage   = (*R2Storage)(nil)
+	_ URLSigner = (*R2Storage)(nil)
+	_ Storage   = (*FileStorage)(nil)
+)
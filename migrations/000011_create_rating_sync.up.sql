@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS chess_account_syncs (
+    user_id        VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source         VARCHAR(16) NOT NULL,
+    username       TEXT NOT NULL DEFAULT '',
+    games_cursor   TIMESTAMPTZ,
+    last_synced_at TIMESTAMPTZ,
+    last_error     TEXT,
+    updated_at     TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, source)
+);
+
+CREATE TABLE IF NOT EXISTS rating_snapshots (
+    id           SERIAL PRIMARY KEY,
+    user_id      VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source       VARCHAR(16) NOT NULL,
+    time_control VARCHAR(16) NOT NULL,
+    rating       INT NOT NULL,
+    games        INT NOT NULL DEFAULT 0,
+    recorded_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rating_snapshots_series ON rating_snapshots(user_id, source, time_control);
+CREATE INDEX IF NOT EXISTS idx_rating_snapshots_recorded_at ON rating_snapshots(recorded_at);
+
+CREATE TABLE IF NOT EXISTS external_games (
+    id              SERIAL PRIMARY KEY,
+    user_id         VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source          VARCHAR(16) NOT NULL,
+    game_id         TEXT NOT NULL,
+    played_at       TIMESTAMPTZ NOT NULL,
+    time_control    VARCHAR(16),
+    rated           BOOLEAN DEFAULT FALSE,
+    color           VARCHAR(5),
+    result          VARCHAR(8),
+    opponent_name   TEXT,
+    opponent_rating INT,
+    url             TEXT,
+    created_at      TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT idx_external_games_source_game UNIQUE (source, game_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_games_user_id ON external_games(user_id);
+CREATE INDEX IF NOT EXISTS idx_external_games_played_at ON external_games(played_at);
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS official_ratings (
+    id              SERIAL PRIMARY KEY,
+    user_id         VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    federation      VARCHAR(8) NOT NULL,
+    period          DATE NOT NULL,
+    external_id     VARCHAR(16),
+    listed_name     TEXT,
+    standard_rating INT,
+    rapid_rating    INT,
+    blitz_rating    INT,
+    title           VARCHAR(8),
+    source_file     TEXT,
+    imported_at     TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT idx_official_ratings_period UNIQUE (user_id, federation, period)
+);
+
+CREATE TABLE IF NOT EXISTS rating_id_mismatches (
+    id          SERIAL PRIMARY KEY,
+    user_id     VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    federation  VARCHAR(8) NOT NULL,
+    field       VARCHAR(16) NOT NULL,
+    external_id VARCHAR(16),
+    expected    TEXT,
+    actual      TEXT,
+    detected_at TIMESTAMPTZ DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    CONSTRAINT idx_rating_id_mismatch_field UNIQUE (user_id, federation, field)
+);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS tournament_registrations (
+    id                 SERIAL PRIMARY KEY,
+    tournament_id      INT NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    student_id         VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status             TEXT NOT NULL,
+    section            TEXT DEFAULT '',
+    score              NUMERIC(4,1),
+    place              INT,
+    rating_change      INT,
+    result_notes       TEXT,
+    result_recorded_by VARCHAR(10),
+    result_recorded_at TIMESTAMPTZ,
+    created_by         VARCHAR(10),
+    updated_by         VARCHAR(10),
+    created_at         TIMESTAMPTZ DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT idx_tournament_registrations_student UNIQUE (tournament_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_registrations_student_id ON tournament_registrations(student_id);
@@ -0,0 +1,17 @@
+-- Offline ZIP centroid dataset (GeoNames postal codes, US.txt)
+CREATE TABLE IF NOT EXISTS zip_centroids (
+    zipcode    VARCHAR(10) PRIMARY KEY,
+    place_name TEXT DEFAULT '',
+    state      VARCHAR(2) DEFAULT '',
+    latitude   DOUBLE PRECISION NOT NULL,
+    longitude  DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_zip_centroids_place ON zip_centroids(place_name, state);
+
+-- Tournament location, geocoded from its zipcode or city/state
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS zipcode VARCHAR(10) DEFAULT '';
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_lat_lng ON tournaments(latitude, longitude);
@@ -0,0 +1,12 @@
+-- Lease/claim job queue columns for zipcode_scrape_scopes
+ALTER TABLE zipcode_scrape_scopes ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0;
+ALTER TABLE zipcode_scrape_scopes ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE zipcode_scrape_scopes ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE zipcode_scrape_scopes ADD COLUMN IF NOT EXISTS last_attempt_at TIMESTAMPTZ;
+ALTER TABLE zipcode_scrape_scopes ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+ALTER TABLE zipcode_scrape_scopes ADD COLUMN IF NOT EXISTS lease_owner TEXT;
+ALTER TABLE zipcode_scrape_scopes ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ;
+ALTER TABLE zipcode_scrape_scopes ADD COLUMN IF NOT EXISTS last_result_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_zipcode_scrape_scopes_due
+    ON zipcode_scrape_scopes(priority DESC, last_scraped ASC NULLS FIRST);
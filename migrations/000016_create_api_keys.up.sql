@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           SERIAL PRIMARY KEY,
+    name         TEXT NOT NULL,
+    prefix       VARCHAR(32) NOT NULL UNIQUE,
+    key_hash     VARCHAR(64) NOT NULL,
+    scopes       JSONB DEFAULT '[]',
+    created_by   VARCHAR(10),
+    expires_at   TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip TEXT,
+    rotated_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- which key last submitted a tournament
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS api_key_id INT REFERENCES api_keys(id) ON DELETE SET NULL;
@@ -0,0 +1,18 @@
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ DEFAULT NOW();
+
+CREATE TABLE IF NOT EXISTS tournament_revisions (
+    id            SERIAL PRIMARY KEY,
+    tournament_id INT NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    change_type   TEXT NOT NULL,
+    date_changed  BOOLEAN NOT NULL DEFAULT FALSE,
+    changes       JSONB DEFAULT '{}',
+    zipcode       VARCHAR(10),
+    distance      INT,
+    api_key_id    INT REFERENCES api_keys(id) ON DELETE SET NULL,
+    detected_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_revisions_tournament_id ON tournament_revisions(tournament_id);
+CREATE INDEX IF NOT EXISTS idx_tournament_revisions_detected_at ON tournament_revisions(detected_at);
@@ -0,0 +1,27 @@
+-- Structured details parsed server-side from captured event pages
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS end_date DATE;
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS entry_fee_cents INT;
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS time_control TEXT;
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS sections JSONB;
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS rating_requirements JSONB;
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS venue_address TEXT;
+
+CREATE TABLE IF NOT EXISTS tournament_pages (
+    id             SERIAL PRIMARY KEY,
+    url_path       TEXT NOT NULL,
+    tournament_id  INT REFERENCES tournaments(id) ON DELETE SET NULL,
+    html           TEXT,
+    content_hash   VARCHAR(64),
+    parser_version TEXT,
+    parsed         JSONB,
+    report         JSONB,
+    valid          BOOLEAN NOT NULL DEFAULT FALSE,
+    applied        BOOLEAN NOT NULL DEFAULT FALSE,
+    api_key_id     INT REFERENCES api_keys(id) ON DELETE SET NULL,
+    captured_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_pages_url_path ON tournament_pages(url_path);
+CREATE INDEX IF NOT EXISTS idx_tournament_pages_tournament_id ON tournament_pages(tournament_id);
+CREATE INDEX IF NOT EXISTS idx_tournament_pages_valid ON tournament_pages(valid);
@@ -0,0 +1,23 @@
+-- Named note visibility levels (were 1-4) and per-note share lists
+ALTER TABLE notes ALTER COLUMN visibility DROP DEFAULT;
+ALTER TABLE notes ALTER COLUMN visibility TYPE TEXT USING (
+    CASE visibility::text
+        WHEN '1' THEN 'admin_only'
+        WHEN '2' THEN 'mentors'
+        WHEN '3' THEN 'staff'
+        WHEN '4' THEN 'student_and_staff'
+        ELSE 'admin_only'
+    END
+);
+ALTER TABLE notes ALTER COLUMN visibility SET DEFAULT 'student_and_staff';
+
+CREATE TABLE IF NOT EXISTS note_shares (
+    id         SERIAL PRIMARY KEY,
+    note_id    INT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id    VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    shared_by  VARCHAR(10),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_note_shares_note_user ON note_shares(note_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_note_shares_user_id ON note_shares(user_id);
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS note_revisions (
+    id              SERIAL PRIMARY KEY,
+    note_id         INT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    revision        INT NOT NULL,
+    action          TEXT NOT NULL,
+    restored_from   INT,
+    title           TEXT,
+    description     TEXT,
+    primary_tag     TEXT,
+    tags            JSONB,
+    is_starred      BOOLEAN,
+    additional_info JSONB,
+    visibility      TEXT,
+    edited_by       VARCHAR(10),
+    edited_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_note_revisions_note_rev ON note_revisions(note_id, revision);
+
+CREATE TABLE IF NOT EXISTS lesson_plan_revisions (
+    id             SERIAL PRIMARY KEY,
+    lesson_plan_id INT NOT NULL REFERENCES lesson_plans(id) ON DELETE CASCADE,
+    revision       INT NOT NULL,
+    action         TEXT NOT NULL,
+    restored_from  INT,
+    title          TEXT,
+    description    JSONB,
+    start_date     TIMESTAMPTZ,
+    end_date       TIMESTAMPTZ,
+    result         TEXT,
+    active         BOOLEAN,
+    edited_by      VARCHAR(10),
+    edited_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lesson_plan_revisions_plan_rev ON lesson_plan_revisions(lesson_plan_id, revision);
@@ -0,0 +1,39 @@
+-- Structured lesson plans: ordered curriculum items linked to attendances
+ALTER TABLE lesson_plans ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS lesson_plan_items (
+    id             SERIAL PRIMARY KEY,
+    lesson_plan_id INT NOT NULL REFERENCES lesson_plans(id) ON DELETE CASCADE,
+    position       INT NOT NULL,
+    topic          TEXT NOT NULL,
+    objective      TEXT,
+    target_date    DATE,
+    status         TEXT NOT NULL DEFAULT 'pending',
+    completed_at   TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_lesson_plan_items_lesson_plan_id ON lesson_plan_items(lesson_plan_id);
+CREATE INDEX IF NOT EXISTS idx_lesson_plan_items_deleted_at ON lesson_plan_items(deleted_at);
+
+CREATE TABLE IF NOT EXISTS lesson_plan_item_sessions (
+    lesson_plan_item_id INT NOT NULL REFERENCES lesson_plan_items(id) ON DELETE CASCADE,
+    attendance_id       INT NOT NULL REFERENCES attendances(id) ON DELETE CASCADE,
+    session_date        DATE,
+    linked_by           VARCHAR(10),
+    created_at          TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (lesson_plan_item_id, attendance_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lesson_plan_item_sessions_attendance_id ON lesson_plan_item_sessions(attendance_id);
+
+-- Existing plans: one pending item per description line
+INSERT INTO lesson_plan_items (lesson_plan_id, position, topic, status, created_at, updated_at)
+SELECT lp.id, t.ord, t.topic, 'pending', NOW(), NOW()
+FROM lesson_plans lp
+CROSS JOIN LATERAL jsonb_array_elements_text(
+    CASE WHEN jsonb_typeof(lp.description) = 'array' THEN lp.description ELSE '[]'::jsonb END
+) WITH ORDINALITY AS t(topic, ord)
+WHERE NOT EXISTS (SELECT 1 FROM lesson_plan_items i WHERE i.lesson_plan_id = lp.id);
@@ -0,0 +1,59 @@
+-- Academy curriculum library: levels > modules > topics, and per-student mastery
+CREATE TABLE IF NOT EXISTS curriculum_levels (
+    id          SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT,
+    position    INT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_curriculum_levels_name ON curriculum_levels(name);
+CREATE INDEX IF NOT EXISTS idx_curriculum_levels_deleted_at ON curriculum_levels(deleted_at);
+
+CREATE TABLE IF NOT EXISTS curriculum_modules (
+    id          SERIAL PRIMARY KEY,
+    level_id    INT NOT NULL REFERENCES curriculum_levels(id),
+    title       TEXT NOT NULL,
+    description TEXT,
+    position    INT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_curriculum_modules_level_id ON curriculum_modules(level_id);
+CREATE INDEX IF NOT EXISTS idx_curriculum_modules_deleted_at ON curriculum_modules(deleted_at);
+
+CREATE TABLE IF NOT EXISTS curriculum_topics (
+    id          SERIAL PRIMARY KEY,
+    module_id   INT NOT NULL REFERENCES curriculum_modules(id),
+    title       TEXT NOT NULL,
+    objectives  JSONB,
+    resources   JSONB,
+    position    INT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_curriculum_topics_module_id ON curriculum_topics(module_id);
+CREATE INDEX IF NOT EXISTS idx_curriculum_topics_deleted_at ON curriculum_topics(deleted_at);
+
+CREATE TABLE IF NOT EXISTS topic_masteries (
+    student_id  VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    topic_id    INT NOT NULL REFERENCES curriculum_topics(id),
+    mastered_at TIMESTAMPTZ,
+    marked_by   VARCHAR(10),
+    note        TEXT,
+    PRIMARY KEY (student_id, topic_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_topic_masteries_topic_id ON topic_masteries(topic_id);
+
+ALTER TABLE user_details ADD COLUMN IF NOT EXISTS curriculum_level_id INT REFERENCES curriculum_levels(id);
+CREATE INDEX IF NOT EXISTS idx_user_details_curriculum_level_id ON user_details(curriculum_level_id);
+
+ALTER TABLE lesson_plan_items ADD COLUMN IF NOT EXISTS topic_id INT REFERENCES curriculum_topics(id);
+CREATE INDEX IF NOT EXISTS idx_lesson_plan_items_topic_id ON lesson_plan_items(topic_id);
@@ -0,0 +1,55 @@
+-- Structured assessments: admin-defined rubrics scored per student or coach
+CREATE TABLE IF NOT EXISTS rubrics (
+    id          SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    type        TEXT NOT NULL,
+    description TEXT,
+    min_score   INT NOT NULL DEFAULT 1,
+    max_score   INT NOT NULL DEFAULT 5,
+    active      BOOLEAN DEFAULT TRUE,
+    created_by  VARCHAR(10),
+    created_at  TIMESTAMPTZ DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rubrics_name ON rubrics(name);
+CREATE INDEX IF NOT EXISTS idx_rubrics_type ON rubrics(type);
+
+CREATE TABLE IF NOT EXISTS rubric_criterions (
+    id          SERIAL PRIMARY KEY,
+    rubric_id   INT NOT NULL REFERENCES rubrics(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    description TEXT,
+    position    INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_rubric_criterions_rubric_id ON rubric_criterions(rubric_id);
+
+CREATE TABLE IF NOT EXISTS assessments (
+    id          SERIAL PRIMARY KEY,
+    rubric_id   INT NOT NULL REFERENCES rubrics(id),
+    type        TEXT NOT NULL,
+    subject_id  VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assessor_id VARCHAR(10) NOT NULL REFERENCES users(id),
+    assessed_on DATE NOT NULL,
+    comment     TEXT,
+    created_at  TIMESTAMPTZ DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_assessments_rubric_id ON assessments(rubric_id);
+CREATE INDEX IF NOT EXISTS idx_assessments_subject_id ON assessments(subject_id);
+CREATE INDEX IF NOT EXISTS idx_assessments_assessor_id ON assessments(assessor_id);
+CREATE INDEX IF NOT EXISTS idx_assessments_assessed_on ON assessments(assessed_on);
+CREATE INDEX IF NOT EXISTS idx_assessments_deleted_at ON assessments(deleted_at);
+
+CREATE TABLE IF NOT EXISTS assessment_scores (
+    assessment_id INT NOT NULL REFERENCES assessments(id) ON DELETE CASCADE,
+    criterion_id  INT NOT NULL REFERENCES rubric_criterions(id),
+    score         INT NOT NULL,
+    comment       TEXT,
+    PRIMARY KEY (assessment_id, criterion_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assessment_scores_criterion_id ON assessment_scores(criterion_id);
@@ -0,0 +1,15 @@
+-- Files attached to notes; the bytes live in object storage under storage_key
+CREATE TABLE IF NOT EXISTS note_attachments (
+    id           SERIAL PRIMARY KEY,
+    note_id      INT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    file_name    TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes   BIGINT NOT NULL,
+    storage_key  TEXT NOT NULL,
+    caption      TEXT,
+    metadata     JSONB,
+    uploaded_by  VARCHAR(10),
+    created_at   TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_attachments_note_id ON note_attachments(note_id);
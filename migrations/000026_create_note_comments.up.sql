@@ -0,0 +1,28 @@
+-- Threaded comments on notes and the @mentions inbox
+CREATE TABLE IF NOT EXISTS note_comments (
+    id         SERIAL PRIMARY KEY,
+    note_id    INT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    parent_id  INT REFERENCES note_comments(id),
+    author_id  VARCHAR(10) NOT NULL,
+    body       TEXT NOT NULL,
+    edited_at  TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_comments_note_id ON note_comments(note_id);
+CREATE INDEX IF NOT EXISTS idx_note_comments_parent_id ON note_comments(parent_id);
+CREATE INDEX IF NOT EXISTS idx_note_comments_deleted_at ON note_comments(deleted_at);
+
+CREATE TABLE IF NOT EXISTS note_mentions (
+    comment_id INT NOT NULL REFERENCES note_comments(id) ON DELETE CASCADE,
+    user_id    VARCHAR(10) NOT NULL,
+    note_id    INT NOT NULL,
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_mentions_user_id ON note_mentions(user_id);
+CREATE INDEX IF NOT EXISTS idx_note_mentions_note_id ON note_mentions(note_id);
@@ -0,0 +1,25 @@
+-- In-app notifications and per-user delivery preferences
+CREATE TABLE IF NOT EXISTS notifications (
+    id         SERIAL PRIMARY KEY,
+    user_id    VARCHAR(10) NOT NULL,
+    type       TEXT NOT NULL,
+    title      TEXT NOT NULL,
+    body       TEXT,
+    data       JSONB,
+    actor_id   VARCHAR(10),
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_type ON notifications(type);
+CREATE INDEX IF NOT EXISTS idx_notifications_read_at ON notifications(read_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id        VARCHAR(10) PRIMARY KEY,
+    channels       JSONB,
+    event_channels JSONB,
+    webhook_url    TEXT,
+    updated_at     TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,30 @@
+-- Class reminders: per-occurrence cancellations, holidays, and the sent log
+-- that keeps a reminder from going out twice
+CREATE TABLE IF NOT EXISTS class_cancellations (
+    id          SERIAL PRIMARY KEY,
+    schedule_id INT NOT NULL REFERENCES class_schedules(id) ON DELETE CASCADE,
+    date        DATE NOT NULL,
+    reason      TEXT,
+    created_by  VARCHAR(10),
+    created_at  TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_class_cancellations_schedule_date ON class_cancellations(schedule_id, date);
+
+CREATE TABLE IF NOT EXISTS holidays (
+    id         SERIAL PRIMARY KEY,
+    date       DATE NOT NULL,
+    name       TEXT NOT NULL,
+    created_by VARCHAR(10),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_holidays_date ON holidays(date);
+
+CREATE TABLE IF NOT EXISTS class_reminders (
+    schedule_id  INT NOT NULL,
+    occurs_at    TIMESTAMPTZ NOT NULL,
+    recipient_id VARCHAR(10) NOT NULL,
+    sent_at      TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (schedule_id, occurs_at, recipient_id)
+);
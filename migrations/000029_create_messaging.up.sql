@@ -0,0 +1,59 @@
+-- Messaging: academy chat groups with tracked membership, and the log of
+-- templated messages sent through the provider
+CREATE TABLE IF NOT EXISTS messaging_groups (
+    id          SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    provider    TEXT NOT NULL DEFAULT 'whatsapp',
+    invite_link TEXT,
+    description TEXT,
+    created_by  VARCHAR(10),
+    created_at  TIMESTAMPTZ DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messaging_groups_name ON messaging_groups(name);
+
+CREATE TABLE IF NOT EXISTS messaging_group_members (
+    group_id INT NOT NULL REFERENCES messaging_groups(id) ON DELETE CASCADE,
+    user_id  VARCHAR(10) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_by VARCHAR(10),
+    added_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_messaging_group_members_user_id ON messaging_group_members(user_id);
+
+-- Users flagged by hand before groups were tracked go into one group so
+-- the flag survives the next membership change
+INSERT INTO messaging_groups (name, provider, description)
+SELECT 'Academy (imported)', 'whatsapp', 'Users marked as added to WhatsApp before groups were tracked'
+WHERE EXISTS (SELECT 1 FROM user_details WHERE added_in_whatsapp)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO messaging_group_members (group_id, user_id)
+SELECT g.id, d.user_id
+FROM user_details d, messaging_groups g
+WHERE d.added_in_whatsapp AND g.name = 'Academy (imported)'
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS outbound_messages (
+    id                  SERIAL PRIMARY KEY,
+    provider            TEXT NOT NULL,
+    provider_message_id TEXT,
+    user_id             VARCHAR(10),
+    to_phone            TEXT,
+    template            TEXT NOT NULL,
+    params              JSONB,
+    status              TEXT NOT NULL,
+    error               TEXT,
+    sent_at             TIMESTAMPTZ,
+    delivered_at        TIMESTAMPTZ,
+    read_at             TIMESTAMPTZ,
+    created_by          VARCHAR(10),
+    created_at          TIMESTAMPTZ DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbound_messages_provider_message_id ON outbound_messages(provider_message_id);
+CREATE INDEX IF NOT EXISTS idx_outbound_messages_user_id ON outbound_messages(user_id);
+CREATE INDEX IF NOT EXISTS idx_outbound_messages_status ON outbound_messages(status);
@@ -0,0 +1,40 @@
+-- Outbound webhooks: admin-configured endpoints and the delivery log used
+-- for retries and replays
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id         SERIAL PRIMARY KEY,
+    name       TEXT NOT NULL,
+    url        TEXT NOT NULL,
+    secret     TEXT NOT NULL,
+    events     JSONB DEFAULT '[]',
+    active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(10),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               SERIAL PRIMARY KEY,
+    endpoint_id      INT NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event_id         VARCHAR(32) NOT NULL,
+    event_type       TEXT NOT NULL,
+    payload          JSONB,
+    status           TEXT NOT NULL DEFAULT 'pending',
+    attempts         INT NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ,
+    last_status_code INT,
+    last_error       TEXT,
+    delivered_at     TIMESTAMPTZ,
+    replay_of        INT,
+    created_at       TIMESTAMPTZ DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_id ON webhook_deliveries(endpoint_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries(event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries(status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries(next_attempt_at);
+
+-- An event is queued for an endpoint once; replays are deliberate copies
+-- and exempt. Handlers rely on this to skip an event they already queued.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_event
+    ON webhook_deliveries(endpoint_id, event_id) WHERE replay_of IS NULL;
@@ -0,0 +1,26 @@
+-- Transactional outbox: side effects written with the change that caused
+-- them and carried out by a background dispatcher
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id              SERIAL PRIMARY KEY,
+    kind            TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    payload         JSONB,
+    status          TEXT NOT NULL DEFAULT 'pending',
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    last_error      TEXT,
+    processed_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_messages_idempotency_key ON outbox_messages(idempotency_key);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_kind ON outbox_messages(kind);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_status ON outbox_messages(status);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_next_attempt_at ON outbox_messages(next_attempt_at);
+
+-- Events can now be handled more than once; queue each for an endpoint once
+-- (replays are deliberate copies and exempt). Also created by 000030 on new
+-- databases.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_event
+    ON webhook_deliveries(endpoint_id, event_id) WHERE replay_of IS NULL;
@@ -0,0 +1,30 @@
+-- Background jobs: shared schedule state and run history
+CREATE TABLE IF NOT EXISTS job_states (
+    name             VARCHAR(64) PRIMARY KEY,
+    schedule         TEXT NOT NULL,
+    paused           BOOLEAN NOT NULL DEFAULT FALSE,
+    run_requested    BOOLEAN NOT NULL DEFAULT FALSE,
+    run_requested_by VARCHAR(10),
+    next_run_at      TIMESTAMPTZ,
+    last_run_at      TIMESTAMPTZ,
+    last_status      TEXT,
+    last_error       TEXT,
+    updated_at       TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id           SERIAL PRIMARY KEY,
+    job_name     VARCHAR(64) NOT NULL,
+    trigger      TEXT NOT NULL,
+    triggered_by VARCHAR(10),
+    status       TEXT NOT NULL,
+    instance     TEXT,
+    started_at   TIMESTAMPTZ,
+    finished_at  TIMESTAMPTZ,
+    duration_ms  BIGINT,
+    error        TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_name ON job_runs(job_name);
+CREATE INDEX IF NOT EXISTS idx_job_runs_status ON job_runs(status);
+CREATE INDEX IF NOT EXISTS idx_job_runs_started_at ON job_runs(started_at);
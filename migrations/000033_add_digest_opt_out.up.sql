@@ -0,0 +1,2 @@
+-- Weekly digest emails can be turned off per user
+ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS digest_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,8 @@
+-- Attendance review outcome, for verification rejection rates
+ALTER TABLE attendances ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ;
+ALTER TABLE attendances ADD COLUMN IF NOT EXISTS rejected_at TIMESTAMPTZ;
+ALTER TABLE attendances ADD COLUMN IF NOT EXISTS rejection_reason TEXT;
+ALTER TABLE attendances ADD COLUMN IF NOT EXISTS reviewed_by VARCHAR(10);
+
+CREATE INDEX IF NOT EXISTS idx_attendances_coach_date ON attendances(coach_id, date);
+CREATE INDEX IF NOT EXISTS idx_notes_created_by_created_at ON notes(created_by, created_at);
@@ -0,0 +1,3 @@
+-- A game between two of our students is stored once for each of them
+ALTER TABLE external_games DROP CONSTRAINT IF EXISTS idx_external_games_source_game;
+ALTER TABLE external_games ADD CONSTRAINT idx_external_games_user_source_game UNIQUE (user_id, source, game_id);
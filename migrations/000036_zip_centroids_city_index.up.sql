@@ -0,0 +1,2 @@
+-- The city fallback of tournament geocoding matches on LOWER(place_name)
+CREATE INDEX IF NOT EXISTS idx_zip_centroids_city ON zip_centroids(LOWER(place_name), state);
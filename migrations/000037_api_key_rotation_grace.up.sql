@@ -0,0 +1,6 @@
+-- A rotated key's previous secret keeps working until previous_expires_at
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS previous_prefix VARCHAR(32);
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS previous_key_hash VARCHAR(64);
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS previous_expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_previous_prefix ON api_keys(previous_prefix);
@@ -0,0 +1,3 @@
+-- Plans deactivated before archived_at existed count as archived since their
+-- last update
+UPDATE lesson_plans SET archived_at = updated_at WHERE NOT active AND archived_at IS NULL;
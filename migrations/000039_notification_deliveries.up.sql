@@ -0,0 +1,11 @@
+-- Events can be handled more than once; notify each user of an event once
+-- per channel
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS event_id VARCHAR(32);
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    event_id   VARCHAR(32) NOT NULL,
+    user_id    VARCHAR(10) NOT NULL,
+    channel    VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (event_id, user_id, channel)
+);
@@ -0,0 +1,10 @@
+-- Each weekly class names its coach, so reminders don't guess one for
+-- students with several
+ALTER TABLE class_schedules ADD COLUMN IF NOT EXISTS coach_id VARCHAR(10);
+CREATE INDEX IF NOT EXISTS idx_class_schedules_coach_id ON class_schedules(coach_id);
+
+-- Unambiguous for students with a single coach; the rest are left for an
+-- admin to set
+UPDATE class_schedules cs SET coach_id = r.coach_id
+FROM (SELECT user_id, MIN(coach_id) AS coach_id FROM relations GROUP BY user_id HAVING COUNT(*) = 1) r
+WHERE r.user_id = cs.student_id AND cs.coach_id IS NULL;